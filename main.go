@@ -17,42 +17,51 @@ limitations under the License.
 package main
 
 import (
+	"bytes"
+	"crypto/tls"
 	"fmt"
+	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
 	"net/http/pprof"
 	"os"
 	"strconv"
-	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/openshift/origin/pkg/util/proc"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
-	"k8s.io/client-go/informers"
+	"golang.org/x/net/context"
 	clientset "k8s.io/client-go/kubernetes"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	"k8s.io/client-go/tools/clientcmd"
 
+	"k8s.io/kube-state-metrics/pkg/auth"
+	"k8s.io/kube-state-metrics/pkg/builder"
 	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
+	"k8s.io/kube-state-metrics/pkg/fixtures"
+	"k8s.io/kube-state-metrics/pkg/heartbeat"
 	"k8s.io/kube-state-metrics/pkg/metrics"
 	"k8s.io/kube-state-metrics/pkg/options"
 	"k8s.io/kube-state-metrics/pkg/version"
 )
 
 const (
-	metricsPath = "/metrics"
-	healthzPath = "/healthz"
+	metricsPath          = "/metrics"
+	metricsWatchPath     = "/metrics/watch"
+	healthzPath          = "/healthz"
+	livezPath            = "/livez"
+	readyzPath           = "/readyz"
+	debugObjectPath      = "/debug/object"
+	debugCardinalityPath = "/debug/cardinality"
+
+	// metricsWatchInterval is the interval in which metrics watches check
+	// for changes.
+	metricsWatchInterval = time.Second
 )
 
-// promLogger implements promhttp.Logger
-type promLogger struct{}
-
-func (pl promLogger) Println(v ...interface{}) {
-	glog.Error(v)
-}
-
 func main() {
 	opts := options.NewOptions()
 	opts.AddFlags()
@@ -106,9 +115,37 @@ func main() {
 		glog.Infof("A metric blacklist has been configured. The following metrics will not be exposed: %s.", opts.MetricBlacklist.String())
 	}
 
+	if opts.Pod != "" {
+		opts.Shard, err = options.ShardFromPodName(opts.Pod)
+		if err != nil {
+			glog.Fatalf("Failed to derive the shard from the pod name: %v", err)
+		}
+	}
+	if (opts.TLSCertFile == "") != (opts.TLSPrivateKeyFile == "") {
+		glog.Fatal("--tls-cert-file and --tls-private-key-file have to be set together.")
+	}
+	if opts.TLSClientCAFile != "" && opts.TLSCertFile == "" {
+		glog.Fatal("--tls-client-ca-file requires --tls-cert-file, as client certificates are only sent over HTTPS.")
+	}
+	if opts.MaxLabelValueLength < 0 || (opts.MaxLabelValueLength > 0 && opts.MaxLabelValueLength < kcollectors.MinLabelValueLength) {
+		glog.Fatalf("Invalid --max-label-value-length %d, it has to be zero or at least %d to leave room for the hash of truncated values.", opts.MaxLabelValueLength, kcollectors.MinLabelValueLength)
+	}
+	if opts.TotalShards < 1 || opts.Shard < 0 || opts.Shard >= opts.TotalShards {
+		glog.Fatalf("Invalid shard %d of %d shards, the shard has to be between 0 and --total-shards minus one.", opts.Shard, opts.TotalShards)
+	}
+	if opts.TotalShards > 1 {
+		glog.Infof("Using shard %d of %d shards", opts.Shard, opts.TotalShards)
+	}
+
 	proc.StartReaper()
 
-	kubeClient, err := createKubeClient(opts.Apiserver, opts.Kubeconfig)
+	var kubeClient clientset.Interface
+	if opts.Fixtures != "" {
+		glog.Infof("Using fixtures from %s instead of an apiserver", opts.Fixtures)
+		kubeClient, err = fixtures.NewClientset(opts.Fixtures)
+	} else {
+		kubeClient, err = createKubeClient(opts.Apiserver, opts.Kubeconfig)
+	}
 	if err != nil {
 		glog.Fatalf("Failed to create client: %v", err)
 	}
@@ -116,13 +153,58 @@ func main() {
 	ksmMetricsRegistry := prometheus.NewRegistry()
 	ksmMetricsRegistry.Register(kcollectors.ResourcesPerScrapeMetric)
 	ksmMetricsRegistry.Register(kcollectors.ScrapeErrorTotalMetric)
+	ksmMetricsRegistry.Register(kcollectors.ObjectErrorTotalMetric)
+	ksmMetricsRegistry.Register(kcollectors.ClockSkewMetric)
+	ksmMetricsRegistry.Register(metrics.SeriesFilteredTotalMetric)
+	ksmMetricsRegistry.Register(metrics.DeprecatedAPIUsageMetric)
+	ksmMetricsRegistry.Register(metrics.ListWatchErrorsTotalMetric)
+	ksmMetricsRegistry.Register(metrics.CollectorGenerateDurationMetric)
+	ksmMetricsRegistry.Register(metrics.NewBuildInfoCollector(version.GetVersion()))
+	ksmMetricsRegistry.Register(metrics.NewShardCollector(opts.Shard, opts.TotalShards, kcollectors.InformerSyncTracker.ObjectCounts))
+	ksmMetricsRegistry.Register(metrics.NewInformerCollector(kcollectors.InformerSyncTracker.SyncStatus, kcollectors.InformerSyncTracker.LastSyncTimes))
 	ksmMetricsRegistry.Register(prometheus.NewProcessCollector(os.Getpid(), ""))
 	ksmMetricsRegistry.Register(prometheus.NewGoCollector())
-	go telemetryServer(ksmMetricsRegistry, opts.TelemetryHost, opts.TelemetryPort)
+	var pathAuthorizer *auth.PathAuthorizer
+	if opts.DelegatedAuth {
+		glog.Infof("Only serving the metrics server paths and the pprof endpoints to callers allowed to get them, caching reviews for %s", opts.DelegatedAuthCacheTTL)
+		pathAuthorizer = auth.NewPathAuthorizer(kubeClient, opts.DelegatedAuthCacheTTL)
+	}
+	go telemetryServer(ksmMetricsRegistry, pathAuthorizer, opts.TelemetryHost, opts.TelemetryPort, opts)
+
+	collectorGatherers, err := builder.NewBuilder(kubeClient, opts).
+		WithNamespaces(namespaces).
+		WithEnabledCollectors(collectors).
+		Build()
+	if err != nil {
+		glog.Fatalf("Failed to register collectors: %v", err)
+	}
 
-	registry := prometheus.NewRegistry()
-	registerCollectors(registry, kubeClient, collectors, namespaces, opts)
-	metricsServer(metrics.FilteredGatherer(registry, opts.MetricWhitelist, opts.MetricBlacklist), opts.Host, opts.Port)
+	if opts.HeartbeatURL != "" {
+		instance := opts.HeartbeatInstance
+		if instance == "" {
+			instance, err = os.Hostname()
+			if err != nil {
+				glog.Fatalf("Failed to determine heartbeat instance: %v", err)
+			}
+		}
+		glog.Infof("Sending heartbeats to %s every %s", opts.HeartbeatURL, opts.HeartbeatInterval)
+		sender := heartbeat.NewSender(opts.HeartbeatURL, instance, version.Release,
+			kcollectors.InformerSyncTracker.LastSyncTimes, opts.HeartbeatInterval)
+		sender.Shard, sender.TotalShards = opts.Shard, opts.TotalShards
+		sender.MetricsURL, sender.Labels = opts.HeartbeatMetricsURL, opts.HeartbeatLabels
+		go sender.Run(opts.HeartbeatInterval, context.Background().Done())
+	}
+
+	wrapGatherer, err := builder.GathererWrapper(kubeClient, opts)
+	if err != nil {
+		glog.Fatalf("Failed to configure metrics: %v", err)
+	}
+	var authorizer *auth.NamespaceAuthorizer
+	if opts.NamespaceIsolation {
+		glog.Infof("Only serving the metrics of the namespaces callers can get pods in, caching reviews for %s", opts.NamespaceIsolationCacheTTL)
+		authorizer = auth.NewNamespaceAuthorizer(kubeClient, opts.NamespaceIsolationCacheTTL)
+	}
+	metricsServer(collectorGatherers, wrapGatherer, authorizer, pathAuthorizer, opts.Host, opts.Port, opts)
 }
 
 func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface, error) {
@@ -134,6 +216,9 @@ func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface,
 	config.UserAgent = version.GetVersion().String()
 	config.AcceptContentTypes = "application/vnd.kubernetes.protobuf,application/json"
 	config.ContentType = "application/vnd.kubernetes.protobuf"
+	config.WrapTransport = func(rt http.RoundTripper) http.RoundTripper {
+		return metrics.ListWatchErrorTransport(metrics.DeprecationWarningTransport(rt))
+	}
 
 	kubeClient, err := clientset.NewForConfig(config)
 	if err != nil {
@@ -155,7 +240,7 @@ func createKubeClient(apiserver string, kubeconfig string) (clientset.Interface,
 	return kubeClient, nil
 }
 
-func telemetryServer(registry prometheus.Gatherer, host string, port int) {
+func telemetryServer(registry prometheus.Gatherer, pathAuthorizer *auth.PathAuthorizer, host string, port int, opts *options.Options) {
 	// Address to listen on for web interface and telemetry
 	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
 
@@ -164,7 +249,20 @@ func telemetryServer(registry prometheus.Gatherer, host string, port int) {
 	mux := http.NewServeMux()
 
 	// Add metricsPath
-	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: promLogger{}}))
+	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: metrics.PromLogger{}}))
+	if opts.EnablePprof {
+		// The profiles reveal as much as the metrics server, so they require
+		// the same bearer token, if configured.
+		authorize := func(h http.Handler) http.Handler { return h }
+		if pathAuthorizer != nil {
+			authorize = pathAuthorizer.Handler
+		}
+		mux.Handle("/debug/pprof/", authorize(http.HandlerFunc(pprof.Index)))
+		mux.Handle("/debug/pprof/cmdline", authorize(http.HandlerFunc(pprof.Cmdline)))
+		mux.Handle("/debug/pprof/profile", authorize(http.HandlerFunc(pprof.Profile)))
+		mux.Handle("/debug/pprof/symbol", authorize(http.HandlerFunc(pprof.Symbol)))
+		mux.Handle("/debug/pprof/trace", authorize(http.HandlerFunc(pprof.Trace)))
+	}
 	// Add index
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
@@ -177,30 +275,76 @@ func telemetryServer(registry prometheus.Gatherer, host string, port int) {
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+	log.Fatal(newServer(listenAddress, mux, opts).ListenAndServe())
 }
 
-func metricsServer(registry prometheus.Gatherer, host string, port int) {
+func metricsServer(collectorGatherers metrics.CollectorGatherers, wrapGatherer func(prometheus.Gatherer) prometheus.Gatherer, authorizer *auth.NamespaceAuthorizer, pathAuthorizer *auth.PathAuthorizer, host string, port int, opts *options.Options) {
 	// Address to listen on for web interface and telemetry
 	listenAddress := net.JoinHostPort(host, strconv.Itoa(port))
 
 	glog.Infof("Starting metrics server: %s", listenAddress)
 
+	srv := newServer(listenAddress, nil, opts)
+	// protect requires a verified client certificate for the paths serving
+	// the inventory of the cluster, if configured. Health checks stay open.
+	protect := func(h http.Handler) http.Handler { return h }
+	if opts.TLSClientCAFile != "" {
+		tlsConfig, err := metrics.ClientCertTLSConfig(opts.TLSClientCAFile)
+		if err != nil {
+			glog.Fatalf("Failed to configure client certificate authentication: %v", err)
+		}
+		srv.TLSConfig = tlsConfig
+		protect = metrics.ClientCertHandler
+	}
+	// authorize additionally requires a bearer token allowed to get the path,
+	// if configured. The debug endpoints have their own token instead.
+	authorize := protect
+	if pathAuthorizer != nil {
+		authorize = func(h http.Handler) http.Handler { return protect(pathAuthorizer.Handler(h)) }
+	}
+
 	mux := http.NewServeMux()
 
-	mux.Handle("/debug/pprof/", http.HandlerFunc(pprof.Index))
-	mux.Handle("/debug/pprof/cmdline", http.HandlerFunc(pprof.Cmdline))
-	mux.Handle("/debug/pprof/profile", http.HandlerFunc(pprof.Profile))
-	mux.Handle("/debug/pprof/symbol", http.HandlerFunc(pprof.Symbol))
-	mux.Handle("/debug/pprof/trace", http.HandlerFunc(pprof.Trace))
+	mux.Handle("/debug/pprof/", authorize(http.HandlerFunc(pprof.Index)))
+	mux.Handle("/debug/pprof/cmdline", authorize(http.HandlerFunc(pprof.Cmdline)))
+	mux.Handle("/debug/pprof/profile", authorize(http.HandlerFunc(pprof.Profile)))
+	mux.Handle("/debug/pprof/symbol", authorize(http.HandlerFunc(pprof.Symbol)))
+	mux.Handle("/debug/pprof/trace", authorize(http.HandlerFunc(pprof.Trace)))
 
 	// Add metricsPath
-	mux.Handle(metricsPath, promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: promLogger{}}))
-	// Add healthzPath
-	mux.HandleFunc(healthzPath, func(w http.ResponseWriter, r *http.Request) {
-		w.WriteHeader(200)
-		w.Write([]byte("ok"))
-	})
+	mux.Handle(metricsPath, authorize(builder.MetricsHandler(collectorGatherers, wrapGatherer, authorizer, opts)))
+	// Add metricsWatchPath
+	if opts.MetricsWatch {
+		watchHandlerFor := func(allowed func(namespace string) bool) http.Handler {
+			g := wrapGatherer(collectorGatherers.Gatherer())
+			if allowed != nil {
+				g = metrics.NamespaceFilteredGatherer(g, allowed)
+			}
+			return metrics.WatchHandler(g, kcollectors.InformerSyncTracker.Generation, metricsWatchInterval)
+		}
+		if authorizer != nil {
+			mux.Handle(metricsWatchPath, authorize(authorizer.Handler(watchHandlerFor)))
+		} else {
+			mux.Handle(metricsWatchPath, authorize(watchHandlerFor(nil)))
+		}
+	}
+	// Add debugObjectPath and debugCardinalityPath
+	if opts.DebugTokenFile != "" {
+		token, err := ioutil.ReadFile(opts.DebugTokenFile)
+		if err != nil {
+			glog.Fatalf("Failed to read debug token: %v", err)
+		}
+		if len(bytes.TrimSpace(token)) == 0 {
+			glog.Fatalf("Debug token file %s is empty", opts.DebugTokenFile)
+		}
+		debugToken := string(bytes.TrimSpace(token))
+		mux.Handle(debugObjectPath, protect(metrics.BearerTokenHandler(metrics.ObjectHandler(collectorGatherers), debugToken)))
+		mux.Handle(debugCardinalityPath, protect(metrics.BearerTokenHandler(metrics.CardinalityHandler(wrapGatherer(collectorGatherers.Gatherer())), debugToken)))
+	}
+	// Add healthzPath, livezPath and readyzPath
+	mux.Handle(healthzPath, metrics.OKHandler())
+	mux.Handle(livezPath, metrics.OKHandler())
+	mux.Handle(readyzPath, metrics.ReadyzHandler(kcollectors.InformerSyncTracker.SyncStatus, metrics.ListWatchFailures.FailingSince, opts.ReadinessFailureThreshold))
 	// Add index
 	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
 		w.Write([]byte(`<html>
@@ -210,33 +354,32 @@ func metricsServer(registry prometheus.Gatherer, host string, port int) {
 			 <ul>
              <li><a href='` + metricsPath + `'>metrics</a></li>
              <li><a href='` + healthzPath + `'>healthz</a></li>
+             <li><a href='` + livezPath + `'>livez</a></li>
+             <li><a href='` + readyzPath + `'>readyz</a></li>
 			 </ul>
              </body>
              </html>`))
 	})
-	log.Fatal(http.ListenAndServe(listenAddress, mux))
+	srv.Handler = mux
+	if opts.TLSCertFile != "" {
+		log.Fatal(srv.ListenAndServeTLS(opts.TLSCertFile, opts.TLSPrivateKeyFile))
+	}
+	log.Fatal(srv.ListenAndServe())
 }
 
-// registerCollectors creates and starts informers and initializes and
-// registers metrics for collection.
-func registerCollectors(registry prometheus.Registerer, kubeClient clientset.Interface, enabledCollectors options.CollectorSet, namespaces options.NamespaceList, opts *options.Options) {
-	informerFactories := []informers.SharedInformerFactory{}
-	for _, ns := range namespaces {
-		informerFactories = append(
-			informerFactories,
-			informers.NewSharedInformerFactoryWithOptions(
-				kubeClient, 0, informers.WithNamespace(ns),
-			),
-		)
-	}
-	activeCollectors := []string{}
-	for c := range enabledCollectors {
-		f, ok := kcollectors.AvailableCollectors[c]
-		if ok {
-			f(registry, informerFactories, opts)
-			activeCollectors = append(activeCollectors, c)
-		}
+// newServer returns an http.Server configured with the server flags.
+func newServer(addr string, handler http.Handler, opts *options.Options) *http.Server {
+	srv := &http.Server{
+		Addr:           addr,
+		Handler:        handler,
+		ReadTimeout:    opts.ServerReadTimeout,
+		WriteTimeout:   opts.ServerWriteTimeout,
+		IdleTimeout:    opts.ServerIdleTimeout,
+		MaxHeaderBytes: opts.ServerMaxHeaderBytes,
 	}
-
-	glog.Infof("Active collectors: %s", strings.Join(activeCollectors, ","))
+	if opts.ServerDisableHTTP2 {
+		// A non-nil, empty TLSNextProto map disables HTTP/2.
+		srv.TLSNextProto = map[string]func(*http.Server, *tls.Conn, http.Handler){}
+	}
+	return srv
 }
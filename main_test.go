@@ -17,21 +17,131 @@ limitations under the License.
 package main
 
 import (
+	"flag"
+	"io/ioutil"
 	"net/http/httptest"
+	"path/filepath"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
+	"k8s.io/kube-state-metrics/pkg/builder"
+	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
+	"k8s.io/kube-state-metrics/pkg/fixtures"
+	"k8s.io/kube-state-metrics/pkg/metrics"
 	"k8s.io/kube-state-metrics/pkg/options"
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/fake"
 
-	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+var update = flag.Bool("update", false, "update the golden files of the fixture tests")
+
+// TestFixtures runs kube-state-metrics against a fake clientset seeded with
+// the manifests in tests/fixtures/manifests and compares the /metrics output
+// with the golden files in tests/fixtures. Run it with -update to rewrite the
+// golden files after intended changes.
+func TestFixtures(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Golden string
+		Opts   func(*options.Options)
+	}{
+		{
+			Desc:   "all metrics",
+			Golden: "default.golden",
+			Opts:   func(*options.Options) {},
+		},
+		{
+			Desc:   "metric whitelist",
+			Golden: "whitelist.golden",
+			Opts: func(o *options.Options) {
+				o.MetricWhitelist = options.MetricSet{"kube_pod_status_phase": struct{}{}, "kube_deployment_status_replicas_available": struct{}{}}
+			},
+		},
+		{
+			Desc:   "lite mode",
+			Golden: "lite.golden",
+			Opts: func(o *options.Options) {
+				o.Lite = true
+			},
+		},
+	}
+
+	for _, test := range tests {
+		kubeClient, err := fixtures.NewClientset(filepath.Join("tests", "fixtures", "manifests"))
+		if err != nil {
+			t.Fatalf("loading fixtures failed: %v", err)
+		}
+
+		opts := options.NewOptions()
+		test.Opts(opts)
+		collectors := options.CollectorSet{"namespaces": struct{}{}, "deployments": struct{}{}, "services": struct{}{}, "pods": struct{}{}}
+		collectorGatherers, err := builder.NewBuilder(kubeClient, opts).WithEnabledCollectors(collectors).Build()
+		if err != nil {
+			t.Fatalf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+		}
+		wrapGatherer, err := builder.GathererWrapper(kubeClient, opts)
+		if err != nil {
+			t.Fatalf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+		}
+		handler := builder.MetricsHandler(collectorGatherers, wrapGatherer, nil, opts)
+
+		for deadline := time.Now().Add(10 * time.Second); !kcollectors.InformerSyncTracker.HasSynced(); {
+			if time.Now().After(deadline) {
+				t.Fatal("informers did not sync")
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest("GET", "http://localhost:8080/metrics", nil))
+		got := dropTimeDependentFamilies(w.Body.String())
+
+		golden := filepath.Join("tests", "fixtures", test.Golden)
+		if *update {
+			if err := ioutil.WriteFile(golden, []byte(got), 0644); err != nil {
+				t.Fatalf("writing golden file failed: %v", err)
+			}
+			continue
+		}
+		want, err := ioutil.ReadFile(golden)
+		if err != nil {
+			t.Fatalf("reading golden file failed: %v", err)
+		}
+		if got != string(want) {
+			t.Errorf("Test error for Desc: %s. /metrics output does not match %s; want:\n%s\ngot:\n%s", test.Desc, golden, want, got)
+		}
+	}
+}
+
+// timeDependentFamilies are the metric families whose values depend on the
+// time of the scrape rather than only on the fixtures.
+var timeDependentFamilies = []string{"kube_pending_pods_age_seconds"}
+
+// dropTimeDependentFamilies removes the lines of the timeDependentFamilies
+// from the text exposition of metrics.
+func dropTimeDependentFamilies(metrics string) string {
+	var kept []string
+	for _, line := range strings.SplitAfter(metrics, "\n") {
+		dependent := false
+		for _, name := range timeDependentFamilies {
+			if strings.Contains(line, " "+name+" ") || strings.HasPrefix(line, name) {
+				dependent = true
+				break
+			}
+		}
+		if !dependent {
+			kept = append(kept, line)
+		}
+	}
+	return strings.Join(kept, "")
+}
+
 func BenchmarkKubeStateMetrics(t *testing.B) {
 	kubeClient := fake.NewSimpleClientset()
 
@@ -43,9 +153,14 @@ func BenchmarkKubeStateMetrics(t *testing.B) {
 	collectors := options.DefaultCollectors
 	namespaces := options.DefaultNamespaces
 
-	registry := prometheus.NewRegistry()
-	registerCollectors(registry, kubeClient, collectors, namespaces, opts)
-	handler := promhttp.HandlerFor(registry, promhttp.HandlerOpts{ErrorLog: promLogger{}})
+	collectorGatherers, err := builder.NewBuilder(kubeClient, opts).
+		WithNamespaces(namespaces).
+		WithEnabledCollectors(collectors).
+		Build()
+	if err != nil {
+		t.Fatalf("registering collectors failed: %v", err)
+	}
+	handler := promhttp.HandlerFor(collectorGatherers.Gatherer(), promhttp.HandlerOpts{ErrorLog: metrics.PromLogger{}})
 
 	req := httptest.NewRequest("GET", "http://localhost:8080/metrics", nil)
 
@@ -0,0 +1,196 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package auth authenticates and authorizes requests to kube-state-metrics
+// against the Kubernetes API, with TokenReviews and SubjectAccessReviews.
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// cacheSize is the maximum number of cached reviews per kind.
+const cacheSize = 4096
+
+// reviewer authenticates bearer tokens and reviews the access of their users,
+// caching both for a fixed duration, so that scrapes don't put load on the
+// apiserver.
+type reviewer struct {
+	client    clientset.Interface
+	ttl       time.Duration
+	users     *cache.LRUExpireCache
+	decisions *cache.LRUExpireCache
+}
+
+// decisionKey identifies a cached SubjectAccessReview, either of the pods of
+// a namespace or of a non-resource path.
+type decisionKey struct {
+	token     [sha256.Size]byte
+	namespace string
+	path      string
+}
+
+func newReviewer(client clientset.Interface, ttl time.Duration) *reviewer {
+	return &reviewer{
+		client:    client,
+		ttl:       ttl,
+		users:     cache.NewLRUExpireCache(cacheSize),
+		decisions: cache.NewLRUExpireCache(cacheSize),
+	}
+}
+
+// Authenticate returns the user a bearer token belongs to, or nil if the
+// token is not valid.
+func (a *reviewer) Authenticate(token string) (*authenticationv1.UserInfo, error) {
+	key := sha256.Sum256([]byte(token))
+	if user, ok := a.users.Get(key); ok {
+		return user.(*authenticationv1.UserInfo), nil
+	}
+
+	review, err := a.client.AuthenticationV1().TokenReviews().Create(&authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reviewing token failed: %v", err)
+	}
+	var user *authenticationv1.UserInfo
+	if review.Status.Authenticated {
+		user = &review.Status.User
+	}
+	a.users.Add(key, user, a.ttl)
+	return user, nil
+}
+
+// NamespaceAuthorizer authenticates bearer tokens and authorizes their users
+// to read the metrics of a namespace if they can get the pods in it.
+type NamespaceAuthorizer struct {
+	*reviewer
+}
+
+// NewNamespaceAuthorizer returns a NamespaceAuthorizer caching reviews for
+// ttl.
+func NewNamespaceAuthorizer(client clientset.Interface, ttl time.Duration) *NamespaceAuthorizer {
+	return &NamespaceAuthorizer{reviewer: newReviewer(client, ttl)}
+}
+
+// CanGetPods returns whether the user of a bearer token can get the pods in
+// the namespace, or in all namespaces if it is empty.
+func (a *NamespaceAuthorizer) CanGetPods(token string, user *authenticationv1.UserInfo, namespace string) (bool, error) {
+	key := decisionKey{token: sha256.Sum256([]byte(token)), namespace: namespace}
+	allowed, err := a.review(key, user, authorizationv1.SubjectAccessReviewSpec{
+		ResourceAttributes: &authorizationv1.ResourceAttributes{
+			Namespace: namespace,
+			Verb:      "get",
+			Resource:  "pods",
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("reviewing access of %s to namespace %q failed: %v", user.Username, namespace, err)
+	}
+	return allowed, nil
+}
+
+// review returns whether user has the access described by spec, caching the
+// decision under key.
+func (a *reviewer) review(key decisionKey, user *authenticationv1.UserInfo, spec authorizationv1.SubjectAccessReviewSpec) (bool, error) {
+	if allowed, ok := a.decisions.Get(key); ok {
+		return allowed.(bool), nil
+	}
+	extra := map[string]authorizationv1.ExtraValue{}
+	for k, v := range user.Extra {
+		extra[k] = authorizationv1.ExtraValue(v)
+	}
+	spec.User = user.Username
+	spec.UID = user.UID
+	spec.Groups = user.Groups
+	spec.Extra = extra
+	review, err := a.client.AuthorizationV1().SubjectAccessReviews().Create(&authorizationv1.SubjectAccessReview{Spec: spec})
+	if err != nil {
+		return false, err
+	}
+	a.decisions.Add(key, review.Status.Allowed, a.ttl)
+	return review.Status.Allowed, nil
+}
+
+// Handler authenticates the bearer token of every request and passes it on
+// to the handler returned by handlerFor. Users which can get pods in all
+// namespaces get the handler for a nil function, all others the handler for
+// a function reporting whether they can get the pods in a namespace.
+func (a *NamespaceAuthorizer) Handler(handlerFor func(allowed func(namespace string) bool) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, user, ok := a.authenticateRequest(w, r)
+		if !ok {
+			return
+		}
+
+		all, err := a.CanGetPods(token, user, "")
+		if err != nil {
+			glog.Errorf("authorizing request failed: %v", err)
+			http.Error(w, "authorization failed", http.StatusInternalServerError)
+			return
+		}
+		if all {
+			handlerFor(nil).ServeHTTP(w, r)
+			return
+		}
+		handlerFor(func(namespace string) bool {
+			allowed, err := a.CanGetPods(token, user, namespace)
+			if err != nil {
+				glog.Errorf("authorizing request failed: %v", err)
+				return false
+			}
+			return allowed
+		}).ServeHTTP(w, r)
+	})
+}
+
+// authenticateRequest returns the bearer token of a request and its user. If
+// the request can't be authenticated, it answers it and returns false.
+func (a *reviewer) authenticateRequest(w http.ResponseWriter, r *http.Request) (string, *authenticationv1.UserInfo, bool) {
+	auth := r.Header.Get("Authorization")
+	if !strings.HasPrefix(auth, "Bearer ") {
+		unauthorized(w)
+		return "", nil, false
+	}
+	token := strings.TrimPrefix(auth, "Bearer ")
+
+	user, err := a.Authenticate(token)
+	if err != nil {
+		glog.Errorf("authenticating request failed: %v", err)
+		http.Error(w, "authentication failed", http.StatusInternalServerError)
+		return "", nil, false
+	}
+	if user == nil {
+		unauthorized(w)
+		return "", nil, false
+	}
+	return token, user, true
+}
+
+func unauthorized(w http.ResponseWriter) {
+	w.Header().Set("WWW-Authenticate", "Bearer")
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// newFakeClient returns a clientset authenticating the tokens "admin-token"
+// and "team-a-token", where admin can get pods in all namespaces and the
+// /metrics path, and team-a only pods in the team-a namespace. reviews counts
+// the reviews created.
+func newFakeClient(reviews *int) *fake.Clientset {
+	users := map[string]string{"admin-token": "admin", "team-a-token": "team-a"}
+	client := fake.NewSimpleClientset()
+	client.PrependReactor("create", "tokenreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+		if user, ok := users[review.Spec.Token]; ok {
+			review.Status.Authenticated = true
+			review.Status.User = authenticationv1.UserInfo{Username: user}
+		}
+		return true, review, nil
+	})
+	client.PrependReactor("create", "subjectaccessreviews", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		*reviews++
+		review := action.(k8stesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+		if attrs := review.Spec.NonResourceAttributes; attrs != nil {
+			review.Status.Allowed = attrs.Verb == "get" && attrs.Path == "/metrics" && review.Spec.User == "admin"
+			return true, review, nil
+		}
+		attrs := review.Spec.ResourceAttributes
+		review.Status.Allowed = attrs.Verb == "get" && attrs.Resource == "pods" &&
+			(review.Spec.User == "admin" || attrs.Namespace == review.Spec.User)
+		return true, review, nil
+	})
+	return client
+}
+
+func TestHandler(t *testing.T) {
+	tests := []struct {
+		Desc          string
+		Authorization string
+		WantCode      int
+		WantAll       bool
+		WantAllowed   map[string]bool
+	}{
+		{
+			Desc:     "no token",
+			WantCode: http.StatusUnauthorized,
+		},
+		{
+			Desc:          "invalid token",
+			Authorization: "Bearer unknown",
+			WantCode:      http.StatusUnauthorized,
+		},
+		{
+			Desc:          "cluster-wide access",
+			Authorization: "Bearer admin-token",
+			WantCode:      http.StatusOK,
+			WantAll:       true,
+		},
+		{
+			Desc:          "namespace access",
+			Authorization: "Bearer team-a-token",
+			WantCode:      http.StatusOK,
+			WantAllowed:   map[string]bool{"team-a": true, "team-b": false},
+		},
+	}
+
+	for _, test := range tests {
+		var reviews int
+		a := NewNamespaceAuthorizer(newFakeClient(&reviews), time.Minute)
+		h := a.Handler(func(allowed func(namespace string) bool) http.Handler {
+			if (allowed == nil) != test.WantAll {
+				t.Errorf("Test error for Desc: %s. Want cluster-wide access %t.", test.Desc, test.WantAll)
+			}
+			for namespace, want := range test.WantAllowed {
+				if got := allowed(namespace); got != want {
+					t.Errorf("Test error for Desc: %s. Want access to %s %t, got %t.", test.Desc, namespace, want, got)
+				}
+			}
+			return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+		})
+
+		r := httptest.NewRequest("GET", "/metrics", nil)
+		if test.Authorization != "" {
+			r.Header.Set("Authorization", test.Authorization)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.WantCode {
+			t.Errorf("Test error for Desc: %s. Want code %d, got %d.", test.Desc, test.WantCode, w.Code)
+		}
+	}
+}
+
+func TestNamespaceAuthorizerCaches(t *testing.T) {
+	var reviews int
+	a := NewNamespaceAuthorizer(newFakeClient(&reviews), time.Minute)
+
+	for i := 0; i < 3; i++ {
+		user, err := a.Authenticate("team-a-token")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if user == nil || user.Username != "team-a" {
+			t.Fatalf("want user team-a, got %v", user)
+		}
+		allowed, err := a.CanGetPods("team-a-token", user, "team-a")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !allowed {
+			t.Fatal("want team-a to be allowed to get pods in team-a")
+		}
+	}
+	if reviews != 2 {
+		t.Errorf("want 2 reviews, got %d", reviews)
+	}
+}
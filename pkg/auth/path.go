@@ -0,0 +1,79 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// PathAuthorizer authenticates bearer tokens and authorizes their users to
+// request a path if they can get it as non-resource URL, like kube-rbac-proxy
+// does.
+type PathAuthorizer struct {
+	*reviewer
+}
+
+// NewPathAuthorizer returns a PathAuthorizer caching reviews for ttl.
+func NewPathAuthorizer(client clientset.Interface, ttl time.Duration) *PathAuthorizer {
+	return &PathAuthorizer{reviewer: newReviewer(client, ttl)}
+}
+
+// CanGetPath returns whether the user of a bearer token can get the
+// non-resource URL path, like the /metrics of Kubernetes components.
+func (a *PathAuthorizer) CanGetPath(token string, user *authenticationv1.UserInfo, path string) (bool, error) {
+	key := decisionKey{token: sha256.Sum256([]byte(token)), path: path}
+	allowed, err := a.review(key, user, authorizationv1.SubjectAccessReviewSpec{
+		NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+			Path: path,
+			Verb: "get",
+		},
+	})
+	if err != nil {
+		return false, fmt.Errorf("reviewing access of %s to path %q failed: %v", user.Username, path, err)
+	}
+	return allowed, nil
+}
+
+// Handler authenticates the bearer token of every request and only passes it
+// on to h if its user can get the path of the request.
+func (a *PathAuthorizer) Handler(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token, user, ok := a.authenticateRequest(w, r)
+		if !ok {
+			return
+		}
+		allowed, err := a.CanGetPath(token, user, r.URL.Path)
+		if err != nil {
+			glog.Errorf("authorizing request failed: %v", err)
+			http.Error(w, "authorization failed", http.StatusInternalServerError)
+			return
+		}
+		if !allowed {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
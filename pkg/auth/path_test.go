@@ -0,0 +1,77 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestPathAuthorizerHandler(t *testing.T) {
+	tests := []struct {
+		Desc          string
+		Path          string
+		Authorization string
+		WantCode      int
+	}{
+		{
+			Desc:     "no token",
+			Path:     "/metrics",
+			WantCode: http.StatusUnauthorized,
+		},
+		{
+			Desc:          "invalid token",
+			Path:          "/metrics",
+			Authorization: "Bearer unknown",
+			WantCode:      http.StatusUnauthorized,
+		},
+		{
+			Desc:          "allowed path",
+			Path:          "/metrics",
+			Authorization: "Bearer admin-token",
+			WantCode:      http.StatusOK,
+		},
+		{
+			Desc:          "other path",
+			Path:          "/metrics/watch",
+			Authorization: "Bearer admin-token",
+			WantCode:      http.StatusForbidden,
+		},
+		{
+			Desc:          "forbidden user",
+			Path:          "/metrics",
+			Authorization: "Bearer team-a-token",
+			WantCode:      http.StatusForbidden,
+		},
+	}
+	for _, test := range tests {
+		var reviews int
+		a := NewPathAuthorizer(newFakeClient(&reviews), time.Minute)
+		h := a.Handler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		r := httptest.NewRequest("GET", test.Path, nil)
+		if test.Authorization != "" {
+			r.Header.Set("Authorization", test.Authorization)
+		}
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.WantCode {
+			t.Errorf("Test error for Desc: %s. Want code %d, got %d.", test.Desc, test.WantCode, w.Code)
+		}
+	}
+}
@@ -0,0 +1,398 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package builder assembles the collectors and the metrics handler of
+// kube-state-metrics. Programs embedding kube-state-metrics use it to register
+// their own collectors alongside the built-in ones and to serve them with the
+// same filtering, aggregation and authorization as the kube-state-metrics
+// binary.
+package builder
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/auth"
+	kcollectors "k8s.io/kube-state-metrics/pkg/collectors"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// RegisterCollectorFunc registers the metrics of a collector with the given
+// registry. Collectors backed by informers create them from the given
+// factories, one per watched namespace, and have them tracked by
+// collectors.InformerSyncTracker before running them.
+type RegisterCollectorFunc func(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options)
+
+// Builder registers the enabled collectors, each with its own registry so
+// that scrapes can select the collectors they are interested in.
+type Builder struct {
+	kubeClient        clientset.Interface
+	opts              *options.Options
+	namespaces        options.NamespaceList
+	enabledCollectors options.CollectorSet
+	collectors        map[string]RegisterCollectorFunc
+}
+
+// NewBuilder returns a Builder of the default collectors in all namespaces,
+// which can be changed with WithNamespaces and WithEnabledCollectors.
+func NewBuilder(kubeClient clientset.Interface, opts *options.Options) *Builder {
+	b := &Builder{
+		kubeClient:        kubeClient,
+		opts:              opts,
+		namespaces:        options.DefaultNamespaces,
+		enabledCollectors: options.CollectorSet{},
+		collectors:        map[string]RegisterCollectorFunc{},
+	}
+	for c := range options.DefaultCollectors {
+		b.enabledCollectors[c] = struct{}{}
+	}
+	for c, f := range kcollectors.AvailableCollectors {
+		b.collectors[c] = f
+	}
+	return b
+}
+
+// WithNamespaces sets the namespaces whose objects are collected. They are
+// set as the namespaces of the options as well, so that collectors
+// aggregating objects across namespaces know whether they see all of them.
+func (b *Builder) WithNamespaces(namespaces options.NamespaceList) *Builder {
+	b.namespaces = namespaces
+	b.opts.Namespaces = namespaces
+	return b
+}
+
+// WithEnabledCollectors sets the collectors to register. Unknown collectors
+// are ignored.
+func (b *Builder) WithEnabledCollectors(collectors options.CollectorSet) *Builder {
+	b.enabledCollectors = options.CollectorSet{}
+	for c := range collectors {
+		b.enabledCollectors[c] = struct{}{}
+	}
+	return b
+}
+
+// WithCollector adds a collector with the given name, which is enabled in
+// addition to the enabled collectors. A built-in collector of the same name
+// is replaced.
+func (b *Builder) WithCollector(name string, f RegisterCollectorFunc) *Builder {
+	b.collectors[name] = f
+	b.enabledCollectors[name] = struct{}{}
+	return b
+}
+
+// Build creates and starts informers and registers the enabled collectors.
+// It returns the gatherers of the registered collectors by name.
+func (b *Builder) Build() (metrics.CollectorGatherers, error) {
+	var transforms *kcollectors.InformerTransformConfig
+	if b.opts.InformerTransformConfig != "" {
+		var err error
+		transforms, err = kcollectors.LoadInformerTransformConfig(b.opts.InformerTransformConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load informer transform config: %v", err)
+		}
+	}
+	transforms = transforms.WithUnrequestedAnnotationsDropped(b.opts.AnnotationWhitelist)
+
+	podSelector, err := podFieldSelector(b.opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var tweak func(*metav1.ListOptions)
+	if b.opts.ListChunkSize > 0 {
+		tweak = kcollectors.ChunkingListOptions(b.opts.ListChunkSize)
+	}
+
+	informerFactories := []informers.SharedInformerFactory{}
+	for _, ns := range b.namespaces {
+		factoryOpts := []informers.SharedInformerOption{informers.WithNamespace(ns)}
+		if tweak != nil {
+			factoryOpts = append(factoryOpts, informers.WithTweakListOptions(tweak))
+		}
+		factory := informers.NewSharedInformerFactoryWithOptions(b.kubeClient, 0, factoryOpts...)
+		// The pod informer is registered first, so that it is the one
+		// shared even if pods are transformed as well.
+		if podSelector != "" {
+			kcollectors.RegisterPodInformer(factory, b.kubeClient, ns, podSelector, transforms, tweak)
+		}
+		kcollectors.RegisterTransformingInformers(factory, b.kubeClient, ns, transforms, tweak)
+		informerFactories = append(informerFactories, factory)
+	}
+	collectorGatherers := metrics.CollectorGatherers{}
+	activeCollectors := []string{}
+	for c := range b.enabledCollectors {
+		f, ok := b.collectors[c]
+		if ok {
+			registry := metrics.NewRegistry()
+			f(registry, informerFactories, b.opts)
+			collectorGatherers[c] = metrics.ScrapeResultGatherer(c, registry)
+			activeCollectors = append(activeCollectors, c)
+		}
+	}
+
+	// The apiresources collector is backed by the discovery API instead of
+	// informers and therefore needs the client itself. Its series, including
+	// kube_cluster_version_info, describe the whole cluster, so like the
+	// addons collector only the first shard registers it.
+	if _, ok := b.enabledCollectors["apiresources"]; ok && b.opts.Shard == 0 {
+		registry := prometheus.NewRegistry()
+		kcollectors.RegisterAPIResourceCollector(registry, b.kubeClient.Discovery(), b.opts)
+		collectorGatherers["apiresources"] = metrics.ScrapeResultGatherer("apiresources", registry)
+		activeCollectors = append(activeCollectors, "apiresources")
+	}
+
+	// The customresources collector lists arbitrary resources with the REST
+	// client of the discovery API, as there are no informers for them.
+	if b.opts.CustomResourceStateConfigFile != "" {
+		config, err := kcollectors.LoadCustomResourceStateConfig(b.opts.CustomResourceStateConfigFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load custom resource state config: %v", err)
+		}
+		if client := b.kubeClient.Discovery().RESTClient(); client != nil {
+			registry := prometheus.NewRegistry()
+			kcollectors.RegisterCustomResourceStateCollector(registry, client, config, b.namespaces, b.opts)
+			collectorGatherers["customresources"] = metrics.ScrapeResultGatherer("customresources", registry)
+			activeCollectors = append(activeCollectors, "customresources")
+		} else {
+			glog.Warningf("Custom resources can't be listed without an apiserver, the customresources collector is disabled")
+		}
+	}
+
+	// The addons collector reports cluster-wide singletons from unsharded
+	// informers, so only the first shard registers it.
+	if len(b.opts.AddonWorkloads) > 0 && b.opts.Shard == 0 {
+		registry := prometheus.NewRegistry()
+		kcollectors.RegisterAddonCollector(registry, informerFactories, b.opts)
+		collectorGatherers["addons"] = metrics.ScrapeResultGatherer("addons", registry)
+		activeCollectors = append(activeCollectors, "addons")
+	}
+
+	// The collectors of resources without typed clients in the vendored
+	// client-go list their objects with a REST client instead of informers.
+	// The snapshot resources are custom resources as well, whose objects are
+	// listed like those of the customresources collector.
+	restCollectors := map[string]func(prometheus.Registerer, rest.Interface){
+		"volumesnapshots": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterVolumeSnapshotCollector(r, client, b.namespaces, b.opts)
+		},
+		"volumesnapshotcontents": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterVolumeSnapshotContentCollector(r, client, b.opts)
+		},
+		"verticalpodautoscalers": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterVerticalPodAutoscalerCollector(r, client, b.namespaces, b.opts)
+		},
+		"csinodes": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterCSINodeCollector(r, client, b.opts)
+		},
+		"csidrivers": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterCSIDriverCollector(r, client, b.opts)
+		},
+		"customresourcedefinitions": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterCustomResourceDefinitionCollector(r, client, b.opts)
+		},
+		"apiservices": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterAPIServiceCollector(r, client, b.opts)
+		},
+		"runtimeclasses": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterRuntimeClassCollector(r, client, b.opts)
+		},
+		"ingressclasses": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterIngressClassCollector(r, client, b.opts)
+		},
+		"endpointslices": func(r prometheus.Registerer, client rest.Interface) {
+			kcollectors.RegisterEndpointSliceCollector(r, client, b.namespaces, b.opts)
+		},
+	}
+	for c, register := range restCollectors {
+		if _, ok := b.enabledCollectors[c]; !ok {
+			continue
+		}
+		client := b.kubeClient.Discovery().RESTClient()
+		if client == nil {
+			glog.Warningf("Objects of %s can't be listed without an apiserver, the %s collector is disabled", c, c)
+			continue
+		}
+		registry := prometheus.NewRegistry()
+		register(registry, client)
+		collectorGatherers[c] = metrics.ScrapeResultGatherer(c, registry)
+		activeCollectors = append(activeCollectors, c)
+	}
+
+	glog.Infof("Active collectors: %s", strings.Join(activeCollectors, ","))
+	return collectorGatherers, nil
+}
+
+// podFieldSelector returns the field selector of the watched pods, combining
+// --node and --pod-field-selector.
+func podFieldSelector(opts *options.Options) (string, error) {
+	var selectors []fields.Selector
+	if opts.PodFieldSelector != "" {
+		selector, err := fields.ParseSelector(opts.PodFieldSelector)
+		if err != nil {
+			return "", fmt.Errorf("invalid pod field selector: %v", err)
+		}
+		selectors = append(selectors, selector)
+	}
+	if opts.Node != "" {
+		selectors = append(selectors, fields.OneTermEqualSelector("spec.nodeName", opts.Node))
+	}
+	if len(selectors) == 0 {
+		return "", nil
+	}
+	return fields.AndSelectors(selectors...).String(), nil
+}
+
+// GathererWrapper returns a function wrapping the gatherer of the collected
+// metrics to aggregate, filter and label them according to opts.
+func GathererWrapper(kubeClient clientset.Interface, opts *options.Options) (func(prometheus.Gatherer) prometheus.Gatherer, error) {
+	var (
+		tenantOf metrics.TenantFunc
+		err      error
+	)
+	if opts.TenantNamespaceLabel != "" || opts.TenantNamespaceRegex != "" {
+		tenantOf, err = tenantFunc(kubeClient, opts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure tenant label: %v", err)
+		}
+	}
+	var aggregationRules []metrics.AggregationRule
+	if opts.AggregationConfig != "" {
+		aggregationRules, err = metrics.LoadAggregationRules(opts.AggregationConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load aggregation config: %v", err)
+		}
+		glog.Infof("Loaded %d aggregation rules from %s", len(aggregationRules), opts.AggregationConfig)
+	}
+	var relabelRules []metrics.RelabelRule
+	if opts.RelabelConfig != "" {
+		relabelRules, err = metrics.LoadRelabelRules(opts.RelabelConfig)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load relabel config: %v", err)
+		}
+		glog.Infof("Loaded %d relabel rules from %s", len(relabelRules), opts.RelabelConfig)
+	}
+	return func(g prometheus.Gatherer) prometheus.Gatherer {
+		// Old names are added first so that they can be filtered and
+		// aggregated like any other metric.
+		if len(opts.CompatMetrics) > 0 {
+			g = metrics.CompatGatherer(g, opts.CompatMetrics)
+		}
+		if len(aggregationRules) > 0 {
+			g = metrics.AggregatingGatherer(g, aggregationRules)
+		}
+		g = metrics.FilteredGatherer(g, opts.MetricWhitelist, opts.MetricBlacklist)
+		if len(relabelRules) > 0 {
+			g = metrics.RelabelingGatherer(g, relabelRules)
+		}
+		if opts.Lite {
+			g = metrics.LiteGatherer(g)
+		}
+		if tenantOf != nil {
+			g = metrics.TenantGatherer(g, tenantOf)
+		}
+		return g
+	}, nil
+}
+
+// tenantFunc derives the tenant of a namespace from its labels and, as a
+// fallback, from its name.
+func tenantFunc(kubeClient clientset.Interface, opts *options.Options) (metrics.TenantFunc, error) {
+	var tenantFuncs []metrics.TenantFunc
+
+	if opts.TenantNamespaceLabel != "" {
+		factory := informers.NewSharedInformerFactory(kubeClient, 0)
+		// Only the labels of namespaces are used.
+		kcollectors.RegisterTransformingInformers(factory, kubeClient, metav1.NamespaceAll, &kcollectors.InformerTransformConfig{
+			Resources: map[string]kcollectors.InformerTransform{"namespaces": {DropUnrequestedAnnotations: true}},
+		}, nil)
+		namespaceLister := factory.Core().V1().Namespaces().Lister()
+		factory.Start(context.Background().Done())
+
+		tenantFuncs = append(tenantFuncs, func(namespace string) string {
+			ns, err := namespaceLister.Get(namespace)
+			if err != nil {
+				return ""
+			}
+			return ns.Labels[opts.TenantNamespaceLabel]
+		})
+	}
+
+	if opts.TenantNamespaceRegex != "" {
+		re, err := regexp.Compile(opts.TenantNamespaceRegex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid tenant namespace regex: %v", err)
+		}
+		tenantFuncs = append(tenantFuncs, metrics.NamespaceRegexTenant(re))
+	}
+
+	return metrics.FirstTenant(tenantFuncs...), nil
+}
+
+// MetricsHandler returns the handler serving the metrics of all collectors or
+// of the ones selected per request, wrapped with wrapGatherer.
+// If authorizer is not nil, callers only get the metrics of the namespaces
+// they can get pods in, unless they can get pods in all of them.
+func MetricsHandler(collectorGatherers metrics.CollectorGatherers, wrapGatherer func(prometheus.Gatherer) prometheus.Gatherer, authorizer *auth.NamespaceAuthorizer, opts *options.Options) http.Handler {
+	filterText := textFamilyFilter(opts)
+	handlerFor := func(gs []prometheus.Gatherer) http.Handler {
+		return metrics.StreamingHandler(gs, wrapGatherer, filterText)
+	}
+	handler := handlerFor(collectorGatherers.Gatherers())
+	if opts.MetricsCacheMaxAge > 0 {
+		handler = metrics.NewCachedHandler(wrapGatherer(collectorGatherers.Gatherer()), kcollectors.InformerSyncTracker.Generation, opts.MetricsCacheMaxAge)
+	}
+	handler = metrics.SelectingHandler(handler, collectorGatherers, handlerFor)
+	if authorizer == nil {
+		return handler
+	}
+
+	return authorizer.Handler(func(allowed func(namespace string) bool) http.Handler {
+		if allowed == nil {
+			return handler
+		}
+		// The output differs per caller, so it is never cached.
+		filteredHandlerFor := func(gs []prometheus.Gatherer) http.Handler {
+			return metrics.StreamingHandler(gs, func(g prometheus.Gatherer) prometheus.Gatherer {
+				return metrics.NamespaceFilteredGatherer(wrapGatherer(g), allowed)
+			}, nil)
+		}
+		return metrics.SelectingHandler(filteredHandlerFor(collectorGatherers.Gatherers()), collectorGatherers, filteredHandlerFor)
+	})
+}
+
+// textFamilyFilter returns the filter of the precomputed metric families the
+// metrics handler writes as they are, or nil if opts transform metrics beyond
+// filtering whole families, so that precomputed metrics have to be wrapped
+// like any other metric.
+func textFamilyFilter(opts *options.Options) func(metrics.TextFamily) bool {
+	if len(opts.CompatMetrics) > 0 || opts.AggregationConfig != "" || opts.RelabelConfig != "" || opts.Lite ||
+		opts.TenantNamespaceLabel != "" || opts.TenantNamespaceRegex != "" {
+		return nil
+	}
+	return metrics.TextFamilyFilter(opts.MetricWhitelist, opts.MetricBlacklist)
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestBuilderWithCollector(t *testing.T) {
+	kubeClient := fake.NewSimpleClientset()
+	opts := options.NewOptions()
+
+	custom := func(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+		g := prometheus.NewGauge(prometheus.GaugeOpts{Name: "custom_widgets", Help: "Number of widgets."})
+		g.Set(3)
+		registry.MustRegister(g)
+	}
+	collectorGatherers, err := NewBuilder(kubeClient, opts).
+		WithEnabledCollectors(options.CollectorSet{"configmaps": struct{}{}, "unknown": struct{}{}}).
+		WithCollector("widgets", custom).
+		Build()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, c := range []string{"configmaps", "widgets"} {
+		if _, ok := collectorGatherers[c]; !ok {
+			t.Errorf("expected collector %s to be registered", c)
+		}
+	}
+	if len(collectorGatherers) != 2 {
+		t.Errorf("expected 2 collectors, got %d", len(collectorGatherers))
+	}
+
+	wrapGatherer, err := GathererWrapper(kubeClient, opts)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	w := httptest.NewRecorder()
+	MetricsHandler(collectorGatherers, wrapGatherer, nil, opts).ServeHTTP(w, httptest.NewRequest("GET", "/metrics?collectors=widgets", nil))
+	if !strings.Contains(w.Body.String(), "custom_widgets 3") {
+		t.Errorf("expected the metrics of the custom collector, got:\n%s", w.Body.String())
+	}
+}
+
+func TestPodFieldSelector(t *testing.T) {
+	tests := []struct {
+		node, selector string
+		want           string
+		err            bool
+	}{
+		{},
+		{node: "node1", want: "spec.nodeName=node1"},
+		{selector: "status.phase!=Succeeded", want: "status.phase!=Succeeded"},
+		{node: "node1", selector: "status.phase!=Succeeded", want: "status.phase!=Succeeded,spec.nodeName=node1"},
+		{selector: "status.phase", err: true},
+	}
+	for _, test := range tests {
+		opts := options.NewOptions()
+		opts.Node, opts.PodFieldSelector = test.node, test.selector
+		got, err := podFieldSelector(opts)
+		if test.err != (err != nil) {
+			t.Errorf("node %q, selector %q: expected error %t, got %v", test.node, test.selector, test.err, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("node %q, selector %q: expected %q, got %q", test.node, test.selector, test.want, got)
+		}
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+	"sort"
+
+	"github.com/prometheus/client_golang/prometheus"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descAddonWorkloadAvailable = prometheus.NewDesc(
+		"kube_addon_workload_available",
+		"Whether the workload running a cluster addon is available.",
+		[]string{"addon"},
+		nil,
+	)
+)
+
+// RegisterAddonCollector registers a collector of the availability of the
+// workloads of opts.AddonWorkloads. Their informers are shared with the
+// deployments, daemonsets and statefulsets collectors, but unsharded, so the
+// collector must only be registered on a single shard.
+func RegisterAddonCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+	kinds := map[string]bool{}
+	for _, ref := range opts.AddonWorkloads {
+		kinds[ref.Kind] = true
+	}
+
+	store := informerWorkloadStore{}
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		if kinds["deployment"] {
+			store["deployment"] = append(store["deployment"], f.Extensions().V1beta1().Deployments().Informer())
+		}
+		if kinds["daemonset"] {
+			store["daemonset"] = append(store["daemonset"], f.Extensions().V1beta1().DaemonSets().Informer())
+		}
+		if kinds["statefulset"] {
+			store["statefulset"] = append(store["statefulset"], f.Apps().V1beta1().StatefulSets().Informer())
+		}
+	}
+	for _, kindInfs := range store {
+		infs = append(infs, kindInfs...)
+	}
+
+	registry.MustRegister(&addonCollector{store: store, opts: opts})
+	InformerSyncTracker.TrackDependencies("addon", infs)
+	infs.Run(context.Background().Done())
+}
+
+type workloadStore interface {
+	Get(ref options.WorkloadReference) (obj interface{}, ok bool)
+}
+
+// informerWorkloadStore gets workloads from the informers of their kind.
+type informerWorkloadStore map[string]SharedInformerList
+
+func (s informerWorkloadStore) Get(ref options.WorkloadReference) (interface{}, bool) {
+	for _, inf := range s[ref.Kind] {
+		if obj, ok, err := inf.GetStore().GetByKey(ref.Namespace + "/" + ref.Name); err == nil && ok {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// addonCollector collects the availability of the workloads of cluster
+// addons.
+type addonCollector struct {
+	store workloadStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (ac *addonCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descAddonWorkloadAvailable
+}
+
+// Collect implements the prometheus.Collector interface.
+func (ac *addonCollector) Collect(ch chan<- prometheus.Metric) {
+	addons := make([]string, 0, len(ac.opts.AddonWorkloads))
+	for addon := range ac.opts.AddonWorkloads {
+		addons = append(addons, addon)
+	}
+	sort.Strings(addons)
+
+	for _, addon := range addons {
+		obj, ok := ac.store.Get(ac.opts.AddonWorkloads[addon])
+		available := ok && workloadAvailable(obj)
+		ch <- prometheus.MustNewConstMetric(descAddonWorkloadAvailable, prometheus.GaugeValue, boolFloat64(available), addon)
+	}
+}
+
+// workloadAvailable returns whether a daemonset or statefulset runs all of
+// its desired pods, at least one, as available or ready. A deployment that
+// isn't scaled to zero is available if its Available condition is true,
+// which tolerates the unavailable pods its rollout strategy allows.
+func workloadAvailable(obj interface{}) bool {
+	switch w := obj.(type) {
+	case *v1beta1.Deployment:
+		if w.Spec.Replicas != nil && *w.Spec.Replicas == 0 {
+			return false
+		}
+		for _, c := range w.Status.Conditions {
+			if c.Type == v1beta1.DeploymentAvailable {
+				return c.Status == v1.ConditionTrue
+			}
+		}
+	case *v1beta1.DaemonSet:
+		return w.Status.DesiredNumberScheduled > 0 && w.Status.NumberAvailable >= w.Status.DesiredNumberScheduled
+	case *appsv1beta1.StatefulSet:
+		replicas := int32(1)
+		if w.Spec.Replicas != nil {
+			replicas = *w.Spec.Replicas
+		}
+		return replicas > 0 && w.Status.ReadyReplicas >= replicas
+	}
+	return false
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockWorkloadStore map[options.WorkloadReference]interface{}
+
+func (s mockWorkloadStore) Get(ref options.WorkloadReference) (interface{}, bool) {
+	obj, ok := s[ref]
+	return obj, ok
+}
+
+func TestAddonCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_addon_workload_available Whether the workload running a cluster addon is available.
+		# TYPE kube_addon_workload_available gauge
+	`
+	var zero int32
+	coredns := options.WorkloadReference{Kind: "deployment", Namespace: "kube-system", Name: "coredns"}
+	dashboard := options.WorkloadReference{Kind: "deployment", Namespace: "kube-system", Name: "dashboard"}
+	kubeProxy := options.WorkloadReference{Kind: "daemonset", Namespace: "kube-system", Name: "kube-proxy"}
+	calico := options.WorkloadReference{Kind: "daemonset", Namespace: "kube-system", Name: "calico-node"}
+	etcd := options.WorkloadReference{Kind: "statefulset", Namespace: "kube-system", Name: "etcd"}
+	missing := options.WorkloadReference{Kind: "deployment", Namespace: "kube-system", Name: "metrics-server"}
+
+	store := mockWorkloadStore{
+		coredns: &v1beta1.Deployment{
+			Status: v1beta1.DeploymentStatus{Conditions: []v1beta1.DeploymentCondition{
+				{Type: v1beta1.DeploymentProgressing, Status: v1.ConditionTrue},
+				{Type: v1beta1.DeploymentAvailable, Status: v1.ConditionTrue},
+			}},
+		},
+		dashboard: &v1beta1.Deployment{
+			Spec: v1beta1.DeploymentSpec{Replicas: &zero},
+			Status: v1beta1.DeploymentStatus{Conditions: []v1beta1.DeploymentCondition{
+				{Type: v1beta1.DeploymentAvailable, Status: v1.ConditionTrue},
+			}},
+		},
+		kubeProxy: &v1beta1.DaemonSet{
+			Status: v1beta1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberAvailable: 3},
+		},
+		calico: &v1beta1.DaemonSet{
+			Status: v1beta1.DaemonSetStatus{DesiredNumberScheduled: 3, NumberAvailable: 2},
+		},
+		etcd: &appsv1beta1.StatefulSet{
+			Status: appsv1beta1.StatefulSetStatus{ReadyReplicas: 1},
+		},
+	}
+	opts := &options.Options{AddonWorkloads: options.AddonWorkloads{
+		"coredns":        coredns,
+		"dashboard":      dashboard,
+		"kube-proxy":     kubeProxy,
+		"cni":            calico,
+		"etcd":           etcd,
+		"metrics-server": missing,
+	}}
+
+	want := metadata + `
+		kube_addon_workload_available{addon="cni"} 0
+		kube_addon_workload_available{addon="coredns"} 1
+		kube_addon_workload_available{addon="dashboard"} 0
+		kube_addon_workload_available{addon="etcd"} 1
+		kube_addon_workload_available{addon="kube-proxy"} 1
+		kube_addon_workload_available{addon="metrics-server"} 0
+	`
+	ac := &addonCollector{store: store, opts: opts}
+	if err := testutils.GatherAndCompare(ac, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
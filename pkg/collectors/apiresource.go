@@ -0,0 +1,144 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/discovery"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descClusterVersionInfo = prometheus.NewDesc(
+		"kube_cluster_version_info",
+		"Information about the version of the Kubernetes apiserver.",
+		[]string{"major", "minor", "git_version", "git_commit", "platform"},
+		nil,
+	)
+	descAPIResourceInfo = prometheus.NewDesc(
+		"kube_apiresource_info",
+		"Information about a resource served by the Kubernetes apiserver.",
+		[]string{"group", "version", "resource", "kind", "namespaced"},
+		nil,
+	)
+)
+
+// apiResourceStore is the subset of discovery.DiscoveryInterface the
+// apiResourceCollector relies on.
+type apiResourceStore interface {
+	ServerVersion() (*version.Info, error)
+	ServerResources() ([]*metav1.APIResourceList, error)
+}
+
+// RegisterAPIResourceCollector registers a collector exposing the apiserver
+// version and the resources it serves. Unlike the other collectors it is
+// backed by the discovery API instead of informers.
+func RegisterAPIResourceCollector(registry prometheus.Registerer, client discovery.DiscoveryInterface, opts *options.Options) {
+	registry.MustRegister(&apiResourceCollector{store: client, opts: opts, now: time.Now})
+}
+
+// apiResourceCollector collects metrics about the apiserver version and the
+// resources it serves.
+type apiResourceCollector struct {
+	store apiResourceStore
+	opts  *options.Options
+	now   func() time.Time
+
+	// Discovery results only change on apiserver upgrades or when API
+	// extensions are installed, so they are cached for resyncPeriod instead
+	// of being requested on every scrape.
+	mu          sync.Mutex
+	lastRefresh time.Time
+	version     *version.Info
+	resources   []*metav1.APIResourceList
+}
+
+// Describe implements the prometheus.Collector interface.
+func (ac *apiResourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descClusterVersionInfo
+	ch <- descAPIResourceInfo
+}
+
+// Collect implements the prometheus.Collector interface.
+func (ac *apiResourceCollector) Collect(ch chan<- prometheus.Metric) {
+	v, resources, err := ac.discover()
+	if err != nil {
+		addScrapeError(ch, "apiresource", err)
+		glog.Errorf("discovering apiresources failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "apiresource"}).Add(0)
+
+	ch <- mustNewConstMetric(descClusterVersionInfo, prometheus.GaugeValue, 1,
+		v.Major, v.Minor, v.GitVersion, v.GitCommit, v.Platform)
+
+	var n int
+	for _, rl := range resources {
+		gv, err := schema.ParseGroupVersion(rl.GroupVersion)
+		if err != nil {
+			glog.Errorf("parsing group version %q failed: %s", rl.GroupVersion, err)
+			continue
+		}
+		for _, r := range rl.APIResources {
+			// Subresources such as pods/status are not interesting on their own.
+			if strings.Contains(r.Name, "/") {
+				continue
+			}
+			ch <- mustNewConstMetric(descAPIResourceInfo, prometheus.GaugeValue, 1,
+				gv.Group, gv.Version, r.Name, r.Kind, strconv.FormatBool(r.Namespaced))
+			n++
+		}
+	}
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "apiresource"}).Observe(float64(n))
+	glog.V(4).Infof("collected %d apiresources", n)
+}
+
+func (ac *apiResourceCollector) discover() (*version.Info, []*metav1.APIResourceList, error) {
+	ac.mu.Lock()
+	defer ac.mu.Unlock()
+
+	if ac.version != nil && ac.now().Sub(ac.lastRefresh) < resyncPeriod {
+		return ac.version, ac.resources, nil
+	}
+
+	v, err := ac.store.ServerVersion()
+	if err != nil {
+		return nil, nil, err
+	}
+	resources, err := ac.store.ServerResources()
+	if err != nil {
+		// Aggregated apiservers that are down make discovery fail partially,
+		// the groups that could be discovered are still worth exposing.
+		if !discovery.IsGroupDiscoveryFailedError(err) || len(resources) == 0 {
+			return nil, nil, err
+		}
+		glog.Warningf("partially discovered apiresources: %s", err)
+	}
+
+	ac.version, ac.resources, ac.lastRefresh = v, resources, ac.now()
+	return ac.version, ac.resources, nil
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockAPIResourceStore struct {
+	version   *version.Info
+	resources []*metav1.APIResourceList
+}
+
+func (s mockAPIResourceStore) ServerVersion() (*version.Info, error) {
+	return s.version, nil
+}
+
+func (s mockAPIResourceStore) ServerResources() ([]*metav1.APIResourceList, error) {
+	return s.resources, nil
+}
+
+func TestAPIResourceCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_cluster_version_info Information about the version of the Kubernetes apiserver.
+		# TYPE kube_cluster_version_info gauge
+		# HELP kube_apiresource_info Information about a resource served by the Kubernetes apiserver.
+		# TYPE kube_apiresource_info gauge
+	`
+	cases := []struct {
+		store mockAPIResourceStore
+		want  string
+	}{
+		{
+			store: mockAPIResourceStore{
+				version: &version.Info{
+					Major:      "1",
+					Minor:      "10",
+					GitVersion: "v1.10.0",
+					GitCommit:  "fc32d2f3698e36b93322a3465f63a14e9f0eaead",
+					Platform:   "linux/amd64",
+				},
+				resources: []*metav1.APIResourceList{
+					{
+						GroupVersion: "v1",
+						APIResources: []metav1.APIResource{
+							{Name: "pods", Kind: "Pod", Namespaced: true},
+							{Name: "pods/status", Kind: "Pod", Namespaced: true},
+							{Name: "nodes", Kind: "Node"},
+						},
+					},
+					{
+						GroupVersion: "extensions/v1beta1",
+						APIResources: []metav1.APIResource{
+							{Name: "deployments", Kind: "Deployment", Namespaced: true},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_cluster_version_info{git_commit="fc32d2f3698e36b93322a3465f63a14e9f0eaead",git_version="v1.10.0",major="1",minor="10",platform="linux/amd64"} 1
+				kube_apiresource_info{group="",kind="Pod",namespaced="true",resource="pods",version="v1"} 1
+				kube_apiresource_info{group="",kind="Node",namespaced="false",resource="nodes",version="v1"} 1
+				kube_apiresource_info{group="extensions",kind="Deployment",namespaced="true",resource="deployments",version="v1beta1"} 1
+			`,
+		},
+	}
+	for _, c := range cases {
+		ac := &apiResourceCollector{
+			store: c.store,
+			opts:  &options.Options{},
+			now:   time.Now,
+		}
+		if err := testutils.GatherAndCompare(ac, c.want, nil); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// APIServices are served by apiregistration.k8s.io, whose typed client
+	// is part of the kube-aggregator and not vendored.
+	apiServiceResource = CustomResource{
+		Group:    "apiregistration.k8s.io",
+		Version:  "v1",
+		Resource: "apiservices",
+		Kind:     "APIService",
+	}
+
+	descAPIServiceLabelsDefaultLabels = []string{"apiservice"}
+
+	descAPIServiceInfo = prometheus.NewDesc(
+		"kube_apiservice_info",
+		"Information about the API service.",
+		append(descAPIServiceLabelsDefaultLabels, "group", "version", "service_namespace", "service_name"),
+		nil,
+	)
+	descAPIServiceCreated = prometheus.NewDesc(
+		"kube_apiservice_created",
+		"Unix creation timestamp",
+		descAPIServiceLabelsDefaultLabels,
+		nil,
+	)
+	descAPIServiceStatusCondition = prometheus.NewDesc(
+		"kube_apiservice_status_condition",
+		"The condition of the API service.",
+		append(descAPIServiceLabelsDefaultLabels, "condition", "status"),
+		nil,
+	)
+)
+
+// RegisterAPIServiceCollector registers a collector of the APIServices, which
+// are cluster-scoped. Like the runtimeclasses collector it lists them on
+// every scrape.
+func RegisterAPIServiceCollector(registry prometheus.Registerer, client rest.Interface, opts *options.Options) {
+	registry.MustRegister(&apiServiceCollector{store: restCustomResourceStore{client: client}, opts: opts})
+}
+
+// apiServiceCollector collects metrics about all APIServices in the cluster.
+type apiServiceCollector struct {
+	store customResourceStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (ac *apiServiceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descAPIServiceInfo
+	ch <- descAPIServiceCreated
+	ch <- descAPIServiceStatusCondition
+}
+
+// Collect implements the prometheus.Collector interface.
+func (ac *apiServiceCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, ac.store, apiServiceResource, options.NamespaceList{""}, ac.opts, func(obj unstructured.Unstructured) {
+		ac.collectAPIService(ch, obj)
+	})
+}
+
+func (ac *apiServiceCollector) collectAPIService(ch chan<- prometheus.Metric, s unstructured.Unstructured) {
+	defer recoverObjectError("apiservice", &s)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{s.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	// API services served by the apiserver itself have no service.
+	group, _, _ := unstructured.NestedString(s.Object, "spec", "group")
+	version, _, _ := unstructured.NestedString(s.Object, "spec", "version")
+	serviceNamespace, _, _ := unstructured.NestedString(s.Object, "spec", "service", "namespace")
+	serviceName, _, _ := unstructured.NestedString(s.Object, "spec", "service", "name")
+	addGauge(descAPIServiceInfo, 1, group, version, serviceNamespace, serviceName)
+
+	if t := s.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descAPIServiceCreated, float64(t.Unix()))
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(s.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		addConditionMetrics(ch, ac.opts.ConditionEncoding, descAPIServiceStatusCondition, v1.ConditionStatus(status), s.GetName(), conditionType)
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestAPIServiceCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_apiservice_info Information about the API service.
+		# TYPE kube_apiservice_info gauge
+		# HELP kube_apiservice_created Unix creation timestamp
+		# TYPE kube_apiservice_created gauge
+		# HELP kube_apiservice_status_condition The condition of the API service.
+		# TYPE kube_apiservice_status_condition gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"apiservices": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "v1.apps",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+				},
+				"spec": map[string]interface{}{
+					"group":   "apps",
+					"version": "v1",
+				},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": "True"},
+					},
+				},
+			}},
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name": "v1beta1.metrics.k8s.io",
+				},
+				"spec": map[string]interface{}{
+					"group":   "metrics.k8s.io",
+					"version": "v1beta1",
+					"service": map[string]interface{}{"namespace": "kube-system", "name": "metrics-server"},
+				},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Available", "status": "False", "reason": "FailedDiscoveryCheck"},
+					},
+				},
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_apiservice_info{apiservice="v1.apps",group="apps",service_name="",service_namespace="",version="v1"} 1
+		kube_apiservice_info{apiservice="v1beta1.metrics.k8s.io",group="metrics.k8s.io",service_name="metrics-server",service_namespace="kube-system",version="v1beta1"} 1
+		kube_apiservice_created{apiservice="v1.apps"} 1.501569018e+09
+		kube_apiservice_status_condition{apiservice="v1.apps",condition="Available",status="false"} 0
+		kube_apiservice_status_condition{apiservice="v1.apps",condition="Available",status="true"} 1
+		kube_apiservice_status_condition{apiservice="v1.apps",condition="Available",status="unknown"} 0
+		kube_apiservice_status_condition{apiservice="v1beta1.metrics.k8s.io",condition="Available",status="false"} 1
+		kube_apiservice_status_condition{apiservice="v1beta1.metrics.k8s.io",condition="Available",status="true"} 0
+		kube_apiservice_status_condition{apiservice="v1beta1.metrics.k8s.io",condition="Available",status="unknown"} 0
+	`
+	ac := &apiServiceCollector{store: store, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(ac, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestAPIServiceCollectorWithProtobufPreferringClient(t *testing.T) {
+	client, stop := newProtobufPreferringClient(t, map[string]string{
+		"/apis/apiregistration.k8s.io/v1/apiservices": `{"apiVersion":"apiregistration.k8s.io/v1","kind":"APIServiceList","items":[{"metadata":{"name":"v1.apps"},"spec":{"group":"apps","version":"v1"}}]}`,
+	})
+	defer stop()
+
+	ac := &apiServiceCollector{store: restCustomResourceStore{client: client}, opts: &options.Options{}}
+	want := `
+		# HELP kube_apiservice_info Information about the API service.
+		# TYPE kube_apiservice_info gauge
+		kube_apiservice_info{apiservice="v1.apps",group="apps",service_name="",service_namespace="",version="v1"} 1
+	`
+	if err := testutils.GatherAndCompare(ac, want, []string{"kube_apiservice_info"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"k8s.io/api/certificates/v1beta1"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descCSRLabelsName          = "kube_certificatesigningrequest_labels"
+	descCSRLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descCSRLabelsDefaultLabels = []string{"certificatesigningrequest"}
+
+	descCSRLabels = prometheus.NewDesc(
+		descCSRLabelsName,
+		descCSRLabelsHelp,
+		descCSRLabelsDefaultLabels,
+		nil,
+	)
+	descCSRCreated = prometheus.NewDesc(
+		"kube_certificatesigningrequest_created",
+		"Unix creation timestamp",
+		descCSRLabelsDefaultLabels,
+		nil,
+	)
+	descCSRCondition = prometheus.NewDesc(
+		"kube_certificatesigningrequest_condition",
+		"The number of each certificatesigningrequest condition",
+		append(descCSRLabelsDefaultLabels, "condition"),
+		nil,
+	)
+	descCSRApprovalLatency = prometheus.NewDesc(
+		"kube_certificatesigningrequest_approval_latency_seconds",
+		"The number of seconds between the creation of a certificatesigningrequest and its approval.",
+		descCSRLabelsDefaultLabels,
+		nil,
+	)
+)
+
+type CertificateSigningRequestLister func() ([]v1beta1.CertificateSigningRequest, error)
+
+func (l CertificateSigningRequestLister) List() ([]v1beta1.CertificateSigningRequest, error) {
+	return l()
+}
+
+func RegisterCertificateSigningRequestCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Certificates().V1beta1().CertificateSigningRequests().Informer(), opts))
+	}
+
+	csrLister := CertificateSigningRequestLister(func() (csrs []v1beta1.CertificateSigningRequest, err error) {
+		for _, csrinf := range infs {
+			for _, c := range csrinf.GetStore().List() {
+				csrs = append(csrs, *(c.(*v1beta1.CertificateSigningRequest)))
+			}
+		}
+		return csrs, nil
+	})
+
+	registry.MustRegister(&csrCollector{store: csrLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_certificatesigningrequest_deleted", descCSRLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("certificatesigningrequest", infs)
+	infs.Run(context.Background().Done())
+}
+
+type csrStore interface {
+	List() ([]v1beta1.CertificateSigningRequest, error)
+}
+
+// csrCollector collects metrics about all certificatesigningrequests in the cluster.
+type csrCollector struct {
+	store csrStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (cc *csrCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descCSRLabels
+	ch <- descCSRCreated
+	ch <- descCSRCondition
+	ch <- descCSRApprovalLatency
+}
+
+// Collect implements the prometheus.Collector interface.
+func (cc *csrCollector) Collect(ch chan<- prometheus.Metric) {
+	csrs, err := cc.store.List()
+	if err != nil {
+		addScrapeError(ch, "certificatesigningrequest", err)
+		glog.Errorf("listing certificatesigningrequests failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "certificatesigningrequest"}).Add(0)
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "certificatesigningrequest"}).Observe(float64(len(csrs)))
+	for _, csr := range csrs {
+		cc.collectCSR(ch, csr)
+	}
+
+	glog.V(4).Infof("collected %d certificatesigningrequests", len(csrs))
+}
+
+func csrLabelsDesc(labelKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descCSRLabelsName,
+		descCSRLabelsHelp,
+		append(descCSRLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func (cc *csrCollector) collectCSR(ch chan<- prometheus.Metric, csr v1beta1.CertificateSigningRequest) {
+	defer recoverObjectError("certificatesigningrequest", &csr.ObjectMeta)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{csr.Name}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(csr.Labels, cc.opts.MaxLabelValueLength)
+	addGauge(csrLabelsDesc(labelKeys), 1, labelValues...)
+
+	if !csr.CreationTimestamp.IsZero() {
+		addGauge(descCSRCreated, float64(csr.CreationTimestamp.Unix()))
+	}
+
+	var approved, denied float64
+	for _, c := range csr.Status.Conditions {
+		switch c.Type {
+		case v1beta1.CertificateApproved:
+			approved++
+			// The approval latency is only meaningful if both timestamps
+			// are known, older apiservers leave LastUpdateTime unset.
+			if !csr.CreationTimestamp.IsZero() && !c.LastUpdateTime.IsZero() {
+				addGauge(descCSRApprovalLatency, c.LastUpdateTime.Sub(csr.CreationTimestamp.Time).Seconds())
+			}
+		case v1beta1.CertificateDenied:
+			denied++
+		}
+	}
+	addGauge(descCSRCondition, approved, "approved")
+	addGauge(descCSRCondition, denied, "denied")
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/certificates/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockCSRStore struct {
+	list func() ([]v1beta1.CertificateSigningRequest, error)
+}
+
+func (ms mockCSRStore) List() ([]v1beta1.CertificateSigningRequest, error) {
+	return ms.list()
+}
+
+func TestCSRCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	const metadata = `
+		# HELP kube_certificatesigningrequest_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_certificatesigningrequest_labels gauge
+		# HELP kube_certificatesigningrequest_created Unix creation timestamp
+		# TYPE kube_certificatesigningrequest_created gauge
+		# HELP kube_certificatesigningrequest_condition The number of each certificatesigningrequest condition
+		# TYPE kube_certificatesigningrequest_condition gauge
+		# HELP kube_certificatesigningrequest_approval_latency_seconds The number of seconds between the creation of a certificatesigningrequest and its approval.
+		# TYPE kube_certificatesigningrequest_approval_latency_seconds gauge
+	`
+	cases := []struct {
+		csrs []v1beta1.CertificateSigningRequest
+		want string
+	}{
+		{
+			csrs: []v1beta1.CertificateSigningRequest{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "csr-pending",
+						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+						Labels: map[string]string{
+							"app": "node-bootstrap",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "csr-approved",
+						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+					},
+					Status: v1beta1.CertificateSigningRequestStatus{
+						Conditions: []v1beta1.CertificateSigningRequestCondition{
+							{
+								Type:           v1beta1.CertificateApproved,
+								LastUpdateTime: metav1.Time{Time: time.Unix(1500000042, 0)},
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "csr-denied",
+					},
+					Status: v1beta1.CertificateSigningRequestStatus{
+						Conditions: []v1beta1.CertificateSigningRequestCondition{
+							{
+								Type: v1beta1.CertificateDenied,
+							},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_certificatesigningrequest_labels{certificatesigningrequest="csr-pending",label_app="node-bootstrap"} 1
+				kube_certificatesigningrequest_labels{certificatesigningrequest="csr-approved"} 1
+				kube_certificatesigningrequest_labels{certificatesigningrequest="csr-denied"} 1
+				kube_certificatesigningrequest_created{certificatesigningrequest="csr-pending"} 1.5e+09
+				kube_certificatesigningrequest_created{certificatesigningrequest="csr-approved"} 1.5e+09
+				kube_certificatesigningrequest_condition{certificatesigningrequest="csr-pending",condition="approved"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="csr-pending",condition="denied"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="csr-approved",condition="approved"} 1
+				kube_certificatesigningrequest_condition{certificatesigningrequest="csr-approved",condition="denied"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="csr-denied",condition="approved"} 0
+				kube_certificatesigningrequest_condition{certificatesigningrequest="csr-denied",condition="denied"} 1
+				kube_certificatesigningrequest_approval_latency_seconds{certificatesigningrequest="csr-approved"} 42
+			`,
+		},
+	}
+	for _, c := range cases {
+		cc := &csrCollector{
+			store: mockCSRStore{
+				list: func() ([]v1beta1.CertificateSigningRequest, error) { return c.csrs, nil },
+			},
+			opts: &options.Options{},
+		}
+		if err := testutils.GatherAndCompare(cc, c.want, nil); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
@@ -17,15 +17,30 @@ limitations under the License.
 package collectors
 
 import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
 	"time"
+	"unicode/utf8"
 
 	"regexp"
 
+	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/time/rate"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kubernetes/pkg/apis/core/v1/helper"
 )
 
 var (
@@ -47,39 +62,496 @@ var (
 		[]string{"resource"},
 	)
 
+	ObjectErrorTotalMetric = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "ksm_object_error_total",
+			Help: "Total errors encountered when generating the metrics of a single object",
+		},
+		[]string{"resource"},
+	)
+
+	// objectErrorLogLimiter limits the logging of object errors, which are
+	// likely to recur for the same objects on every scrape.
+	objectErrorLogLimiter = rate.NewLimiter(rate.Every(10*time.Second), 5)
+
+	ClockSkewMetric = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "ksm_clock_skew_seconds",
+			Help: "Number of seconds the creation timestamp of the newest object of a resource was ahead of the local clock when it was observed",
+		},
+		[]string{"resource"},
+	)
+
+	// descScrapeError describes the invalid metrics sent by addScrapeError,
+	// which are never exposed.
+	descScrapeError = prometheus.NewDesc("ksm_scrape_error", "Failure to list the objects of a resource", nil, nil)
+
 	invalidLabelCharRE = regexp.MustCompile(`[^a-zA-Z0-9_]`)
+
+	// InformerSyncTracker records when the informers of each resource last
+	// synchronized with the apiserver.
+	InformerSyncTracker = NewSyncTracker()
 )
 
+// recoverObjectError recovers from a panic while generating the metrics of a
+// single object, e.g. due to a nil pointer or an invalid label value, so that
+// the metrics of all other objects are still exposed. The remaining metrics of
+// the object are dropped, the error is counted and the object is logged at a
+// limited rate. It has to be deferred directly by the function generating the
+// metrics of the object.
+func recoverObjectError(resource string, o metav1.Object) {
+	r := recover()
+	if r == nil {
+		return
+	}
+	ObjectErrorTotalMetric.WithLabelValues(resource).Inc()
+	if objectErrorLogLimiter.Allow() {
+		name := o.GetName()
+		if o.GetNamespace() != "" {
+			name = o.GetNamespace() + "/" + name
+		}
+		glog.Errorf("generating metrics of %s %s failed: %v", resource, name, r)
+	}
+}
+
+// addScrapeError counts a failure to list the objects of a resource and
+// sends it to ch as an invalid metric, so that the gather of the collector in
+// progress, and only that one, returns it as an error.
+func addScrapeError(ch chan<- prometheus.Metric, resource string, err error) {
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": resource}).Inc()
+	ch <- prometheus.NewInvalidMetric(descScrapeError, fmt.Errorf("listing %s failed: %v", resource, err))
+}
+
 var AvailableCollectors = map[string]func(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options){
-	"cronjobs":                 RegisterCronJobCollector,
-	"daemonsets":               RegisterDaemonSetCollector,
-	"deployments":              RegisterDeploymentCollector,
-	"jobs":                     RegisterJobCollector,
-	"limitranges":              RegisterLimitRangeCollector,
-	"nodes":                    RegisterNodeCollector,
-	"pods":                     RegisterPodCollector,
-	"replicasets":              RegisterReplicaSetCollector,
-	"replicationcontrollers":   RegisterReplicationControllerCollector,
-	"resourcequotas":           RegisterResourceQuotaCollector,
-	"services":                 RegisterServiceCollector,
-	"statefulsets":             RegisterStatefulSetCollector,
-	"persistentvolumes":        RegisterPersistentVolumeCollector,
-	"persistentvolumeclaims":   RegisterPersistentVolumeClaimCollector,
-	"namespaces":               RegisterNamespaceCollector,
-	"horizontalpodautoscalers": RegisterHorizontalPodAutoScalerCollector,
-	"endpoints":                RegisterEndpointCollector,
-	"secrets":                  RegisterSecretCollector,
-	"configmaps":               RegisterConfigMapCollector,
+	"cronjobs":                        RegisterCronJobCollector,
+	"daemonsets":                      RegisterDaemonSetCollector,
+	"deployments":                     RegisterDeploymentCollector,
+	"jobs":                            RegisterJobCollector,
+	"limitranges":                     RegisterLimitRangeCollector,
+	"nodes":                           RegisterNodeCollector,
+	"pods":                            RegisterPodCollector,
+	"replicasets":                     RegisterReplicaSetCollector,
+	"replicationcontrollers":          RegisterReplicationControllerCollector,
+	"resourcequotas":                  RegisterResourceQuotaCollector,
+	"services":                        RegisterServiceCollector,
+	"statefulsets":                    RegisterStatefulSetCollector,
+	"persistentvolumes":               RegisterPersistentVolumeCollector,
+	"persistentvolumeclaims":          RegisterPersistentVolumeClaimCollector,
+	"namespaces":                      RegisterNamespaceCollector,
+	"horizontalpodautoscalers":        RegisterHorizontalPodAutoScalerCollector,
+	"endpoints":                       RegisterEndpointCollector,
+	"secrets":                         RegisterSecretCollector,
+	"configmaps":                      RegisterConfigMapCollector,
+	"certificatesigningrequests":      RegisterCertificateSigningRequestCollector,
+	"poddisruptionbudgets":            RegisterPodDisruptionBudgetCollector,
+	"podsecuritypolicies":             RegisterPodSecurityPolicyCollector,
+	"ingresses":                       RegisterIngressCollector,
+	"storageclasses":                  RegisterStorageClassCollector,
+	"mutatingwebhookconfigurations":   RegisterMutatingWebhookConfigurationCollector,
+	"validatingwebhookconfigurations": RegisterValidatingWebhookConfigurationCollector,
+	"volumeattachments":               RegisterVolumeAttachmentCollector,
+	"events":                          RegisterEventCollector,
 }
 
 type SharedInformerList []cache.SharedInformer
 
+var (
+	startedInformersMu sync.Mutex
+	startedInformers   = map[cache.SharedInformer]bool{}
+)
+
+// Run starts the informers of the list. Informers shared by several
+// collectors are only started once.
 func (sil SharedInformerList) Run(stopCh <-chan struct{}) {
+	startedInformersMu.Lock()
+	defer startedInformersMu.Unlock()
+
 	for _, sinf := range sil {
+		sinf = unshardedInformer(sinf)
+		if startedInformers[sinf] {
+			continue
+		}
+		startedInformers[sinf] = true
 		go sinf.Run(stopCh)
 	}
 }
 
+// SyncTracker tracks the informers of each resource and the last time they
+// delivered an event.
+type SyncTracker struct {
+	mu         sync.Mutex
+	now        func() time.Time
+	informers  map[string]SharedInformerList
+	owned      map[string]SharedInformerList
+	lastSync   map[string]time.Time
+	newest     map[string]time.Time
+	generation uint64
+	clockSkew  *prometheus.GaugeVec
+}
+
+// NewSyncTracker returns an empty SyncTracker.
+func NewSyncTracker() *SyncTracker {
+	return &SyncTracker{
+		now:       time.Now,
+		informers: map[string]SharedInformerList{},
+		owned:     map[string]SharedInformerList{},
+		lastSync:  map[string]time.Time{},
+		newest:    map[string]time.Time{},
+		clockSkew: ClockSkewMetric,
+	}
+}
+
+// Track starts tracking the given informers under the given resource name.
+// It has to be called before the informers are run.
+func (t *SyncTracker) Track(resource string, infs SharedInformerList) {
+	t.track(resource, infs, true)
+}
+
+// TrackDependencies tracks informers of other resources the metrics of the
+// given resource are derived from. Their events count as events of the
+// resource, but their objects are not counted as objects of the resource. It
+// has to be called before the informers are run.
+func (t *SyncTracker) TrackDependencies(resource string, infs SharedInformerList) {
+	t.track(resource, infs, false)
+}
+
+func (t *SyncTracker) track(resource string, infs SharedInformerList, owned bool) {
+	touch := func() {
+		t.mu.Lock()
+		t.lastSync[resource] = t.now()
+		t.generation++
+		t.mu.Unlock()
+	}
+	for _, inf := range infs {
+		inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				touch()
+				if owned {
+					t.observeCreation(resource, obj)
+				}
+			},
+			UpdateFunc: func(interface{}, interface{}) { touch() },
+			DeleteFunc: func(interface{}) { touch() },
+		})
+	}
+
+	t.mu.Lock()
+	t.informers[resource] = append(t.informers[resource], infs...)
+	if owned {
+		t.owned[resource] = append(t.owned[resource], infs...)
+	}
+	t.mu.Unlock()
+	t.clockSkew.WithLabelValues(resource).Add(0)
+}
+
+// observeCreation updates the clock skew of the resource if obj is the newest
+// object observed so far. In steady state the newest object has just been
+// created, so its creation timestamp, set by the apiserver, lying ahead of the
+// local clock means the clocks are skewed.
+func (t *SyncTracker) observeCreation(resource string, obj interface{}) {
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	created := o.GetCreationTimestamp().Time
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if !created.After(t.newest[resource]) {
+		return
+	}
+	t.newest[resource] = created
+
+	skew := created.Sub(t.now()).Seconds()
+	if skew < 0 {
+		skew = 0
+	}
+	t.clockSkew.WithLabelValues(resource).Set(skew)
+}
+
+// ObjectCounts returns the number of objects in the stores of the informers
+// of every resource, leaving out dependencies. With sharding, these are the
+// objects of the shard.
+func (t *SyncTracker) ObjectCounts() map[string]int {
+	t.mu.Lock()
+	informers := make(map[string]SharedInformerList, len(t.owned))
+	for resource, infs := range t.owned {
+		informers[resource] = infs
+	}
+	t.mu.Unlock()
+
+	counts := make(map[string]int, len(informers))
+	for resource, infs := range informers {
+		for _, inf := range infs {
+			counts[resource] += len(inf.GetStore().List())
+		}
+	}
+	return counts
+}
+
+// LastSyncTimes returns the time of the last event of every resource whose
+// informers have synced. Resources without any objects report the first time
+// their informers were seen synced.
+func (t *SyncTracker) LastSyncTimes() map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	times := make(map[string]time.Time, len(t.informers))
+	for resource, infs := range t.informers {
+		if !infs.HasSynced() {
+			continue
+		}
+		if _, ok := t.lastSync[resource]; !ok {
+			t.lastSync[resource] = t.now()
+		}
+		times[resource] = t.lastSync[resource]
+	}
+	return times
+}
+
+// SyncStatus returns whether the informers of every tracked resource have
+// synced.
+func (t *SyncTracker) SyncStatus() map[string]bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	status := make(map[string]bool, len(t.informers))
+	for resource, infs := range t.informers {
+		status[resource] = infs.HasSynced()
+	}
+	return status
+}
+
+// Resources returns the sorted names of all tracked resources.
+func (t *SyncTracker) Resources() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	resources := make([]string, 0, len(t.informers))
+	for resource := range t.informers {
+		resources = append(resources, resource)
+	}
+	sort.Strings(resources)
+	return resources
+}
+
+// Generation returns a counter incremented on every informer event of any
+// tracked resource.
+func (t *SyncTracker) Generation() uint64 {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	return t.generation
+}
+
+// HasSynced returns whether the informers of all tracked resources have
+// synced.
+func (t *SyncTracker) HasSynced() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, infs := range t.informers {
+		if !infs.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// HasSynced returns whether all informers of the list have synced.
+func (sil SharedInformerList) HasSynced() bool {
+	for _, sinf := range sil {
+		if !sinf.HasSynced() {
+			return false
+		}
+	}
+	return true
+}
+
+// rolloutTracker measures how long rollouts of a workload take, from the
+// moment a new generation is observed until the workload reports it as
+// rolled out.
+type rolloutTracker struct {
+	mu  sync.Mutex
+	now func() time.Time
+	// rolledOut returns the namespace, name and generation of a workload
+	// and whether that generation has been rolled out.
+	rolledOut func(obj interface{}) (namespace, name string, generation int64, done, ok bool)
+	rollouts  map[string]*rollout
+}
+
+type rollout struct {
+	generation   int64
+	started      time.Time
+	lastDuration time.Duration
+	hasDuration  bool
+}
+
+func newRolloutTracker(rolledOut func(obj interface{}) (namespace, name string, generation int64, done, ok bool)) *rolloutTracker {
+	return &rolloutTracker{
+		now:       time.Now,
+		rolledOut: rolledOut,
+		rollouts:  map[string]*rollout{},
+	}
+}
+
+// handler returns the event handler feeding the tracker. Rollouts already in
+// progress when a workload is first observed are not measured, as their
+// start is unknown.
+func (t *rolloutTracker) handler() cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			t.observe(obj, false)
+		},
+		UpdateFunc: func(_, newObj interface{}) {
+			t.observe(newObj, true)
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			namespace, name, _, _, ok := t.rolledOut(obj)
+			if !ok {
+				return
+			}
+			t.mu.Lock()
+			delete(t.rollouts, namespace+"/"+name)
+			t.mu.Unlock()
+		},
+	}
+}
+
+func (t *rolloutTracker) observe(obj interface{}, update bool) {
+	namespace, name, generation, done, ok := t.rolledOut(obj)
+	if !ok {
+		return
+	}
+	key := namespace + "/" + name
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.rollouts[key]
+	if !ok {
+		t.rollouts[key] = &rollout{generation: generation}
+		return
+	}
+	if update && generation > r.generation {
+		r.generation = generation
+		r.started = t.now()
+	}
+	if done && !r.started.IsZero() {
+		r.lastDuration = t.now().Sub(r.started)
+		r.hasDuration = true
+		r.started = time.Time{}
+	}
+}
+
+// lastDuration returns the duration of the last measured rollout of a
+// workload.
+func (t *rolloutTracker) lastDuration(namespace, name string) (time.Duration, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	r, ok := t.rollouts[namespace+"/"+name]
+	if !ok || !r.hasDuration {
+		return 0, false
+	}
+	return r.lastDuration, true
+}
+
+// registerDeletedObjects registers a collector keeping a series named name
+// for every object deleted within the last --deleted-object-retention, so
+// recent deletions can be shown without consulting audit logs. The labels are
+// the default labels of the resource, i.e. its namespace, if namespaced, and
+// its name.
+func registerDeletedObjects(registry prometheus.Registerer, infs SharedInformerList, name string, labels []string, opts *options.Options) {
+	if opts.DeletedObjectRetention <= 0 {
+		return
+	}
+	dc := newDeletedObjectCollector(name, labels, opts.DeletedObjectRetention)
+	for _, inf := range infs {
+		inf.AddEventHandler(cache.ResourceEventHandlerFuncs{DeleteFunc: dc.observeDeletion})
+	}
+	registry.MustRegister(dc)
+}
+
+type deletedObject struct {
+	labelValues []string
+	deleted     time.Time
+}
+
+// deletedObjectCollector exposes the Unix deletion timestamp of recently
+// deleted objects.
+type deletedObjectCollector struct {
+	desc      *prometheus.Desc
+	labels    []string
+	retention time.Duration
+	now       func() time.Time
+
+	mu      sync.Mutex
+	objects map[string]deletedObject
+}
+
+func newDeletedObjectCollector(name string, labels []string, retention time.Duration) *deletedObjectCollector {
+	return &deletedObjectCollector{
+		desc:      prometheus.NewDesc(name, "Unix deletion timestamp of objects deleted recently.", labels, nil),
+		labels:    labels,
+		retention: retention,
+		now:       time.Now,
+		objects:   map[string]deletedObject{},
+	}
+}
+
+func (dc *deletedObjectCollector) observeDeletion(obj interface{}) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+	lv := make([]string, len(dc.labels))
+	for i, l := range dc.labels {
+		lv[i] = o.GetName()
+		if l == "namespace" && len(dc.labels) > 1 {
+			lv[i] = o.GetNamespace()
+		}
+	}
+
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	now := dc.now()
+	dc.prune(now)
+	dc.objects[strings.Join(lv, "/")] = deletedObject{labelValues: lv, deleted: now}
+}
+
+// prune forgets the objects deleted before the retention. It has to be
+// called with mu held.
+func (dc *deletedObjectCollector) prune(now time.Time) {
+	for k, o := range dc.objects {
+		if now.Sub(o.deleted) > dc.retention {
+			delete(dc.objects, k)
+		}
+	}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (dc *deletedObjectCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- dc.desc
+}
+
+// Collect implements the prometheus.Collector interface.
+func (dc *deletedObjectCollector) Collect(ch chan<- prometheus.Metric) {
+	dc.mu.Lock()
+	defer dc.mu.Unlock()
+	dc.prune(dc.now())
+	for _, o := range dc.objects {
+		ch <- mustNewConstMetric(dc.desc, prometheus.GaugeValue, float64(o.deleted.Unix()), append([]string{}, o.labelValues...)...)
+	}
+}
+
 func boolFloat64(b bool) float64 {
 	if b {
 		return 1
@@ -89,46 +561,237 @@ func boolFloat64(b bool) float64 {
 
 // addConditionMetrics generates one metric for each possible node condition
 // status. For this function to work properly, the last label in the metric
-// description must be the condition.
-func addConditionMetrics(ch chan<- prometheus.Metric, desc *prometheus.Desc, cs v1.ConditionStatus, lv ...string) {
-	ch <- prometheus.MustNewConstMetric(
+// description must be the condition. With the binary encoding, only the
+// metric of the true status is generated, so that conditions are 0 or 1 like
+// all other boolean metrics.
+func addConditionMetrics(ch chan<- prometheus.Metric, encoding options.ConditionEncoding, desc *prometheus.Desc, cs v1.ConditionStatus, lv ...string) {
+	ch <- mustNewConstMetric(
 		desc, prometheus.GaugeValue, boolFloat64(cs == v1.ConditionTrue),
 		append(lv, "true")...,
 	)
-	ch <- prometheus.MustNewConstMetric(
+	if encoding == options.ConditionEncodingBinary {
+		return
+	}
+	ch <- mustNewConstMetric(
 		desc, prometheus.GaugeValue, boolFloat64(cs == v1.ConditionFalse),
 		append(lv, "false")...,
 	)
-	ch <- prometheus.MustNewConstMetric(
+	ch <- mustNewConstMetric(
 		desc, prometheus.GaugeValue, boolFloat64(cs == v1.ConditionUnknown),
 		append(lv, "unknown")...,
 	)
 }
 
-func kubeLabelsToPrometheusLabels(labels map[string]string) ([]string, []string) {
+// addPodTemplateResourceMetrics generates a metric for every resource request
+// and limit of the containers of a pod template. For this function to work
+// properly, the metric descriptions must end with the container, resource and
+// unit labels.
+func addPodTemplateResourceMetrics(ch chan<- prometheus.Metric, requestsDesc, limitsDesc *prometheus.Desc, spec v1.PodSpec, lv ...string) {
+	for _, c := range spec.Containers {
+		for _, r := range []struct {
+			desc      *prometheus.Desc
+			resources v1.ResourceList
+		}{
+			{requestsDesc, c.Resources.Requests},
+			{limitsDesc, c.Resources.Limits},
+		} {
+			for resourceName, val := range r.resources {
+				v, unit, ok := resourceValue(resourceName, val)
+				if !ok {
+					continue
+				}
+				ch <- mustNewConstMetric(r.desc, prometheus.GaugeValue, v,
+					append(lv, c.Name, sanitizeLabelName(string(resourceName)), string(unit))...)
+			}
+		}
+	}
+}
+
+// resourceValue returns the value of a resource quantity in the unit the
+// resource metrics expose it in. Resources without a known unit are not
+// exposed.
+func resourceValue(resourceName v1.ResourceName, val resource.Quantity) (float64, constant.ResourceUnit, bool) {
+	switch resourceName {
+	case v1.ResourceCPU:
+		return float64(val.MilliValue()) / 1000, constant.UnitCore, true
+	case v1.ResourceStorage, v1.ResourceEphemeralStorage, v1.ResourceMemory:
+		return float64(val.Value()), constant.UnitByte, true
+	}
+	switch {
+	case helper.IsHugePageResourceName(resourceName), helper.IsAttachableVolumeResourceName(resourceName):
+		return float64(val.Value()), constant.UnitByte, true
+	case helper.IsExtendedResourceName(resourceName):
+		return float64(val.Value()), constant.UnitInteger, true
+	}
+	return 0, "", false
+}
+
+// newSummarizedObjectsDesc returns the descriptor of the
+// kube_summarized_objects family for the given resource. The resource is a
+// constant label so that every collector can register its own descriptor of
+// the shared family.
+func newSummarizedObjectsDesc(resource string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		"kube_summarized_objects",
+		"Number of objects per namespace for which no per-object metrics are exposed.",
+		[]string{"namespace"},
+		prometheus.Labels{"resource": resource},
+	)
+}
+
+// detailed returns whether per-object metrics should be exposed for the
+// given object. Without --detailed-namespaces and --detailed-label-selector
+// every object is detailed, cluster-scoped objects are always detailed.
+func detailed(opts *options.Options, obj metav1.Object) bool {
+	if len(opts.DetailedNamespaces) == 0 && opts.DetailedLabelSelector.Selector == nil {
+		return true
+	}
+	if obj.GetNamespace() == metav1.NamespaceAll {
+		return true
+	}
+	for _, ns := range opts.DetailedNamespaces {
+		if ns == obj.GetNamespace() {
+			return true
+		}
+	}
+	return opts.DetailedLabelSelector.Selector != nil && opts.DetailedLabelSelector.Matches(labels.Set(obj.GetLabels()))
+}
+
+// addSummarizedObjects exposes the number of objects per namespace that
+// were not detailed.
+func addSummarizedObjects(ch chan<- prometheus.Metric, desc *prometheus.Desc, summarized map[string]int) {
+	for ns, n := range summarized {
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, float64(n), ns)
+	}
+}
+
+// kubeLabelsToPrometheusLabels converts Kubernetes labels to Prometheus label
+// names and values. Values longer than maxValueLength are truncated, zero
+// means no limit. Invalid UTF-8 sequences are replaced before truncating, as
+// their replacement is longer than the sequence.
+func kubeLabelsToPrometheusLabels(labels map[string]string, maxValueLength int) ([]string, []string) {
 	labelKeys := make([]string, len(labels))
 	labelValues := make([]string, len(labels))
 	i := 0
 	for k, v := range labels {
 		labelKeys[i] = "label_" + sanitizeLabelName(k)
-		labelValues[i] = v
+		labelValues[i] = truncateLabelValue(sanitizeLabelValue(v), maxValueLength)
 		i++
 	}
 	return labelKeys, labelValues
 }
 
-func kubeAnnotationsToPrometheusAnnotations(annotations map[string]string) ([]string, []string) {
+// kubeAnnotationsToPrometheusAnnotations converts Kubernetes annotations to
+// Prometheus label names and values. Like label values, annotation values are
+// sanitized and then truncated to maxValueLength, zero means no limit.
+func kubeAnnotationsToPrometheusAnnotations(annotations map[string]string, maxValueLength int) ([]string, []string) {
 	annotationKeys := make([]string, len(annotations))
 	annotationValues := make([]string, len(annotations))
 	i := 0
 	for k, v := range annotations {
 		annotationKeys[i] = "annotation_" + sanitizeLabelName(k)
-		annotationValues[i] = v
+		annotationValues[i] = truncateLabelValue(sanitizeLabelValue(v), maxValueLength)
 		i++
 	}
 	return annotationKeys, annotationValues
 }
 
+// kubeWhitelistedAnnotationsToPrometheusAnnotations behaves like
+// kubeAnnotationsToPrometheusAnnotations but only converts the annotations
+// present in the given whitelist.
+func kubeWhitelistedAnnotationsToPrometheusAnnotations(annotations map[string]string, whitelist options.AnnotationSet, maxValueLength int) ([]string, []string) {
+	whitelisted := map[string]string{}
+	for k, v := range annotations {
+		if whitelist.Has(k) {
+			whitelisted[k] = v
+		}
+	}
+	return kubeAnnotationsToPrometheusAnnotations(whitelisted, maxValueLength)
+}
+
+// webhookLabelValues returns the name, the failure policy and the namespace
+// and name of the service of an admission webhook. Webhooks called by URL have
+// an empty service namespace and name.
+func webhookLabelValues(w admissionregistration.Webhook) []string {
+	failurePolicy := admissionregistration.Ignore
+	if w.FailurePolicy != nil {
+		failurePolicy = *w.FailurePolicy
+	}
+	var serviceNamespace, serviceName string
+	if s := w.ClientConfig.Service; s != nil {
+		serviceNamespace, serviceName = s.Namespace, s.Name
+	}
+	return []string{w.Name, string(failurePolicy), serviceNamespace, serviceName}
+}
+
+// workloadID identifies a top-level workload by a hash of its namespace, kind
+// and name. Unlike the uid it survives the workload being recreated, and
+// unlike the names of ReplicaSets or pods it doesn't change with rollouts.
+func workloadID(namespace, kind, name string) string {
+	h := fnv.New64a()
+	h.Write([]byte(namespace + "/" + kind + "/" + name))
+	return fmt.Sprintf("%016x", h.Sum64())
+}
+
+// MinLabelValueLength is the smallest --max-label-value-length that leaves
+// room for the hash suffix of truncated values.
+const MinLabelValueLength = 9
+
+// truncateLabelValue shortens values longer than maxLength bytes to at most
+// maxLength bytes. The truncated value ends with a tilde followed by a hash of
+// the original value, so that distinct values stay distinct. Below
+// MinLabelValueLength, the suffix itself is cut to maxLength. A maxLength of
+// zero means no limit.
+func truncateLabelValue(v string, maxLength int) string {
+	if maxLength <= 0 || len(v) <= maxLength {
+		return v
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(v))
+	suffix := fmt.Sprintf("~%08x", h.Sum32())
+	if maxLength < len(suffix) {
+		return suffix[:maxLength]
+	}
+
+	n := maxLength - len(suffix)
+	// Don't cut multi-byte characters in half.
+	for n > 0 && !utf8.RuneStart(v[n]) {
+		n--
+	}
+	return v[:n] + suffix
+}
+
+// mustNewConstMetric is prometheus.MustNewConstMetric for label values copied
+// from arbitrary object fields. Invalid UTF-8 sequences are replaced, as a
+// single invalid label value would otherwise make the whole scrape fail.
+func mustNewConstMetric(desc *prometheus.Desc, valueType prometheus.ValueType, value float64, labelValues ...string) prometheus.Metric {
+	for i, lv := range labelValues {
+		labelValues[i] = sanitizeLabelValue(lv)
+	}
+	return prometheus.MustNewConstMetric(desc, valueType, value, labelValues...)
+}
+
+// sanitizeLabelValue replaces every invalid UTF-8 sequence in s with the
+// Unicode replacement character.
+func sanitizeLabelValue(s string) string {
+	if utf8.ValidString(s) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); {
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b.WriteRune(utf8.RuneError)
+		} else {
+			b.WriteString(s[i : i+size])
+		}
+		i += size
+	}
+	return b.String()
+}
+
 func sanitizeLabelName(s string) string {
 	return invalidLabelCharRE.ReplaceAllString(s, "_")
 }
@@ -0,0 +1,243 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestSyncTrackerClockSkew(t *testing.T) {
+	const metadata = `
+		# HELP ksm_clock_skew_seconds Number of seconds the creation timestamp of the newest object of a resource was ahead of the local clock when it was observed
+		# TYPE ksm_clock_skew_seconds gauge
+	`
+	now := time.Unix(1500000000, 0)
+	type observation struct {
+		created, observed time.Duration
+	}
+
+	cases := []struct {
+		observations []observation
+		want         string
+	}{
+		// Objects created in the past don't indicate skew.
+		{
+			observations: []observation{{-time.Hour, 0}, {-time.Minute, 0}},
+			want: metadata + `
+				ksm_clock_skew_seconds{resource="pod"} 0
+			`,
+		},
+		// Only the newest object determines the skew.
+		{
+			observations: []observation{{-time.Hour, 0}, {5 * time.Second, 0}, {time.Second, 0}},
+			want: metadata + `
+				ksm_clock_skew_seconds{resource="pod"} 5
+			`,
+		},
+		// Skew is reset once a newer object is not ahead anymore.
+		{
+			observations: []observation{{5 * time.Second, 0}, {6 * time.Second, 10 * time.Second}},
+			want: metadata + `
+				ksm_clock_skew_seconds{resource="pod"} 0
+			`,
+		},
+	}
+	for _, c := range cases {
+		tracker := NewSyncTracker()
+		tracker.clockSkew = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: "ksm_clock_skew_seconds",
+			Help: "Number of seconds the creation timestamp of the newest object of a resource was ahead of the local clock when it was observed",
+		}, []string{"resource"})
+
+		for _, o := range c.observations {
+			tracker.now = func() time.Time { return now.Add(o.observed) }
+			tracker.observeCreation("pod", &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{CreationTimestamp: metav1.Time{Time: now.Add(o.created)}},
+			})
+		}
+		if err := testutils.GatherAndCompare(tracker.clockSkew, c.want, nil); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
+
+func TestSanitizeLabelValue(t *testing.T) {
+	tests := []struct {
+		Value  string
+		Wanted string
+	}{
+		{Value: "", Wanted: ""},
+		{Value: "nginx", Wanted: "nginx"},
+		{Value: "größe 日本", Wanted: "größe 日本"},
+		{Value: "a\xffb", Wanted: "a\uFFFDb"},
+		{Value: "\xe6\x97", Wanted: "\uFFFD\uFFFD"},
+	}
+
+	for _, test := range tests {
+		if got := sanitizeLabelValue(test.Value); got != test.Wanted {
+			t.Errorf("sanitizing %q: want %q, got %q", test.Value, test.Wanted, got)
+		}
+	}
+}
+
+func TestTruncateLabelValue(t *testing.T) {
+	tests := []struct {
+		Value     string
+		MaxLength int
+		Wanted    string
+	}{
+		{Value: "nginx", MaxLength: 0, Wanted: "nginx"},
+		{Value: "nginx", MaxLength: 5, Wanted: "nginx"},
+		{Value: "abcdefghijklmnopqrstuvwxyz", MaxLength: 16, Wanted: "abcdefg~b0bc0c82"},
+		{Value: "abcdefghijklmnopqrstuvwxyZ", MaxLength: 16, Wanted: "abcdefg~90bbda22"},
+		// The multi-byte character is dropped instead of being cut in half.
+		{Value: "abcdef日本語xyz", MaxLength: 16, Wanted: "abcdef~557a4975"},
+		// Without room for the value, the hash suffix is cut instead.
+		{Value: "abcdefghijklmnopqrstuvwxyz", MaxLength: 9, Wanted: "~b0bc0c82"},
+		{Value: "abcdefghijklmnopqrstuvwxyz", MaxLength: 8, Wanted: "~b0bc0c8"},
+		{Value: "abcdefghijklmnopqrstuvwxyz", MaxLength: 1, Wanted: "~"},
+		{Value: "ab", MaxLength: 1, Wanted: "~"},
+	}
+
+	for _, test := range tests {
+		got := truncateLabelValue(test.Value, test.MaxLength)
+		if got != test.Wanted {
+			t.Errorf("truncating %q to %d: want %q, got %q", test.Value, test.MaxLength, test.Wanted, got)
+		}
+		if test.MaxLength > 0 && len(got) > test.MaxLength {
+			t.Errorf("truncating %q to %d: got %d bytes", test.Value, test.MaxLength, len(got))
+		}
+	}
+}
+
+func TestKubeLabelsToPrometheusLabelsSanitizesBeforeTruncating(t *testing.T) {
+	// Every invalid byte is replaced by the three bytes of U+FFFD.
+	v := strings.Repeat("\xff", 64)
+	const maxLength = 16
+
+	_, labelValues := kubeLabelsToPrometheusLabels(map[string]string{"app": v}, maxLength)
+	_, annotationValues := kubeAnnotationsToPrometheusAnnotations(map[string]string{"app": v}, maxLength)
+	for _, got := range []string{labelValues[0], annotationValues[0]} {
+		if len(got) > maxLength {
+			t.Errorf("converting %q with a maximum length of %d: got %d bytes", v, maxLength, len(got))
+		}
+		if !utf8.ValidString(got) {
+			t.Errorf("converting %q: got invalid UTF-8 %q", v, got)
+		}
+	}
+
+	// Short invalid values may grow past the limit when sanitized.
+	_, labelValues = kubeLabelsToPrometheusLabels(map[string]string{"app": "\xff\xff\xff\xff\xff\xff\xff\xff"}, maxLength)
+	if len(labelValues[0]) > maxLength {
+		t.Errorf("converting a short invalid value: got %d bytes, more than %d", len(labelValues[0]), maxLength)
+	}
+}
+
+func TestWorkloadID(t *testing.T) {
+	id := workloadID("ns1", "Deployment", "depl1")
+	if want := "b83d9403569aaef4"; id != want {
+		t.Errorf("want workload id %q, got %q", want, id)
+	}
+	if other := workloadID("ns1", "StatefulSet", "depl1"); other == id {
+		t.Errorf("workloads of different kinds share the id %q", id)
+	}
+	if other := workloadID("ns2", "Deployment", "depl1"); other == id {
+		t.Errorf("workloads in different namespaces share the id %q", id)
+	}
+}
+
+func TestRecoverObjectError(t *testing.T) {
+	collect := func(ch chan<- prometheus.Metric, o metav1.ObjectMeta, panics bool) {
+		defer recoverObjectError("test", &o)
+
+		if panics {
+			var p *v1.Pod
+			_ = p.Name
+		}
+		ch <- prometheus.MustNewConstMetric(descPodInfo, prometheus.GaugeValue, 1, o.Namespace, o.Name, "", "", "", "", "", "")
+	}
+
+	ch := make(chan prometheus.Metric, 2)
+	collect(ch, metav1.ObjectMeta{Namespace: "ns1", Name: "pod1"}, true)
+	collect(ch, metav1.ObjectMeta{Namespace: "ns1", Name: "pod2"}, false)
+	close(ch)
+
+	if n := len(ch); n != 1 {
+		t.Errorf("want metrics of 1 object, got %d", n)
+	}
+	m := &dto.Metric{}
+	if err := ObjectErrorTotalMetric.WithLabelValues("test").Write(m); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if errors := m.GetCounter().GetValue(); errors != 1 {
+		t.Errorf("want 1 object error, got %v", errors)
+	}
+}
+
+func TestDeletedObjectCollector(t *testing.T) {
+	now := time.Unix(1500000000, 0)
+
+	pods := newDeletedObjectCollector("kube_pod_deleted", descPodLabelsDefaultLabels, 10*time.Minute)
+	pods.now = func() time.Time { return now }
+	pods.observeDeletion(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: "pod1"}})
+	now = now.Add(5 * time.Minute)
+	pods.observeDeletion(cache.DeletedFinalStateUnknown{Key: "ns2/pod2", Obj: &v1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "ns2", Name: "pod2"}}})
+
+	want := `
+		# HELP kube_pod_deleted Unix deletion timestamp of objects deleted recently.
+		# TYPE kube_pod_deleted gauge
+		kube_pod_deleted{namespace="ns1",pod="pod1"} 1.5e+09
+		kube_pod_deleted{namespace="ns2",pod="pod2"} 1.5000003e+09
+	`
+	if err := testutils.GatherAndCompare(pods, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	// pod1 is forgotten after the retention.
+	now = now.Add(6 * time.Minute)
+	want = `
+		# HELP kube_pod_deleted Unix deletion timestamp of objects deleted recently.
+		# TYPE kube_pod_deleted gauge
+		kube_pod_deleted{namespace="ns2",pod="pod2"} 1.5000003e+09
+	`
+	if err := testutils.GatherAndCompare(pods, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	// Cluster-scoped objects only have a name label.
+	nodes := newDeletedObjectCollector("kube_node_deleted", descNodeLabelsDefaultLabels, 10*time.Minute)
+	nodes.now = func() time.Time { return now }
+	nodes.observeDeletion(&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1"}})
+	want = `
+		# HELP kube_node_deleted Unix deletion timestamp of objects deleted recently.
+		# TYPE kube_node_deleted gauge
+		kube_node_deleted{node="node1"} 1.50000066e+09
+	`
+	if err := testutils.GatherAndCompare(nodes, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
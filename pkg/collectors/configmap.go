@@ -22,7 +22,6 @@ import (
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -49,6 +48,8 @@ var (
 		append(descConfigMapLabelsDefaultLabels, "resource_version"),
 		nil,
 	)
+
+	descConfigMapSummarizedObjects = newSummarizedObjectsDesc("configmap")
 )
 
 type ConfigMapLister func() ([]v1.ConfigMap, error)
@@ -60,7 +61,7 @@ func (l ConfigMapLister) List() ([]v1.ConfigMap, error) {
 func RegisterConfigMapCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().ConfigMaps().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().ConfigMaps().Informer(), opts))
 	}
 
 	configMapLister := ConfigMapLister(func() (configMaps []v1.ConfigMap, err error) {
@@ -72,7 +73,13 @@ func RegisterConfigMapCollector(registry prometheus.Registerer, informerFactorie
 		return configMaps, nil
 	})
 
-	registry.MustRegister(&configMapCollector{store: configMapLister, opts: opts})
+	cmc := &configMapCollector{store: configMapLister, opts: opts}
+	cmc.metrics = newObjectMetricsCache(registry, infs, opts, func(ch chan<- prometheus.Metric, obj interface{}) {
+		cmc.collectConfigMap(ch, *obj.(*v1.ConfigMap))
+	})
+	registry.MustRegister(cmc)
+	registerDeletedObjects(registry, infs, "kube_configmap_deleted", descConfigMapLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("configmap", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -82,8 +89,9 @@ type configMapStore interface {
 
 // configMapCollector collects metrics about all configMaps in the cluster.
 type configMapCollector struct {
-	store configMapStore
-	opts  *options.Options
+	store   configMapStore
+	opts    *options.Options
+	metrics *objectMetricsCache
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -91,30 +99,41 @@ func (sc *configMapCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descConfigMapInfo
 	ch <- descConfigMapCreated
 	ch <- descConfigMapMetadataResourceVersion
+	ch <- descConfigMapSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (cmc *configMapCollector) Collect(ch chan<- prometheus.Metric) {
 	configMaps, err := cmc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "configmap"}).Inc()
+		addScrapeError(ch, "configmap", err)
 		glog.Errorf("listing configmaps failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "configmap"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "configmap"}).Observe(float64(len(configMaps)))
+	summarized := map[string]int{}
 	for _, s := range configMaps {
-		cmc.collectConfigMap(ch, s)
+		if !detailed(cmc.opts, &s.ObjectMeta) {
+			summarized[s.Namespace]++
+			continue
+		}
+		if cmc.metrics == nil {
+			cmc.collectConfigMap(ch, s)
+		}
 	}
+	addSummarizedObjects(ch, descConfigMapSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d configmaps", len(configMaps))
 }
 
 func (cmc *configMapCollector) collectConfigMap(ch chan<- prometheus.Metric, s v1.ConfigMap) {
+	defer recoverObjectError("configmap", &s.ObjectMeta)
+
 	addConstMetric := func(desc *prometheus.Desc, t prometheus.ValueType, v float64, lv ...string) {
 		lv = append([]string{s.Namespace, s.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, t, v, lv...)
+		ch <- mustNewConstMetric(desc, t, v, lv...)
 	}
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		addConstMetric(desc, prometheus.GaugeValue, v, lv...)
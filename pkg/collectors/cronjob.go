@@ -28,7 +28,9 @@ import (
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 
+	v1batch "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	"k8s.io/api/core/v1"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -46,7 +48,7 @@ var (
 	descCronJobInfo = prometheus.NewDesc(
 		"kube_cronjob_info",
 		"Info about cronjob.",
-		append(descCronJobLabelsDefaultLabels, "schedule", "concurrency_policy"),
+		append(descCronJobLabelsDefaultLabels, "schedule", "concurrency_policy", "workload_id"),
 		nil,
 	)
 	descCronJobCreated = prometheus.NewDesc(
@@ -85,8 +87,78 @@ var (
 		descCronJobLabelsDefaultLabels,
 		nil,
 	)
+
+	descCronJobSummarizedObjects = newSummarizedObjectsDesc("cronjob")
 )
 
+func newCronJobCompletionCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_cronjob_job_completions_total",
+			Help: "The number of jobs of the cronjob observed to complete since kube-state-metrics started, by result.",
+		},
+		append(descCronJobLabelsDefaultLabels, "result"),
+	)
+}
+
+// jobResult returns whether the job completed successfully or failed, and
+// when. Jobs which haven't finished yet have no result.
+func jobResult(j *v1batch.Job) (result string, finished time.Time, ok bool) {
+	for _, c := range j.Status.Conditions {
+		if c.Status != v1.ConditionTrue {
+			continue
+		}
+		switch c.Type {
+		case v1batch.JobComplete:
+			return "success", c.LastTransitionTime.Time, true
+		case v1batch.JobFailed:
+			return "failed", c.LastTransitionTime.Time, true
+		}
+	}
+	return "", time.Time{}, false
+}
+
+// cronJobCompletionHandler counts the jobs of cronjobs as they finish. Jobs
+// that finished before since are skipped when first listed, and updates only
+// count jobs that weren't finished before, so relists and resyncs don't count
+// jobs twice.
+func cronJobCompletionHandler(completions *prometheus.CounterVec, since time.Time) cache.ResourceEventHandler {
+	count := func(j *v1batch.Job, result string) {
+		owner := metav1.GetControllerOf(j)
+		if owner == nil || owner.Kind != "CronJob" {
+			return
+		}
+		completions.WithLabelValues(j.Namespace, owner.Name, result).Inc()
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			j, ok := obj.(*v1batch.Job)
+			if !ok {
+				return
+			}
+			if result, finished, ok := jobResult(j); ok && !finished.Before(since) {
+				count(j, result)
+			}
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			old, ok := oldObj.(*v1batch.Job)
+			if !ok {
+				return
+			}
+			j, ok := newObj.(*v1batch.Job)
+			if !ok {
+				return
+			}
+			if _, _, finished := jobResult(old); finished {
+				return
+			}
+			if result, _, ok := jobResult(j); ok {
+				count(j, result)
+			}
+		},
+	}
+}
+
 type CronJobLister func() ([]batchv1beta1.CronJob, error)
 
 func (l CronJobLister) List() ([]batchv1beta1.CronJob, error) {
@@ -97,7 +169,7 @@ func RegisterCronJobCollector(registry prometheus.Registerer, informerFactories
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Batch().V1beta1().CronJobs().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Batch().V1beta1().CronJobs().Informer(), opts))
 	}
 
 	cronJobLister := CronJobLister(func() (cronjobs []batchv1beta1.CronJob, err error) {
@@ -109,8 +181,24 @@ func RegisterCronJobCollector(registry prometheus.Registerer, informerFactories
 		return cronjobs, nil
 	})
 
-	registry.MustRegister(&cronJobCollector{store: cronJobLister, opts: opts})
+	// Completions are observed on the jobs of the cronjobs, which are gone
+	// soon after they finished. They are counted by the shard of the cronjob.
+	jobInfs := SharedInformerList{}
+	for _, f := range informerFactories {
+		jobInfs = append(jobInfs, newControllerShardedInformer(f.Batch().V1().Jobs().Informer(), opts))
+	}
+	completions := newCronJobCompletionCounter()
+	since := time.Now()
+	for _, jinf := range jobInfs {
+		jinf.AddEventHandler(cronJobCompletionHandler(completions, since))
+	}
+
+	registry.MustRegister(&cronJobCollector{store: cronJobLister, opts: opts}, completions)
+	registerDeletedObjects(registry, infs, "kube_cronjob_deleted", descCronJobLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("cronjob", infs)
+	InformerSyncTracker.TrackDependencies("cronjob", jobInfs)
 	infs.Run(context.Background().Done())
+	jobInfs.Run(context.Background().Done())
 }
 
 type cronJobStore interface {
@@ -133,22 +221,29 @@ func (dc *cronJobCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descCronJobSpecSuspend
 	ch <- descCronJobSpecStartingDeadlineSeconds
 	ch <- descCronJobNextScheduledTime
+	ch <- descCronJobSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (cjc *cronJobCollector) Collect(ch chan<- prometheus.Metric) {
 	cronjobs, err := cjc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "cronjob"}).Inc()
+		addScrapeError(ch, "cronjob", err)
 		glog.Errorf("listing cronjobs failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "cronjob"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "cronjob"}).Observe(float64(len(cronjobs)))
+	summarized := map[string]int{}
 	for _, cj := range cronjobs {
+		if !detailed(cjc.opts, &cj.ObjectMeta) {
+			summarized[cj.Namespace]++
+			continue
+		}
 		cjc.collectCronJob(ch, cj)
 	}
+	addSummarizedObjects(ch, descCronJobSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d cronjobs", len(cronjobs))
 }
@@ -177,9 +272,11 @@ func cronJobLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (jc *cronJobCollector) collectCronJob(ch chan<- prometheus.Metric, j batchv1beta1.CronJob) {
+	defer recoverObjectError("cronjob", &j.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{j.Namespace, j.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 
 	if j.Spec.StartingDeadlineSeconds != nil {
@@ -194,9 +291,9 @@ func (jc *cronJobCollector) collectCronJob(ch chan<- prometheus.Metric, j batchv
 		addGauge(descCronJobNextScheduledTime, float64(nextScheduledTime.Unix()))
 	}
 
-	addGauge(descCronJobInfo, 1, j.Spec.Schedule, string(j.Spec.ConcurrencyPolicy))
+	addGauge(descCronJobInfo, 1, j.Spec.Schedule, string(j.Spec.ConcurrencyPolicy), workloadID(j.Namespace, "CronJob", j.Name))
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels, jc.opts.MaxLabelValueLength)
 	addGauge(cronJobLabelsDesc(labelKeys), 1, labelValues...)
 
 	if !j.CreationTimestamp.IsZero() {
@@ -22,11 +22,12 @@ import (
 	"testing"
 	"time"
 
+	v1batch "k8s.io/api/batch/v1"
 	batchv1beta1 "k8s.io/api/batch/v1beta1"
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 var (
@@ -197,9 +198,9 @@ func TestCronJobCollector(t *testing.T) {
 			want: metadata + `
 				kube_cronjob_created{cronjob="ActiveCronJob1NoLastScheduled",namespace="ns1"} 1.520766296e+09
 
-				kube_cronjob_info{concurrency_policy="Forbid",cronjob="ActiveRunningCronJob1",namespace="ns1",schedule="0 */6 * * *"} 1
-				kube_cronjob_info{concurrency_policy="Forbid",cronjob="SuspendedCronJob1",namespace="ns1",schedule="0 */3 * * *"} 1
-				kube_cronjob_info{concurrency_policy="Forbid",cronjob="ActiveCronJob1NoLastScheduled",namespace="ns1",schedule="25 * * * *"} 1
+				kube_cronjob_info{concurrency_policy="Forbid",cronjob="ActiveRunningCronJob1",namespace="ns1",schedule="0 */6 * * *",workload_id="aba4387319a73829"} 1
+				kube_cronjob_info{concurrency_policy="Forbid",cronjob="SuspendedCronJob1",namespace="ns1",schedule="0 */3 * * *",workload_id="e34d4c2f75a1ebfd"} 1
+				kube_cronjob_info{concurrency_policy="Forbid",cronjob="ActiveCronJob1NoLastScheduled",namespace="ns1",schedule="25 * * * *",workload_id="708ffa32618f9c10"} 1
 
 				kube_cronjob_labels{cronjob="ActiveCronJob1NoLastScheduled",label_app="example-active-no-last-scheduled-1",namespace="ns1"} 1
 				kube_cronjob_labels{cronjob="ActiveRunningCronJob1",label_app="example-active-running-1",namespace="ns1"} 1
@@ -239,3 +240,61 @@ func TestCronJobCollector(t *testing.T) {
 		}
 	}
 }
+
+func TestCronJobCompletionHandler(t *testing.T) {
+	const metadata = `
+		# HELP kube_cronjob_job_completions_total The number of jobs of the cronjob observed to complete since kube-state-metrics started, by result.
+		# TYPE kube_cronjob_job_completions_total counter
+	`
+
+	since := time.Unix(1500000000, 0)
+	controller := true
+	newJob := func(name, cronJob string, condition v1batch.JobConditionType, finished time.Time) *v1batch.Job {
+		j := &v1batch.Job{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "ns1",
+			},
+		}
+		if cronJob != "" {
+			j.OwnerReferences = []metav1.OwnerReference{
+				{Kind: "CronJob", Name: cronJob, Controller: &controller},
+			}
+		}
+		if condition != "" {
+			j.Status.Conditions = []v1batch.JobCondition{
+				{Type: condition, Status: v1.ConditionTrue, LastTransitionTime: metav1.Time{Time: finished}},
+			}
+		}
+		return j
+	}
+
+	completions := newCronJobCompletionCounter()
+	h := cronJobCompletionHandler(completions, since)
+
+	// Finished before the start.
+	h.OnAdd(newJob("backup-1", "backup", v1batch.JobComplete, since.Add(-time.Hour)))
+	// Finished while kube-state-metrics was starting.
+	h.OnAdd(newJob("backup-2", "backup", v1batch.JobFailed, since.Add(time.Second)))
+
+	running := newJob("backup-3", "backup", "", time.Time{})
+	h.OnAdd(running)
+	succeeded := newJob("backup-3", "backup", v1batch.JobComplete, since.Add(time.Minute))
+	h.OnUpdate(running, succeeded)
+	h.OnUpdate(succeeded, succeeded)
+
+	running = newJob("report-1", "report", "", time.Time{})
+	h.OnUpdate(running, newJob("report-1", "report", v1batch.JobFailed, since.Add(time.Minute)))
+
+	// Not owned by a cronjob.
+	h.OnUpdate(newJob("manual", "", "", time.Time{}), newJob("manual", "", v1batch.JobComplete, since.Add(time.Minute)))
+
+	want := metadata + `
+		kube_cronjob_job_completions_total{cronjob="backup",namespace="ns1",result="failed"} 1
+		kube_cronjob_job_completions_total{cronjob="backup",namespace="ns1",result="success"} 1
+		kube_cronjob_job_completions_total{cronjob="report",namespace="ns1",result="failed"} 1
+	`
+	if err := testutils.GatherAndCompare(completions, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// CSIDrivers were added to storage.k8s.io after the vendored client-go,
+	// which has no typed client for them.
+	csiDriverResource = CustomResource{
+		Group:    "storage.k8s.io",
+		Version:  "v1",
+		Resource: "csidrivers",
+		Kind:     "CSIDriver",
+	}
+
+	descCSIDriverLabelsDefaultLabels = []string{"csidriver"}
+
+	descCSIDriverCreated = prometheus.NewDesc(
+		"kube_csidriver_created",
+		"Unix creation timestamp",
+		descCSIDriverLabelsDefaultLabels,
+		nil,
+	)
+	descCSIDriverSpecAttachRequired = prometheus.NewDesc(
+		"kube_csidriver_spec_attach_required",
+		"Whether volumes of the CSI driver have to be attached to nodes before they are mounted.",
+		descCSIDriverLabelsDefaultLabels,
+		nil,
+	)
+	descCSIDriverSpecPodInfoOnMount = prometheus.NewDesc(
+		"kube_csidriver_spec_pod_info_on_mount",
+		"Whether the CSI driver gets information about the pod when mounting volumes.",
+		descCSIDriverLabelsDefaultLabels,
+		nil,
+	)
+)
+
+// RegisterCSIDriverCollector registers a collector of the CSIDrivers, which
+// are cluster-scoped. Like the runtimeclasses collector it lists them on
+// every scrape.
+func RegisterCSIDriverCollector(registry prometheus.Registerer, client rest.Interface, opts *options.Options) {
+	registry.MustRegister(&csiDriverCollector{store: restCustomResourceStore{client: client}, opts: opts})
+}
+
+// csiDriverCollector collects metrics about all CSIDrivers in the cluster.
+type csiDriverCollector struct {
+	store customResourceStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (cc *csiDriverCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descCSIDriverCreated
+	ch <- descCSIDriverSpecAttachRequired
+	ch <- descCSIDriverSpecPodInfoOnMount
+}
+
+// Collect implements the prometheus.Collector interface.
+func (cc *csiDriverCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, cc.store, csiDriverResource, options.NamespaceList{""}, cc.opts, func(obj unstructured.Unstructured) {
+		cc.collectCSIDriver(ch, obj)
+	})
+}
+
+func (cc *csiDriverCollector) collectCSIDriver(ch chan<- prometheus.Metric, d unstructured.Unstructured) {
+	defer recoverObjectError("csidriver", &d)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{d.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	if t := d.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descCSIDriverCreated, float64(t.Unix()))
+	}
+
+	// Unset fields take the defaults of the apiserver.
+	attachRequired, ok, _ := unstructured.NestedBool(d.Object, "spec", "attachRequired")
+	if !ok {
+		attachRequired = true
+	}
+	addGauge(descCSIDriverSpecAttachRequired, boolFloat64(attachRequired))
+	podInfoOnMount, _, _ := unstructured.NestedBool(d.Object, "spec", "podInfoOnMount")
+	addGauge(descCSIDriverSpecPodInfoOnMount, boolFloat64(podInfoOnMount))
+}
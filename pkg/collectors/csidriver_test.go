@@ -0,0 +1,67 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestCSIDriverCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_csidriver_created Unix creation timestamp
+		# TYPE kube_csidriver_created gauge
+		# HELP kube_csidriver_spec_attach_required Whether volumes of the CSI driver have to be attached to nodes before they are mounted.
+		# TYPE kube_csidriver_spec_attach_required gauge
+		# HELP kube_csidriver_spec_pod_info_on_mount Whether the CSI driver gets information about the pod when mounting volumes.
+		# TYPE kube_csidriver_spec_pod_info_on_mount gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"csidrivers": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "ebs.csi.aws.com",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+				},
+				"spec": map[string]interface{}{},
+			}},
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name": "secrets-store.csi.k8s.io",
+				},
+				"spec": map[string]interface{}{
+					"attachRequired": false,
+					"podInfoOnMount": true,
+				},
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_csidriver_created{csidriver="ebs.csi.aws.com"} 1.501569018e+09
+		kube_csidriver_spec_attach_required{csidriver="ebs.csi.aws.com"} 1
+		kube_csidriver_spec_attach_required{csidriver="secrets-store.csi.k8s.io"} 0
+		kube_csidriver_spec_pod_info_on_mount{csidriver="ebs.csi.aws.com"} 0
+		kube_csidriver_spec_pod_info_on_mount{csidriver="secrets-store.csi.k8s.io"} 1
+	`
+	cc := &csiDriverCollector{store: store, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(cc, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// CSINodes were added to storage.k8s.io after the vendored client-go,
+	// which has no typed client for them.
+	csiNodeResource = CustomResource{
+		Group:    "storage.k8s.io",
+		Version:  "v1",
+		Resource: "csinodes",
+		Kind:     "CSINode",
+	}
+
+	descCSINodeLabelsDefaultLabels = []string{"csinode"}
+
+	descCSINodeCreated = prometheus.NewDesc(
+		"kube_csinode_created",
+		"Unix creation timestamp",
+		descCSINodeLabelsDefaultLabels,
+		nil,
+	)
+	descCSINodeDriverInfo = prometheus.NewDesc(
+		"kube_csinode_driver_info",
+		"Information about a CSI driver installed on the node.",
+		append(descCSINodeLabelsDefaultLabels, "driver", "node_id", "topology_keys"),
+		nil,
+	)
+	descCSINodeDriverAllocatableVolumes = prometheus.NewDesc(
+		"kube_csinode_driver_allocatable_volumes",
+		"Maximum number of volumes of the CSI driver which can be attached to the node.",
+		append(descCSINodeLabelsDefaultLabels, "driver"),
+		nil,
+	)
+)
+
+// RegisterCSINodeCollector registers a collector of the CSINodes, which are
+// cluster-scoped. Like the runtimeclasses collector it lists them on every
+// scrape.
+func RegisterCSINodeCollector(registry prometheus.Registerer, client rest.Interface, opts *options.Options) {
+	registry.MustRegister(&csiNodeCollector{store: restCustomResourceStore{client: client}, opts: opts})
+}
+
+// csiNodeCollector collects metrics about all CSINodes in the cluster.
+type csiNodeCollector struct {
+	store customResourceStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (cc *csiNodeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descCSINodeCreated
+	ch <- descCSINodeDriverInfo
+	ch <- descCSINodeDriverAllocatableVolumes
+}
+
+// Collect implements the prometheus.Collector interface.
+func (cc *csiNodeCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, cc.store, csiNodeResource, options.NamespaceList{""}, cc.opts, func(obj unstructured.Unstructured) {
+		cc.collectCSINode(ch, obj)
+	})
+}
+
+func (cc *csiNodeCollector) collectCSINode(ch chan<- prometheus.Metric, n unstructured.Unstructured) {
+	defer recoverObjectError("csinode", &n)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{n.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	if t := n.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descCSINodeCreated, float64(t.Unix()))
+	}
+
+	drivers, _, _ := unstructured.NestedSlice(n.Object, "spec", "drivers")
+	for _, d := range drivers {
+		driver, ok := d.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		name, _, _ := unstructured.NestedString(driver, "name")
+		nodeID, _, _ := unstructured.NestedString(driver, "nodeID")
+		topologyKeys, _, _ := unstructured.NestedStringSlice(driver, "topologyKeys")
+		addGauge(descCSINodeDriverInfo, 1, name, nodeID, strings.Join(topologyKeys, ","))
+
+		if count, ok, _ := unstructured.NestedInt64(driver, "allocatable", "count"); ok {
+			addGauge(descCSINodeDriverAllocatableVolumes, float64(count), name)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestCSINodeCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_csinode_created Unix creation timestamp
+		# TYPE kube_csinode_created gauge
+		# HELP kube_csinode_driver_info Information about a CSI driver installed on the node.
+		# TYPE kube_csinode_driver_info gauge
+		# HELP kube_csinode_driver_allocatable_volumes Maximum number of volumes of the CSI driver which can be attached to the node.
+		# TYPE kube_csinode_driver_allocatable_volumes gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"csinodes": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "node1",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+				},
+				"spec": map[string]interface{}{
+					"drivers": []interface{}{
+						map[string]interface{}{
+							"name":         "ebs.csi.aws.com",
+							"nodeID":       "i-0123456789",
+							"topologyKeys": []interface{}{"topology.ebs.csi.aws.com/zone", "kubernetes.io/os"},
+							"allocatable":  map[string]interface{}{"count": int64(25)},
+						},
+						map[string]interface{}{
+							"name":   "nfs.csi.k8s.io",
+							"nodeID": "node1",
+						},
+					},
+				},
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_csinode_created{csinode="node1"} 1.501569018e+09
+		kube_csinode_driver_info{csinode="node1",driver="ebs.csi.aws.com",node_id="i-0123456789",topology_keys="topology.ebs.csi.aws.com/zone,kubernetes.io/os"} 1
+		kube_csinode_driver_info{csinode="node1",driver="nfs.csi.k8s.io",node_id="node1",topology_keys=""} 1
+		kube_csinode_driver_allocatable_volumes{csinode="node1",driver="ebs.csi.aws.com"} 25
+	`
+	cc := &csiNodeCollector{store: store, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(cc, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
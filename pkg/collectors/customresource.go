@@ -0,0 +1,441 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/model"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/jsonpath"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// CustomResourceStateConfig is the format of the custom resource state
+// configuration file.
+type CustomResourceStateConfig struct {
+	Resources []CustomResource `json:"resources"`
+}
+
+// CustomResource describes the metrics generated for the objects of a
+// resource served by the apiserver, typically one defined by a
+// CustomResourceDefinition.
+type CustomResource struct {
+	// Group, Version and Resource identify the resource, e.g.
+	// cert-manager.io, v1 and certificates. The group is empty for the core
+	// group.
+	Group    string `json:"group"`
+	Version  string `json:"version"`
+	Resource string `json:"resource"`
+	// Kind is the kind of the objects. Its lower case form is the label
+	// holding the name of the objects.
+	Kind string `json:"kind"`
+	// Namespaced is whether the objects are namespaced, in which case the
+	// metrics carry a namespace label.
+	Namespaced bool `json:"namespaced"`
+	// MetricNamePrefix is prepended to the names of the metrics. It defaults
+	// to kube_ followed by the lower case kind.
+	MetricNamePrefix string `json:"metricNamePrefix"`
+	// LabelsFromPath maps label names to JSONPath expressions whose results
+	// are added as labels to all metrics of the resource.
+	LabelsFromPath map[string]string      `json:"labelsFromPath"`
+	Metrics        []CustomResourceMetric `json:"metrics"`
+}
+
+// CustomResourceMetric describes a metric generated for every object of a
+// custom resource.
+type CustomResourceMetric struct {
+	// Name is appended to the metric name prefix of the resource.
+	Name string `json:"name"`
+	Help string `json:"help"`
+	// Type is gauge, whose value is the result of Path, or info, whose
+	// value is always 1.
+	Type string `json:"type"`
+	// Path is a JSONPath expression such as .status.replicas. Booleans are
+	// converted to 0 or 1, strings are looked up in ValueMap or parsed as
+	// numbers. Objects without a numeric result don't get the metric.
+	Path     string             `json:"path"`
+	ValueMap map[string]float64 `json:"valueMap"`
+	// LabelsFromPath maps label names to JSONPath expressions whose results
+	// are added as labels to the metric.
+	LabelsFromPath map[string]string `json:"labelsFromPath"`
+}
+
+// LoadCustomResourceStateConfig reads and validates the custom resource
+// state configuration of the given YAML file.
+func LoadCustomResourceStateConfig(path string) (*CustomResourceStateConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseCustomResourceStateConfig(b)
+}
+
+// ParseCustomResourceStateConfig parses and validates a YAML custom resource
+// state configuration.
+func ParseCustomResourceStateConfig(b []byte) (*CustomResourceStateConfig, error) {
+	var c CustomResourceStateConfig
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	names := map[string]bool{}
+	for i, r := range c.Resources {
+		if r.Version == "" || r.Resource == "" || r.Kind == "" {
+			return nil, fmt.Errorf("resource %d: version, resource and kind are required", i)
+		}
+		if err := validateLabelPaths(r.LabelsFromPath); err != nil {
+			return nil, fmt.Errorf("resource %d: %v", i, err)
+		}
+		for j, m := range r.Metrics {
+			name := r.metricName(m)
+			if !model.IsValidMetricName(model.LabelValue(name)) {
+				return nil, fmt.Errorf("resource %d, metric %d: invalid metric name %q", i, j, name)
+			}
+			if names[name] {
+				return nil, fmt.Errorf("resource %d, metric %d: duplicate metric name %q", i, j, name)
+			}
+			names[name] = true
+
+			switch m.Type {
+			case "gauge":
+				if _, err := parseJSONPath(m.Path); err != nil {
+					return nil, fmt.Errorf("resource %d, metric %d: invalid path %q: %v", i, j, m.Path, err)
+				}
+			case "info":
+			default:
+				return nil, fmt.Errorf("resource %d, metric %d: unknown type %q", i, j, m.Type)
+			}
+			if err := validateLabelPaths(m.LabelsFromPath); err != nil {
+				return nil, fmt.Errorf("resource %d, metric %d: %v", i, j, err)
+			}
+			labels := map[string]bool{}
+			for _, l := range r.labelNames(m) {
+				if labels[l] {
+					return nil, fmt.Errorf("resource %d, metric %d: duplicate label name %q", i, j, l)
+				}
+				labels[l] = true
+			}
+		}
+	}
+	return &c, nil
+}
+
+func validateLabelPaths(labelsFromPath map[string]string) error {
+	for l, path := range labelsFromPath {
+		if !model.LabelName(l).IsValid() {
+			return fmt.Errorf("invalid label name %q", l)
+		}
+		if _, err := parseJSONPath(path); err != nil {
+			return fmt.Errorf("invalid path %q of label %q: %v", path, l, err)
+		}
+	}
+	return nil
+}
+
+// parseJSONPath parses a JSONPath expression, which may be given with or
+// without the surrounding braces of kubectl's JSONPath templates.
+func parseJSONPath(path string) (*jsonpath.JSONPath, error) {
+	if !strings.HasPrefix(path, "{") {
+		path = "{" + path + "}"
+	}
+	jp := jsonpath.New("path")
+	jp.AllowMissingKeys(true)
+	if err := jp.Parse(path); err != nil {
+		return nil, err
+	}
+	return jp, nil
+}
+
+// findJSONPath returns the first result of the JSONPath expression in obj.
+func findJSONPath(path string, obj map[string]interface{}) (interface{}, bool) {
+	// JSONPath expressions keep state while being evaluated, so they are
+	// parsed for every evaluation to allow concurrent scrapes.
+	jp, err := parseJSONPath(path)
+	if err != nil {
+		return nil, false
+	}
+	results, err := jp.FindResults(obj)
+	if err != nil || len(results) == 0 || len(results[0]) == 0 {
+		return nil, false
+	}
+	v := results[0][0]
+	if !v.IsValid() || !v.CanInterface() {
+		return nil, false
+	}
+	return v.Interface(), true
+}
+
+func (r CustomResource) metricName(m CustomResourceMetric) string {
+	prefix := r.MetricNamePrefix
+	if prefix == "" {
+		prefix = "kube_" + strings.ToLower(r.Kind)
+	}
+	return prefix + "_" + m.Name
+}
+
+// labelNames returns the names of the labels of the metric: the namespace of
+// namespaced objects, the name of the objects, and the labels from paths of
+// the resource and of the metric, each sorted by name.
+func (r CustomResource) labelNames(m CustomResourceMetric) []string {
+	var labels []string
+	if r.Namespaced {
+		labels = append(labels, "namespace")
+	}
+	labels = append(labels, strings.ToLower(r.Kind))
+	labels = append(labels, sortedKeys(r.LabelsFromPath)...)
+	return append(labels, sortedKeys(m.LabelsFromPath)...)
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// customResourceStore lists the objects of custom resources.
+type customResourceStore interface {
+	List(r CustomResource, namespace string) ([]unstructured.Unstructured, error)
+}
+
+// restCustomResourceStore lists the objects of custom resources with a REST
+// client, as the typed clients only know the built-in resources.
+type restCustomResourceStore struct {
+	client rest.Interface
+}
+
+func (s restCustomResourceStore) List(r CustomResource, namespace string) ([]unstructured.Unstructured, error) {
+	path := []string{"/apis", r.Group, r.Version}
+	if r.Group == "" {
+		path = []string{"/api", r.Version}
+	}
+	if namespace != "" {
+		path = append(path, "namespaces", namespace)
+	}
+	path = append(path, r.Resource)
+
+	// The objects are decoded as JSON, while the client may prefer protobuf,
+	// in which built-in resources are served.
+	b, err := s.client.Get().AbsPath(path...).SetHeader("Accept", "application/json").Context(metrics.UntrackedContext(context.Background())).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	var list unstructured.UnstructuredList
+	if err := list.UnmarshalJSON(b); err != nil {
+		return nil, err
+	}
+	return list.Items, nil
+}
+
+// listCustomResourceObjects lists the objects of a resource in the given
+// namespaces. Clusters not serving the resource, e.g. because they are too
+// old, have no objects, which is not an error.
+func listCustomResourceObjects(store customResourceStore, r CustomResource, namespaces options.NamespaceList) ([]unstructured.Unstructured, error) {
+	var objs []unstructured.Unstructured
+	for _, ns := range namespaces {
+		l, err := store.List(r, ns)
+		if apierrors.IsNotFound(err) {
+			glog.V(4).Infof("%s are not served by the apiserver", r.Resource)
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		objs = append(objs, l...)
+	}
+	return objs, nil
+}
+
+// collectCustomResourceObjects collects the objects of a resource owned by the
+// shard of opts and records the outcome of the scrape. It backs the collectors
+// of well-known resources without typed clients in the vendored client-go.
+func collectCustomResourceObjects(ch chan<- prometheus.Metric, store customResourceStore, r CustomResource, namespaces options.NamespaceList, opts *options.Options, collect func(unstructured.Unstructured)) {
+	resourceLabel := prometheus.Labels{"resource": r.Resource[:len(r.Resource)-1]}
+	objs, err := listCustomResourceObjects(store, r, namespaces)
+	if err != nil {
+		addScrapeError(ch, resourceLabel["resource"], err)
+		glog.Errorf("listing %s failed: %s", r.Resource, err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(resourceLabel).Add(0)
+	ResourcesPerScrapeMetric.With(resourceLabel).Observe(float64(len(objs)))
+	for _, obj := range objs {
+		if ownedByShard(opts, obj.GetUID()) {
+			collect(obj)
+		}
+	}
+	glog.V(4).Infof("collected %d %s", len(objs), r.Resource)
+}
+
+// RegisterCustomResourceStateCollector registers a collector generating the
+// metrics of the given configuration for the objects of custom resources.
+// Like the apiresources collector it is not backed by informers: the objects
+// are listed on every scrape.
+func RegisterCustomResourceStateCollector(registry prometheus.Registerer, client rest.Interface, config *CustomResourceStateConfig, namespaces options.NamespaceList, opts *options.Options) {
+	registry.MustRegister(newCustomResourceCollector(restCustomResourceStore{client: client}, config, namespaces, opts))
+}
+
+type customResourceMetric struct {
+	CustomResourceMetric
+	desc *prometheus.Desc
+}
+
+type customResource struct {
+	CustomResource
+	metrics []customResourceMetric
+}
+
+// customResourceCollector collects the configured metrics of custom
+// resources.
+type customResourceCollector struct {
+	store      customResourceStore
+	resources  []customResource
+	namespaces options.NamespaceList
+	opts       *options.Options
+}
+
+func newCustomResourceCollector(store customResourceStore, config *CustomResourceStateConfig, namespaces options.NamespaceList, opts *options.Options) *customResourceCollector {
+	cc := &customResourceCollector{store: store, namespaces: namespaces, opts: opts}
+	for _, r := range config.Resources {
+		cr := customResource{CustomResource: r}
+		for _, m := range r.Metrics {
+			help := m.Help
+			if help == "" {
+				help = fmt.Sprintf("%s of %s.", m.Name, r.Kind)
+			}
+			cr.metrics = append(cr.metrics, customResourceMetric{
+				CustomResourceMetric: m,
+				desc:                 prometheus.NewDesc(r.metricName(m), help, r.labelNames(m), nil),
+			})
+		}
+		cc.resources = append(cc.resources, cr)
+	}
+	return cc
+}
+
+// Describe implements the prometheus.Collector interface.
+func (cc *customResourceCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, r := range cc.resources {
+		for _, m := range r.metrics {
+			ch <- m.desc
+		}
+	}
+}
+
+// Collect implements the prometheus.Collector interface.
+func (cc *customResourceCollector) Collect(ch chan<- prometheus.Metric) {
+	var listErr error
+	var n int
+	for _, r := range cc.resources {
+		namespaces := cc.namespaces
+		if !r.Namespaced {
+			namespaces = options.NamespaceList{""}
+		}
+		objs, err := listCustomResourceObjects(cc.store, r.CustomResource, namespaces)
+		if err != nil {
+			listErr = err
+			glog.Errorf("listing %s failed: %s", r.Resource, err)
+			continue
+		}
+		for _, obj := range objs {
+			if ownedByShard(cc.opts, obj.GetUID()) {
+				cc.collectCustomResource(ch, r, obj)
+			}
+		}
+		n += len(objs)
+	}
+
+	if listErr != nil {
+		addScrapeError(ch, "customresource", listErr)
+	} else {
+		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "customresource"}).Add(0)
+	}
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "customresource"}).Observe(float64(n))
+	glog.V(4).Infof("collected %d customresources", n)
+}
+
+func (cc *customResourceCollector) collectCustomResource(ch chan<- prometheus.Metric, r customResource, obj unstructured.Unstructured) {
+	var base []string
+	if r.Namespaced {
+		base = append(base, obj.GetNamespace())
+	}
+	base = append(base, obj.GetName())
+	base = append(base, labelValuesFromPaths(r.LabelsFromPath, obj.Object)...)
+
+	for _, m := range r.metrics {
+		v := 1.0
+		if m.Type == "gauge" {
+			result, ok := findJSONPath(m.Path, obj.Object)
+			if !ok {
+				continue
+			}
+			if v, ok = customResourceValue(result, m.ValueMap); !ok {
+				continue
+			}
+		}
+		lv := append(append([]string{}, base...), labelValuesFromPaths(m.LabelsFromPath, obj.Object)...)
+		ch <- mustNewConstMetric(m.desc, prometheus.GaugeValue, v, lv...)
+	}
+}
+
+// labelValuesFromPaths returns the results of the JSONPath expressions,
+// ordered by label name. Missing results yield empty label values.
+func labelValuesFromPaths(labelsFromPath map[string]string, obj map[string]interface{}) []string {
+	var values []string
+	for _, l := range sortedKeys(labelsFromPath) {
+		result, ok := findJSONPath(labelsFromPath[l], obj)
+		if !ok || result == nil {
+			values = append(values, "")
+			continue
+		}
+		values = append(values, fmt.Sprint(result))
+	}
+	return values
+}
+
+// customResourceValue converts a JSONPath result to a metric value.
+func customResourceValue(result interface{}, valueMap map[string]float64) (float64, bool) {
+	switch v := result.(type) {
+	case int64:
+		return float64(v), true
+	case float64:
+		return v, true
+	case bool:
+		return boolFloat64(v), true
+	case string:
+		if f, ok := valueMap[v]; ok {
+			return f, true
+		}
+		f, err := strconv.ParseFloat(v, 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,312 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockCustomResourceStore struct {
+	objects map[string][]unstructured.Unstructured
+}
+
+func (s mockCustomResourceStore) List(r CustomResource, namespace string) ([]unstructured.Unstructured, error) {
+	objs, ok := s.objects[r.Resource]
+	if !ok {
+		return nil, fmt.Errorf("resource %s not found", r.Resource)
+	}
+	var filtered []unstructured.Unstructured
+	for _, obj := range objs {
+		if namespace == "" || obj.GetNamespace() == namespace {
+			filtered = append(filtered, obj)
+		}
+	}
+	return filtered, nil
+}
+
+const testCustomResourceStateConfig = `
+resources:
+- group: cert-manager.io
+  version: v1
+  resource: certificates
+  kind: Certificate
+  namespaced: true
+  labelsFromPath:
+    issuer: .spec.issuerRef.name
+  metrics:
+  - name: ready
+    help: Whether the certificate is ready.
+    type: gauge
+    path: .status.conditions[?(@.type=="Ready")].status
+    valueMap:
+      "True": 1
+      "False": 0
+  - name: revision
+    help: The revision of the certificate.
+    type: gauge
+    path: .status.revision
+  - name: info
+    help: Information about the certificate.
+    type: info
+    labelsFromPath:
+      secret_name: .spec.secretName
+- group: argoproj.io
+  version: v1alpha1
+  resource: appprojects
+  kind: AppProject
+  metricNamePrefix: argocd_appproject
+  metrics:
+  - name: orphaned_resources_warn
+    type: gauge
+    path: '{.spec.orphanedResources.warn}'
+`
+
+// newProtobufPreferringClient returns the REST client of the discovery API of
+// a clientset configured like the one of kube-state-metrics, which prefers
+// protobuf. It talks to an apiserver serving the given JSON lists by path,
+// which like a real one answers in protobuf whenever that is preferred. The
+// returned function stops the apiserver.
+func newProtobufPreferringClient(t *testing.T, lists map[string]string) (rest.Interface, func()) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list, ok := lists[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		if !strings.HasPrefix(r.Header.Get("Accept"), "application/json") {
+			w.Header().Set("Content-Type", "application/vnd.kubernetes.protobuf")
+			w.Write([]byte("k8s\x00\n\x0c"))
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(list))
+	}))
+
+	client, err := clientset.NewForConfig(&rest.Config{
+		Host: srv.URL,
+		ContentConfig: rest.ContentConfig{
+			AcceptContentTypes: "application/vnd.kubernetes.protobuf,application/json",
+			ContentType:        "application/vnd.kubernetes.protobuf",
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error creating client: %v", err)
+	}
+	return client.Discovery().RESTClient(), srv.Close
+}
+
+func TestRESTCustomResourceStoreRequestsJSON(t *testing.T) {
+	client, stop := newProtobufPreferringClient(t, map[string]string{
+		"/apis/storage.k8s.io/v1/storageclasses": `{"apiVersion":"storage.k8s.io/v1","kind":"StorageClassList","items":[{"metadata":{"name":"standard"}}]}`,
+	})
+	defer stop()
+	r := CustomResource{Group: "storage.k8s.io", Version: "v1", Resource: "storageclasses", Kind: "StorageClass"}
+
+	objs, err := restCustomResourceStore{client: client}.List(r, "")
+	if err != nil {
+		t.Fatalf("unexpected error listing a built-in resource: %v", err)
+	}
+	if len(objs) != 1 || objs[0].GetName() != "standard" {
+		t.Errorf("expected the storage class standard, got %v", objs)
+	}
+}
+
+func TestCustomResourceCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_certificate_ready Whether the certificate is ready.
+		# TYPE kube_certificate_ready gauge
+		# HELP kube_certificate_revision The revision of the certificate.
+		# TYPE kube_certificate_revision gauge
+		# HELP kube_certificate_info Information about the certificate.
+		# TYPE kube_certificate_info gauge
+		# HELP argocd_appproject_orphaned_resources_warn orphaned_resources_warn of AppProject.
+		# TYPE argocd_appproject_orphaned_resources_warn gauge
+	`
+
+	config, err := ParseCustomResourceStateConfig([]byte(testCustomResourceStateConfig))
+	if err != nil {
+		t.Fatalf("unexpected error parsing config: %v", err)
+	}
+
+	newObject := func(namespace, name string, spec, status map[string]interface{}) unstructured.Unstructured {
+		obj := unstructured.Unstructured{Object: map[string]interface{}{"spec": spec}}
+		if status != nil {
+			obj.Object["status"] = status
+		}
+		obj.SetNamespace(namespace)
+		obj.SetName(name)
+		return obj
+	}
+	store := mockCustomResourceStore{
+		objects: map[string][]unstructured.Unstructured{
+			"certificates": {
+				newObject("ns1", "web", map[string]interface{}{
+					"issuerRef":  map[string]interface{}{"name": "letsencrypt"},
+					"secretName": "web-tls",
+				}, map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "Issuing", "status": "False"},
+						map[string]interface{}{"type": "Ready", "status": "True"},
+					},
+					"revision": int64(3),
+				}),
+				// Not issued yet.
+				newObject("ns2", "api", map[string]interface{}{
+					"secretName": "api-tls",
+				}, nil),
+			},
+			"appprojects": {
+				newObject("argocd", "default", map[string]interface{}{
+					"orphanedResources": map[string]interface{}{"warn": true},
+				}, nil),
+			},
+		},
+	}
+
+	cases := []struct {
+		namespaces options.NamespaceList
+		want       string
+		metrics    []string
+	}{
+		{
+			namespaces: options.NamespaceList{""},
+			want: metadata + `
+				kube_certificate_ready{certificate="web",issuer="letsencrypt",namespace="ns1"} 1
+				kube_certificate_revision{certificate="web",issuer="letsencrypt",namespace="ns1"} 3
+				kube_certificate_info{certificate="web",issuer="letsencrypt",namespace="ns1",secret_name="web-tls"} 1
+				kube_certificate_info{certificate="api",issuer="",namespace="ns2",secret_name="api-tls"} 1
+				argocd_appproject_orphaned_resources_warn{appproject="default"} 1
+			`,
+		},
+		{
+			// Cluster-scoped resources are listed regardless of the namespaces.
+			namespaces: options.NamespaceList{"ns2"},
+			want: metadata + `
+				kube_certificate_info{certificate="api",issuer="",namespace="ns2",secret_name="api-tls"} 1
+				argocd_appproject_orphaned_resources_warn{appproject="default"} 1
+			`,
+		},
+	}
+	for _, c := range cases {
+		cc := newCustomResourceCollector(store, config, c.namespaces, &options.Options{})
+		if err := testutils.GatherAndCompare(cc, c.want, c.metrics); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
+
+func TestCustomResourceCollectorWithoutCRDs(t *testing.T) {
+	config, err := ParseCustomResourceStateConfig([]byte(testCustomResourceStateConfig))
+	if err != nil {
+		t.Fatalf("unexpected error parsing config: %v", err)
+	}
+	registry := prometheus.NewRegistry()
+	registry.MustRegister(newCustomResourceCollector(notFoundCustomResourceStore{}, config, options.NamespaceList{""}, &options.Options{}))
+
+	// Configured resources which are not installed have no objects.
+	if _, err := registry.Gather(); err != nil {
+		t.Errorf("expected no scrape error without the CustomResourceDefinitions, got %v", err)
+	}
+}
+
+func TestParseCustomResourceStateConfig(t *testing.T) {
+	cases := []struct {
+		config string
+		err    bool
+	}{
+		{config: testCustomResourceStateConfig},
+		{
+			config: `
+resources:
+- version: v1
+  resource: certificates
+`,
+			err: true,
+		},
+		{
+			config: `
+resources:
+- version: v1
+  resource: certificates
+  kind: Certificate
+  metrics:
+  - name: ready
+    type: histogram
+`,
+			err: true,
+		},
+		{
+			config: `
+resources:
+- version: v1
+  resource: certificates
+  kind: Certificate
+  metrics:
+  - name: ready
+    type: gauge
+    path: .status[
+`,
+			err: true,
+		},
+		{
+			config: `
+resources:
+- version: v1
+  resource: certificates
+  kind: Certificate
+  metrics:
+  - name: ready
+    type: gauge
+    path: .status.ready
+  - name: ready
+    type: info
+`,
+			err: true,
+		},
+		{
+			config: `
+resources:
+- version: v1
+  resource: certificates
+  kind: Certificate
+  metrics:
+  - name: info
+    type: info
+    labelsFromPath:
+      certificate: .metadata.name
+`,
+			err: true,
+		},
+	}
+	for i, c := range cases {
+		_, err := ParseCustomResourceStateConfig([]byte(c.config))
+		if c.err != (err != nil) {
+			t.Errorf("case %d: expected error %t, got %v", i, c.err, err)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// CustomResourceDefinitions are served by apiextensions.k8s.io, whose
+	// typed client is not vendored.
+	customResourceDefinitionResource = CustomResource{
+		Group:    "apiextensions.k8s.io",
+		Version:  "v1",
+		Resource: "customresourcedefinitions",
+		Kind:     "CustomResourceDefinition",
+	}
+
+	descCustomResourceDefinitionLabelsDefaultLabels = []string{"customresourcedefinition"}
+
+	descCustomResourceDefinitionInfo = prometheus.NewDesc(
+		"kube_customresourcedefinition_info",
+		"Information about custom resource definition.",
+		append(descCustomResourceDefinitionLabelsDefaultLabels, "group", "version", "scope"),
+		nil,
+	)
+	descCustomResourceDefinitionCreated = prometheus.NewDesc(
+		"kube_customresourcedefinition_created",
+		"Unix creation timestamp",
+		descCustomResourceDefinitionLabelsDefaultLabels,
+		nil,
+	)
+	descCustomResourceDefinitionStatusCondition = prometheus.NewDesc(
+		"kube_customresourcedefinition_status_condition",
+		"The condition of a custom resource definition.",
+		append(descCustomResourceDefinitionLabelsDefaultLabels, "condition", "status"),
+		nil,
+	)
+)
+
+// RegisterCustomResourceDefinitionCollector registers a collector of the
+// CustomResourceDefinitions, which are cluster-scoped. Like the
+// runtimeclasses collector it lists them on every scrape.
+func RegisterCustomResourceDefinitionCollector(registry prometheus.Registerer, client rest.Interface, opts *options.Options) {
+	registry.MustRegister(&customResourceDefinitionCollector{store: restCustomResourceStore{client: client}, opts: opts})
+}
+
+// customResourceDefinitionCollector collects metrics about all
+// CustomResourceDefinitions in the cluster.
+type customResourceDefinitionCollector struct {
+	store customResourceStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (cc *customResourceDefinitionCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descCustomResourceDefinitionInfo
+	ch <- descCustomResourceDefinitionCreated
+	ch <- descCustomResourceDefinitionStatusCondition
+}
+
+// Collect implements the prometheus.Collector interface.
+func (cc *customResourceDefinitionCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, cc.store, customResourceDefinitionResource, options.NamespaceList{""}, cc.opts, func(obj unstructured.Unstructured) {
+		cc.collectCustomResourceDefinition(ch, obj)
+	})
+}
+
+func (cc *customResourceDefinitionCollector) collectCustomResourceDefinition(ch chan<- prometheus.Metric, d unstructured.Unstructured) {
+	defer recoverObjectError("customresourcedefinition", &d)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{d.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	group, _, _ := unstructured.NestedString(d.Object, "spec", "group")
+	scope, _, _ := unstructured.NestedString(d.Object, "spec", "scope")
+	addGauge(descCustomResourceDefinitionInfo, 1, group, storageVersion(d.Object), scope)
+
+	if t := d.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descCustomResourceDefinitionCreated, float64(t.Unix()))
+	}
+
+	conditions, _, _ := unstructured.NestedSlice(d.Object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		conditionType, _, _ := unstructured.NestedString(condition, "type")
+		status, _, _ := unstructured.NestedString(condition, "status")
+		addConditionMetrics(ch, cc.opts.ConditionEncoding, descCustomResourceDefinitionStatusCondition, v1.ConditionStatus(status), d.GetName(), conditionType)
+	}
+}
+
+// storageVersion returns the version in which the objects of a custom
+// resource definition are stored.
+func storageVersion(obj map[string]interface{}) string {
+	versions, _, _ := unstructured.NestedSlice(obj, "spec", "versions")
+	for _, v := range versions {
+		version, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if storage, _, _ := unstructured.NestedBool(version, "storage"); storage {
+			name, _, _ := unstructured.NestedString(version, "name")
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestCustomResourceDefinitionCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_customresourcedefinition_info Information about custom resource definition.
+		# TYPE kube_customresourcedefinition_info gauge
+		# HELP kube_customresourcedefinition_created Unix creation timestamp
+		# TYPE kube_customresourcedefinition_created gauge
+		# HELP kube_customresourcedefinition_status_condition The condition of a custom resource definition.
+		# TYPE kube_customresourcedefinition_status_condition gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"customresourcedefinitions": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "certificates.cert-manager.io",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+				},
+				"spec": map[string]interface{}{
+					"group": "cert-manager.io",
+					"scope": "Namespaced",
+					"versions": []interface{}{
+						map[string]interface{}{"name": "v1alpha2", "served": true, "storage": false},
+						map[string]interface{}{"name": "v1", "served": true, "storage": true},
+					},
+				},
+				"status": map[string]interface{}{
+					"conditions": []interface{}{
+						map[string]interface{}{"type": "NamesAccepted", "status": "True"},
+						map[string]interface{}{"type": "Established", "status": "False"},
+					},
+				},
+			}},
+		},
+	}}
+
+	tests := []struct {
+		encoding options.ConditionEncoding
+		want     string
+	}{
+		{
+			encoding: options.ConditionEncodingStateSet,
+			want: metadata + `
+				kube_customresourcedefinition_info{customresourcedefinition="certificates.cert-manager.io",group="cert-manager.io",scope="Namespaced",version="v1"} 1
+				kube_customresourcedefinition_created{customresourcedefinition="certificates.cert-manager.io"} 1.501569018e+09
+				kube_customresourcedefinition_status_condition{condition="Established",customresourcedefinition="certificates.cert-manager.io",status="false"} 1
+				kube_customresourcedefinition_status_condition{condition="Established",customresourcedefinition="certificates.cert-manager.io",status="true"} 0
+				kube_customresourcedefinition_status_condition{condition="Established",customresourcedefinition="certificates.cert-manager.io",status="unknown"} 0
+				kube_customresourcedefinition_status_condition{condition="NamesAccepted",customresourcedefinition="certificates.cert-manager.io",status="false"} 0
+				kube_customresourcedefinition_status_condition{condition="NamesAccepted",customresourcedefinition="certificates.cert-manager.io",status="true"} 1
+				kube_customresourcedefinition_status_condition{condition="NamesAccepted",customresourcedefinition="certificates.cert-manager.io",status="unknown"} 0
+			`,
+		},
+		{
+			encoding: options.ConditionEncodingBinary,
+			want: metadata + `
+				kube_customresourcedefinition_info{customresourcedefinition="certificates.cert-manager.io",group="cert-manager.io",scope="Namespaced",version="v1"} 1
+				kube_customresourcedefinition_created{customresourcedefinition="certificates.cert-manager.io"} 1.501569018e+09
+				kube_customresourcedefinition_status_condition{condition="Established",customresourcedefinition="certificates.cert-manager.io",status="true"} 0
+				kube_customresourcedefinition_status_condition{condition="NamesAccepted",customresourcedefinition="certificates.cert-manager.io",status="true"} 1
+			`,
+		},
+	}
+	for _, test := range tests {
+		cc := &customResourceDefinitionCollector{store: store, opts: &options.Options{ConditionEncoding: test.encoding}}
+		if err := testutils.GatherAndCompare(cc, test.want, nil); err != nil {
+			t.Errorf("unexpected collecting result with %s encoding:\n%s", test.encoding, err)
+		}
+	}
+}
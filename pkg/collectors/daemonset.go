@@ -17,12 +17,13 @@ limitations under the License.
 package collectors
 
 import (
+	"strconv"
+
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/context"
 	"k8s.io/api/extensions/v1beta1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -31,6 +32,15 @@ var (
 	descDaemonSetLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descDaemonSetLabelsDefaultLabels = []string{"namespace", "daemonset"}
 
+	descDaemonSetAnnotationsName = "kube_daemonset_annotations"
+	descDaemonSetAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
+	descDaemonSetInfo = prometheus.NewDesc(
+		"kube_daemonset_info",
+		"Information about daemonset.",
+		append(descDaemonSetLabelsDefaultLabels, "workload_id"),
+		nil,
+	)
 	descDaemonSetCreated = prometheus.NewDesc(
 		"kube_daemonset_created",
 		"Unix creation timestamp",
@@ -85,12 +95,38 @@ var (
 		descDaemonSetLabelsDefaultLabels,
 		nil,
 	)
+	descDaemonSetSpecTemplateResourceRequests = prometheus.NewDesc(
+		"kube_daemonset_spec_template_resource_requests",
+		"The number of requested resource by a container of the pod template.",
+		append(descDaemonSetLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+	descDaemonSetSpecTemplateResourceLimits = prometheus.NewDesc(
+		"kube_daemonset_spec_template_resource_limits",
+		"The number of resource limit of a container of the pod template.",
+		append(descDaemonSetLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
 	descDaemonSetLabels = prometheus.NewDesc(
 		descDaemonSetLabelsName,
 		descDaemonSetLabelsHelp,
 		descDaemonSetLabelsDefaultLabels,
 		nil,
 	)
+	descDaemonSetAnnotations = prometheus.NewDesc(
+		descDaemonSetAnnotationsName,
+		descDaemonSetAnnotationsHelp,
+		descDaemonSetLabelsDefaultLabels,
+		nil,
+	)
+	descDaemonSetOwner = prometheus.NewDesc(
+		"kube_daemonset_owner",
+		"Information about the DaemonSet's owner.",
+		append(descDaemonSetLabelsDefaultLabels, "owner_kind", "owner_name", "owner_is_controller"),
+		nil,
+	)
+
+	descDaemonSetSummarizedObjects = newSummarizedObjectsDesc("daemonset")
 )
 
 type DaemonSetLister func() ([]v1beta1.DaemonSet, error)
@@ -103,7 +139,7 @@ func RegisterDaemonSetCollector(registry prometheus.Registerer, informerFactorie
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Extensions().V1beta1().DaemonSets().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Extensions().V1beta1().DaemonSets().Informer(), opts))
 	}
 
 	dsLister := DaemonSetLister(func() (daemonsets []v1beta1.DaemonSet, err error) {
@@ -116,6 +152,8 @@ func RegisterDaemonSetCollector(registry prometheus.Registerer, informerFactorie
 	})
 
 	registry.MustRegister(&daemonsetCollector{store: dsLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_daemonset_deleted", descDaemonSetLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("daemonset", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -131,6 +169,7 @@ type daemonsetCollector struct {
 
 // Describe implements the prometheus.Collector interface.
 func (dc *daemonsetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descDaemonSetInfo
 	ch <- descDaemonSetCreated
 	ch <- descDaemonSetCurrentNumberScheduled
 	ch <- descDaemonSetNumberAvailable
@@ -140,23 +179,34 @@ func (dc *daemonsetCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descDaemonSetNumberReady
 	ch <- descDaemonSetUpdatedNumberScheduled
 	ch <- descDaemonSetMetadataGeneration
+	ch <- descDaemonSetSpecTemplateResourceRequests
+	ch <- descDaemonSetSpecTemplateResourceLimits
 	ch <- descDaemonSetLabels
+	ch <- descDaemonSetAnnotations
+	ch <- descDaemonSetOwner
+	ch <- descDaemonSetSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (dc *daemonsetCollector) Collect(ch chan<- prometheus.Metric) {
 	dss, err := dc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "daemonset"}).Inc()
+		addScrapeError(ch, "daemonset", err)
 		glog.Errorf("listing daemonsets failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "daemonset"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "daemonset"}).Observe(float64(len(dss)))
+	summarized := map[string]int{}
 	for _, d := range dss {
+		if !detailed(dc.opts, &d.ObjectMeta) {
+			summarized[d.Namespace]++
+			continue
+		}
 		dc.collectDaemonSet(ch, d)
 	}
+	addSummarizedObjects(ch, descDaemonSetSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d daemonsets", len(dss))
 }
@@ -170,11 +220,23 @@ func DaemonSetLabelsDesc(labelKeys []string) *prometheus.Desc {
 	)
 }
 
+func daemonSetAnnotationsDesc(annotationKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descDaemonSetAnnotationsName,
+		descDaemonSetAnnotationsHelp,
+		append(descDaemonSetLabelsDefaultLabels, annotationKeys...),
+		nil,
+	)
+}
+
 func (dc *daemonsetCollector) collectDaemonSet(ch chan<- prometheus.Metric, d v1beta1.DaemonSet) {
+	defer recoverObjectError("daemonset", &d.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{d.Namespace, d.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
+	addGauge(descDaemonSetInfo, 1, workloadID(d.Namespace, "DaemonSet", d.Name))
 	if !d.CreationTimestamp.IsZero() {
 		addGauge(descDaemonSetCreated, float64(d.CreationTimestamp.Unix()))
 	}
@@ -186,7 +248,25 @@ func (dc *daemonsetCollector) collectDaemonSet(ch chan<- prometheus.Metric, d v1
 	addGauge(descDaemonSetNumberReady, float64(d.Status.NumberReady))
 	addGauge(descDaemonSetUpdatedNumberScheduled, float64(d.Status.UpdatedNumberScheduled))
 	addGauge(descDaemonSetMetadataGeneration, float64(d.ObjectMeta.Generation))
+	addPodTemplateResourceMetrics(ch, descDaemonSetSpecTemplateResourceRequests, descDaemonSetSpecTemplateResourceLimits,
+		d.Spec.Template.Spec, d.Namespace, d.Name)
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.ObjectMeta.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.ObjectMeta.Labels, dc.opts.MaxLabelValueLength)
 	addGauge(DaemonSetLabelsDesc(labelKeys), 1, labelValues...)
+
+	annotationKeys, annotationValues := kubeWhitelistedAnnotationsToPrometheusAnnotations(d.ObjectMeta.Annotations, dc.opts.AnnotationWhitelist, dc.opts.MaxLabelValueLength)
+	addGauge(daemonSetAnnotationsDesc(annotationKeys), 1, annotationValues...)
+
+	owners := d.GetOwnerReferences()
+	if len(owners) == 0 {
+		addGauge(descDaemonSetOwner, 1, "<none>", "<none>", "<none>")
+	} else {
+		for _, owner := range owners {
+			if owner.Controller != nil {
+				addGauge(descDaemonSetOwner, 1, owner.Kind, owner.Name, strconv.FormatBool(*owner.Controller))
+			} else {
+				addGauge(descDaemonSetOwner, 1, owner.Kind, owner.Name, "false")
+			}
+		}
+	}
 }
@@ -20,10 +20,12 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockDaemonSetStore struct {
@@ -34,10 +36,14 @@ func (ds mockDaemonSetStore) List() (daemonsets []v1beta1.DaemonSet, err error)
 	return ds.f()
 }
 
+var dsController = true
+
 func TestDaemonSetCollector(t *testing.T) {
 	// Fixed metadata on type and help text. We prepend this to every expected
 	// output so we only have to modify a single place when doing adjustments.
 	const metadata = `
+		# HELP kube_daemonset_info Information about daemonset.
+		# TYPE kube_daemonset_info gauge
 		# HELP kube_daemonset_created Unix creation timestamp
 		# TYPE kube_daemonset_created gauge
 		# HELP kube_daemonset_metadata_generation Sequence number representing a specific generation of the desired state.
@@ -58,6 +64,14 @@ func TestDaemonSetCollector(t *testing.T) {
 		# TYPE kube_daemonset_updated_number_scheduled gauge
 		# HELP kube_daemonset_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_daemonset_labels gauge
+		# HELP kube_daemonset_annotations Kubernetes annotations converted to Prometheus labels.
+		# TYPE kube_daemonset_annotations gauge
+		# HELP kube_daemonset_owner Information about the DaemonSet's owner.
+		# TYPE kube_daemonset_owner gauge
+		# HELP kube_daemonset_spec_template_resource_requests The number of requested resource by a container of the pod template.
+		# TYPE kube_daemonset_spec_template_resource_requests gauge
+		# HELP kube_daemonset_spec_template_resource_limits The number of resource limit of a container of the pod template.
+		# TYPE kube_daemonset_spec_template_resource_limits gauge
 `
 	cases := []struct {
 		dss  []v1beta1.DaemonSet
@@ -72,6 +86,10 @@ func TestDaemonSetCollector(t *testing.T) {
 						Labels: map[string]string{
 							"app": "example1",
 						},
+						Annotations: map[string]string{
+							"team":                          "infra",
+							"deprecated.daemonset.template": "{}",
+						},
 						Generation: 21,
 					},
 					Status: v1beta1.DaemonSetStatus{
@@ -105,6 +123,32 @@ func TestDaemonSetCollector(t *testing.T) {
 							"app": "example3",
 						},
 						Generation: 15,
+						OwnerReferences: []metav1.OwnerReference{
+							{
+								Kind:       "Addon",
+								Name:       "node-agent",
+								Controller: &dsController,
+							},
+						},
+					},
+					Spec: v1beta1.DaemonSetSpec{
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										Name: "agent",
+										Resources: v1.ResourceRequirements{
+											Requests: v1.ResourceList{
+												v1.ResourceEphemeralStorage: resource.MustParse("1Gi"),
+											},
+											Limits: v1.ResourceList{
+												v1.ResourceCPU: resource.MustParse("100m"),
+											},
+										},
+									},
+								},
+							},
+						},
 					},
 					Status: v1beta1.DaemonSetStatus{
 						CurrentNumberScheduled: 10,
@@ -118,6 +162,9 @@ func TestDaemonSetCollector(t *testing.T) {
 				},
 			},
 			want: metadata + `
+				kube_daemonset_info{daemonset="ds1",namespace="ns1",workload_id="6756204abba1c7a5"} 1
+				kube_daemonset_info{daemonset="ds2",namespace="ns2",workload_id="dc7a6cbd335a3d35"} 1
+				kube_daemonset_info{daemonset="ds3",namespace="ns3",workload_id="8eb985a343306365"} 1
 				kube_daemonset_created{daemonset="ds2",namespace="ns2"} 1.5e+09
 				kube_daemonset_created{daemonset="ds3",namespace="ns3"} 1.5e+09
 				kube_daemonset_metadata_generation{namespace="ns1",daemonset="ds1"} 21
@@ -147,6 +194,14 @@ func TestDaemonSetCollector(t *testing.T) {
 				kube_daemonset_labels{label_app="example1",namespace="ns1",daemonset="ds1"} 1
 				kube_daemonset_labels{label_app="example2",namespace="ns2",daemonset="ds2"} 1
 				kube_daemonset_labels{label_app="example3",namespace="ns3",daemonset="ds3"} 1
+				kube_daemonset_annotations{annotation_team="infra",namespace="ns1",daemonset="ds1"} 1
+				kube_daemonset_annotations{namespace="ns2",daemonset="ds2"} 1
+				kube_daemonset_annotations{namespace="ns3",daemonset="ds3"} 1
+				kube_daemonset_owner{daemonset="ds1",namespace="ns1",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
+				kube_daemonset_owner{daemonset="ds2",namespace="ns2",owner_is_controller="<none>",owner_kind="<none>",owner_name="<none>"} 1
+				kube_daemonset_owner{daemonset="ds3",namespace="ns3",owner_is_controller="true",owner_kind="Addon",owner_name="node-agent"} 1
+				kube_daemonset_spec_template_resource_requests{container="agent",daemonset="ds3",namespace="ns3",resource="ephemeral_storage",unit="byte"} 1.073741824e+09
+				kube_daemonset_spec_template_resource_limits{container="agent",daemonset="ds3",namespace="ns3",resource="cpu",unit="core"} 0.1
 			`,
 		},
 	}
@@ -155,7 +210,9 @@ func TestDaemonSetCollector(t *testing.T) {
 			store: mockDaemonSetStore{
 				f: func() ([]v1beta1.DaemonSet, error) { return c.dss, nil },
 			},
-			opts: &options.Options{},
+			opts: &options.Options{
+				AnnotationWhitelist: options.AnnotationSet{"team": struct{}{}},
+			},
 		}
 		if err := testutils.GatherAndCompare(dc, c.want, nil); err != nil {
 			t.Errorf("unexpected collecting result:\n%s", err)
@@ -23,7 +23,6 @@ import (
 	"k8s.io/api/extensions/v1beta1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -32,6 +31,12 @@ var (
 	descDeploymentLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descDeploymentLabelsDefaultLabels = []string{"namespace", "deployment"}
 
+	descDeploymentInfo = prometheus.NewDesc(
+		"kube_deployment_info",
+		"Information about deployment.",
+		append(descDeploymentLabelsDefaultLabels, "workload_id"),
+		nil,
+	)
 	descDeploymentCreated = prometheus.NewDesc(
 		"kube_deployment_created",
 		"Unix creation timestamp",
@@ -99,18 +104,38 @@ var (
 		nil,
 	)
 
+	descDeploymentLastRolloutDuration = prometheus.NewDesc(
+		"kube_deployment_last_rollout_duration_seconds",
+		"The duration of the last rollout of the deployment observed by kube-state-metrics, from the generation change until all replicas are updated and available.",
+		descDeploymentLabelsDefaultLabels,
+		nil,
+	)
 	descDeploymentMetadataGeneration = prometheus.NewDesc(
 		"kube_deployment_metadata_generation",
 		"Sequence number representing a specific generation of the desired state.",
 		descDeploymentLabelsDefaultLabels,
 		nil,
 	)
+	descDeploymentSpecTemplateResourceRequests = prometheus.NewDesc(
+		"kube_deployment_spec_template_resource_requests",
+		"The number of requested resource by a container of the pod template.",
+		append(descDeploymentLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+	descDeploymentSpecTemplateResourceLimits = prometheus.NewDesc(
+		"kube_deployment_spec_template_resource_limits",
+		"The number of resource limit of a container of the pod template.",
+		append(descDeploymentLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
 
 	descDeploymentLabels = prometheus.NewDesc(
 		descDeploymentLabelsName,
 		descDeploymentLabelsHelp,
 		descDeploymentLabelsDefaultLabels, nil,
 	)
+
+	descDeploymentSummarizedObjects = newSummarizedObjectsDesc("deployment")
 )
 
 type DeploymentLister func() ([]v1beta1.Deployment, error)
@@ -123,7 +148,7 @@ func RegisterDeploymentCollector(registry prometheus.Registerer, informerFactori
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Extensions().V1beta1().Deployments().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Extensions().V1beta1().Deployments().Informer(), opts))
 	}
 
 	dplLister := DeploymentLister(func() (deployments []v1beta1.Deployment, err error) {
@@ -135,7 +160,14 @@ func RegisterDeploymentCollector(registry prometheus.Registerer, informerFactori
 		return deployments, nil
 	})
 
-	registry.MustRegister(&deploymentCollector{store: dplLister, opts: opts})
+	rollouts := newRolloutTracker(deploymentRolledOut)
+	for _, dinf := range infs {
+		dinf.AddEventHandler(rollouts.handler())
+	}
+
+	registry.MustRegister(&deploymentCollector{store: dplLister, opts: opts, rollouts: rollouts})
+	registerDeletedObjects(registry, infs, "kube_deployment_deleted", descDeploymentLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("deployment", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -145,12 +177,33 @@ type deploymentStore interface {
 
 // deploymentCollector collects metrics about all deployments in the cluster.
 type deploymentCollector struct {
-	store deploymentStore
-	opts  *options.Options
+	store    deploymentStore
+	opts     *options.Options
+	rollouts *rolloutTracker
+}
+
+// deploymentRolledOut reports whether the current generation of a deployment
+// has been rolled out, that is observed by the controller and all replicas
+// are updated and available.
+func deploymentRolledOut(obj interface{}) (namespace, name string, generation int64, done, ok bool) {
+	d, ok := obj.(*v1beta1.Deployment)
+	if !ok {
+		return "", "", 0, false, false
+	}
+	replicas := int32(1)
+	if d.Spec.Replicas != nil {
+		replicas = *d.Spec.Replicas
+	}
+	done = d.Status.ObservedGeneration >= d.Generation &&
+		d.Status.UpdatedReplicas == replicas &&
+		d.Status.Replicas == replicas &&
+		d.Status.AvailableReplicas == replicas
+	return d.Namespace, d.Name, d.Generation, done, true
 }
 
 // Describe implements the prometheus.Collector interface.
 func (dc *deploymentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descDeploymentInfo
 	ch <- descDeploymentCreated
 	ch <- descDeploymentStatusReplicas
 	ch <- descDeploymentStatusReplicasAvailable
@@ -162,23 +215,33 @@ func (dc *deploymentCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descDeploymentStrategyRollingUpdateMaxSurge
 	ch <- descDeploymentSpecReplicas
 	ch <- descDeploymentMetadataGeneration
+	ch <- descDeploymentSpecTemplateResourceRequests
+	ch <- descDeploymentSpecTemplateResourceLimits
+	ch <- descDeploymentLastRolloutDuration
 	ch <- descDeploymentLabels
+	ch <- descDeploymentSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (dc *deploymentCollector) Collect(ch chan<- prometheus.Metric) {
 	ds, err := dc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "deployment"}).Inc()
+		addScrapeError(ch, "deployment", err)
 		glog.Errorf("listing deployments failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "deployment"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "deployment"}).Observe(float64(len(ds)))
+	summarized := map[string]int{}
 	for _, d := range ds {
+		if !detailed(dc.opts, &d.ObjectMeta) {
+			summarized[d.Namespace]++
+			continue
+		}
 		dc.collectDeployment(ch, d)
 	}
+	addSummarizedObjects(ch, descDeploymentSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d deployments", len(ds))
 }
@@ -193,11 +256,14 @@ func deploymentLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (dc *deploymentCollector) collectDeployment(ch chan<- prometheus.Metric, d v1beta1.Deployment) {
+	defer recoverObjectError("deployment", &d.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{d.Namespace, d.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.Labels)
+	addGauge(descDeploymentInfo, 1, workloadID(d.Namespace, "Deployment", d.Name))
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(d.Labels, dc.opts.MaxLabelValueLength)
 	addGauge(deploymentLabelsDesc(labelKeys), 1, labelValues...)
 	if !d.CreationTimestamp.IsZero() {
 		addGauge(descDeploymentCreated, float64(d.CreationTimestamp.Unix()))
@@ -210,6 +276,14 @@ func (dc *deploymentCollector) collectDeployment(ch chan<- prometheus.Metric, d
 	addGauge(descDeploymentSpecPaused, boolFloat64(d.Spec.Paused))
 	addGauge(descDeploymentSpecReplicas, float64(*d.Spec.Replicas))
 	addGauge(descDeploymentMetadataGeneration, float64(d.ObjectMeta.Generation))
+	addPodTemplateResourceMetrics(ch, descDeploymentSpecTemplateResourceRequests, descDeploymentSpecTemplateResourceLimits,
+		d.Spec.Template.Spec, d.Namespace, d.Name)
+
+	if dc.rollouts != nil {
+		if duration, ok := dc.rollouts.lastDuration(d.Namespace, d.Name); ok {
+			addGauge(descDeploymentLastRolloutDuration, duration.Seconds())
+		}
+	}
 
 	if d.Spec.Strategy.RollingUpdate == nil {
 		return
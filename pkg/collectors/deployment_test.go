@@ -20,11 +20,13 @@ import (
 	"testing"
 	"time"
 
+	"k8s.io/api/core/v1"
 	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 var (
@@ -50,6 +52,8 @@ func TestDeploymentCollector(t *testing.T) {
 	// Fixed metadata on type and help text. We prepend this to every expected
 	// output so we only have to modify a single place when doing adjustments.
 	const metadata = `
+		# HELP kube_deployment_info Information about deployment.
+		# TYPE kube_deployment_info gauge
 		# HELP kube_deployment_created Unix creation timestamp
 		# TYPE kube_deployment_created gauge
 		# HELP kube_deployment_metadata_generation Sequence number representing a specific generation of the desired state.
@@ -74,6 +78,10 @@ func TestDeploymentCollector(t *testing.T) {
 		# TYPE kube_deployment_spec_strategy_rollingupdate_max_surge gauge
 		# HELP kube_deployment_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_deployment_labels gauge
+		# HELP kube_deployment_spec_template_resource_requests The number of requested resource by a container of the pod template.
+		# TYPE kube_deployment_spec_template_resource_requests gauge
+		# HELP kube_deployment_spec_template_resource_limits The number of resource limit of a container of the pod template.
+		# TYPE kube_deployment_spec_template_resource_limits gauge
 	`
 	cases := []struct {
 		depls []v1beta1.Deployment
@@ -106,6 +114,24 @@ func TestDeploymentCollector(t *testing.T) {
 								MaxSurge:       &depl1MaxSurge,
 							},
 						},
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										Name: "app",
+										Resources: v1.ResourceRequirements{
+											Requests: v1.ResourceList{
+												v1.ResourceCPU:    resource.MustParse("250m"),
+												v1.ResourceMemory: resource.MustParse("64Mi"),
+											},
+											Limits: v1.ResourceList{
+												v1.ResourceMemory: resource.MustParse("128Mi"),
+											},
+										},
+									},
+								},
+							},
+						},
 					},
 				}, {
 					ObjectMeta: metav1.ObjectMeta{
@@ -136,6 +162,8 @@ func TestDeploymentCollector(t *testing.T) {
 				},
 			},
 			want: metadata + `
+				kube_deployment_info{deployment="depl1",namespace="ns1",workload_id="b83d9403569aaef4"} 1
+				kube_deployment_info{deployment="depl2",namespace="ns2",workload_id="cc981e5b314cbbd6"} 1
 				kube_deployment_created{deployment="depl1",namespace="ns1"} 1.5e+09
 				kube_deployment_metadata_generation{namespace="ns1",deployment="depl1"} 21
 				kube_deployment_metadata_generation{namespace="ns2",deployment="depl2"} 14
@@ -159,6 +187,9 @@ func TestDeploymentCollector(t *testing.T) {
 				kube_deployment_status_replicas_updated{namespace="ns2",deployment="depl2"} 1
 				kube_deployment_labels{label_app="example1",namespace="ns1",deployment="depl1"} 1
 				kube_deployment_labels{label_app="example2",namespace="ns2",deployment="depl2"} 1
+				kube_deployment_spec_template_resource_requests{container="app",deployment="depl1",namespace="ns1",resource="cpu",unit="core"} 0.25
+				kube_deployment_spec_template_resource_requests{container="app",deployment="depl1",namespace="ns1",resource="memory",unit="byte"} 6.7108864e+07
+				kube_deployment_spec_template_resource_limits{container="app",deployment="depl1",namespace="ns1",resource="memory",unit="byte"} 1.34217728e+08
 			`,
 		},
 	}
@@ -174,3 +205,63 @@ func TestDeploymentCollector(t *testing.T) {
 		}
 	}
 }
+
+func TestDeploymentRolloutDuration(t *testing.T) {
+	const metadata = `
+		# HELP kube_deployment_last_rollout_duration_seconds The duration of the last rollout of the deployment observed by kube-state-metrics, from the generation change until all replicas are updated and available.
+		# TYPE kube_deployment_last_rollout_duration_seconds gauge
+	`
+	replicas := int32(3)
+	depl := func(name string, generation, observedGeneration int64, updated int32) *v1beta1.Deployment {
+		return &v1beta1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       name,
+				Namespace:  "ns1",
+				Generation: generation,
+			},
+			Spec: v1beta1.DeploymentSpec{
+				Replicas: &replicas,
+			},
+			Status: v1beta1.DeploymentStatus{
+				ObservedGeneration: observedGeneration,
+				Replicas:           replicas,
+				UpdatedReplicas:    updated,
+				AvailableReplicas:  replicas,
+			},
+		}
+	}
+
+	now := time.Unix(1500000000, 0)
+	rollouts := newRolloutTracker(deploymentRolledOut)
+	rollouts.now = func() time.Time { return now }
+	h := rollouts.handler()
+
+	// depl1 is rolled out completely while being observed.
+	h.OnAdd(depl("depl1", 1, 1, 3))
+	h.OnUpdate(depl("depl1", 1, 1, 3), depl("depl1", 2, 1, 3))
+	now = now.Add(30 * time.Second)
+	h.OnUpdate(depl("depl1", 2, 1, 3), depl("depl1", 2, 2, 1))
+	now = now.Add(60 * time.Second)
+	h.OnUpdate(depl("depl1", 2, 2, 1), depl("depl1", 2, 2, 3))
+
+	// The rollout of depl2 started before it was first observed.
+	h.OnAdd(depl("depl2", 2, 1, 3))
+	now = now.Add(30 * time.Second)
+	h.OnUpdate(depl("depl2", 2, 1, 3), depl("depl2", 2, 2, 3))
+
+	dc := &deploymentCollector{
+		store: mockDeploymentStore{
+			f: func() ([]v1beta1.Deployment, error) {
+				return []v1beta1.Deployment{*depl("depl1", 2, 2, 3), *depl("depl2", 2, 2, 3)}, nil
+			},
+		},
+		opts:     &options.Options{},
+		rollouts: rollouts,
+	}
+	want := metadata + `
+		kube_deployment_last_rollout_duration_seconds{deployment="depl1",namespace="ns1"} 90
+	`
+	if err := testutils.GatherAndCompare(dc, want, []string{"kube_deployment_last_rollout_duration_seconds"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -24,7 +24,6 @@ import (
 
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -67,6 +66,8 @@ var (
 		descEndpointLabelsDefaultLabels,
 		nil,
 	)
+
+	descEndpointSummarizedObjects = newSummarizedObjectsDesc("endpoint")
 )
 
 type EndpointLister func() ([]v1.Endpoints, error)
@@ -79,7 +80,7 @@ func RegisterEndpointCollector(registry prometheus.Registerer, informerFactories
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().Endpoints().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().Endpoints().Informer(), opts))
 	}
 
 	endpointLister := EndpointLister(func() (endpoints []v1.Endpoints, err error) {
@@ -91,7 +92,13 @@ func RegisterEndpointCollector(registry prometheus.Registerer, informerFactories
 		return endpoints, nil
 	})
 
-	registry.MustRegister(&endpointCollector{store: endpointLister, opts: opts})
+	ec := &endpointCollector{store: endpointLister, opts: opts}
+	ec.metrics = newObjectMetricsCache(registry, infs, opts, func(ch chan<- prometheus.Metric, obj interface{}) {
+		ec.collectEndpoints(ch, *obj.(*v1.Endpoints))
+	})
+	registry.MustRegister(ec)
+	registerDeletedObjects(registry, infs, "kube_endpoint_deleted", descEndpointLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("endpoint", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -101,8 +108,9 @@ type endpointStore interface {
 
 // endpointCollector collects metrics about all endpoints in the cluster.
 type endpointCollector struct {
-	store endpointStore
-	opts  *options.Options
+	store   endpointStore
+	opts    *options.Options
+	metrics *objectMetricsCache
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -112,30 +120,41 @@ func (pc *endpointCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descEndpointCreated
 	ch <- descEndpointAddressAvailable
 	ch <- descEndpointAddressNotReady
+	ch <- descEndpointSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (ec *endpointCollector) Collect(ch chan<- prometheus.Metric) {
 	endpoints, err := ec.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "endpoint"}).Inc()
+		addScrapeError(ch, "endpoint", err)
 		glog.Errorf("listing endpoints failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "endpoint"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "endpoint"}).Observe(float64(len(endpoints)))
+	summarized := map[string]int{}
 	for _, e := range endpoints {
-		ec.collectEndpoints(ch, e)
+		if !detailed(ec.opts, &e.ObjectMeta) {
+			summarized[e.Namespace]++
+			continue
+		}
+		if ec.metrics == nil {
+			ec.collectEndpoints(ch, e)
+		}
 	}
+	addSummarizedObjects(ch, descEndpointSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d endpoints", len(endpoints))
 }
 
 func (ec *endpointCollector) collectEndpoints(ch chan<- prometheus.Metric, e v1.Endpoints) {
+	defer recoverObjectError("endpoint", &e.ObjectMeta)
+
 	addConstMetric := func(desc *prometheus.Desc, t prometheus.ValueType, v float64, lv ...string) {
 		lv = append([]string{e.Namespace, e.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, t, v, lv...)
+		ch <- mustNewConstMetric(desc, t, v, lv...)
 	}
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		addConstMetric(desc, prometheus.GaugeValue, v, lv...)
@@ -145,7 +164,7 @@ func (ec *endpointCollector) collectEndpoints(ch chan<- prometheus.Metric, e v1.
 	if !e.CreationTimestamp.IsZero() {
 		addGauge(descEndpointCreated, float64(e.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(e.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(e.Labels, ec.opts.MaxLabelValueLength)
 	addGauge(endpointLabelsDesc(labelKeys), 1, labelValues...)
 
 	var available int
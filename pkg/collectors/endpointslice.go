@@ -0,0 +1,138 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// EndpointSlices were added to discovery.k8s.io after the vendored
+	// client-go, which has no typed client for them.
+	endpointSliceResource = CustomResource{
+		Group:      "discovery.k8s.io",
+		Version:    "v1",
+		Resource:   "endpointslices",
+		Kind:       "EndpointSlice",
+		Namespaced: true,
+	}
+
+	// endpointSliceServiceNameLabel names the service an EndpointSlice
+	// belongs to.
+	endpointSliceServiceNameLabel = "kubernetes.io/service-name"
+
+	descEndpointSliceLabelsDefaultLabels = []string{"namespace", "endpointslice"}
+
+	descEndpointSliceInfo = prometheus.NewDesc(
+		"kube_endpointslice_info",
+		"Information about the endpointslice.",
+		append(descEndpointSliceLabelsDefaultLabels, "service", "address_type"),
+		nil,
+	)
+	descEndpointSliceCreated = prometheus.NewDesc(
+		"kube_endpointslice_created",
+		"Unix creation timestamp",
+		descEndpointSliceLabelsDefaultLabels,
+		nil,
+	)
+	descEndpointSliceEndpoints = prometheus.NewDesc(
+		"kube_endpointslice_endpoints",
+		"The number of endpoints of the endpointslice.",
+		descEndpointSliceLabelsDefaultLabels,
+		nil,
+	)
+	descEndpointSliceEndpointsHints = prometheus.NewDesc(
+		"kube_endpointslice_endpoints_hints",
+		"The number of endpoints of the endpointslice hinted to be consumed from the zone.",
+		append(descEndpointSliceLabelsDefaultLabels, "for_zone"),
+		nil,
+	)
+)
+
+// RegisterEndpointSliceCollector registers a collector of the EndpointSlices
+// in the given namespaces. Like the apiresources collector it is not backed by
+// informers: the EndpointSlices are listed on every scrape.
+func RegisterEndpointSliceCollector(registry prometheus.Registerer, client rest.Interface, namespaces options.NamespaceList, opts *options.Options) {
+	registry.MustRegister(&endpointSliceCollector{store: restCustomResourceStore{client: client}, namespaces: namespaces, opts: opts})
+}
+
+// endpointSliceCollector collects metrics about all EndpointSlices in the
+// cluster.
+type endpointSliceCollector struct {
+	store      customResourceStore
+	namespaces options.NamespaceList
+	opts       *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (ec *endpointSliceCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descEndpointSliceInfo
+	ch <- descEndpointSliceCreated
+	ch <- descEndpointSliceEndpoints
+	ch <- descEndpointSliceEndpointsHints
+}
+
+// Collect implements the prometheus.Collector interface.
+func (ec *endpointSliceCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, ec.store, endpointSliceResource, ec.namespaces, ec.opts, func(obj unstructured.Unstructured) {
+		ec.collectEndpointSlice(ch, obj)
+	})
+}
+
+func (ec *endpointSliceCollector) collectEndpointSlice(ch chan<- prometheus.Metric, s unstructured.Unstructured) {
+	defer recoverObjectError("endpointslice", &s)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{s.GetNamespace(), s.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	addressType, _, _ := unstructured.NestedString(s.Object, "addressType")
+	addGauge(descEndpointSliceInfo, 1, s.GetLabels()[endpointSliceServiceNameLabel], addressType)
+
+	if t := s.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descEndpointSliceCreated, float64(t.Unix()))
+	}
+
+	// Topology aware routing hints each endpoint to the zones it should be
+	// consumed from, endpoints without hints are consumed from all zones.
+	endpoints, _, _ := unstructured.NestedSlice(s.Object, "endpoints")
+	hints := map[string]int{}
+	for _, e := range endpoints {
+		endpoint, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		forZones, _, _ := unstructured.NestedSlice(endpoint, "hints", "forZones")
+		for _, z := range forZones {
+			zone, ok := z.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _, _ := unstructured.NestedString(zone, "name")
+			hints[name]++
+		}
+	}
+	addGauge(descEndpointSliceEndpoints, float64(len(endpoints)))
+	for zone, n := range hints {
+		addGauge(descEndpointSliceEndpointsHints, float64(n), zone)
+	}
+}
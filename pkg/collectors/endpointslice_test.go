@@ -0,0 +1,105 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestEndpointSliceCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_endpointslice_info Information about the endpointslice.
+		# TYPE kube_endpointslice_info gauge
+		# HELP kube_endpointslice_created Unix creation timestamp
+		# TYPE kube_endpointslice_created gauge
+		# HELP kube_endpointslice_endpoints The number of endpoints of the endpointslice.
+		# TYPE kube_endpointslice_endpoints gauge
+		# HELP kube_endpointslice_endpoints_hints The number of endpoints of the endpointslice hinted to be consumed from the zone.
+		# TYPE kube_endpointslice_endpoints_hints gauge
+	`
+	hintedEndpoint := func(ip string, zones ...string) interface{} {
+		var forZones []interface{}
+		for _, z := range zones {
+			forZones = append(forZones, map[string]interface{}{"name": z})
+		}
+		return map[string]interface{}{
+			"addresses": []interface{}{ip},
+			"hints":     map[string]interface{}{"forZones": forZones},
+		}
+	}
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"endpointslices": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"namespace":         "ns1",
+					"name":              "web-abc12",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+					"labels":            map[string]interface{}{"kubernetes.io/service-name": "web"},
+				},
+				"addressType": "IPv4",
+				"endpoints": []interface{}{
+					hintedEndpoint("10.0.0.1", "zone-a"),
+					hintedEndpoint("10.0.0.2", "zone-a"),
+					hintedEndpoint("10.0.0.3", "zone-b"),
+					map[string]interface{}{"addresses": []interface{}{"10.0.0.4"}},
+				},
+			}},
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"namespace": "ns2",
+					"name":      "db-xyz34",
+				},
+				"addressType": "IPv6",
+			}},
+		},
+	}}
+
+	cases := []struct {
+		namespaces options.NamespaceList
+		want       string
+	}{
+		{
+			namespaces: options.NamespaceList{""},
+			want: metadata + `
+				kube_endpointslice_info{address_type="IPv4",endpointslice="web-abc12",namespace="ns1",service="web"} 1
+				kube_endpointslice_info{address_type="IPv6",endpointslice="db-xyz34",namespace="ns2",service=""} 1
+				kube_endpointslice_created{endpointslice="web-abc12",namespace="ns1"} 1.501569018e+09
+				kube_endpointslice_endpoints{endpointslice="web-abc12",namespace="ns1"} 4
+				kube_endpointslice_endpoints{endpointslice="db-xyz34",namespace="ns2"} 0
+				kube_endpointslice_endpoints_hints{endpointslice="web-abc12",for_zone="zone-a",namespace="ns1"} 2
+				kube_endpointslice_endpoints_hints{endpointslice="web-abc12",for_zone="zone-b",namespace="ns1"} 1
+			`,
+		},
+		{
+			namespaces: options.NamespaceList{"ns2"},
+			want: metadata + `
+				kube_endpointslice_info{address_type="IPv6",endpointslice="db-xyz34",namespace="ns2",service=""} 1
+				kube_endpointslice_endpoints{endpointslice="db-xyz34",namespace="ns2"} 0
+			`,
+		},
+	}
+	for _, c := range cases {
+		ec := &endpointSliceCollector{store: store, namespaces: c.namespaces, opts: &options.Options{}}
+		if err := testutils.GatherAndCompare(ec, c.want, nil); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"golang.org/x/net/context"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func newEventCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_event_total",
+			Help: "The number of occurrences of events observed since kube-state-metrics started.",
+		},
+		[]string{"namespace", "kind", "reason", "type"},
+	)
+}
+
+// eventCount returns the number of occurrences of the event. The API server
+// deduplicates repeated events by increasing their count instead of creating
+// new events.
+func eventCount(e *v1.Event) int32 {
+	if e.Series != nil {
+		return e.Series.Count
+	}
+	if e.Count > 0 {
+		return e.Count
+	}
+	return 1
+}
+
+// eventCountHandler counts the occurrences of events. Only occurrences after
+// since are counted: events created before are skipped when first listed, and
+// updates only count the increase of the event count, so relists and resyncs
+// don't count occurrences twice.
+func eventCountHandler(events *prometheus.CounterVec, since time.Time) cache.ResourceEventHandler {
+	count := func(e *v1.Event, n int32) {
+		if n <= 0 {
+			return
+		}
+		events.WithLabelValues(e.Namespace, e.InvolvedObject.Kind, e.Reason, e.Type).Add(float64(n))
+	}
+	return cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			e, ok := obj.(*v1.Event)
+			if !ok || e.CreationTimestamp.Time.Before(since) {
+				return
+			}
+			count(e, eventCount(e))
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			old, ok := oldObj.(*v1.Event)
+			if !ok {
+				return
+			}
+			e, ok := newObj.(*v1.Event)
+			if !ok {
+				return
+			}
+			count(e, eventCount(e)-eventCount(old))
+		},
+	}
+}
+
+// RegisterEventCollector registers the opt-in events collector, which counts
+// events instead of exposing metrics per event, as events are short-lived and
+// churn heavily.
+func RegisterEventCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Core().V1().Events().Informer(), opts))
+	}
+
+	events := newEventCounter()
+	since := time.Now()
+	for _, einf := range infs {
+		einf.AddEventHandler(eventCountHandler(events, since))
+	}
+
+	registry.MustRegister(events)
+	InformerSyncTracker.Track("event", infs)
+	infs.Run(context.Background().Done())
+}
@@ -0,0 +1,74 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestEventCountHandler(t *testing.T) {
+	const metadata = `
+		# HELP kube_event_total The number of occurrences of events observed since kube-state-metrics started.
+		# TYPE kube_event_total counter
+	`
+
+	since := time.Unix(1500000000, 0)
+	newEvent := func(namespace, name, kind, reason, eventType string, created time.Time, count int32) *v1.Event {
+		return &v1.Event{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: created},
+			},
+			InvolvedObject: v1.ObjectReference{Kind: kind},
+			Reason:         reason,
+			Type:           eventType,
+			Count:          count,
+		}
+	}
+
+	events := newEventCounter()
+	h := eventCountHandler(events, since)
+
+	// Created before the start, only later occurrences are counted.
+	old := newEvent("ns1", "e1", "Pod", "BackOff", v1.EventTypeWarning, since.Add(-time.Hour), 10)
+	h.OnAdd(old)
+	h.OnUpdate(old, newEvent("ns1", "e1", "Pod", "BackOff", v1.EventTypeWarning, since.Add(-time.Hour), 13))
+
+	h.OnAdd(newEvent("ns1", "e2", "Pod", "Scheduled", v1.EventTypeNormal, since.Add(time.Minute), 1))
+	h.OnAdd(newEvent("ns2", "e3", "Node", "NodeNotReady", v1.EventTypeNormal, since.Add(time.Minute), 0))
+
+	// Resyncs don't change the count.
+	repeated := newEvent("ns2", "e4", "Pod", "BackOff", v1.EventTypeWarning, since.Add(time.Minute), 2)
+	h.OnAdd(repeated)
+	h.OnUpdate(repeated, repeated)
+
+	want := metadata + `
+		kube_event_total{kind="Node",namespace="ns2",reason="NodeNotReady",type="Normal"} 1
+		kube_event_total{kind="Pod",namespace="ns1",reason="BackOff",type="Warning"} 3
+		kube_event_total{kind="Pod",namespace="ns1",reason="Scheduled",type="Normal"} 1
+		kube_event_total{kind="Pod",namespace="ns2",reason="BackOff",type="Warning"} 2
+	`
+	if err := testutils.GatherAndCompare(events, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -74,6 +74,8 @@ var (
 		append(descHorizontalPodAutoscalerLabelsDefaultLabels, "condition", "status"),
 		nil,
 	)
+
+	descHPASummarizedObjects = newSummarizedObjectsDesc("horizontalpodautoscaler")
 )
 
 type HPALister func() (autoscaling.HorizontalPodAutoscalerList, error)
@@ -86,7 +88,7 @@ func RegisterHorizontalPodAutoScalerCollector(registry prometheus.Registerer, in
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Autoscaling().V2beta1().HorizontalPodAutoscalers().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Autoscaling().V2beta1().HorizontalPodAutoscalers().Informer(), opts))
 	}
 
 	hpaLister := HPALister(func() (hpas autoscaling.HorizontalPodAutoscalerList, err error) {
@@ -98,10 +100,62 @@ func RegisterHorizontalPodAutoScalerCollector(registry prometheus.Registerer, in
 		return hpas, nil
 	})
 
-	registry.MustRegister(&hpaCollector{store: hpaLister, opts: opts})
+	scaleEvents := newHPAScaleEventsCounter()
+	for _, hpainf := range infs {
+		hpainf.AddEventHandler(hpaScaleEventsHandler(scaleEvents))
+	}
+
+	registry.MustRegister(&hpaCollector{store: hpaLister, opts: opts}, scaleEvents)
+	registerDeletedObjects(registry, infs, "kube_hpa_deleted", descHorizontalPodAutoscalerLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("horizontalpodautoscaler", infs)
 	infs.Run(context.Background().Done())
 }
 
+func newHPAScaleEventsCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_hpa_scale_events_total",
+			Help: "The number of changes of the desired number of replicas observed since kube-state-metrics started.",
+		},
+		append(descHorizontalPodAutoscalerLabelsDefaultLabels, "direction"),
+	)
+}
+
+// hpaScaleEventsHandler counts changes of the desired number of replicas of
+// HorizontalPodAutoscalers by direction. The counters of deleted
+// HorizontalPodAutoscalers are removed.
+func hpaScaleEventsHandler(scaleEvents *prometheus.CounterVec) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			oldHPA, ok := oldObj.(*autoscaling.HorizontalPodAutoscaler)
+			if !ok {
+				return
+			}
+			newHPA, ok := newObj.(*autoscaling.HorizontalPodAutoscaler)
+			if !ok {
+				return
+			}
+			switch {
+			case newHPA.Status.DesiredReplicas > oldHPA.Status.DesiredReplicas:
+				scaleEvents.WithLabelValues(newHPA.Namespace, newHPA.Name, "up").Inc()
+			case newHPA.Status.DesiredReplicas < oldHPA.Status.DesiredReplicas:
+				scaleEvents.WithLabelValues(newHPA.Namespace, newHPA.Name, "down").Inc()
+			}
+		},
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			h, ok := obj.(*autoscaling.HorizontalPodAutoscaler)
+			if !ok {
+				return
+			}
+			scaleEvents.DeleteLabelValues(h.Namespace, h.Name, "up")
+			scaleEvents.DeleteLabelValues(h.Namespace, h.Name, "down")
+		},
+	}
+}
+
 type hpaStore interface {
 	List() (hpas autoscaling.HorizontalPodAutoscalerList, err error)
 }
@@ -120,22 +174,29 @@ func (hc *hpaCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descHorizontalPodAutoscalerStatusCurrentReplicas
 	ch <- descHorizontalPodAutoscalerStatusDesiredReplicas
 	ch <- descHorizontalPodAutoscalerLabels
+	ch <- descHPASummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (hc *hpaCollector) Collect(ch chan<- prometheus.Metric) {
 	hpas, err := hc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "horizontalpodautoscaler"}).Inc()
+		addScrapeError(ch, "horizontalpodautoscaler", err)
 		glog.Errorf("listing HorizontalPodAutoscalers failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "horizontalpodautoscaler"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "horizontalpodautoscaler"}).Observe(float64(len(hpas.Items)))
+	summarized := map[string]int{}
 	for _, h := range hpas.Items {
+		if !detailed(hc.opts, &h.ObjectMeta) {
+			summarized[h.Namespace]++
+			continue
+		}
 		hc.collectHPA(ch, h)
 	}
+	addSummarizedObjects(ch, descHPASummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d hpas", len(hpas.Items))
 }
@@ -150,11 +211,13 @@ func hpaLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (hc *hpaCollector) collectHPA(ch chan<- prometheus.Metric, h autoscaling.HorizontalPodAutoscaler) {
+	defer recoverObjectError("horizontalpodautoscaler", &h.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{h.Namespace, h.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(h.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(h.Labels, hc.opts.MaxLabelValueLength)
 	addGauge(hpaLabelsDesc(labelKeys), 1, labelValues...)
 	addGauge(descHorizontalPodAutoscalerMetadataGeneration, float64(h.ObjectMeta.Generation))
 	addGauge(descHorizontalPodAutoscalerSpecMaxReplicas, float64(h.Spec.MaxReplicas))
@@ -163,6 +226,6 @@ func (hc *hpaCollector) collectHPA(ch chan<- prometheus.Metric, h autoscaling.Ho
 	addGauge(descHorizontalPodAutoscalerStatusDesiredReplicas, float64(h.Status.DesiredReplicas))
 
 	for _, c := range h.Status.Conditions {
-		addConditionMetrics(ch, descHorizontalPodAutoscalerCondition, c.Status, h.Namespace, h.Name, string(c.Type))
+		addConditionMetrics(ch, hc.opts.ConditionEncoding, descHorizontalPodAutoscalerCondition, c.Status, h.Namespace, h.Name, string(c.Type))
 	}
 }
@@ -21,8 +21,9 @@ import (
 
 	autoscaling "k8s.io/api/autoscaling/v2beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 var (
@@ -111,3 +112,41 @@ func TestHPACollector(t *testing.T) {
 		}
 	}
 }
+
+func TestHPAScaleEventsHandler(t *testing.T) {
+	const metadata = `
+		# HELP kube_hpa_scale_events_total The number of changes of the desired number of replicas observed since kube-state-metrics started.
+		# TYPE kube_hpa_scale_events_total counter
+	`
+
+	hpa := func(namespace, name string, desired int32) *autoscaling.HorizontalPodAutoscaler {
+		return &autoscaling.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Status: autoscaling.HorizontalPodAutoscalerStatus{
+				DesiredReplicas: desired,
+			},
+		}
+	}
+
+	scaleEvents := newHPAScaleEventsCounter()
+	h := hpaScaleEventsHandler(scaleEvents)
+	h.OnUpdate(hpa("ns1", "hpa1", 2), hpa("ns1", "hpa1", 4))
+	h.OnUpdate(hpa("ns1", "hpa1", 4), hpa("ns1", "hpa1", 4))
+	h.OnUpdate(hpa("ns1", "hpa1", 4), hpa("ns1", "hpa1", 3))
+	h.OnUpdate(hpa("ns1", "hpa1", 3), hpa("ns1", "hpa1", 5))
+	h.OnUpdate(hpa("ns2", "hpa2", 1), hpa("ns2", "hpa2", 2))
+	h.OnUpdate(hpa("ns2", "hpa3", 1), hpa("ns2", "hpa3", 2))
+	h.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns2/hpa3", Obj: hpa("ns2", "hpa3", 2)})
+
+	want := metadata + `
+		kube_hpa_scale_events_total{direction="down",hpa="hpa1",namespace="ns1"} 1
+		kube_hpa_scale_events_total{direction="up",hpa="hpa1",namespace="ns1"} 2
+		kube_hpa_scale_events_total{direction="up",hpa="hpa2",namespace="ns2"} 1
+	`
+	if err := testutils.GatherAndCompare(scaleEvents, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
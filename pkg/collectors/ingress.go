@@ -0,0 +1,313 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descIngressLabelsName          = "kube_ingress_labels"
+	descIngressLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descIngressLabelsDefaultLabels = []string{"namespace", "ingress"}
+
+	descIngressInfo = prometheus.NewDesc(
+		"kube_ingress_info",
+		"Information about ingress.",
+		descIngressLabelsDefaultLabels,
+		nil,
+	)
+	descIngressLabels = prometheus.NewDesc(
+		descIngressLabelsName,
+		descIngressLabelsHelp,
+		descIngressLabelsDefaultLabels,
+		nil,
+	)
+	descIngressCreated = prometheus.NewDesc(
+		"kube_ingress_created",
+		"Unix creation timestamp",
+		descIngressLabelsDefaultLabels,
+		nil,
+	)
+	descIngressPath = prometheus.NewDesc(
+		"kube_ingress_path",
+		"Ingress host, paths and backend service information. The default backend has an empty host and path.",
+		append(descIngressLabelsDefaultLabels, "host", "path", "service_name", "service_port"),
+		nil,
+	)
+	descIngressTLS = prometheus.NewDesc(
+		"kube_ingress_tls",
+		"Ingress TLS host and secret information.",
+		append(descIngressLabelsDefaultLabels, "tls_host", "secret"),
+		nil,
+	)
+
+	descIngressBackendReady = prometheus.NewDesc(
+		"kube_ingress_backend_ready",
+		"Whether the backend service of an ingress path has ready endpoints. The default backend has an empty host and path.",
+		append(descIngressLabelsDefaultLabels, "host", "path", "service_name", "service_port"),
+		nil,
+	)
+
+	descIngressSummarizedObjects = newSummarizedObjectsDesc("ingress")
+)
+
+type IngressLister func() ([]v1beta1.Ingress, error)
+
+func (l IngressLister) List() ([]v1beta1.Ingress, error) {
+	return l()
+}
+
+func RegisterIngressCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Extensions().V1beta1().Ingresses().Informer(), opts))
+	}
+
+	ingressLister := IngressLister(func() (ingresses []v1beta1.Ingress, err error) {
+		for _, inginf := range infs {
+			for _, i := range inginf.GetStore().List() {
+				ingresses = append(ingresses, *(i.(*v1beta1.Ingress)))
+			}
+		}
+		return ingresses, nil
+	})
+
+	// The readiness of backends is derived from their services and
+	// endpoints, if those are collected as well.
+	backendInfs := SharedInformerList{}
+	var backends backendStore
+	if backendsCollected(opts) {
+		store := informerBackendStore{}
+		for _, f := range informerFactories {
+			sinf := f.Core().V1().Services().Informer()
+			einf := f.Core().V1().Endpoints().Informer()
+			store.services = append(store.services, sinf)
+			store.endpoints = append(store.endpoints, einf)
+			backendInfs = append(backendInfs, sinf, einf)
+		}
+		backends = store
+	}
+
+	registry.MustRegister(&ingressCollector{store: ingressLister, backends: backends, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_ingress_deleted", descIngressLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("ingress", infs)
+	InformerSyncTracker.TrackDependencies("ingress", backendInfs)
+	infs.Run(context.Background().Done())
+	backendInfs.Run(context.Background().Done())
+}
+
+type ingressStore interface {
+	List() (ingresses []v1beta1.Ingress, err error)
+}
+
+// backendsCollected returns whether the services and endpoints collectors are
+// enabled next to the ingresses collector.
+func backendsCollected(opts *options.Options) bool {
+	enabled := opts.Collectors
+	if len(enabled) == 0 {
+		enabled = options.DefaultCollectors
+	}
+	_, services := enabled["services"]
+	_, endpoints := enabled["endpoints"]
+	return services && endpoints
+}
+
+// backendStore looks up the services and endpoints of ingress backends.
+type backendStore interface {
+	Service(namespace, name string) (*v1.Service, bool)
+	Endpoints(namespace, name string) (*v1.Endpoints, bool)
+}
+
+// informerBackendStore looks up services and endpoints in the stores of
+// informers.
+type informerBackendStore struct {
+	services  SharedInformerList
+	endpoints SharedInformerList
+}
+
+func (s informerBackendStore) Service(namespace, name string) (*v1.Service, bool) {
+	obj, ok := getFromInformers(s.services, namespace, name)
+	if !ok {
+		return nil, false
+	}
+	svc, ok := obj.(*v1.Service)
+	return svc, ok
+}
+
+func (s informerBackendStore) Endpoints(namespace, name string) (*v1.Endpoints, bool) {
+	obj, ok := getFromInformers(s.endpoints, namespace, name)
+	if !ok {
+		return nil, false
+	}
+	e, ok := obj.(*v1.Endpoints)
+	return e, ok
+}
+
+// getFromInformers returns the object with the given namespace and name from
+// the first informer storing it.
+func getFromInformers(infs SharedInformerList, namespace, name string) (interface{}, bool) {
+	for _, inf := range infs {
+		if obj, exists, err := inf.GetStore().GetByKey(namespace + "/" + name); err == nil && exists {
+			return obj, true
+		}
+	}
+	return nil, false
+}
+
+// ingressCollector collects metrics about all ingresses in the cluster.
+type ingressCollector struct {
+	store ingressStore
+	// backends is nil if the readiness of backends is not collected.
+	backends backendStore
+	opts     *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (ic *ingressCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descIngressInfo
+	ch <- descIngressLabels
+	ch <- descIngressCreated
+	ch <- descIngressPath
+	ch <- descIngressTLS
+	ch <- descIngressBackendReady
+	ch <- descIngressSummarizedObjects
+}
+
+// Collect implements the prometheus.Collector interface.
+func (ic *ingressCollector) Collect(ch chan<- prometheus.Metric) {
+	ingresses, err := ic.store.List()
+	if err != nil {
+		addScrapeError(ch, "ingress", err)
+		glog.Errorf("listing ingresses failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "ingress"}).Add(0)
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "ingress"}).Observe(float64(len(ingresses)))
+	summarized := map[string]int{}
+	for _, i := range ingresses {
+		if !detailed(ic.opts, &i.ObjectMeta) {
+			summarized[i.Namespace]++
+			continue
+		}
+		ic.collectIngress(ch, i)
+	}
+	addSummarizedObjects(ch, descIngressSummarizedObjects, summarized)
+
+	glog.V(4).Infof("collected %d ingresses", len(ingresses))
+}
+
+func ingressLabelsDesc(labelKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descIngressLabelsName,
+		descIngressLabelsHelp,
+		append(descIngressLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func (ic *ingressCollector) collectIngress(ch chan<- prometheus.Metric, i v1beta1.Ingress) {
+	defer recoverObjectError("ingress", &i.ObjectMeta)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{i.Namespace, i.Name}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+	addGauge(descIngressInfo, 1)
+
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(i.Labels, ic.opts.MaxLabelValueLength)
+	addGauge(ingressLabelsDesc(labelKeys), 1, labelValues...)
+
+	if !i.CreationTimestamp.IsZero() {
+		addGauge(descIngressCreated, float64(i.CreationTimestamp.Unix()))
+	}
+
+	addPath := func(host, path string, b v1beta1.IngressBackend) {
+		addGauge(descIngressPath, 1, host, path, b.ServiceName, b.ServicePort.String())
+		if ic.backends != nil {
+			addGauge(descIngressBackendReady, boolFloat64(backendReady(ic.backends, i.Namespace, b)), host, path, b.ServiceName, b.ServicePort.String())
+		}
+	}
+	if b := i.Spec.Backend; b != nil {
+		addPath("", "", *b)
+	}
+	for _, rule := range i.Spec.Rules {
+		if rule.HTTP == nil {
+			continue
+		}
+		for _, path := range rule.HTTP.Paths {
+			addPath(rule.Host, path.Path, path.Backend)
+		}
+	}
+
+	for _, tls := range i.Spec.TLS {
+		for _, host := range tls.Hosts {
+			addGauge(descIngressTLS, 1, host, tls.SecretName)
+		}
+	}
+}
+
+// backendReady returns whether the service port of an ingress backend has
+// ready endpoints. Backends of ExternalName services have no endpoints and
+// are always ready.
+func backendReady(backends backendStore, namespace string, b v1beta1.IngressBackend) bool {
+	svc, ok := backends.Service(namespace, b.ServiceName)
+	if !ok {
+		return false
+	}
+	if svc.Spec.Type == v1.ServiceTypeExternalName {
+		return true
+	}
+
+	var port *v1.ServicePort
+	for i, p := range svc.Spec.Ports {
+		if (b.ServicePort.Type == intstr.Int && p.Port == b.ServicePort.IntVal) ||
+			(b.ServicePort.Type == intstr.String && p.Name == b.ServicePort.StrVal) {
+			port = &svc.Spec.Ports[i]
+			break
+		}
+	}
+	if port == nil {
+		return false
+	}
+
+	e, ok := backends.Endpoints(namespace, b.ServiceName)
+	if !ok {
+		return false
+	}
+	// Endpoint ports carry the names of the service ports.
+	for _, subset := range e.Subsets {
+		if len(subset.Addresses) == 0 {
+			continue
+		}
+		for _, p := range subset.Ports {
+			if p.Name == port.Name {
+				return true
+			}
+		}
+	}
+	return false
+}
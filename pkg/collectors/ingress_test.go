@@ -0,0 +1,225 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockIngressStore struct {
+	f func() ([]v1beta1.Ingress, error)
+}
+
+func (is mockIngressStore) List() ([]v1beta1.Ingress, error) {
+	return is.f()
+}
+
+type mockBackendStore struct {
+	services  map[string]*v1.Service
+	endpoints map[string]*v1.Endpoints
+}
+
+func (bs mockBackendStore) Service(namespace, name string) (*v1.Service, bool) {
+	s, ok := bs.services[namespace+"/"+name]
+	return s, ok
+}
+
+func (bs mockBackendStore) Endpoints(namespace, name string) (*v1.Endpoints, bool) {
+	e, ok := bs.endpoints[namespace+"/"+name]
+	return e, ok
+}
+
+func TestIngressCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+
+	const metadata = `
+		# HELP kube_ingress_info Information about ingress.
+		# TYPE kube_ingress_info gauge
+		# HELP kube_ingress_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_ingress_labels gauge
+		# HELP kube_ingress_created Unix creation timestamp
+		# TYPE kube_ingress_created gauge
+		# HELP kube_ingress_path Ingress host, paths and backend service information. The default backend has an empty host and path.
+		# TYPE kube_ingress_path gauge
+		# HELP kube_ingress_tls Ingress TLS host and secret information.
+		# TYPE kube_ingress_tls gauge
+	`
+	cases := []struct {
+		ingresses []v1beta1.Ingress
+		want      string
+	}{
+		{
+			ingresses: []v1beta1.Ingress{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "ingress1",
+						Namespace: "ns1",
+						Labels: map[string]string{
+							"app": "web",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "ingress2",
+						Namespace:         "ns2",
+						CreationTimestamp: metav1StartTime,
+					},
+					Spec: v1beta1.IngressSpec{
+						Backend: &v1beta1.IngressBackend{
+							ServiceName: "default-backend",
+							ServicePort: intstr.FromInt(80),
+						},
+						Rules: []v1beta1.IngressRule{
+							{
+								Host: "example.com",
+								IngressRuleValue: v1beta1.IngressRuleValue{
+									HTTP: &v1beta1.HTTPIngressRuleValue{
+										Paths: []v1beta1.HTTPIngressPath{
+											{
+												Path: "/api",
+												Backend: v1beta1.IngressBackend{
+													ServiceName: "api",
+													ServicePort: intstr.FromString("http"),
+												},
+											},
+											{
+												Path: "/",
+												Backend: v1beta1.IngressBackend{
+													ServiceName: "web",
+													ServicePort: intstr.FromInt(8080),
+												},
+											},
+										},
+									},
+								},
+							},
+							{
+								Host: "no-http.example.com",
+							},
+						},
+						TLS: []v1beta1.IngressTLS{
+							{
+								Hosts:      []string{"example.com", "www.example.com"},
+								SecretName: "example-tls",
+							},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_ingress_info{ingress="ingress1",namespace="ns1"} 1
+				kube_ingress_info{ingress="ingress2",namespace="ns2"} 1
+				kube_ingress_labels{ingress="ingress1",label_app="web",namespace="ns1"} 1
+				kube_ingress_labels{ingress="ingress2",namespace="ns2"} 1
+				kube_ingress_created{ingress="ingress2",namespace="ns2"} 1.501569018e+09
+				kube_ingress_path{host="",ingress="ingress2",namespace="ns2",path="",service_name="default-backend",service_port="80"} 1
+				kube_ingress_path{host="example.com",ingress="ingress2",namespace="ns2",path="/api",service_name="api",service_port="http"} 1
+				kube_ingress_path{host="example.com",ingress="ingress2",namespace="ns2",path="/",service_name="web",service_port="8080"} 1
+				kube_ingress_tls{ingress="ingress2",namespace="ns2",secret="example-tls",tls_host="example.com"} 1
+				kube_ingress_tls{ingress="ingress2",namespace="ns2",secret="example-tls",tls_host="www.example.com"} 1
+			`,
+		},
+	}
+	for _, c := range cases {
+		ic := &ingressCollector{
+			store: mockIngressStore{
+				f: func() ([]v1beta1.Ingress, error) { return c.ingresses, nil },
+			},
+			opts: &options.Options{},
+		}
+		if err := testutils.GatherAndCompare(ic, c.want, nil); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
+
+func TestIngressBackendReady(t *testing.T) {
+	const metadata = `
+		# HELP kube_ingress_backend_ready Whether the backend service of an ingress path has ready endpoints. The default backend has an empty host and path.
+		# TYPE kube_ingress_backend_ready gauge
+	`
+	ingress := v1beta1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "ingress1", Namespace: "ns1"},
+		Spec: v1beta1.IngressSpec{
+			Backend: &v1beta1.IngressBackend{
+				ServiceName: "missing",
+				ServicePort: intstr.FromInt(80),
+			},
+			Rules: []v1beta1.IngressRule{{
+				Host: "example.com",
+				IngressRuleValue: v1beta1.IngressRuleValue{
+					HTTP: &v1beta1.HTTPIngressRuleValue{
+						Paths: []v1beta1.HTTPIngressPath{
+							{Path: "/api", Backend: v1beta1.IngressBackend{ServiceName: "api", ServicePort: intstr.FromString("http")}},
+							{Path: "/", Backend: v1beta1.IngressBackend{ServiceName: "web", ServicePort: intstr.FromInt(8080)}},
+							{Path: "/metrics", Backend: v1beta1.IngressBackend{ServiceName: "web", ServicePort: intstr.FromInt(9090)}},
+							{Path: "/external", Backend: v1beta1.IngressBackend{ServiceName: "external", ServicePort: intstr.FromInt(443)}},
+						},
+					},
+				},
+			}},
+		},
+	}
+	backends := mockBackendStore{
+		services: map[string]*v1.Service{
+			"ns1/api": {Spec: v1.ServiceSpec{Ports: []v1.ServicePort{{Name: "http", Port: 80}}}},
+			"ns1/web": {Spec: v1.ServiceSpec{Ports: []v1.ServicePort{
+				{Name: "web", Port: 8080},
+				{Name: "metrics", Port: 9090},
+			}}},
+			"ns1/external": {Spec: v1.ServiceSpec{Type: v1.ServiceTypeExternalName}},
+		},
+		endpoints: map[string]*v1.Endpoints{
+			"ns1/api": {Subsets: []v1.EndpointSubset{{
+				NotReadyAddresses: []v1.EndpointAddress{{IP: "10.0.0.1"}},
+				Ports:             []v1.EndpointPort{{Name: "http", Port: 8000}},
+			}}},
+			"ns1/web": {Subsets: []v1.EndpointSubset{{
+				Addresses: []v1.EndpointAddress{{IP: "10.0.0.2"}},
+				Ports:     []v1.EndpointPort{{Name: "web", Port: 8080}},
+			}}},
+		},
+	}
+	want := metadata + `
+		kube_ingress_backend_ready{host="",ingress="ingress1",namespace="ns1",path="",service_name="missing",service_port="80"} 0
+		kube_ingress_backend_ready{host="example.com",ingress="ingress1",namespace="ns1",path="/api",service_name="api",service_port="http"} 0
+		kube_ingress_backend_ready{host="example.com",ingress="ingress1",namespace="ns1",path="/",service_name="web",service_port="8080"} 1
+		kube_ingress_backend_ready{host="example.com",ingress="ingress1",namespace="ns1",path="/metrics",service_name="web",service_port="9090"} 0
+		kube_ingress_backend_ready{host="example.com",ingress="ingress1",namespace="ns1",path="/external",service_name="external",service_port="443"} 1
+	`
+	ic := &ingressCollector{
+		store: mockIngressStore{
+			f: func() ([]v1beta1.Ingress, error) { return []v1beta1.Ingress{ingress}, nil },
+		},
+		backends: backends,
+		opts:     &options.Options{},
+	}
+	if err := testutils.GatherAndCompare(ic, want, []string{"kube_ingress_backend_ready"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
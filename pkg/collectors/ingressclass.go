@@ -0,0 +1,107 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// IngressClasses were added to networking.k8s.io after the vendored
+	// client-go, which has no typed client for them.
+	ingressClassResource = CustomResource{
+		Group:    "networking.k8s.io",
+		Version:  "v1",
+		Resource: "ingressclasses",
+		Kind:     "IngressClass",
+	}
+
+	// isDefaultIngressClassAnnotation marks an ingressclass as the class of
+	// ingresses which don't name one.
+	isDefaultIngressClassAnnotation = "ingressclass.kubernetes.io/is-default-class"
+
+	descIngressClassLabelsDefaultLabels = []string{"ingressclass"}
+
+	descIngressClassInfo = prometheus.NewDesc(
+		"kube_ingressclass_info",
+		"Information about the ingressclass.",
+		append(descIngressClassLabelsDefaultLabels, "controller"),
+		nil,
+	)
+	descIngressClassCreated = prometheus.NewDesc(
+		"kube_ingressclass_created",
+		"Unix creation timestamp",
+		descIngressClassLabelsDefaultLabels,
+		nil,
+	)
+	descIngressClassIsDefault = prometheus.NewDesc(
+		"kube_ingressclass_is_default",
+		"Whether the ingressclass is marked as the default ingressclass.",
+		descIngressClassLabelsDefaultLabels,
+		nil,
+	)
+)
+
+// RegisterIngressClassCollector registers a collector of the IngressClasses,
+// which are cluster-scoped. Like the runtimeclasses collector it lists them
+// on every scrape.
+func RegisterIngressClassCollector(registry prometheus.Registerer, client rest.Interface, opts *options.Options) {
+	registry.MustRegister(&ingressClassCollector{store: restCustomResourceStore{client: client}, opts: opts})
+}
+
+// ingressClassCollector collects metrics about all IngressClasses in the
+// cluster.
+type ingressClassCollector struct {
+	store customResourceStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (ic *ingressClassCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descIngressClassInfo
+	ch <- descIngressClassCreated
+	ch <- descIngressClassIsDefault
+}
+
+// Collect implements the prometheus.Collector interface.
+func (ic *ingressClassCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, ic.store, ingressClassResource, options.NamespaceList{""}, ic.opts, func(obj unstructured.Unstructured) {
+		ic.collectIngressClass(ch, obj)
+	})
+}
+
+func (ic *ingressClassCollector) collectIngressClass(ch chan<- prometheus.Metric, c unstructured.Unstructured) {
+	defer recoverObjectError("ingressclass", &c)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{c.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	controller, _, _ := unstructured.NestedString(c.Object, "spec", "controller")
+	addGauge(descIngressClassInfo, 1, controller)
+
+	if t := c.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descIngressClassCreated, float64(t.Unix()))
+	}
+
+	addGauge(descIngressClassIsDefault, boolFloat64(c.GetAnnotations()[isDefaultIngressClassAnnotation] == "true"))
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestIngressClassCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_ingressclass_info Information about the ingressclass.
+		# TYPE kube_ingressclass_info gauge
+		# HELP kube_ingressclass_created Unix creation timestamp
+		# TYPE kube_ingressclass_created gauge
+		# HELP kube_ingressclass_is_default Whether the ingressclass is marked as the default ingressclass.
+		# TYPE kube_ingressclass_is_default gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"ingressclasses": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "nginx",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+					"annotations": map[string]interface{}{
+						"ingressclass.kubernetes.io/is-default-class": "true",
+					},
+				},
+				"spec": map[string]interface{}{
+					"controller": "k8s.io/ingress-nginx",
+				},
+			}},
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name": "alb",
+				},
+				"spec": map[string]interface{}{
+					"controller": "ingress.k8s.aws/alb",
+				},
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_ingressclass_info{controller="k8s.io/ingress-nginx",ingressclass="nginx"} 1
+		kube_ingressclass_info{controller="ingress.k8s.aws/alb",ingressclass="alb"} 1
+		kube_ingressclass_created{ingressclass="nginx"} 1.501569018e+09
+		kube_ingressclass_is_default{ingressclass="nginx"} 1
+		kube_ingressclass_is_default{ingressclass="alb"} 0
+	`
+	ic := &ingressClassCollector{store: store, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(ic, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
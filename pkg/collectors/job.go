@@ -22,7 +22,6 @@ import (
 	"golang.org/x/net/context"
 	v1batch "k8s.io/api/batch/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -110,6 +109,8 @@ var (
 		descJobLabelsDefaultLabels,
 		nil,
 	)
+
+	descJobSummarizedObjects = newSummarizedObjectsDesc("job")
 )
 
 type JobLister func() ([]v1batch.Job, error)
@@ -122,7 +123,7 @@ func RegisterJobCollector(registry prometheus.Registerer, informerFactories []in
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Batch().V1().Jobs().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Batch().V1().Jobs().Informer(), opts))
 	}
 
 	jobLister := JobLister(func() (jobs []v1batch.Job, err error) {
@@ -134,7 +135,13 @@ func RegisterJobCollector(registry prometheus.Registerer, informerFactories []in
 		return jobs, nil
 	})
 
-	registry.MustRegister(&jobCollector{store: jobLister, opts: opts})
+	jc := &jobCollector{store: jobLister, opts: opts}
+	jc.metrics = newObjectMetricsCache(registry, infs, opts, func(ch chan<- prometheus.Metric, obj interface{}) {
+		jc.collectJob(ch, *obj.(*v1batch.Job))
+	})
+	registry.MustRegister(jc)
+	registerDeletedObjects(registry, infs, "kube_job_deleted", descJobLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("job", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -144,8 +151,9 @@ type jobStore interface {
 
 // jobCollector collects metrics about all jobs in the cluster.
 type jobCollector struct {
-	store jobStore
-	opts  *options.Options
+	store   jobStore
+	opts    *options.Options
+	metrics *objectMetricsCache
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -163,22 +171,31 @@ func (dc *jobCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descJobConditionFailed
 	ch <- descJobStatusStartTime
 	ch <- descJobStatusCompletionTime
+	ch <- descJobSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (jc *jobCollector) Collect(ch chan<- prometheus.Metric) {
 	jobs, err := jc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "job"}).Inc()
+		addScrapeError(ch, "job", err)
 		glog.Errorf("listing jobs failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "job"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "job"}).Observe(float64(len(jobs)))
+	summarized := map[string]int{}
 	for _, j := range jobs {
-		jc.collectJob(ch, j)
+		if !detailed(jc.opts, &j.ObjectMeta) {
+			summarized[j.Namespace]++
+			continue
+		}
+		if jc.metrics == nil {
+			jc.collectJob(ch, j)
+		}
 	}
+	addSummarizedObjects(ch, descJobSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d jobs", len(jobs))
 }
@@ -193,14 +210,16 @@ func jobLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (jc *jobCollector) collectJob(ch chan<- prometheus.Metric, j v1batch.Job) {
+	defer recoverObjectError("job", &j.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{j.Namespace, j.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 
 	addGauge(descJobInfo, 1)
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(j.Labels, jc.opts.MaxLabelValueLength)
 	addGauge(jobLabelsDesc(labelKeys), 1, labelValues...)
 
 	if j.Spec.Parallelism != nil {
@@ -233,9 +252,9 @@ func (jc *jobCollector) collectJob(ch chan<- prometheus.Metric, j v1batch.Job) {
 	for _, c := range j.Status.Conditions {
 		switch c.Type {
 		case v1batch.JobComplete:
-			addConditionMetrics(ch, descJobConditionComplete, c.Status, j.Namespace, j.Name)
+			addConditionMetrics(ch, jc.opts.ConditionEncoding, descJobConditionComplete, c.Status, j.Namespace, j.Name)
 		case v1batch.JobFailed:
-			addConditionMetrics(ch, descJobConditionFailed, c.Status, j.Namespace, j.Name)
+			addConditionMetrics(ch, jc.opts.ConditionEncoding, descJobConditionFailed, c.Status, j.Namespace, j.Name)
 		}
 	}
 }
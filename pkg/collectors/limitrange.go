@@ -22,13 +22,18 @@ import (
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
 var (
+	descLimitRangeLabelsName          = "kube_limitrange_labels"
+	descLimitRangeLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descLimitRangeLabelsDefaultLabels = []string{"limitrange", "namespace"}
-	descLimitRange                    = prometheus.NewDesc(
+
+	descLimitRangeAnnotationsName = "kube_limitrange_annotations"
+	descLimitRangeAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
+	descLimitRange = prometheus.NewDesc(
 		"kube_limitrange",
 		"Information about limit range.",
 		append(descLimitRangeLabelsDefaultLabels, "resource", "type", "constraint"),
@@ -41,6 +46,21 @@ var (
 		descLimitRangeLabelsDefaultLabels,
 		nil,
 	)
+
+	descLimitRangeLabels = prometheus.NewDesc(
+		descLimitRangeLabelsName,
+		descLimitRangeLabelsHelp,
+		descLimitRangeLabelsDefaultLabels,
+		nil,
+	)
+	descLimitRangeAnnotations = prometheus.NewDesc(
+		descLimitRangeAnnotationsName,
+		descLimitRangeAnnotationsHelp,
+		descLimitRangeLabelsDefaultLabels,
+		nil,
+	)
+
+	descLimitRangeSummarizedObjects = newSummarizedObjectsDesc("limitrange")
 )
 
 type LimitRangeLister func() (v1.LimitRangeList, error)
@@ -53,7 +73,7 @@ func RegisterLimitRangeCollector(registry prometheus.Registerer, informerFactori
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().LimitRanges().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().LimitRanges().Informer(), opts))
 	}
 
 	limitRangeLister := LimitRangeLister(func() (ranges v1.LimitRangeList, err error) {
@@ -66,6 +86,8 @@ func RegisterLimitRangeCollector(registry prometheus.Registerer, informerFactori
 	})
 
 	registry.MustRegister(&limitRangeCollector{store: limitRangeLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_limitrange_deleted", descLimitRangeLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("limitrange", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -83,34 +105,67 @@ type limitRangeCollector struct {
 func (lrc *limitRangeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descLimitRange
 	ch <- descLimitRangeCreated
+	ch <- descLimitRangeLabels
+	ch <- descLimitRangeAnnotations
+	ch <- descLimitRangeSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (lrc *limitRangeCollector) Collect(ch chan<- prometheus.Metric) {
 	limitRangeCollector, err := lrc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "limitrange"}).Inc()
+		addScrapeError(ch, "limitrange", err)
 		glog.Errorf("listing limit ranges failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "limitrange"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "limitrange"}).Observe(float64(len(limitRangeCollector.Items)))
+	summarized := map[string]int{}
 	for _, rq := range limitRangeCollector.Items {
+		if !detailed(lrc.opts, &rq.ObjectMeta) {
+			summarized[rq.Namespace]++
+			continue
+		}
 		lrc.collectLimitRange(ch, rq)
 	}
+	addSummarizedObjects(ch, descLimitRangeSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d limitranges", len(limitRangeCollector.Items))
 }
 
+func limitRangeLabelsDesc(labelKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descLimitRangeLabelsName,
+		descLimitRangeLabelsHelp,
+		append(descLimitRangeLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func limitRangeAnnotationsDesc(annotationKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descLimitRangeAnnotationsName,
+		descLimitRangeAnnotationsHelp,
+		append(descLimitRangeLabelsDefaultLabels, annotationKeys...),
+		nil,
+	)
+}
+
 func (lrc *limitRangeCollector) collectLimitRange(ch chan<- prometheus.Metric, rq v1.LimitRange) {
+	defer recoverObjectError("limitrange", &rq.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{rq.Name, rq.Namespace}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 	if !rq.CreationTimestamp.IsZero() {
 		addGauge(descLimitRangeCreated, float64(rq.CreationTimestamp.Unix()))
 	}
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(rq.Labels, lrc.opts.MaxLabelValueLength)
+	addGauge(limitRangeLabelsDesc(labelKeys), 1, labelValues...)
+	annotationKeys, annotationValues := kubeWhitelistedAnnotationsToPrometheusAnnotations(rq.Annotations, lrc.opts.AnnotationWhitelist, lrc.opts.MaxLabelValueLength)
+	addGauge(limitRangeAnnotationsDesc(annotationKeys), 1, annotationValues...)
 
 	rawLimitRanges := rq.Spec.Limits
 	for _, rawLimitRange := range rawLimitRanges {
@@ -23,8 +23,8 @@ import (
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockLimitRangeStore struct {
@@ -45,6 +45,10 @@ func TestLimitRangeollector(t *testing.T) {
 	# TYPE kube_limitrange_created gauge
 	# HELP kube_limitrange Information about limit range.
 	# TYPE kube_limitrange gauge
+	# HELP kube_limitrange_labels Kubernetes labels converted to Prometheus labels.
+	# TYPE kube_limitrange_labels gauge
+	# HELP kube_limitrange_annotations Kubernetes annotations converted to Prometheus labels.
+	# TYPE kube_limitrange_annotations gauge
 	`
 	cases := []struct {
 		ranges  []v1.LimitRange
@@ -58,6 +62,11 @@ func TestLimitRangeollector(t *testing.T) {
 						Name:              "quotaTest",
 						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 						Namespace:         "testNS",
+						Labels:            map[string]string{"team": "platform"},
+						Annotations: map[string]string{
+							"owner":       "platform@example.com",
+							"description": "Pod memory limits",
+						},
 					},
 					Spec: v1.LimitRangeSpec{
 						Limits: []v1.LimitRangeItem{
@@ -85,6 +94,8 @@ func TestLimitRangeollector(t *testing.T) {
 			},
 			want: metadata + `
 		kube_limitrange_created{limitrange="quotaTest",namespace="testNS"} 1.5e+09
+		kube_limitrange_labels{label_team="platform",limitrange="quotaTest",namespace="testNS"} 1
+		kube_limitrange_annotations{annotation_owner="platform@example.com",limitrange="quotaTest",namespace="testNS"} 1
 		kube_limitrange{limitrange="quotaTest",namespace="testNS",resource="memory",type="Pod",constraint="min"} 2.1e+09
 		kube_limitrange{limitrange="quotaTest",namespace="testNS",resource="memory",type="Pod",constraint="max"} 2.1e+09
 		kube_limitrange{limitrange="quotaTest",namespace="testNS",resource="memory",type="Pod",constraint="default"} 2.1e+09
@@ -100,7 +111,7 @@ func TestLimitRangeollector(t *testing.T) {
 					return v1.LimitRangeList{Items: c.ranges}, nil
 				},
 			},
-			opts: &options.Options{},
+			opts: &options.Options{AnnotationWhitelist: options.AnnotationSet{"owner": struct{}{}}},
 		}
 		if err := testutils.GatherAndCompare(dc, c.want, c.metrics); err != nil {
 			t.Errorf("unexpected collecting result:\n%s", err)
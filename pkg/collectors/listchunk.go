@@ -0,0 +1,61 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// ChunkingListOptions returns a function tweaking the list options of
+// informers, so that their lists are paginated with the given chunk size.
+//
+// Informers list with resource version "0", which the apiserver serves from
+// its watch cache in a single response regardless of the limit. The resource
+// version is therefore cleared, so that lists are served page by page. Lists
+// are told apart from watches by the limit set by the pager of informers. If a
+// continuation expires, the pager falls back to a full list without limit,
+// which is left as it is.
+func ChunkingListOptions(chunkSize int64) func(*metav1.ListOptions) {
+	return func(o *metav1.ListOptions) {
+		if o.Limit == 0 {
+			return
+		}
+		o.Limit = chunkSize
+		o.ResourceVersion = ""
+	}
+}
+
+// tweakingListWatch applies tweak to the options of all lists and watches of
+// lw, like the tweak of informer factories. It returns lw if tweak is nil.
+func tweakingListWatch(lw *cache.ListWatch, tweak func(*metav1.ListOptions)) *cache.ListWatch {
+	if tweak == nil {
+		return lw
+	}
+	return &cache.ListWatch{
+		ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+			tweak(&o)
+			return lw.ListFunc(o)
+		},
+		WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+			tweak(&o)
+			return lw.WatchFunc(o)
+		},
+	}
+}
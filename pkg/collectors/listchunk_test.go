@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+func TestChunkingListOptions(t *testing.T) {
+	var lists, watches []metav1.ListOptions
+	lw := tweakingListWatch(&cache.ListWatch{
+		ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+			lists = append(lists, o)
+			list := &v1.PodList{Items: []v1.Pod{{ObjectMeta: metav1.ObjectMeta{Name: "pod"}}}}
+			if o.Continue == "" {
+				list.Continue = "next"
+			}
+			return list, nil
+		},
+		WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+			watches = append(watches, o)
+			return watch.NewFake(), nil
+		},
+	}, ChunkingListOptions(100))
+
+	// Informers list with resource version "0" and watch from the resource
+	// version of the list.
+	list, err := lw.List(metav1.ListOptions{ResourceVersion: "0"})
+	if err != nil {
+		t.Fatalf("unexpected error listing: %v", err)
+	}
+	if _, err := lw.Watch(metav1.ListOptions{ResourceVersion: "42"}); err != nil {
+		t.Fatalf("unexpected error watching: %v", err)
+	}
+
+	wantLists := []metav1.ListOptions{
+		{Limit: 100},
+		{Limit: 100, Continue: "next"},
+	}
+	if len(lists) != len(wantLists) {
+		t.Fatalf("expected %d list requests, got %v", len(wantLists), lists)
+	}
+	for i, want := range wantLists {
+		if got := lists[i]; got.Limit != want.Limit || got.Continue != want.Continue || got.ResourceVersion != "" {
+			t.Errorf("expected list request %d with limit %d, continue %q and no resource version, got %+v", i, want.Limit, want.Continue, got)
+		}
+	}
+	if items, _ := meta.ExtractList(list); len(items) != 2 {
+		t.Errorf("expected the pages to be joined into 2 items, got %d", len(items))
+	}
+	if len(watches) != 1 || watches[0].Limit != 0 || watches[0].ResourceVersion != "42" {
+		t.Errorf("expected watch options to be unchanged, got %v", watches)
+	}
+}
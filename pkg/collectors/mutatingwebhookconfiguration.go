@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descMutatingWebhookConfigurationLabelsDefaultLabels = []string{"mutatingwebhookconfiguration"}
+
+	descMutatingWebhookConfigurationInfo = prometheus.NewDesc(
+		"kube_mutatingwebhookconfiguration_info",
+		"Information about the mutating webhook configuration.",
+		descMutatingWebhookConfigurationLabelsDefaultLabels,
+		nil,
+	)
+	descMutatingWebhookConfigurationCreated = prometheus.NewDesc(
+		"kube_mutatingwebhookconfiguration_created",
+		"Unix creation timestamp",
+		descMutatingWebhookConfigurationLabelsDefaultLabels,
+		nil,
+	)
+	descMutatingWebhookConfigurationWebhook = prometheus.NewDesc(
+		"kube_mutatingwebhookconfiguration_webhook",
+		"Information about a webhook of the mutating webhook configuration.",
+		append(descMutatingWebhookConfigurationLabelsDefaultLabels, "webhook", "failure_policy", "service_namespace", "service_name"),
+		nil,
+	)
+)
+
+type MutatingWebhookConfigurationLister func() ([]admissionregistration.MutatingWebhookConfiguration, error)
+
+func (l MutatingWebhookConfigurationLister) List() ([]admissionregistration.MutatingWebhookConfiguration, error) {
+	return l()
+}
+
+func RegisterMutatingWebhookConfigurationCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Admissionregistration().V1beta1().MutatingWebhookConfigurations().Informer(), opts))
+	}
+
+	mutatingWebhookConfigurationLister := MutatingWebhookConfigurationLister(func() (configurations []admissionregistration.MutatingWebhookConfiguration, err error) {
+		for _, inf := range infs {
+			for _, c := range inf.GetStore().List() {
+				configurations = append(configurations, *(c.(*admissionregistration.MutatingWebhookConfiguration)))
+			}
+		}
+		return configurations, nil
+	})
+
+	registry.MustRegister(&mutatingWebhookConfigurationCollector{store: mutatingWebhookConfigurationLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_mutatingwebhookconfiguration_deleted", descMutatingWebhookConfigurationLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("mutatingwebhookconfiguration", infs)
+	infs.Run(context.Background().Done())
+}
+
+type mutatingWebhookConfigurationStore interface {
+	List() ([]admissionregistration.MutatingWebhookConfiguration, error)
+}
+
+// mutatingWebhookConfigurationCollector collects metrics about all mutating webhook configurations in the cluster.
+type mutatingWebhookConfigurationCollector struct {
+	store mutatingWebhookConfigurationStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *mutatingWebhookConfigurationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descMutatingWebhookConfigurationInfo
+	ch <- descMutatingWebhookConfigurationCreated
+	ch <- descMutatingWebhookConfigurationWebhook
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *mutatingWebhookConfigurationCollector) Collect(ch chan<- prometheus.Metric) {
+	configurations, err := c.store.List()
+	if err != nil {
+		addScrapeError(ch, "mutatingwebhookconfiguration", err)
+		glog.Errorf("listing mutatingwebhookconfigurations failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "mutatingwebhookconfiguration"}).Add(0)
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "mutatingwebhookconfiguration"}).Observe(float64(len(configurations)))
+	for _, configuration := range configurations {
+		c.collectMutatingWebhookConfiguration(ch, configuration)
+	}
+
+	glog.V(4).Infof("collected %d mutatingwebhookconfigurations", len(configurations))
+}
+
+func (c *mutatingWebhookConfigurationCollector) collectMutatingWebhookConfiguration(ch chan<- prometheus.Metric, configuration admissionregistration.MutatingWebhookConfiguration) {
+	defer recoverObjectError("mutatingwebhookconfiguration", &configuration.ObjectMeta)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{configuration.Name}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+	addGauge(descMutatingWebhookConfigurationInfo, 1)
+
+	if !configuration.CreationTimestamp.IsZero() {
+		addGauge(descMutatingWebhookConfigurationCreated, float64(configuration.CreationTimestamp.Unix()))
+	}
+
+	for _, w := range configuration.Webhooks {
+		addGauge(descMutatingWebhookConfigurationWebhook, 1, webhookLabelValues(w)...)
+	}
+}
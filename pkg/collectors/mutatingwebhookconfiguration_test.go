@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockMutatingWebhookConfigurationStore struct {
+	f func() ([]admissionregistration.MutatingWebhookConfiguration, error)
+}
+
+func (s mockMutatingWebhookConfigurationStore) List() ([]admissionregistration.MutatingWebhookConfiguration, error) {
+	return s.f()
+}
+
+func TestMutatingWebhookConfigurationCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+
+	fail := admissionregistration.Fail
+	path := "/mutator"
+	url := "https://mutator.example.com"
+
+	const metadata = `
+		# HELP kube_mutatingwebhookconfiguration_info Information about the mutating webhook configuration.
+		# TYPE kube_mutatingwebhookconfiguration_info gauge
+		# HELP kube_mutatingwebhookconfiguration_created Unix creation timestamp
+		# TYPE kube_mutatingwebhookconfiguration_created gauge
+		# HELP kube_mutatingwebhookconfiguration_webhook Information about a webhook of the mutating webhook configuration.
+		# TYPE kube_mutatingwebhookconfiguration_webhook gauge
+	`
+	cases := []struct {
+		configurations []admissionregistration.MutatingWebhookConfiguration
+		want           string
+	}{
+		{
+			configurations: []admissionregistration.MutatingWebhookConfiguration{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "config1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "config2",
+						CreationTimestamp: metav1StartTime,
+					},
+					Webhooks: []admissionregistration.Webhook{
+						{
+							Name: "service.example.com",
+							ClientConfig: admissionregistration.WebhookClientConfig{
+								Service: &admissionregistration.ServiceReference{
+									Namespace: "ns1",
+									Name:      "mutator",
+									Path:      &path,
+								},
+							},
+							FailurePolicy: &fail,
+						},
+						{
+							Name: "url.example.com",
+							ClientConfig: admissionregistration.WebhookClientConfig{
+								URL: &url,
+							},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_mutatingwebhookconfiguration_info{mutatingwebhookconfiguration="config1"} 1
+				kube_mutatingwebhookconfiguration_info{mutatingwebhookconfiguration="config2"} 1
+				kube_mutatingwebhookconfiguration_created{mutatingwebhookconfiguration="config2"} 1.501569018e+09
+				kube_mutatingwebhookconfiguration_webhook{failure_policy="Fail",mutatingwebhookconfiguration="config2",service_name="mutator",service_namespace="ns1",webhook="service.example.com"} 1
+				kube_mutatingwebhookconfiguration_webhook{failure_policy="Ignore",mutatingwebhookconfiguration="config2",service_name="",service_namespace="",webhook="url.example.com"} 1
+			`,
+		},
+	}
+	for _, c := range cases {
+		wc := &mutatingWebhookConfigurationCollector{
+			store: mockMutatingWebhookConfigurationStore{
+				f: func() ([]admissionregistration.MutatingWebhookConfiguration, error) { return c.configurations, nil },
+			},
+			opts: &options.Options{},
+		}
+		if err := testutils.GatherAndCompare(wc, c.want, nil); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
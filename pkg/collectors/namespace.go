@@ -17,12 +17,13 @@ limitations under the License.
 package collectors
 
 import (
+	"time"
+
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -59,6 +60,12 @@ var (
 		append(descNamespaceLabelsDefaultLabels, "phase"),
 		nil,
 	)
+	descNamespaceTerminatingDuration = prometheus.NewDesc(
+		"kube_namespace_status_terminating_duration_seconds",
+		"The number of seconds a namespace has been terminating since its deletion was requested.",
+		descNamespaceLabelsDefaultLabels,
+		nil,
+	)
 )
 
 // NamespaceLister define NamespaceLister type
@@ -74,7 +81,7 @@ func RegisterNamespaceCollector(registry prometheus.Registerer, informerFactorie
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().Namespaces().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().Namespaces().Informer(), opts))
 	}
 
 	namespaceLister := NamespaceLister(func() (namespaces []v1.Namespace, err error) {
@@ -86,7 +93,9 @@ func RegisterNamespaceCollector(registry prometheus.Registerer, informerFactorie
 		return namespaces, nil
 	})
 
-	registry.MustRegister(&namespaceCollector{store: namespaceLister, opts: opts})
+	registry.MustRegister(&namespaceCollector{store: namespaceLister, opts: opts, now: time.Now})
+	registerDeletedObjects(registry, infs, "kube_namespace_deleted", descNamespaceLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("namespace", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -98,6 +107,9 @@ type namespaceStore interface {
 type namespaceCollector struct {
 	store namespaceStore
 	opts  *options.Options
+	// now is used to compute the terminating duration, it is only
+	// overridden in tests.
+	now func() time.Time
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -106,13 +118,14 @@ func (nsc *namespaceCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descNamespaceLabels
 	ch <- descNamespaceAnnotations
 	ch <- descNamespacePhase
+	ch <- descNamespaceTerminatingDuration
 }
 
 // Collect implements the prometheus.Collector interface.
 func (nsc *namespaceCollector) Collect(ch chan<- prometheus.Metric) {
 	nsls, err := nsc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "namespace"}).Inc()
+		addScrapeError(ch, "namespace", err)
 		glog.Errorf("listing namespace failed: %s", err)
 		return
 	}
@@ -127,22 +140,28 @@ func (nsc *namespaceCollector) Collect(ch chan<- prometheus.Metric) {
 }
 
 func (nsc *namespaceCollector) collectNamespace(ch chan<- prometheus.Metric, ns v1.Namespace) {
+	defer recoverObjectError("namespace", &ns.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{ns.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 
 	addGauge(descNamespacePhase, boolFloat64(ns.Status.Phase == v1.NamespaceActive), string(v1.NamespaceActive))
 	addGauge(descNamespacePhase, boolFloat64(ns.Status.Phase == v1.NamespaceTerminating), string(v1.NamespaceTerminating))
 
+	if ns.Status.Phase == v1.NamespaceTerminating && ns.DeletionTimestamp != nil {
+		addGauge(descNamespaceTerminatingDuration, nsc.now().Sub(ns.DeletionTimestamp.Time).Seconds())
+	}
+
 	if !ns.CreationTimestamp.IsZero() {
 		addGauge(descNamespaceCreated, float64(ns.CreationTimestamp.Unix()))
 	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(ns.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(ns.Labels, nsc.opts.MaxLabelValueLength)
 	addGauge(namespaceLabelsDesc(labelKeys), 1, labelValues...)
 
-	annnotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(ns.Annotations)
+	annnotationKeys, annotationValues := kubeAnnotationsToPrometheusAnnotations(ns.Annotations, nsc.opts.MaxLabelValueLength)
 	addGauge(namespaceAnnotationsDesc(annnotationKeys), 1, annotationValues...)
 }
 
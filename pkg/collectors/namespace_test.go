@@ -22,8 +22,8 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockNamespaceStore struct {
@@ -46,6 +46,8 @@ func TestNamespaceCollector(t *testing.T) {
 		# TYPE kube_namespace_annotations gauge
 		# HELP kube_namespace_status_phase kubernetes namespace status phase.
 		# TYPE kube_namespace_status_phase gauge
+		# HELP kube_namespace_status_terminating_duration_seconds The number of seconds a namespace has been terminating since its deletion was requested.
+		# TYPE kube_namespace_status_terminating_duration_seconds gauge
 	`
 
 	cases := []struct {
@@ -68,7 +70,8 @@ func TestNamespaceCollector(t *testing.T) {
 				},
 				{
 					ObjectMeta: metav1.ObjectMeta{
-						Name: "nsTerminateTest",
+						Name:              "nsTerminateTest",
+						DeletionTimestamp: &metav1.Time{Time: time.Unix(1500000000, 0)},
 					},
 					Spec: v1.NamespaceSpec{
 						Finalizers: []v1.FinalizerName{v1.FinalizerKubernetes},
@@ -133,6 +136,7 @@ func TestNamespaceCollector(t *testing.T) {
 				kube_namespace_status_phase{namespace="nsActiveTest",phase="Terminating"} 0
 				kube_namespace_status_phase{namespace="nsTerminateTest",phase="Active"} 0
 				kube_namespace_status_phase{namespace="nsTerminateTest",phase="Terminating"} 1
+				kube_namespace_status_terminating_duration_seconds{namespace="nsTerminateTest"} 3600
 			`,
 		},
 	}
@@ -142,6 +146,7 @@ func TestNamespaceCollector(t *testing.T) {
 				list: func() ([]v1.Namespace, error) { return c.ns, nil },
 			},
 			opts: &options.Options{},
+			now:  func() time.Time { return time.Unix(1500003600, 0) },
 		}
 		if err := testutils.GatherAndCompare(nsc, c.want, c.metrics); err != nil {
 			t.Errorf("unexpected collecting result:\n%s", err)
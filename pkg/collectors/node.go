@@ -17,18 +17,29 @@ limitations under the License.
 package collectors
 
 import (
+	"math"
+	"time"
+
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	"k8s.io/client-go/informers"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/constant"
 	"k8s.io/kube-state-metrics/pkg/options"
 	"k8s.io/kubernetes/pkg/apis/core/v1/helper"
+	kubeletapis "k8s.io/kubernetes/pkg/kubelet/apis"
 )
 
 var (
+	// osLabels are the well-known labels holding the operating system of a
+	// node, which are also used in node selectors to schedule pods by it.
+	osLabels = []string{"kubernetes.io/os", kubeletapis.LabelOS}
+	// archLabels are the well-known labels holding the architecture of a node.
+	archLabels = []string{"kubernetes.io/arch", kubeletapis.LabelArch}
+
 	descNodeLabelsName          = "kube_node_labels"
 	descNodeLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descNodeLabelsDefaultLabels = []string{"node"}
@@ -42,7 +53,9 @@ var (
 			"container_runtime_version",
 			"kubelet_version",
 			"kubeproxy_version",
-			"provider_id"),
+			"provider_id",
+			"os",
+			"arch"),
 		nil,
 	)
 	descNodeCreated = prometheus.NewDesc(
@@ -75,6 +88,54 @@ var (
 		append(descNodeLabelsDefaultLabels, "condition", "status"),
 		nil,
 	)
+	descNodeHealth = prometheus.NewDesc(
+		"kube_node_health",
+		"Whether a cluster node is healthy, i.e. ready, without memory, disk or PID pressure and schedulable.",
+		descNodeLabelsDefaultLabels,
+		nil,
+	)
+	descNodeStatusHeartbeatSkew = prometheus.NewDesc(
+		"kube_node_status_heartbeat_skew_seconds",
+		"Number of seconds the most recent condition heartbeat of a cluster node is ahead of the local clock. Non-zero values hint at a node clock running ahead.",
+		descNodeLabelsDefaultLabels,
+		nil,
+	)
+	descNodeResourceCommittedRatio = prometheus.NewDesc(
+		"kube_node_resource_committed_ratio",
+		"The ratio of the allocatable resources of a node requested by the pods scheduled to it.",
+		append(descNodeLabelsDefaultLabels, "resource"),
+		nil,
+	)
+	descNodeExtendedResourceStranded = prometheus.NewDesc(
+		"kube_node_extended_resource_stranded",
+		"Whether pods scheduled to a node request an extended resource, like the GPUs of a device plugin, whose capacity is zero.",
+		append(descNodeLabelsDefaultLabels, "resource"),
+		nil,
+	)
+	descNodeStatusVolumesAttached = prometheus.NewDesc(
+		"kube_node_status_volumes_attached",
+		"The number of volumes attached to the node.",
+		descNodeLabelsDefaultLabels,
+		nil,
+	)
+	descNodeStatusVolumesInUse = prometheus.NewDesc(
+		"kube_node_status_volumes_in_use",
+		"The number of volumes in use by the node.",
+		descNodeLabelsDefaultLabels,
+		nil,
+	)
+	descNodeStatusVolumeAttached = prometheus.NewDesc(
+		"kube_node_status_volume_attached",
+		"Information about a volume attached to the node.",
+		append(descNodeLabelsDefaultLabels, "volume", "device_path"),
+		nil,
+	)
+	descNodeStatusVolumeInUse = prometheus.NewDesc(
+		"kube_node_status_volume_in_use",
+		"Information about a volume in use by the node.",
+		append(descNodeLabelsDefaultLabels, "volume"),
+		nil,
+	)
 	descNodeStatusPhase = prometheus.NewDesc(
 		"kube_node_status_phase",
 		"The phase the node is currently in.",
@@ -139,8 +200,15 @@ func (l NodeLister) List() (v1.NodeList, error) {
 
 func RegisterNodeCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
 	infs := SharedInformerList{}
+	podInfs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().Nodes().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().Nodes().Informer(), opts))
+		// The committed resources of nodes are derived from the requests of
+		// their pods, which would be undercounted if not all pods were
+		// watched.
+		if opts.WatchesAllPods() {
+			podInfs = append(podInfs, f.Core().V1().Pods().Informer().(cache.SharedInformer))
+		}
 	}
 
 	nodeLister := NodeLister(func() (machines v1.NodeList, err error) {
@@ -152,8 +220,25 @@ func RegisterNodeCollector(registry prometheus.Registerer, informerFactories []i
 		return machines, nil
 	})
 
-	registry.MustRegister(&nodeCollector{store: nodeLister, opts: opts})
+	podLister := PodLister(func() (pods []v1.Pod, err error) {
+		for _, pinf := range podInfs {
+			for _, m := range pinf.GetStore().List() {
+				pods = append(pods, *m.(*v1.Pod))
+			}
+		}
+		return pods, nil
+	})
+
+	nc := &nodeCollector{store: nodeLister, opts: opts, now: time.Now}
+	if len(podInfs) > 0 {
+		nc.pods = podLister
+	}
+	registry.MustRegister(nc)
+	registerDeletedObjects(registry, infs, "kube_node_deleted", descNodeLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("node", infs)
+	InformerSyncTracker.TrackDependencies("node", podInfs)
 	infs.Run(context.Background().Done())
+	podInfs.Run(context.Background().Done())
 }
 
 type nodeStore interface {
@@ -163,7 +248,9 @@ type nodeStore interface {
 // nodeCollector collects metrics about all nodes in the cluster.
 type nodeCollector struct {
 	store nodeStore
+	pods  podStore
 	opts  *options.Options
+	now   func() time.Time
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -174,10 +261,20 @@ func (nc *nodeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descNodeSpecUnschedulable
 	ch <- descNodeSpecTaint
 	ch <- descNodeStatusCondition
+	ch <- descNodeHealth
+	ch <- descNodeStatusHeartbeatSkew
+	ch <- descNodeResourceCommittedRatio
+	ch <- descNodeExtendedResourceStranded
+	ch <- descNodeStatusVolumesAttached
+	ch <- descNodeStatusVolumesInUse
 	ch <- descNodeStatusPhase
 	ch <- descNodeStatusCapacity
 	ch <- descNodeStatusAllocatable
 
+	if nc.opts.NodeVolumeInfo {
+		ch <- descNodeStatusVolumeAttached
+		ch <- descNodeStatusVolumeInUse
+	}
 	if !nc.opts.DisableNodeNonGenericResourceMetrics {
 		ch <- descNodeStatusCapacityCPU
 		ch <- descNodeStatusCapacityMemory
@@ -192,15 +289,25 @@ func (nc *nodeCollector) Describe(ch chan<- *prometheus.Desc) {
 func (nc *nodeCollector) Collect(ch chan<- prometheus.Metric) {
 	nodes, err := nc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "node"}).Inc()
+		addScrapeError(ch, "node", err)
 		glog.Errorf("listing nodes failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "node"}).Add(0)
 
+	var committed map[string]v1.ResourceList
+	if nc.pods != nil {
+		pods, err := nc.pods.List()
+		if err != nil {
+			glog.Errorf("listing pods of nodes failed: %s", err)
+		} else {
+			committed = committedResources(pods)
+		}
+	}
+
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "node"}).Observe(float64(len(nodes.Items)))
 	for _, n := range nodes.Items {
-		nc.collectNode(ch, n)
+		nc.collectNode(ch, n, committed)
 	}
 
 	glog.V(4).Infof("collected %d nodes", len(nodes.Items))
@@ -215,10 +322,61 @@ func nodeLabelsDesc(labelKeys []string) *prometheus.Desc {
 	)
 }
 
-func (nc *nodeCollector) collectNode(ch chan<- prometheus.Metric, n v1.Node) {
+// committedResources returns the resources requested by the pods scheduled to
+// each node, including the number of pods. Like the scheduler, it only
+// counts pods which have not terminated yet.
+func committedResources(pods []v1.Pod) map[string]v1.ResourceList {
+	committed := map[string]v1.ResourceList{}
+	for _, p := range pods {
+		if p.Spec.NodeName == "" || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+
+		nodeCommitted, ok := committed[p.Spec.NodeName]
+		if !ok {
+			nodeCommitted = v1.ResourceList{}
+			committed[p.Spec.NodeName] = nodeCommitted
+		}
+		for name, q := range podRequests(p) {
+			sum := nodeCommitted[name]
+			sum.Add(q)
+			nodeCommitted[name] = sum
+		}
+		pods := nodeCommitted[v1.ResourcePods]
+		pods.Add(*resource.NewQuantity(1, resource.DecimalSI))
+		nodeCommitted[v1.ResourcePods] = pods
+	}
+	return committed
+}
+
+// podRequests returns the effective requests of a pod, the sum of the
+// requests of its containers or the highest request of any init container,
+// whichever is higher.
+func podRequests(p v1.Pod) v1.ResourceList {
+	requests := v1.ResourceList{}
+	for _, c := range p.Spec.Containers {
+		for name, q := range c.Resources.Requests {
+			sum := requests[name]
+			sum.Add(q)
+			requests[name] = sum
+		}
+	}
+	for _, c := range p.Spec.InitContainers {
+		for name, q := range c.Resources.Requests {
+			if cur, ok := requests[name]; !ok || q.Cmp(cur) > 0 {
+				requests[name] = q
+			}
+		}
+	}
+	return requests
+}
+
+func (nc *nodeCollector) collectNode(ch chan<- prometheus.Metric, n v1.Node, committed map[string]v1.ResourceList) {
+	defer recoverObjectError("node", &n.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{n.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 	// NOTE: the instrumentation API requires providing label values in order of declaration
 	// in the metric descriptor. Be careful when making modifications.
@@ -229,11 +387,13 @@ func (nc *nodeCollector) collectNode(ch chan<- prometheus.Metric, n v1.Node) {
 		n.Status.NodeInfo.KubeletVersion,
 		n.Status.NodeInfo.KubeProxyVersion,
 		n.Spec.ProviderID,
+		nodeOS(n),
+		nodeArch(n),
 	)
 	if !n.CreationTimestamp.IsZero() {
 		addGauge(descNodeCreated, float64(n.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(n.Labels, nc.opts.MaxLabelValueLength)
 	addGauge(nodeLabelsDesc(labelKeys), 1, labelValues...)
 
 	addGauge(descNodeSpecUnschedulable, boolFloat64(n.Spec.Unschedulable))
@@ -252,7 +412,61 @@ func (nc *nodeCollector) collectNode(ch chan<- prometheus.Metric, n v1.Node) {
 		// Third party plugin may report customized condition for cluster node
 		// (e.g. node-problem-detector), and Kubernetes may add new core
 		// conditions in future.
-		addConditionMetrics(ch, descNodeStatusCondition, c.Status, n.Name, string(c.Type))
+		addConditionMetrics(ch, nc.opts.ConditionEncoding, descNodeStatusCondition, c.Status, n.Name, string(c.Type))
+	}
+	addGauge(descNodeHealth, boolFloat64(nodeHealthy(n)))
+
+	// The kubelet stamps condition heartbeats with the node clock, heartbeats
+	// from the future therefore reveal a skewed node clock.
+	var lastHeartbeat time.Time
+	for _, c := range n.Status.Conditions {
+		if c.LastHeartbeatTime.After(lastHeartbeat) {
+			lastHeartbeat = c.LastHeartbeatTime.Time
+		}
+	}
+	if !lastHeartbeat.IsZero() {
+		addGauge(descNodeStatusHeartbeatSkew, math.Max(0, lastHeartbeat.Sub(nc.now()).Seconds()))
+	}
+
+	// Without a pod store the committed resources are unknown, nodes without
+	// any pods have nothing committed.
+	if committed != nil {
+		for name, alloc := range n.Status.Allocatable {
+			if alloc.IsZero() {
+				continue
+			}
+			c := committed[n.Name][name]
+			addGauge(descNodeResourceCommittedRatio, float64(c.MilliValue())/float64(alloc.MilliValue()), sanitizeLabelName(string(name)))
+		}
+
+		// The kubelet zeroes the capacity of the resources of a device
+		// plugin which stopped running, so pods requesting them are stuck.
+		extended := map[v1.ResourceName]struct{}{}
+		for name := range n.Status.Capacity {
+			extended[name] = struct{}{}
+		}
+		for name := range committed[n.Name] {
+			extended[name] = struct{}{}
+		}
+		for name := range extended {
+			if !helper.IsExtendedResourceName(name) {
+				continue
+			}
+			c := committed[n.Name][name]
+			capacity := n.Status.Capacity[name]
+			addGauge(descNodeExtendedResourceStranded, boolFloat64(!c.IsZero() && capacity.IsZero()), sanitizeLabelName(string(name)))
+		}
+	}
+
+	addGauge(descNodeStatusVolumesAttached, float64(len(n.Status.VolumesAttached)))
+	addGauge(descNodeStatusVolumesInUse, float64(len(n.Status.VolumesInUse)))
+	if nc.opts.NodeVolumeInfo {
+		for _, v := range n.Status.VolumesAttached {
+			addGauge(descNodeStatusVolumeAttached, 1, string(v.Name), v.DevicePath)
+		}
+		for _, v := range n.Status.VolumesInUse {
+			addGauge(descNodeStatusVolumeInUse, 1, string(v))
+		}
 	}
 
 	// Set current phase to 1, others to 0 if it is set.
@@ -333,3 +547,50 @@ func (nc *nodeCollector) collectNode(ch chan<- prometheus.Metric, n v1.Node) {
 	}
 
 }
+
+// nodeOS returns the operating system reported by the kubelet, falling back to
+// the operating system labels of the node.
+func nodeOS(n v1.Node) string {
+	if os := n.Status.NodeInfo.OperatingSystem; os != "" {
+		return os
+	}
+	return firstLabelValue(n.Labels, osLabels)
+}
+
+// nodeArch returns the architecture reported by the kubelet, falling back to
+// the architecture labels of the node.
+func nodeArch(n v1.Node) string {
+	if arch := n.Status.NodeInfo.Architecture; arch != "" {
+		return arch
+	}
+	return firstLabelValue(n.Labels, archLabels)
+}
+
+func firstLabelValue(labels map[string]string, keys []string) string {
+	for _, k := range keys {
+		if v, ok := labels[k]; ok {
+			return v
+		}
+	}
+	return ""
+}
+
+// nodeHealthy returns whether the node is ready, does not report any memory,
+// disk or PID pressure and is schedulable.
+func nodeHealthy(n v1.Node) bool {
+	if n.Spec.Unschedulable {
+		return false
+	}
+	ready := false
+	for _, c := range n.Status.Conditions {
+		switch c.Type {
+		case v1.NodeReady:
+			ready = c.Status == v1.ConditionTrue
+		case v1.NodeMemoryPressure, v1.NodeDiskPressure, v1.NodePIDPressure:
+			if c.Status == v1.ConditionTrue {
+				return false
+			}
+		}
+	}
+	return ready
+}
@@ -23,8 +23,8 @@ import (
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockNodeStore struct {
@@ -69,6 +69,14 @@ func TestNodeCollector(t *testing.T) {
 		# HELP kube_node_status_allocatable_memory_bytes The memory resources of a node that are available for scheduling.
 		# HELP kube_node_status_condition The condition of a cluster node.
 		# TYPE kube_node_status_condition gauge
+		# HELP kube_node_health Whether a cluster node is healthy, i.e. ready, without memory, disk or PID pressure and schedulable.
+		# TYPE kube_node_health gauge
+		# HELP kube_node_status_heartbeat_skew_seconds Number of seconds the most recent condition heartbeat of a cluster node is ahead of the local clock. Non-zero values hint at a node clock running ahead.
+		# TYPE kube_node_status_heartbeat_skew_seconds gauge
+		# HELP kube_node_status_volumes_attached The number of volumes attached to the node.
+		# TYPE kube_node_status_volumes_attached gauge
+		# HELP kube_node_status_volumes_in_use The number of volumes in use by the node.
+		# TYPE kube_node_status_volumes_in_use gauge
 	`
 	cases := []struct {
 		nodes   []v1.Node
@@ -89,6 +97,8 @@ func TestNodeCollector(t *testing.T) {
 							KubeProxyVersion:        "kubeproxy",
 							OSImage:                 "osimage",
 							ContainerRuntimeVersion: "rkt",
+							OperatingSystem:         "linux",
+							Architecture:            "amd64",
 						},
 					},
 					Spec: v1.NodeSpec{
@@ -97,9 +107,12 @@ func TestNodeCollector(t *testing.T) {
 				},
 			},
 			want: metadata + `
-				kube_node_info{container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os_image="osimage",provider_id="provider://i-uniqueid"} 1
+				kube_node_info{arch="amd64",container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os="linux",os_image="osimage",provider_id="provider://i-uniqueid"} 1
 				kube_node_labels{node="127.0.0.1"} 1
 				kube_node_spec_unschedulable{node="127.0.0.1"} 0
+				kube_node_health{node="127.0.0.1"} 0
+				kube_node_status_volumes_attached{node="127.0.0.1"} 0
+				kube_node_status_volumes_in_use{node="127.0.0.1"} 0
 			`,
 		},
 		// Verify resource metrics.
@@ -110,7 +123,9 @@ func TestNodeCollector(t *testing.T) {
 						Name:              "127.0.0.1",
 						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 						Labels: map[string]string{
-							"type": "master",
+							"type":                    "master",
+							"kubernetes.io/os":        "windows",
+							"beta.kubernetes.io/arch": "amd64",
 						},
 					},
 					Spec: v1.NodeSpec{
@@ -146,9 +161,10 @@ func TestNodeCollector(t *testing.T) {
 			},
 			want: metadata + `
 				kube_node_created{node="127.0.0.1"} 1.5e+09
-				kube_node_info{container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os_image="osimage",provider_id="provider://i-randomidentifier"} 1
-				kube_node_labels{label_type="master",node="127.0.0.1"} 1
+				kube_node_info{arch="amd64",container_runtime_version="rkt",kernel_version="kernel",kubelet_version="kubelet",kubeproxy_version="kubeproxy",node="127.0.0.1",os="windows",os_image="osimage",provider_id="provider://i-randomidentifier"} 1
+				kube_node_labels{label_beta_kubernetes_io_arch="amd64",label_kubernetes_io_os="windows",label_type="master",node="127.0.0.1"} 1
 				kube_node_spec_unschedulable{node="127.0.0.1"} 1
+				kube_node_health{node="127.0.0.1"} 0
 				kube_node_status_capacity{node="127.0.0.1",resource="cpu",unit="core"} 4.3
 				kube_node_status_capacity{node="127.0.0.1",resource="memory",unit="byte"}2e9
 				kube_node_status_capacity{node="127.0.0.1",resource="pods",unit="integer"} 1000
@@ -167,6 +183,8 @@ func TestNodeCollector(t *testing.T) {
 				kube_node_status_allocatable_cpu_cores{node="127.0.0.1"} 3
 				kube_node_status_allocatable_memory_bytes{node="127.0.0.1"} 1e9
 				kube_node_status_allocatable_pods{node="127.0.0.1"} 555
+				kube_node_status_volumes_attached{node="127.0.0.1"} 0
+				kube_node_status_volumes_in_use{node="127.0.0.1"} 0
 			`,
 		},
 		// Verify phase enumerations.
@@ -281,6 +299,70 @@ func TestNodeCollector(t *testing.T) {
 			`,
 			metrics: []string{"kube_node_status_condition"},
 		},
+		// Verify Health
+		{
+			nodes: []v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "healthy",
+					},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{Type: v1.NodeReady, Status: v1.ConditionTrue},
+							{Type: v1.NodeMemoryPressure, Status: v1.ConditionFalse},
+							{Type: v1.NodeNetworkUnavailable, Status: v1.ConditionTrue},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "pressure",
+					},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{Type: v1.NodeReady, Status: v1.ConditionTrue},
+							{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "cordoned",
+					},
+					Spec: v1.NodeSpec{
+						Unschedulable: true,
+					},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{Type: v1.NodeReady, Status: v1.ConditionTrue},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "unknown",
+					},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{Type: v1.NodeReady, Status: v1.ConditionUnknown},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "new",
+					},
+				},
+			},
+			want: metadata + `
+				kube_node_health{node="healthy"} 1
+				kube_node_health{node="pressure"} 0
+				kube_node_health{node="cordoned"} 0
+				kube_node_health{node="unknown"} 0
+				kube_node_health{node="new"} 0
+			`,
+			metrics: []string{"kube_node_health"},
+		},
 		// Verify SpecTaints
 		{
 			nodes: []v1.Node{
@@ -304,6 +386,42 @@ func TestNodeCollector(t *testing.T) {
 			`,
 			metrics: []string{"kube_node_spec_taint"},
 		},
+		// Verify heartbeat skew, heartbeats lagging behind are no skew.
+		{
+			nodes: []v1.Node{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "127.0.0.1",
+					},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{Type: v1.NodeReady, Status: v1.ConditionTrue, LastHeartbeatTime: metav1.Time{Time: time.Unix(1500000030, 0)}},
+							{Type: v1.NodeOutOfDisk, Status: v1.ConditionFalse, LastHeartbeatTime: metav1.Time{Time: time.Unix(1500000020, 0)}},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "127.0.0.2",
+					},
+					Status: v1.NodeStatus{
+						Conditions: []v1.NodeCondition{
+							{Type: v1.NodeReady, Status: v1.ConditionTrue, LastHeartbeatTime: metav1.Time{Time: time.Unix(1499999990, 0)}},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "127.0.0.3",
+					},
+				},
+			},
+			want: metadata + `
+				kube_node_status_heartbeat_skew_seconds{node="127.0.0.1"} 30
+				kube_node_status_heartbeat_skew_seconds{node="127.0.0.2"} 0
+			`,
+			metrics: []string{"kube_node_status_heartbeat_skew_seconds"},
+		},
 	}
 	for _, c := range cases {
 		dc := &nodeCollector{
@@ -313,9 +431,282 @@ func TestNodeCollector(t *testing.T) {
 				},
 			},
 			opts: &options.Options{},
+			now:  func() time.Time { return time.Unix(1500000000, 0) },
 		}
 		if err := testutils.GatherAndCompare(dc, c.want, c.metrics); err != nil {
 			t.Errorf("unexpected collecting result:\n%s", err)
 		}
 	}
 }
+
+func TestNodeCollectorCommittedRatio(t *testing.T) {
+	const metadata = `
+		# HELP kube_node_resource_committed_ratio The ratio of the allocatable resources of a node requested by the pods scheduled to it.
+		# TYPE kube_node_resource_committed_ratio gauge
+	`
+	nodes := []v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "127.0.0.1",
+			},
+			Status: v1.NodeStatus{
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse("4"),
+					v1.ResourceMemory: resource.MustParse("8G"),
+					v1.ResourcePods:   resource.MustParse("10"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "127.0.0.2",
+			},
+			Status: v1.NodeStatus{
+				Allocatable: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("2"),
+				},
+			},
+		},
+	}
+	container := func(cpu, memory string) v1.Container {
+		return v1.Container{
+			Resources: v1.ResourceRequirements{
+				Requests: v1.ResourceList{
+					v1.ResourceCPU:    resource.MustParse(cpu),
+					v1.ResourceMemory: resource.MustParse(memory),
+				},
+			},
+		}
+	}
+	pods := []v1.Pod{
+		{
+			Spec: v1.PodSpec{
+				NodeName:   "127.0.0.1",
+				Containers: []v1.Container{container("500m", "1G"), container("500m", "1G")},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		},
+		// The init container requests more CPU than all containers together.
+		{
+			Spec: v1.PodSpec{
+				NodeName:       "127.0.0.1",
+				InitContainers: []v1.Container{container("2", "1G")},
+				Containers:     []v1.Container{container("100m", "2G")},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		},
+		// Terminated and unscheduled pods don't commit any resources.
+		{
+			Spec: v1.PodSpec{
+				NodeName:   "127.0.0.1",
+				Containers: []v1.Container{container("1", "1G")},
+			},
+			Status: v1.PodStatus{Phase: v1.PodSucceeded},
+		},
+		{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{container("1", "1G")},
+			},
+			Status: v1.PodStatus{Phase: v1.PodPending},
+		},
+	}
+	want := metadata + `
+		kube_node_resource_committed_ratio{node="127.0.0.1",resource="cpu"} 0.75
+		kube_node_resource_committed_ratio{node="127.0.0.1",resource="memory"} 0.5
+		kube_node_resource_committed_ratio{node="127.0.0.1",resource="pods"} 0.2
+		kube_node_resource_committed_ratio{node="127.0.0.2",resource="cpu"} 0
+	`
+
+	nc := &nodeCollector{
+		store: &mockNodeStore{
+			list: func() (v1.NodeList, error) {
+				return v1.NodeList{Items: nodes}, nil
+			},
+		},
+		pods: mockPodStore{
+			f: func() ([]v1.Pod, error) { return pods, nil },
+		},
+		opts: &options.Options{},
+		now:  time.Now,
+	}
+	if err := testutils.GatherAndCompare(nc, want, []string{"kube_node_resource_committed_ratio"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestNodeCollectorExtendedResourceStranded(t *testing.T) {
+	const metadata = `
+		# HELP kube_node_extended_resource_stranded Whether pods scheduled to a node request an extended resource, like the GPUs of a device plugin, whose capacity is zero.
+		# TYPE kube_node_extended_resource_stranded gauge
+	`
+	const gpu = v1.ResourceName("nvidia.com/gpu")
+	nodes := []v1.Node{
+		// The device plugin of this node crashed.
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "127.0.0.1"},
+			Status: v1.NodeStatus{
+				Capacity: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("4"),
+					gpu:            resource.MustParse("0"),
+				},
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "127.0.0.2"},
+			Status: v1.NodeStatus{
+				Capacity: v1.ResourceList{
+					v1.ResourceCPU: resource.MustParse("4"),
+					gpu:            resource.MustParse("2"),
+				},
+			},
+		},
+		// Nothing requests the resources of this node.
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "127.0.0.3"},
+			Status: v1.NodeStatus{
+				Capacity: v1.ResourceList{
+					gpu: resource.MustParse("0"),
+				},
+			},
+		},
+		// The resource is not advertised at all.
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "127.0.0.4"},
+		},
+	}
+	gpuPod := func(node string) v1.Pod {
+		return v1.Pod{
+			Spec: v1.PodSpec{
+				NodeName: node,
+				Containers: []v1.Container{{
+					Resources: v1.ResourceRequirements{
+						Requests: v1.ResourceList{
+							v1.ResourceCPU: resource.MustParse("1"),
+							gpu:            resource.MustParse("1"),
+						},
+					},
+				}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		}
+	}
+	pods := []v1.Pod{gpuPod("127.0.0.1"), gpuPod("127.0.0.2"), gpuPod("127.0.0.4")}
+	want := metadata + `
+		kube_node_extended_resource_stranded{node="127.0.0.1",resource="nvidia_com_gpu"} 1
+		kube_node_extended_resource_stranded{node="127.0.0.2",resource="nvidia_com_gpu"} 0
+		kube_node_extended_resource_stranded{node="127.0.0.3",resource="nvidia_com_gpu"} 0
+		kube_node_extended_resource_stranded{node="127.0.0.4",resource="nvidia_com_gpu"} 1
+	`
+
+	nc := &nodeCollector{
+		store: &mockNodeStore{
+			list: func() (v1.NodeList, error) {
+				return v1.NodeList{Items: nodes}, nil
+			},
+		},
+		pods: mockPodStore{
+			f: func() ([]v1.Pod, error) { return pods, nil },
+		},
+		opts: &options.Options{},
+		now:  time.Now,
+	}
+	if err := testutils.GatherAndCompare(nc, want, []string{"kube_node_extended_resource_stranded"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestNodeCollectorVolumes(t *testing.T) {
+	const metadata = `
+		# HELP kube_node_status_volumes_attached The number of volumes attached to the node.
+		# TYPE kube_node_status_volumes_attached gauge
+		# HELP kube_node_status_volumes_in_use The number of volumes in use by the node.
+		# TYPE kube_node_status_volumes_in_use gauge
+		# HELP kube_node_status_volume_attached Information about a volume attached to the node.
+		# TYPE kube_node_status_volume_attached gauge
+		# HELP kube_node_status_volume_in_use Information about a volume in use by the node.
+		# TYPE kube_node_status_volume_in_use gauge
+	`
+	nodes := []v1.Node{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "127.0.0.1"},
+			Status: v1.NodeStatus{
+				VolumesAttached: []v1.AttachedVolume{
+					{Name: "kubernetes.io/aws-ebs/aws://us-east-1a/vol-1", DevicePath: "/dev/xvdba"},
+					{Name: "kubernetes.io/aws-ebs/aws://us-east-1a/vol-2", DevicePath: "/dev/xvdbb"},
+				},
+				VolumesInUse: []v1.UniqueVolumeName{"kubernetes.io/aws-ebs/aws://us-east-1a/vol-1"},
+			},
+		},
+	}
+	metrics := []string{
+		"kube_node_status_volumes_attached",
+		"kube_node_status_volumes_in_use",
+		"kube_node_status_volume_attached",
+		"kube_node_status_volume_in_use",
+	}
+	cases := []struct {
+		volumeInfo bool
+		want       string
+	}{
+		{
+			want: metadata + `
+				kube_node_status_volumes_attached{node="127.0.0.1"} 2
+				kube_node_status_volumes_in_use{node="127.0.0.1"} 1
+			`,
+		},
+		{
+			volumeInfo: true,
+			want: metadata + `
+				kube_node_status_volumes_attached{node="127.0.0.1"} 2
+				kube_node_status_volumes_in_use{node="127.0.0.1"} 1
+				kube_node_status_volume_attached{device_path="/dev/xvdba",node="127.0.0.1",volume="kubernetes.io/aws-ebs/aws://us-east-1a/vol-1"} 1
+				kube_node_status_volume_attached{device_path="/dev/xvdbb",node="127.0.0.1",volume="kubernetes.io/aws-ebs/aws://us-east-1a/vol-2"} 1
+				kube_node_status_volume_in_use{node="127.0.0.1",volume="kubernetes.io/aws-ebs/aws://us-east-1a/vol-1"} 1
+			`,
+		},
+	}
+	for _, c := range cases {
+		nc := &nodeCollector{
+			store: &mockNodeStore{
+				list: func() (v1.NodeList, error) {
+					return v1.NodeList{Items: nodes}, nil
+				},
+			},
+			opts: &options.Options{NodeVolumeInfo: c.volumeInfo},
+			now:  time.Now,
+		}
+		if err := testutils.GatherAndCompare(nc, c.want, metrics); err != nil {
+			t.Errorf("unexpected collecting result with volume info %t:\n%s", c.volumeInfo, err)
+		}
+	}
+}
+
+func TestNodeCollectorBinaryConditionEncoding(t *testing.T) {
+	const want = `
+		# HELP kube_node_status_condition The condition of a cluster node.
+		# TYPE kube_node_status_condition gauge
+		kube_node_status_condition{condition="Ready",node="127.0.0.1",status="true"} 0
+		kube_node_status_condition{condition="DiskPressure",node="127.0.0.1",status="true"} 1
+	`
+	node := v1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "127.0.0.1"},
+		Status: v1.NodeStatus{
+			Conditions: []v1.NodeCondition{
+				{Type: v1.NodeReady, Status: v1.ConditionUnknown},
+				{Type: v1.NodeDiskPressure, Status: v1.ConditionTrue},
+			},
+		},
+	}
+	nc := &nodeCollector{
+		store: &mockNodeStore{
+			list: func() (v1.NodeList, error) {
+				return v1.NodeList{Items: []v1.Node{node}}, nil
+			},
+		},
+		opts: &options.Options{ConditionEncoding: options.ConditionEncodingBinary},
+		now:  time.Now,
+	}
+	if err := testutils.GatherAndCompare(nc, want, []string{"kube_node_status_condition"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,209 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// textSourceRegisterer is implemented by registries that can hold metrics
+// precomputed in the text format, like metrics.Registry.
+type textSourceRegisterer interface {
+	RegisterTextSource(metrics.TextSource)
+}
+
+// objectMetricsCache holds the metrics generated for every detailed object of
+// a resource, encoded in the text format. It is updated on informer events,
+// so that scrapes write the encoded metrics as they are instead of generating
+// and encoding the metrics of every object again.
+//
+// Only metrics depending on nothing but the object and the options can be
+// cached. Metrics derived from several objects or from the current time are
+// still generated at scrape time.
+type objectMetricsCache struct {
+	opts     *options.Options
+	generate func(ch chan<- prometheus.Metric, obj interface{})
+
+	mu sync.RWMutex
+	// families maps the name of every cached family to its header and its
+	// number of samples.
+	families map[string]metrics.TextFamily
+	// samples maps the name of every cached family to the samples of each
+	// object, by object key. The samples of an object are replaced, never
+	// modified, so they can be written without holding mu.
+	samples map[string]map[string][]byte
+	// objects maps every object key to the families the object has samples
+	// of.
+	objects map[string][]metrics.TextFamily
+}
+
+// newObjectMetricsCache returns a cache of the metrics generated by generate
+// for the objects of infs, registered with registry, if --precompute-metrics
+// is set and registry can hold precomputed metrics, and nil otherwise.
+// It has to be called before the informers are run.
+func newObjectMetricsCache(registry prometheus.Registerer, infs SharedInformerList, opts *options.Options, generate func(ch chan<- prometheus.Metric, obj interface{})) *objectMetricsCache {
+	if !opts.PrecomputeMetrics {
+		return nil
+	}
+	r, ok := registry.(textSourceRegisterer)
+	if !ok {
+		return nil
+	}
+	c := &objectMetricsCache{
+		opts:     opts,
+		generate: generate,
+		families: map[string]metrics.TextFamily{},
+		samples:  map[string]map[string][]byte{},
+		objects:  map[string][]metrics.TextFamily{},
+	}
+	for _, inf := range infs {
+		inf.AddEventHandler(c)
+	}
+	r.RegisterTextSource(c)
+	return c
+}
+
+// OnAdd implements the cache.ResourceEventHandler interface.
+func (c *objectMetricsCache) OnAdd(obj interface{}) {
+	c.update(obj)
+}
+
+// OnUpdate implements the cache.ResourceEventHandler interface.
+func (c *objectMetricsCache) OnUpdate(oldObj, newObj interface{}) {
+	c.update(newObj)
+}
+
+// OnDelete implements the cache.ResourceEventHandler interface.
+func (c *objectMetricsCache) OnDelete(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	c.mu.Lock()
+	c.remove(key)
+	c.mu.Unlock()
+}
+
+func (c *objectMetricsCache) update(obj interface{}) {
+	key, err := cache.MetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return
+	}
+
+	var generated []prometheus.Metric
+	if detailed(c.opts, o) {
+		ch := make(chan prometheus.Metric)
+		go func() {
+			defer close(ch)
+			c.generate(ch, obj)
+		}()
+		for m := range ch {
+			generated = append(generated, m)
+		}
+	}
+	efs, err := metrics.EncodeText(generated)
+	if err != nil {
+		glog.Errorf("encoding the metrics of %s failed: %v", key, err)
+		efs = nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(key)
+	if len(efs) == 0 {
+		return
+	}
+	tfs := make([]metrics.TextFamily, 0, len(efs))
+	for _, ef := range efs {
+		tf, ok := c.families[ef.Name]
+		if !ok {
+			tf = metrics.TextFamily{Name: ef.Name, Header: ef.Header}
+			c.samples[ef.Name] = map[string][]byte{}
+		}
+		tf.Series += ef.Series
+		c.families[ef.Name] = tf
+		c.samples[ef.Name][key] = ef.Samples
+		tfs = append(tfs, metrics.TextFamily{Name: ef.Name, Series: ef.Series})
+	}
+	c.objects[key] = tfs
+}
+
+// remove drops the samples of the object of the given key. c.mu has to be
+// held for writing.
+func (c *objectMetricsCache) remove(key string) {
+	for _, otf := range c.objects[key] {
+		tf := c.families[otf.Name]
+		tf.Series -= otf.Series
+		delete(c.samples[otf.Name], key)
+		if len(c.samples[otf.Name]) == 0 {
+			delete(c.families, otf.Name)
+			delete(c.samples, otf.Name)
+			continue
+		}
+		c.families[otf.Name] = tf
+	}
+	delete(c.objects, key)
+}
+
+// TextFamilies implements the metrics.TextSource interface.
+func (c *objectMetricsCache) TextFamilies() []metrics.TextFamily {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	tfs := make([]metrics.TextFamily, 0, len(c.families))
+	for _, tf := range c.families {
+		tfs = append(tfs, tf)
+	}
+	sort.Slice(tfs, func(i, j int) bool { return tfs[i].Name < tfs[j].Name })
+	return tfs
+}
+
+// WriteSamples implements the metrics.TextSource interface. The samples are
+// written in the order of the object keys, after releasing c.mu, so that a
+// slow scrape doesn't block informer updates.
+func (c *objectMetricsCache) WriteSamples(w io.Writer, family string) error {
+	c.mu.RLock()
+	keys := make([]string, 0, len(c.samples[family]))
+	for key := range c.samples[family] {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	samples := make([][]byte, 0, len(keys))
+	for _, key := range keys {
+		samples = append(samples, c.samples[family][key])
+	}
+	c.mu.RUnlock()
+
+	for _, s := range samples {
+		if _, err := w.Write(s); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestObjectMetricsCache(t *testing.T) {
+	newPod := func(namespace, name string, created int64) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: time.Unix(created, 0)},
+			},
+		}
+	}
+	pod1, pod2, pod3 := newPod("team-a", "pod1", 1500000000), newPod("team-b", "pod2", 1500000000), newPod("team-a", "pod3", 1500000000)
+	updatedPod1 := newPod("team-a", "pod1", 1600000000)
+
+	opts := &options.Options{
+		PrecomputeMetrics:  true,
+		DetailedNamespaces: options.NamespaceList{"team-a"},
+	}
+	pc := &podCollector{
+		store: mockPodStore{
+			f: func() ([]v1.Pod, error) { return []v1.Pod{*updatedPod1, *pod2}, nil },
+		},
+		opts: opts,
+		now:  time.Now,
+	}
+	registry := metrics.NewRegistry()
+	pc.metrics = newObjectMetricsCache(registry, nil, opts, func(ch chan<- prometheus.Metric, obj interface{}) {
+		pc.collectPod(ch, *obj.(*v1.Pod))
+	})
+	registry.MustRegister(pc)
+
+	pc.metrics.OnAdd(pod1)
+	pc.metrics.OnAdd(pod2)
+	pc.metrics.OnAdd(pod3)
+	pc.metrics.OnUpdate(pod1, updatedPod1)
+
+	// The samples are written in the order of the object keys.
+	var samples bytes.Buffer
+	if err := pc.metrics.WriteSamples(&samples, "kube_pod_created"); err != nil {
+		t.Fatalf("unexpected error writing samples: %v", err)
+	}
+	if want := "kube_pod_created{namespace=\"team-a\",pod=\"pod1\"} 1.6e+09\nkube_pod_created{namespace=\"team-a\",pod=\"pod3\"} 1.5e+09\n"; samples.String() != want {
+		t.Errorf("expected precomputed samples %q, got %q", want, samples.String())
+	}
+
+	pc.metrics.OnDelete(cache.DeletedFinalStateUnknown{Key: "team-a/pod3", Obj: pod3})
+
+	samples.Reset()
+	if err := pc.metrics.WriteSamples(&samples, "kube_pod_created"); err != nil {
+		t.Fatalf("unexpected error writing samples: %v", err)
+	}
+	if want := "kube_pod_created{namespace=\"team-a\",pod=\"pod1\"} 1.6e+09\n"; samples.String() != want {
+		t.Errorf("expected precomputed samples %q, got %q", want, samples.String())
+	}
+	for _, tf := range pc.metrics.TextFamilies() {
+		if tf.Name == "kube_pod_created" && tf.Series != 1 {
+			t.Errorf("expected 1 precomputed kube_pod_created series, got %d", tf.Series)
+		}
+	}
+
+	// Parsed back, the precomputed metrics are gathered along with the
+	// ones generated at scrape time.
+	mfs, err := registry.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error gathering metrics: %v", err)
+	}
+	var got bytes.Buffer
+	for _, mf := range mfs {
+		if mf.GetName() == "kube_pod_created" || mf.GetName() == "kube_summarized_objects" {
+			expfmt.MetricFamilyToText(&got, mf)
+		}
+	}
+	want := `# HELP kube_pod_created Unix creation timestamp
+# TYPE kube_pod_created gauge
+kube_pod_created{namespace="team-a",pod="pod1"} 1.6e+09
+# HELP kube_summarized_objects Number of objects per namespace for which no per-object metrics are exposed.
+# TYPE kube_summarized_objects gauge
+kube_summarized_objects{namespace="team-b",resource="pod"} 1
+`
+	if got.String() != want {
+		t.Errorf("unexpected gathering result:\n%s\nwant:\n%s", got.String(), want)
+	}
+}
+
+func TestObjectMetricsCacheNeedsTextRegistry(t *testing.T) {
+	opts := &options.Options{PrecomputeMetrics: true}
+	if c := newObjectMetricsCache(prometheus.NewRegistry(), nil, opts, nil); c != nil {
+		t.Error("expected no cache for a registry which cannot hold precomputed metrics")
+	}
+}
@@ -22,7 +22,6 @@ import (
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -49,6 +48,12 @@ var (
 		append(descPersistentVolumeLabelsDefaultLabels, "storageclass"),
 		nil,
 	)
+	descPersistentVolumeCSIInfo = prometheus.NewDesc(
+		"kube_persistentvolume_csi_info",
+		"Information about the CSI driver and volume of a persistentvolume.",
+		append(descPersistentVolumeLabelsDefaultLabels, "csi_driver", "csi_volume_handle"),
+		nil,
+	)
 )
 
 type PersistentVolumeLister func() (v1.PersistentVolumeList, error)
@@ -61,7 +66,7 @@ func RegisterPersistentVolumeCollector(registry prometheus.Registerer, informerF
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().PersistentVolumes().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().PersistentVolumes().Informer(), opts))
 	}
 
 	persistentVolumeLister := PersistentVolumeLister(func() (pvs v1.PersistentVolumeList, err error) {
@@ -74,6 +79,8 @@ func RegisterPersistentVolumeCollector(registry prometheus.Registerer, informerF
 	})
 
 	registry.MustRegister(&persistentVolumeCollector{store: persistentVolumeLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_persistentvolume_deleted", descPersistentVolumeLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("persistentvolume", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -91,6 +98,7 @@ type persistentVolumeCollector struct {
 func (collector *persistentVolumeCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descPersistentVolumeStatusPhase
 	ch <- descPersistentVolumeInfo
+	ch <- descPersistentVolumeCSIInfo
 	ch <- descPersistentVolumeLabels
 }
 
@@ -107,7 +115,7 @@ func persistentVolumeLabelsDesc(labelKeys []string) *prometheus.Desc {
 func (collector *persistentVolumeCollector) Collect(ch chan<- prometheus.Metric) {
 	persistentVolumeCollector, err := collector.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "persistentvolume"}).Inc()
+		addScrapeError(ch, "persistentvolume", err)
 		glog.Errorf("listing persistentVolume failed: %s", err)
 		return
 	}
@@ -122,15 +130,20 @@ func (collector *persistentVolumeCollector) Collect(ch chan<- prometheus.Metric)
 }
 
 func (collector *persistentVolumeCollector) collectPersistentVolume(ch chan<- prometheus.Metric, pv v1.PersistentVolume) {
+	defer recoverObjectError("persistentvolume", &pv.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{pv.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(pv.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(pv.Labels, collector.opts.MaxLabelValueLength)
 	addGauge(persistentVolumeLabelsDesc(labelKeys), 1, labelValues...)
 
 	addGauge(descPersistentVolumeInfo, 1, pv.Spec.StorageClassName)
+	if csi := pv.Spec.CSI; csi != nil {
+		addGauge(descPersistentVolumeCSIInfo, 1, csi.Driver, csi.VolumeHandle)
+	}
 	// Set current phase to 1, others to 0 if it is set.
 	if p := pv.Status.Phase; p != "" {
 		addGauge(descPersistentVolumeStatusPhase, boolFloat64(p == v1.VolumePending), string(v1.VolumePending))
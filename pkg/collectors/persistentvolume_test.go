@@ -21,8 +21,8 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockPersistentVolumeStore struct {
@@ -43,6 +43,8 @@ func TestPersistentVolumeCollector(t *testing.T) {
 			# TYPE kube_persistentvolume_labels gauge
 			# HELP kube_persistentvolume_info Information about persistentvolume.
 			# TYPE kube_persistentvolume_info gauge
+			# HELP kube_persistentvolume_csi_info Information about the CSI driver and volume of a persistentvolume.
+			# TYPE kube_persistentvolume_csi_info gauge
 	`
 	cases := []struct {
 		pvs     []v1.PersistentVolume
@@ -181,6 +183,39 @@ func TestPersistentVolumeCollector(t *testing.T) {
 				`,
 			metrics: []string{"kube_persistentvolume_labels"},
 		},
+		{
+			pvs: []v1.PersistentVolume{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-csi-pv",
+					},
+					Spec: v1.PersistentVolumeSpec{
+						PersistentVolumeSource: v1.PersistentVolumeSource{
+							CSI: &v1.CSIPersistentVolumeSource{
+								Driver:       "ebs.csi.aws.com",
+								VolumeHandle: "vol-0123456789",
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "test-hostpath-pv",
+					},
+					Spec: v1.PersistentVolumeSpec{
+						PersistentVolumeSource: v1.PersistentVolumeSource{
+							HostPath: &v1.HostPathVolumeSource{
+								Path: "/data",
+							},
+						},
+					},
+				},
+			},
+			want: metadata + `
+					kube_persistentvolume_csi_info{csi_driver="ebs.csi.aws.com",csi_volume_handle="vol-0123456789",persistentvolume="test-csi-pv"} 1
+				`,
+			metrics: []string{"kube_persistentvolume_csi_info"},
+		},
 	}
 	for _, c := range cases {
 		dc := &persistentVolumeCollector{
@@ -22,7 +22,6 @@ import (
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -55,6 +54,24 @@ var (
 		descPersistentVolumeClaimLabelsDefaultLabels,
 		nil,
 	)
+
+	descPersistentVolumeClaimCreatedBy = prometheus.NewDesc(
+		"kube_persistentvolumeclaim_created_by",
+		"The provisioner the volume of the persistent volume claim is dynamically provisioned by.",
+		append(descPersistentVolumeClaimLabelsDefaultLabels, "provisioner"),
+		nil,
+	)
+
+	descPersistentVolumeClaimSummarizedObjects = newSummarizedObjectsDesc("persistentvolumeclaim")
+)
+
+const (
+	// storageProvisionerAnnotation is set on claims by the persistent volume
+	// controller to the provisioner expected to provision their volumes.
+	storageProvisionerAnnotation = "volume.kubernetes.io/storage-provisioner"
+	// betaStorageProvisionerAnnotation is set instead by controllers older
+	// than Kubernetes 1.23, and along with the former by newer ones.
+	betaStorageProvisionerAnnotation = "volume.beta.kubernetes.io/storage-provisioner"
 )
 
 type PersistentVolumeClaimLister func() (v1.PersistentVolumeClaimList, error)
@@ -67,7 +84,7 @@ func RegisterPersistentVolumeClaimCollector(registry prometheus.Registerer, info
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().PersistentVolumeClaims().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().PersistentVolumeClaims().Informer(), opts))
 	}
 
 	persistentVolumeClaimLister := PersistentVolumeClaimLister(func() (pvcs v1.PersistentVolumeClaimList, err error) {
@@ -80,6 +97,8 @@ func RegisterPersistentVolumeClaimCollector(registry prometheus.Registerer, info
 	})
 
 	registry.MustRegister(&persistentVolumeClaimCollector{store: persistentVolumeClaimLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_persistentvolumeclaim_deleted", descPersistentVolumeClaimLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("persistentvolumeclaim", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -99,6 +118,8 @@ func (collector *persistentVolumeClaimCollector) Describe(ch chan<- *prometheus.
 	ch <- descPersistentVolumeClaimInfo
 	ch <- descPersistentVolumeClaimStatusPhase
 	ch <- descPersistentVolumeClaimResourceRequestsStorage
+	ch <- descPersistentVolumeClaimCreatedBy
+	ch <- descPersistentVolumeClaimSummarizedObjects
 }
 
 func persistentVolumeClaimLabelsDesc(labelKeys []string) *prometheus.Desc {
@@ -114,16 +135,22 @@ func persistentVolumeClaimLabelsDesc(labelKeys []string) *prometheus.Desc {
 func (collector *persistentVolumeClaimCollector) Collect(ch chan<- prometheus.Metric) {
 	persistentVolumeClaimCollector, err := collector.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "persistentvolumeclaim"}).Inc()
+		addScrapeError(ch, "persistentvolumeclaim", err)
 		glog.Errorf("listing persistent volume claims failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "persistentvolumeclaim"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "persistentvolumeclaim"}).Observe(float64(len(persistentVolumeClaimCollector.Items)))
+	summarized := map[string]int{}
 	for _, pvc := range persistentVolumeClaimCollector.Items {
+		if !detailed(collector.opts, &pvc.ObjectMeta) {
+			summarized[pvc.Namespace]++
+			continue
+		}
 		collector.collectPersistentVolumeClaim(ch, pvc)
 	}
+	addSummarizedObjects(ch, descPersistentVolumeClaimSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d persistentvolumeclaims", len(persistentVolumeClaimCollector.Items))
 }
@@ -145,12 +172,14 @@ func getPersistentVolumeClaimClass(claim *v1.PersistentVolumeClaim) string {
 }
 
 func (collector *persistentVolumeClaimCollector) collectPersistentVolumeClaim(ch chan<- prometheus.Metric, pvc v1.PersistentVolumeClaim) {
+	defer recoverObjectError("persistentvolumeclaim", &pvc.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{pvc.Namespace, pvc.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(pvc.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(pvc.Labels, collector.opts.MaxLabelValueLength)
 	addGauge(persistentVolumeClaimLabelsDesc(labelKeys), 1, labelValues...)
 
 	storageClassName := getPersistentVolumeClaimClass(&pvc)
@@ -167,4 +196,12 @@ func (collector *persistentVolumeClaimCollector) collectPersistentVolumeClaim(ch
 	if storage, ok := pvc.Spec.Resources.Requests[v1.ResourceStorage]; ok {
 		addGauge(descPersistentVolumeClaimResourceRequestsStorage, float64(storage.Value()))
 	}
+
+	provisioner, ok := pvc.Annotations[storageProvisionerAnnotation]
+	if !ok {
+		provisioner, ok = pvc.Annotations[betaStorageProvisionerAnnotation]
+	}
+	if ok {
+		addGauge(descPersistentVolumeClaimCreatedBy, 1, provisioner)
+	}
 }
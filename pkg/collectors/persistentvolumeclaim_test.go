@@ -22,8 +22,8 @@ import (
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockPersistentVolumeClaimStore struct {
@@ -46,6 +46,8 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 		# TYPE kube_persistentvolumeclaim_status_phase gauge
 		# HELP kube_persistentvolumeclaim_resource_requests_storage_bytes The capacity of storage requested by the persistent volume claim.
 		# TYPE kube_persistentvolumeclaim_resource_requests_storage_bytes gauge
+		# HELP kube_persistentvolumeclaim_created_by The provisioner the volume of the persistent volume claim is dynamically provisioned by.
+		# TYPE kube_persistentvolumeclaim_created_by gauge
 	`
 	storageClassName := "rbd"
 	cases := []struct {
@@ -63,6 +65,9 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 						Labels: map[string]string{
 							"app": "mysql-server",
 						},
+						Annotations: map[string]string{
+							"volume.beta.kubernetes.io/storage-provisioner": "kubernetes.io/rbd",
+						},
 					},
 					Spec: v1.PersistentVolumeClaimSpec{
 						StorageClassName: &storageClassName,
@@ -81,6 +86,10 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 					ObjectMeta: metav1.ObjectMeta{
 						Name:      "prometheus-data",
 						Namespace: "default",
+						Annotations: map[string]string{
+							"volume.kubernetes.io/storage-provisioner":      "rbd.csi.ceph.com",
+							"volume.beta.kubernetes.io/storage-provisioner": "kubernetes.io/rbd",
+						},
 					},
 					Spec: v1.PersistentVolumeClaimSpec{
 						StorageClassName: &storageClassName,
@@ -116,8 +125,10 @@ func TestPersistentVolumeClaimCollector(t *testing.T) {
 				kube_persistentvolumeclaim_labels{namespace="",persistentvolumeclaim="mongo-data"} 1
 				kube_persistentvolumeclaim_labels{namespace="default",persistentvolumeclaim="prometheus-data"} 1
 				kube_persistentvolumeclaim_labels{label_app="mysql-server",namespace="default",persistentvolumeclaim="mysql-data"} 1
+				kube_persistentvolumeclaim_created_by{namespace="default",persistentvolumeclaim="mysql-data",provisioner="kubernetes.io/rbd"} 1
+				kube_persistentvolumeclaim_created_by{namespace="default",persistentvolumeclaim="prometheus-data",provisioner="rbd.csi.ceph.com"} 1
 			`,
-			metrics: []string{"kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_labels"},
+			metrics: []string{"kube_persistentvolumeclaim_info", "kube_persistentvolumeclaim_status_phase", "kube_persistentvolumeclaim_resource_requests_storage_bytes", "kube_persistentvolumeclaim_labels", "kube_persistentvolumeclaim_created_by"},
 		},
 	}
 	for _, c := range cases {
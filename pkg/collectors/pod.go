@@ -18,6 +18,8 @@ package collectors
 
 import (
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
@@ -39,12 +41,67 @@ var (
 	containerWaitingReasons    = []string{"ContainerCreating", "CrashLoopBackOff", "ErrImagePull", "ImagePullBackOff"}
 	containerTerminatedReasons = []string{"OOMKilled", "Completed", "Error", "ContainerCannotRun"}
 
+	// unschedulableReasons classifies the predicate failures listed in the
+	// PodScheduled condition message of unschedulable pods, e.g. "0/3 nodes are
+	// available: 1 Insufficient cpu, 2 node(s) had taints that the pod didn't
+	// tolerate.", by the phrases the scheduler uses for them. Messages matching
+	// none of them are classified as other. Keep this in sync with the failure
+	// reasons of the scheduler predicates.
+	unschedulableReasons = []struct {
+		reason  string
+		phrases []string
+	}{
+		{"insufficient_cpu", []string{"Insufficient cpu"}},
+		{"insufficient_memory", []string{"Insufficient memory"}},
+		{"insufficient_pods", []string{"Insufficient pods"}},
+		{"insufficient_ephemeral_storage", []string{"Insufficient ephemeral-storage"}},
+		{"insufficient_extended_resource", []string{"Insufficient "}},
+		{"node_affinity", []string{"didn't match node selector", "node(s) didn't match node affinity"}},
+		{"pod_affinity", []string{"didn't match pod affinity", "didn't match pod anti-affinity", "didn't satisfy existing pods anti-affinity"}},
+		{"taints", []string{"had taints that the pod didn't tolerate"}},
+		{"node_unschedulable", []string{"node(s) were unschedulable", "were not ready", "were out of disk space", "had memory pressure", "had disk pressure"}},
+		{"host_ports", []string{"didn't have free ports"}},
+		{"volumes", []string{"volume node affinity conflict", "no available volume zone", "unbound immediate PersistentVolumeClaims", "had no available disk", "exceed max volume count", "didn't find available persistent volumes"}},
+	}
+
+	// podMirrorAnnotation is set on the mirror pods the kubelet creates
+	// through the API server for static pods.
+	podMirrorAnnotation = "kubernetes.io/config.mirror"
+	// podConfigSourceAnnotation holds the source the kubelet got a static
+	// pod from, it is copied to its mirror pod.
+	podConfigSourceAnnotation = "kubernetes.io/config.source"
+	// podSpecOSSourceNodeSelector is the source of kube_pod_spec_os for pods
+	// whose node selector requires an operating system.
+	podSpecOSSourceNodeSelector = "node_selector"
+
+	// podDisruptionTarget is set on pods which are about to be deleted due
+	// to a disruption, it is not part of the vendored API yet.
+	podDisruptionTarget v1.PodConditionType = "DisruptionTarget"
+
 	descPodInfo = prometheus.NewDesc(
 		"kube_pod_info",
 		"Information about pod.",
 		append(descPodLabelsDefaultLabels, "host_ip", "pod_ip", "uid", "node", "created_by_kind", "created_by_name"),
 		nil,
 	)
+	descPodSpecOS = prometheus.NewDesc(
+		"kube_pod_spec_os",
+		"The operating system the pod is scheduled to, as required by the OS label of its node selector.",
+		append(descPodLabelsDefaultLabels, "os", "source"),
+		nil,
+	)
+	descPodIsMirror = prometheus.NewDesc(
+		"kube_pod_is_mirror",
+		"Whether the pod is the mirror of a static pod managed by a kubelet.",
+		descPodLabelsDefaultLabels,
+		nil,
+	)
+	descPodConfigSource = prometheus.NewDesc(
+		"kube_pod_config_source",
+		"The source the kubelet got a static pod from, file or http. Only exposed for mirror pods.",
+		append(descPodLabelsDefaultLabels, "source"),
+		nil,
+	)
 	descPodStartTime = prometheus.NewDesc(
 		"kube_pod_start_time",
 		"Start time in unix timestamp for a pod.",
@@ -63,6 +120,12 @@ var (
 		append(descPodLabelsDefaultLabels, "owner_kind", "owner_name", "owner_is_controller"),
 		nil,
 	)
+	descPodOrphan = prometheus.NewDesc(
+		"kube_pod_orphan",
+		"Whether the pod has no controller owner. Mirror pods are never orphaned.",
+		descPodLabelsDefaultLabels,
+		nil,
+	)
 	descPodLabels = prometheus.NewDesc(
 		descPodLabelsName,
 		descPodLabelsHelp,
@@ -99,10 +162,16 @@ var (
 		append(descPodLabelsDefaultLabels, "condition"),
 		nil,
 	)
+	descPodUnschedulableReason = prometheus.NewDesc(
+		"kube_pod_unschedulable_reason",
+		"Describes why the scheduler could not find a node for an unschedulable pod, derived from the message of its PodScheduled condition.",
+		append(descPodLabelsDefaultLabels, "reason"),
+		nil,
+	)
 	descPodContainerInfo = prometheus.NewDesc(
 		"kube_pod_container_info",
 		"Information about a container in a pod.",
-		append(descPodLabelsDefaultLabels, "container", "image", "image_id", "container_id"),
+		append(descPodLabelsDefaultLabels, "container", "image", "image_id", "container_id", "container_runtime"),
 		nil,
 	)
 	descPodContainerStatusWaiting = prometheus.NewDesc(
@@ -202,8 +271,60 @@ var (
 		append(descPodLabelsDefaultLabels, "volume", "persistentvolumeclaim"),
 		nil,
 	)
+
+	descPodSummarizedObjects = newSummarizedObjectsDesc("pod")
+
+	descPendingPodsAge = prometheus.NewDesc(
+		"kube_pending_pods_age_seconds",
+		"The distribution of the time pending pods have existed for, per namespace.",
+		[]string{"namespace"},
+		nil,
+	)
+	// pendingPodsAgeBuckets range from pods waiting for an image pull to
+	// pods stuck for a day.
+	pendingPodsAgeBuckets = []float64{10, 30, 60, 300, 900, 1800, 3600, 10800, 21600, 86400}
+
+	descPodHostPortConflict = prometheus.NewDesc(
+		"kube_pod_host_port_conflict",
+		"Whether a host port of the pod conflicts with the one of another pod on the same node.",
+		[]string{"namespace", "pod", "node", "host_ip", "host_port", "protocol"},
+		nil,
+	)
 )
 
+func newPodDisruptionCounter() *prometheus.CounterVec {
+	return prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "kube_pod_disruption_total",
+			Help: "The number of pods deleted after being targeted by a disruption.",
+		},
+		[]string{"namespace", "reason"},
+	)
+}
+
+// podDisruptionHandler counts deleted pods that carry a DisruptionTarget
+// condition, distinguishing evictions, preemptions and taint based deletions
+// by the condition reason.
+func podDisruptionHandler(disruptions *prometheus.CounterVec) cache.ResourceEventHandler {
+	return cache.ResourceEventHandlerFuncs{
+		DeleteFunc: func(obj interface{}) {
+			if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+				obj = tombstone.Obj
+			}
+			p, ok := obj.(*v1.Pod)
+			if !ok {
+				return
+			}
+			for _, c := range p.Status.Conditions {
+				if c.Type == podDisruptionTarget && c.Status == v1.ConditionTrue {
+					disruptions.WithLabelValues(p.Namespace, c.Reason).Inc()
+					return
+				}
+			}
+		},
+	}
+}
+
 type PodLister func() ([]v1.Pod, error)
 
 func (l PodLister) List() ([]v1.Pod, error) {
@@ -214,7 +335,7 @@ func RegisterPodCollector(registry prometheus.Registerer, informerFactories []in
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().Pods().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().Pods().Informer(), opts))
 	}
 
 	podLister := PodLister(func() (pods []v1.Pod, err error) {
@@ -226,7 +347,28 @@ func RegisterPodCollector(registry prometheus.Registerer, informerFactories []in
 		return pods, nil
 	})
 
-	registry.MustRegister(&podCollector{store: podLister, opts: opts})
+	// Host port conflicts are between pods of any shard.
+	allPodLister := PodLister(func() (pods []v1.Pod, err error) {
+		for _, pinf := range infs {
+			for _, m := range unshardedInformer(pinf).GetStore().List() {
+				pods = append(pods, *m.(*v1.Pod))
+			}
+		}
+		return pods, nil
+	})
+
+	disruptions := newPodDisruptionCounter()
+	for _, pinf := range infs {
+		pinf.AddEventHandler(podDisruptionHandler(disruptions))
+	}
+
+	pc := &podCollector{store: podLister, allPods: allPodLister, opts: opts, now: time.Now}
+	pc.metrics = newObjectMetricsCache(registry, infs, opts, func(ch chan<- prometheus.Metric, obj interface{}) {
+		pc.collectPod(ch, *obj.(*v1.Pod))
+	})
+	registry.MustRegister(pc, disruptions)
+	registerDeletedObjects(registry, infs, "kube_pod_deleted", descPodLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("pod", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -237,7 +379,11 @@ type podStore interface {
 // podCollector collects metrics about all pods in the cluster.
 type podCollector struct {
 	store podStore
-	opts  *options.Options
+	// allPods lists the pods of all shards. It defaults to store.
+	allPods podStore
+	opts    *options.Options
+	now     func() time.Time
+	metrics *objectMetricsCache
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -246,12 +392,17 @@ func (pc *podCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descPodStartTime
 	ch <- descPodCompletionTime
 	ch <- descPodOwner
+	ch <- descPodOrphan
+	ch <- descPodSpecOS
+	ch <- descPodIsMirror
+	ch <- descPodConfigSource
 	ch <- descPodLabels
 	ch <- descPodCreated
 	ch <- descPodStatusScheduledTime
 	ch <- descPodStatusPhase
 	ch <- descPodStatusReady
 	ch <- descPodStatusScheduled
+	ch <- descPodUnschedulableReason
 	ch <- descPodContainerInfo
 	ch <- descPodContainerStatusWaiting
 	ch <- descPodContainerStatusWaitingReason
@@ -272,26 +423,193 @@ func (pc *podCollector) Describe(ch chan<- *prometheus.Desc) {
 		ch <- descPodContainerResourceLimitsCPUCores
 		ch <- descPodContainerResourceLimitsMemoryBytes
 	}
+	ch <- descPodSummarizedObjects
+	ch <- descPendingPodsAge
+	ch <- descPodHostPortConflict
 }
 
 // Collect implements the prometheus.Collector interface.
 func (pc *podCollector) Collect(ch chan<- prometheus.Metric) {
 	pods, err := pc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "pod"}).Inc()
+		addScrapeError(ch, "pod", err)
 		glog.Errorf("listing pods failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "pod"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "pod"}).Observe(float64(len(pods)))
+	summarized := map[string]int{}
 	for _, p := range pods {
-		pc.collectPod(ch, p)
+		if !detailed(pc.opts, &p.ObjectMeta) {
+			summarized[p.Namespace]++
+			continue
+		}
+		if pc.metrics == nil {
+			pc.collectPod(ch, p)
+		}
 	}
+	addSummarizedObjects(ch, descPodSummarizedObjects, summarized)
+	addPendingPodsAge(ch, pods, pc.now())
+
+	allPods := pods
+	if pc.allPods != nil {
+		if allPods, err = pc.allPods.List(); err != nil {
+			glog.Errorf("listing pods of all shards failed: %s", err)
+			allPods = pods
+		}
+	}
+	addHostPortConflicts(ch, allPods, func(p *v1.Pod) bool {
+		return ownedByShard(pc.opts, p.UID) && detailed(pc.opts, &p.ObjectMeta)
+	})
 
 	glog.V(4).Infof("collected %d pods", len(pods))
 }
 
+// addPendingPodsAge generates a histogram per namespace of the age of all its
+// pending pods, including the ones without per-object metrics.
+func addPendingPodsAge(ch chan<- prometheus.Metric, pods []v1.Pod, now time.Time) {
+	type histogram struct {
+		count   uint64
+		sum     float64
+		buckets map[float64]uint64
+	}
+	histograms := map[string]*histogram{}
+	for _, p := range pods {
+		if p.Status.Phase != v1.PodPending || p.CreationTimestamp.IsZero() {
+			continue
+		}
+		h, ok := histograms[p.Namespace]
+		if !ok {
+			h = &histogram{buckets: make(map[float64]uint64, len(pendingPodsAgeBuckets))}
+			for _, b := range pendingPodsAgeBuckets {
+				h.buckets[b] = 0
+			}
+			histograms[p.Namespace] = h
+		}
+		age := now.Sub(p.CreationTimestamp.Time).Seconds()
+		if age < 0 {
+			age = 0
+		}
+		h.count++
+		h.sum += age
+		for _, b := range pendingPodsAgeBuckets {
+			if age <= b {
+				h.buckets[b]++
+			}
+		}
+	}
+	for namespace, h := range histograms {
+		ch <- prometheus.MustNewConstHistogram(descPendingPodsAge, h.count, h.sum, h.buckets, namespace)
+	}
+}
+
+// addHostPortConflicts flags the host ports of all pods that conflict with a
+// host port of another pod bound to the same node, i.e. that use the same
+// port and protocol on the same or a wildcard host IP. Terminated pods don't
+// hold their host ports anymore and are skipped. Conflicts are found among all
+// given pods, but only flagged for the pods exposed is true for.
+func addHostPortConflicts(ch chan<- prometheus.Metric, pods []v1.Pod, exposed func(*v1.Pod) bool) {
+	type hostPort struct {
+		node     string
+		port     int32
+		protocol v1.Protocol
+	}
+	type hostPortUse struct {
+		pod    *v1.Pod
+		hostIP string
+	}
+	uses := map[hostPort][]hostPortUse{}
+	for i := range pods {
+		p := &pods[i]
+		if p.Spec.NodeName == "" || p.Status.Phase == v1.PodSucceeded || p.Status.Phase == v1.PodFailed {
+			continue
+		}
+		for _, c := range p.Spec.Containers {
+			for _, cp := range c.Ports {
+				if cp.HostPort == 0 {
+					continue
+				}
+				protocol := cp.Protocol
+				if protocol == "" {
+					protocol = v1.ProtocolTCP
+				}
+				hostIP := cp.HostIP
+				if hostIP == "" {
+					hostIP = "0.0.0.0"
+				}
+				key := hostPort{node: p.Spec.NodeName, port: cp.HostPort, protocol: protocol}
+				uses[key] = append(uses[key], hostPortUse{pod: p, hostIP: hostIP})
+			}
+		}
+	}
+
+	for key, us := range uses {
+		conflicting := make([]bool, len(us))
+		for i := range us {
+			for j := i + 1; j < len(us); j++ {
+				if us[i].pod == us[j].pod {
+					continue
+				}
+				if us[i].hostIP == us[j].hostIP || us[i].hostIP == "0.0.0.0" || us[j].hostIP == "0.0.0.0" {
+					conflicting[i], conflicting[j] = true, true
+				}
+			}
+		}
+		for i, u := range us {
+			if conflicting[i] && exposed(u.pod) {
+				ch <- mustNewConstMetric(descPodHostPortConflict, prometheus.GaugeValue, 1,
+					u.pod.Namespace, u.pod.Name, key.node, u.hostIP, strconv.Itoa(int(key.port)), string(key.protocol))
+			}
+		}
+	}
+}
+
+// classifyUnschedulable returns the unschedulableReasons found in the message
+// of the PodScheduled condition of an unschedulable pod. Each predicate
+// failure of the message is classified by the first reason matching it, those
+// matching none are classified as other.
+func classifyUnschedulable(message string) map[string]bool {
+	reasons := map[string]bool{}
+	// The failures are listed after the colon, separated by commas.
+	if i := strings.Index(message, ":"); i >= 0 {
+		message = message[i+1:]
+	}
+	for _, failure := range strings.Split(message, ",") {
+		failure = strings.TrimSpace(failure)
+		if failure == "" {
+			continue
+		}
+		classified := false
+		for _, r := range unschedulableReasons {
+			for _, phrase := range r.phrases {
+				if strings.Contains(failure, phrase) {
+					reasons[r.reason] = true
+					classified = true
+					break
+				}
+			}
+			if classified {
+				break
+			}
+		}
+		if !classified {
+			reasons["other"] = true
+		}
+	}
+	return reasons
+}
+
+// containerRuntime returns the runtime prefix of a container ID of the form
+// <runtime>://<id>, e.g. docker, containerd or cri-o. It is empty for
+// containers which haven't been created yet.
+func containerRuntime(containerID string) string {
+	if i := strings.Index(containerID, "://"); i >= 0 {
+		return containerID[:i]
+	}
+	return ""
+}
+
 func podLabelsDesc(labelKeys []string) *prometheus.Desc {
 	return prometheus.NewDesc(
 		descPodLabelsName,
@@ -302,10 +620,12 @@ func podLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (pc *podCollector) collectPod(ch chan<- prometheus.Metric, p v1.Pod) {
+	defer recoverObjectError("pod", &p.ObjectMeta)
+
 	nodeName := p.Spec.NodeName
 	addConstMetric := func(desc *prometheus.Desc, t prometheus.ValueType, v float64, lv ...string) {
 		lv = append([]string{p.Namespace, p.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, t, v, lv...)
+		ch <- mustNewConstMetric(desc, t, v, lv...)
 	}
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		addConstMetric(desc, prometheus.GaugeValue, v, lv...)
@@ -332,6 +652,18 @@ func (pc *podCollector) collectPod(ch chan<- prometheus.Metric, p v1.Pod) {
 
 	addGauge(descPodInfo, 1, p.Status.HostIP, p.Status.PodIP, string(p.UID), nodeName, createdByKind, createdByName)
 
+	// The pod spec of this API version has no OS of its own, so the OS is
+	// derived from the node selector, which the source label tells consumers.
+	if os := firstLabelValue(p.Spec.NodeSelector, osLabels); os != "" {
+		addGauge(descPodSpecOS, 1, os, podSpecOSSourceNodeSelector)
+	}
+
+	_, isMirror := p.Annotations[podMirrorAnnotation]
+	addGauge(descPodIsMirror, boolFloat64(isMirror))
+	if source, ok := p.Annotations[podConfigSourceAnnotation]; ok {
+		addGauge(descPodConfigSource, 1, source)
+	}
+
 	owners := p.GetOwnerReferences()
 	if len(owners) == 0 {
 		addGauge(descPodOwner, 1, "<none>", "<none>", "<none>")
@@ -344,8 +676,9 @@ func (pc *podCollector) collectPod(ch chan<- prometheus.Metric, p v1.Pod) {
 			}
 		}
 	}
+	addGauge(descPodOrphan, boolFloat64(!isMirror && createdBy == nil))
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(p.Labels, pc.opts.MaxLabelValueLength)
 	addGauge(podLabelsDesc(labelKeys), 1, labelValues...)
 
 	if phase := p.Status.Phase; phase != "" {
@@ -365,12 +698,19 @@ func (pc *podCollector) collectPod(ch chan<- prometheus.Metric, p v1.Pod) {
 	for _, c := range p.Status.Conditions {
 		switch c.Type {
 		case v1.PodReady:
-			addConditionMetrics(ch, descPodStatusReady, c.Status, p.Namespace, p.Name)
+			addConditionMetrics(ch, pc.opts.ConditionEncoding, descPodStatusReady, c.Status, p.Namespace, p.Name)
 		case v1.PodScheduled:
-			addConditionMetrics(ch, descPodStatusScheduled, c.Status, p.Namespace, p.Name)
+			addConditionMetrics(ch, pc.opts.ConditionEncoding, descPodStatusScheduled, c.Status, p.Namespace, p.Name)
 			if c.Status == v1.ConditionTrue {
 				addGauge(descPodStatusScheduledTime, float64(c.LastTransitionTime.Unix()))
 			}
+			if c.Status == v1.ConditionFalse && c.Reason == v1.PodReasonUnschedulable {
+				reasons := classifyUnschedulable(c.Message)
+				for _, r := range unschedulableReasons {
+					addGauge(descPodUnschedulableReason, boolFloat64(reasons[r.reason]), r.reason)
+				}
+				addGauge(descPodUnschedulableReason, boolFloat64(reasons["other"]), "other")
+			}
 		}
 	}
 
@@ -399,7 +739,7 @@ func (pc *podCollector) collectPod(ch chan<- prometheus.Metric, p v1.Pod) {
 
 	for _, cs := range p.Status.ContainerStatuses {
 		addGauge(descPodContainerInfo, 1,
-			cs.Name, cs.Image, cs.ImageID, cs.ContainerID,
+			cs.Name, cs.Image, cs.ImageID, cs.ContainerID, containerRuntime(cs.ContainerID),
 		)
 		addGauge(descPodContainerStatusWaiting, boolFloat64(cs.State.Waiting != nil), cs.Name)
 		for _, reason := range containerWaitingReasons {
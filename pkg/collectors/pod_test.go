@@ -17,14 +17,20 @@ limitations under the License.
 package collectors
 
 import (
+	"fmt"
+	"reflect"
 	"testing"
 	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 	"k8s.io/kubernetes/pkg/util/node"
 )
 
@@ -77,6 +83,16 @@ func TestPodCollector(t *testing.T) {
 		# TYPE kube_pod_completion_time gauge
 		# HELP kube_pod_owner Information about the Pod's owner.
 		# TYPE kube_pod_owner gauge
+		# HELP kube_pod_orphan Whether the pod has no controller owner. Mirror pods are never orphaned.
+		# TYPE kube_pod_orphan gauge
+		# HELP kube_pod_unschedulable_reason Describes why the scheduler could not find a node for an unschedulable pod, derived from the message of its PodScheduled condition.
+		# TYPE kube_pod_unschedulable_reason gauge
+		# HELP kube_pod_spec_os The operating system the pod is scheduled to, as required by the OS label of its node selector.
+		# TYPE kube_pod_spec_os gauge
+		# HELP kube_pod_is_mirror Whether the pod is the mirror of a static pod managed by a kubelet.
+		# TYPE kube_pod_is_mirror gauge
+		# HELP kube_pod_config_source The source the kubelet got a static pod from, file or http. Only exposed for mirror pods.
+		# TYPE kube_pod_config_source gauge
 		# HELP kube_pod_status_phase The pods current phase.
 		# TYPE kube_pod_status_phase gauge
 		# HELP kube_pod_status_ready Describes whether the pod is ready to serve requests.
@@ -146,9 +162,44 @@ func TestPodCollector(t *testing.T) {
 				},
 			},
 			want: metadata + `
-				kube_pod_container_info{container="container1",container_id="docker://ab123",image="k8s.gcr.io/hyperkube1",image_id="docker://sha256:aaa",namespace="ns1",pod="pod1"} 1
-				kube_pod_container_info{container="container2",container_id="docker://cd456",image="k8s.gcr.io/hyperkube2",image_id="docker://sha256:bbb",namespace="ns2",pod="pod2"} 1
-				kube_pod_container_info{container="container3",container_id="docker://ef789",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",namespace="ns2",pod="pod2"} 1
+				kube_pod_container_info{container="container1",container_id="docker://ab123",container_runtime="docker",image="k8s.gcr.io/hyperkube1",image_id="docker://sha256:aaa",namespace="ns1",pod="pod1"} 1
+				kube_pod_container_info{container="container2",container_id="docker://cd456",container_runtime="docker",image="k8s.gcr.io/hyperkube2",image_id="docker://sha256:bbb",namespace="ns2",pod="pod2"} 1
+				kube_pod_container_info{container="container3",container_id="docker://ef789",container_runtime="docker",image="k8s.gcr.io/hyperkube3",image_id="docker://sha256:ccc",namespace="ns2",pod="pod2"} 1
+				`,
+			metrics: []string{"kube_pod_container_info"},
+		}, {
+			pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod1",
+						Namespace: "ns1",
+					},
+					Status: v1.PodStatus{
+						ContainerStatuses: []v1.ContainerStatus{
+							v1.ContainerStatus{
+								Name:        "container1",
+								Image:       "k8s.gcr.io/hyperkube1",
+								ImageID:     "sha256:aaa",
+								ContainerID: "containerd://ab123",
+							},
+							v1.ContainerStatus{
+								Name:        "container2",
+								Image:       "k8s.gcr.io/hyperkube2",
+								ImageID:     "sha256:bbb",
+								ContainerID: "cri-o://cd456",
+							},
+							v1.ContainerStatus{
+								Name:  "container3",
+								Image: "k8s.gcr.io/hyperkube3",
+							},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_pod_container_info{container="container1",container_id="containerd://ab123",container_runtime="containerd",image="k8s.gcr.io/hyperkube1",image_id="sha256:aaa",namespace="ns1",pod="pod1"} 1
+				kube_pod_container_info{container="container2",container_id="cri-o://cd456",container_runtime="cri-o",image="k8s.gcr.io/hyperkube2",image_id="sha256:bbb",namespace="ns1",pod="pod1"} 1
+				kube_pod_container_info{container="container3",container_id="",container_runtime="",image="k8s.gcr.io/hyperkube3",image_id="",namespace="ns1",pod="pod1"} 1
 				`,
 			metrics: []string{"kube_pod_container_info"},
 		}, {
@@ -553,8 +604,123 @@ func TestPodCollector(t *testing.T) {
 				kube_pod_completion_time{namespace="ns2",pod="pod2"} 1501888018
 				kube_pod_owner{namespace="ns1",pod="pod1",owner_kind="<none>",owner_name="<none>",owner_is_controller="<none>"} 1
 				kube_pod_owner{namespace="ns2",pod="pod2",owner_kind="ReplicaSet",owner_name="rs-name",owner_is_controller="true"} 1
+				kube_pod_orphan{namespace="ns1",pod="pod1"} 1
+				kube_pod_orphan{namespace="ns2",pod="pod2"} 0
+				`,
+			metrics: []string{"kube_pod_created", "kube_pod_info", "kube_pod_start_time", "kube_pod_completion_time", "kube_pod_owner", "kube_pod_orphan"},
+		}, {
+			pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "kube-apiserver-node1",
+						Namespace: "kube-system",
+						Annotations: map[string]string{
+							"kubernetes.io/config.hash":   "3a7e3d8b0e4e5b1c",
+							"kubernetes.io/config.mirror": "3a7e3d8b0e4e5b1c",
+							"kubernetes.io/config.seen":   "2018-06-01T10:00:00.000000000Z",
+							"kubernetes.io/config.source": "file",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod1",
+						Namespace: "ns1",
+					},
+				},
+			},
+			want: metadata + `
+				kube_pod_is_mirror{namespace="kube-system",pod="kube-apiserver-node1"} 1
+				kube_pod_is_mirror{namespace="ns1",pod="pod1"} 0
+				kube_pod_config_source{namespace="kube-system",pod="kube-apiserver-node1",source="file"} 1
+				kube_pod_orphan{namespace="kube-system",pod="kube-apiserver-node1"} 0
+				kube_pod_orphan{namespace="ns1",pod="pod1"} 1
+				`,
+			metrics: []string{"kube_pod_is_mirror", "kube_pod_config_source", "kube_pod_orphan"},
+		}, {
+			pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod1",
+						Namespace: "ns1",
+					},
+					Spec: v1.PodSpec{
+						NodeSelector: map[string]string{
+							"kubernetes.io/os": "windows",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod2",
+						Namespace: "ns1",
+					},
+					Spec: v1.PodSpec{
+						NodeSelector: map[string]string{
+							"beta.kubernetes.io/os": "linux",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod3",
+						Namespace: "ns1",
+					},
+				},
+			},
+			want: metadata + `
+				kube_pod_spec_os{namespace="ns1",os="windows",pod="pod1",source="node_selector"} 1
+				kube_pod_spec_os{namespace="ns1",os="linux",pod="pod2",source="node_selector"} 1
+				`,
+			metrics: []string{"kube_pod_spec_os"},
+		}, {
+			pods: []v1.Pod{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod1",
+						Namespace: "ns1",
+					},
+					Status: v1.PodStatus{
+						Conditions: []v1.PodCondition{
+							{
+								Type:    v1.PodScheduled,
+								Status:  v1.ConditionFalse,
+								Reason:  v1.PodReasonUnschedulable,
+								Message: "0/4 nodes are available: 1 Insufficient cpu, 2 node(s) had taints that the pod didn't tolerate, 1 node(s) had a mysterious problem.",
+							},
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "pod2",
+						Namespace: "ns1",
+					},
+					Status: v1.PodStatus{
+						Conditions: []v1.PodCondition{
+							{
+								Type:   v1.PodScheduled,
+								Status: v1.ConditionTrue,
+							},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="insufficient_cpu"} 1
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="insufficient_memory"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="insufficient_pods"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="insufficient_ephemeral_storage"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="insufficient_extended_resource"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="node_affinity"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="pod_affinity"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="taints"} 1
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="node_unschedulable"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="host_ports"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="volumes"} 0
+				kube_pod_unschedulable_reason{namespace="ns1",pod="pod1",reason="other"} 1
 				`,
-			metrics: []string{"kube_pod_created", "kube_pod_info", "kube_pod_start_time", "kube_pod_completion_time", "kube_pod_owner"},
+			metrics: []string{"kube_pod_unschedulable_reason"},
 		}, {
 			pods: []v1.Pod{
 				{
@@ -913,9 +1079,331 @@ func TestPodCollector(t *testing.T) {
 				f: func() ([]v1.Pod, error) { return c.pods, nil },
 			},
 			opts: &options.Options{},
+			now:  time.Now,
 		}
 		if err := testutils.GatherAndCompare(pc, c.want, c.metrics); err != nil {
 			t.Errorf("unexpected collecting result:\n%s", err)
 		}
 	}
 }
+
+func TestPodDisruptionHandler(t *testing.T) {
+	const metadata = `
+		# HELP kube_pod_disruption_total The number of pods deleted after being targeted by a disruption.
+		# TYPE kube_pod_disruption_total counter
+	`
+
+	disruptedPod := func(namespace, name, reason string) *v1.Pod {
+		return &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Status: v1.PodStatus{
+				Conditions: []v1.PodCondition{
+					{
+						Type:   podDisruptionTarget,
+						Status: v1.ConditionTrue,
+						Reason: reason,
+					},
+				},
+			},
+		}
+	}
+
+	disruptions := newPodDisruptionCounter()
+	h := podDisruptionHandler(disruptions)
+	h.OnDelete(disruptedPod("ns1", "pod1", "EvictionByEvictionAPI"))
+	h.OnDelete(disruptedPod("ns1", "pod2", "EvictionByEvictionAPI"))
+	h.OnDelete(cache.DeletedFinalStateUnknown{Key: "ns2/pod3", Obj: disruptedPod("ns2", "pod3", "PreemptionByKubeScheduler")})
+	h.OnDelete(&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod4", Namespace: "ns1"}})
+
+	want := metadata + `
+		kube_pod_disruption_total{namespace="ns1",reason="EvictionByEvictionAPI"} 2
+		kube_pod_disruption_total{namespace="ns2",reason="PreemptionByKubeScheduler"} 1
+	`
+	if err := testutils.GatherAndCompare(disruptions, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestPodCollectorSummarized(t *testing.T) {
+	const metadata = `
+		# HELP kube_pod_created Unix creation timestamp
+		# TYPE kube_pod_created gauge
+		# HELP kube_summarized_objects Number of objects per namespace for which no per-object metrics are exposed.
+		# TYPE kube_summarized_objects gauge
+	`
+
+	newPod := func(namespace, name string, lbls map[string]string) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:              name,
+				Namespace:         namespace,
+				CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
+				Labels:            lbls,
+			},
+		}
+	}
+	pods := []v1.Pod{
+		newPod("team-a", "pod1", nil),
+		newPod("team-b", "pod2", nil),
+		newPod("team-b", "pod3", map[string]string{"tier": "critical"}),
+		newPod("team-c", "pod4", nil),
+		newPod("team-c", "pod5", nil),
+	}
+
+	selector, err := labels.Parse("tier=critical")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		opts *options.Options
+		want string
+	}{
+		{
+			opts: &options.Options{},
+			want: metadata + `
+				kube_pod_created{namespace="team-a",pod="pod1"} 1.5e+09
+				kube_pod_created{namespace="team-b",pod="pod2"} 1.5e+09
+				kube_pod_created{namespace="team-b",pod="pod3"} 1.5e+09
+				kube_pod_created{namespace="team-c",pod="pod4"} 1.5e+09
+				kube_pod_created{namespace="team-c",pod="pod5"} 1.5e+09
+			`,
+		},
+		{
+			opts: &options.Options{
+				DetailedNamespaces:    options.NamespaceList{"team-a"},
+				DetailedLabelSelector: options.LabelSelector{Selector: selector},
+			},
+			want: metadata + `
+				kube_pod_created{namespace="team-a",pod="pod1"} 1.5e+09
+				kube_pod_created{namespace="team-b",pod="pod3"} 1.5e+09
+				kube_summarized_objects{namespace="team-b",resource="pod"} 1
+				kube_summarized_objects{namespace="team-c",resource="pod"} 2
+			`,
+		},
+	}
+	for _, c := range cases {
+		pc := &podCollector{
+			store: mockPodStore{
+				f: func() ([]v1.Pod, error) { return pods, nil },
+			},
+			opts: c.opts,
+			now:  time.Now,
+		}
+		if err := testutils.GatherAndCompare(pc, c.want, []string{"kube_pod_created", "kube_summarized_objects"}); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
+
+func TestPendingPodsAge(t *testing.T) {
+	const metadata = `
+		# HELP kube_pending_pods_age_seconds The distribution of the time pending pods have existed for, per namespace.
+		# TYPE kube_pending_pods_age_seconds histogram
+	`
+	now := time.Unix(1500000000, 0)
+	pod := func(namespace, name string, phase v1.PodPhase, age time.Duration) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Namespace:         namespace,
+				Name:              name,
+				CreationTimestamp: metav1.NewTime(now.Add(-age)),
+			},
+			Status: v1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []v1.Pod{
+		pod("ns1", "pod1", v1.PodPending, 20*time.Second),
+		pod("ns1", "pod2", v1.PodPending, 2*time.Hour),
+		pod("ns1", "pod3", v1.PodRunning, time.Hour),
+		pod("ns2", "pod4", v1.PodPending, 5*time.Second),
+	}
+
+	want := metadata + `
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="10"} 0
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="30"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="60"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="300"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="900"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="1800"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="3600"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="10800"} 2
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="21600"} 2
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="86400"} 2
+		kube_pending_pods_age_seconds_bucket{namespace="ns1",le="+Inf"} 2
+		kube_pending_pods_age_seconds_sum{namespace="ns1"} 7220
+		kube_pending_pods_age_seconds_count{namespace="ns1"} 2
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="10"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="30"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="60"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="300"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="900"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="1800"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="3600"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="10800"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="21600"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="86400"} 1
+		kube_pending_pods_age_seconds_bucket{namespace="ns2",le="+Inf"} 1
+		kube_pending_pods_age_seconds_sum{namespace="ns2"} 5
+		kube_pending_pods_age_seconds_count{namespace="ns2"} 1
+	`
+
+	pc := &podCollector{
+		store: mockPodStore{
+			f: func() ([]v1.Pod, error) { return pods, nil },
+		},
+		// Pending pods are counted whether or not they get per-object metrics.
+		opts: &options.Options{DetailedNamespaces: options.NamespaceList{"ns2"}},
+		now:  func() time.Time { return now },
+	}
+	if err := testutils.GatherAndCompare(pc, want, []string{"kube_pending_pods_age_seconds"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestClassifyUnschedulable(t *testing.T) {
+	tests := []struct {
+		message string
+		want    map[string]bool
+	}{
+		{
+			message: "0/3 nodes are available: 3 Insufficient memory.",
+			want:    map[string]bool{"insufficient_memory": true},
+		},
+		{
+			message: "0/5 nodes are available: 2 Insufficient nvidia.com/gpu, 3 node(s) didn't match node selector.",
+			want:    map[string]bool{"insufficient_extended_resource": true, "node_affinity": true},
+		},
+		{
+			message: "0/2 nodes are available: 1 node(s) had volume node affinity conflict, 1 node(s) were unschedulable.",
+			want:    map[string]bool{"volumes": true, "node_unschedulable": true},
+		},
+		{
+			message: "0/2 nodes are available: 2 node(s) didn't match pod anti-affinity rules.",
+			want:    map[string]bool{"pod_affinity": true},
+		},
+		{
+			message: "no nodes available to schedule pods",
+			want:    map[string]bool{"other": true},
+		},
+		{
+			message: "",
+			want:    map[string]bool{},
+		},
+	}
+
+	for _, test := range tests {
+		if got := classifyUnschedulable(test.message); !reflect.DeepEqual(got, test.want) {
+			t.Errorf("classifying %q: want %v, got %v", test.message, test.want, got)
+		}
+	}
+}
+
+func TestHostPortConflicts(t *testing.T) {
+	const metadata = `
+		# HELP kube_pod_host_port_conflict Whether a host port of the pod conflicts with the one of another pod on the same node.
+		# TYPE kube_pod_host_port_conflict gauge
+	`
+	pod := func(name, node string, phase v1.PodPhase, ports ...v1.ContainerPort) v1.Pod {
+		return v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: name},
+			Spec: v1.PodSpec{
+				NodeName:   node,
+				Containers: []v1.Container{{Name: "c", Ports: ports}},
+			},
+			Status: v1.PodStatus{Phase: phase},
+		}
+	}
+	pods := []v1.Pod{
+		pod("web1", "node1", v1.PodRunning, v1.ContainerPort{HostPort: 80, ContainerPort: 8080}),
+		pod("web2", "node1", v1.PodPending, v1.ContainerPort{HostPort: 80, ContainerPort: 8080, HostIP: "10.0.0.1"}),
+		pod("web3", "node2", v1.PodRunning, v1.ContainerPort{HostPort: 80, ContainerPort: 8080}),
+		pod("dns1", "node1", v1.PodRunning, v1.ContainerPort{HostPort: 53, ContainerPort: 53, Protocol: v1.ProtocolUDP, HostIP: "10.0.0.1"}),
+		pod("dns2", "node1", v1.PodRunning, v1.ContainerPort{HostPort: 53, ContainerPort: 53, Protocol: v1.ProtocolTCP, HostIP: "10.0.0.1"}),
+		pod("dns3", "node1", v1.PodRunning, v1.ContainerPort{HostPort: 53, ContainerPort: 53, Protocol: v1.ProtocolUDP, HostIP: "10.0.0.2"}),
+		pod("old", "node2", v1.PodSucceeded, v1.ContainerPort{HostPort: 80, ContainerPort: 8080}),
+		pod("unscheduled", "", v1.PodPending, v1.ContainerPort{HostPort: 80, ContainerPort: 8080}),
+	}
+
+	want := metadata + `
+		kube_pod_host_port_conflict{host_ip="0.0.0.0",host_port="80",namespace="ns1",node="node1",pod="web1",protocol="TCP"} 1
+		kube_pod_host_port_conflict{host_ip="10.0.0.1",host_port="80",namespace="ns1",node="node1",pod="web2",protocol="TCP"} 1
+	`
+
+	pc := &podCollector{
+		store: mockPodStore{
+			f: func() ([]v1.Pod, error) { return pods, nil },
+		},
+		opts: &options.Options{},
+		now:  time.Now,
+	}
+	if err := testutils.GatherAndCompare(pc, want, []string{"kube_pod_host_port_conflict"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+
+	// Pods without per-object metrics aren't flagged.
+	pc.opts = &options.Options{DetailedNamespaces: options.NamespaceList{"ns2"}}
+	if err := testutils.GatherAndCompare(pc, metadata, []string{"kube_pod_host_port_conflict"}); err != nil {
+		t.Errorf("unexpected collecting result in summarized mode:\n%s", err)
+	}
+}
+
+func TestHostPortConflictsAcrossShards(t *testing.T) {
+	const totalShards = 2
+	// Pairs of pods on the same node conflict, whether they belong to the
+	// same shard or not.
+	var pods []v1.Pod
+	for i := 0; i < 10; i++ {
+		pods = append(pods, v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "ns1", Name: fmt.Sprintf("web%d", i), UID: types.UID(fmt.Sprintf("uid%d", i))},
+			Spec: v1.PodSpec{
+				NodeName:   fmt.Sprintf("node%d", i/2),
+				Containers: []v1.Container{{Name: "c", Ports: []v1.ContainerPort{{HostPort: 80, ContainerPort: 8080}}}},
+			},
+			Status: v1.PodStatus{Phase: v1.PodRunning},
+		})
+	}
+
+	flagged := map[string]int{}
+	for shard := 0; shard < totalShards; shard++ {
+		opts := &options.Options{Shard: shard, TotalShards: totalShards}
+		var owned []v1.Pod
+		for _, p := range pods {
+			if ownedByShard(opts, p.UID) {
+				owned = append(owned, p)
+			}
+		}
+		pc := &podCollector{
+			store:   mockPodStore{f: func() ([]v1.Pod, error) { return owned, nil }},
+			allPods: mockPodStore{f: func() ([]v1.Pod, error) { return pods, nil }},
+			opts:    opts,
+			now:     time.Now,
+		}
+		registry := prometheus.NewRegistry()
+		registry.MustRegister(pc)
+		mfs, err := registry.Gather()
+		if err != nil {
+			t.Fatalf("unexpected error gathering metrics: %v", err)
+		}
+		for _, mf := range mfs {
+			if mf.GetName() != "kube_pod_host_port_conflict" {
+				continue
+			}
+			for _, m := range mf.GetMetric() {
+				for _, l := range m.GetLabel() {
+					if l.GetName() == "pod" {
+						flagged[l.GetValue()]++
+					}
+				}
+			}
+		}
+	}
+	for _, p := range pods {
+		if flagged[p.Name] != 1 {
+			t.Errorf("expected %s to be flagged by exactly one shard, got %d", p.Name, flagged[p.Name])
+		}
+	}
+}
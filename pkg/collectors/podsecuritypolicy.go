@@ -0,0 +1,203 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descPodSecurityPolicyLabelsName          = "kube_podsecuritypolicy_labels"
+	descPodSecurityPolicyLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descPodSecurityPolicyLabelsDefaultLabels = []string{"podsecuritypolicy"}
+
+	descPodSecurityPolicyInfo = prometheus.NewDesc(
+		"kube_podsecuritypolicy_info",
+		"Information about podsecuritypolicy.",
+		append(descPodSecurityPolicyLabelsDefaultLabels, "run_as_user_rule", "se_linux_rule", "supplemental_groups_rule", "fs_group_rule"),
+		nil,
+	)
+	descPodSecurityPolicyCreated = prometheus.NewDesc(
+		"kube_podsecuritypolicy_created",
+		"Unix creation timestamp",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicyLabels = prometheus.NewDesc(
+		descPodSecurityPolicyLabelsName,
+		descPodSecurityPolicyLabelsHelp,
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicySpecPrivileged = prometheus.NewDesc(
+		"kube_podsecuritypolicy_spec_privileged",
+		"Whether privileged pods are allowed.",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicySpecHostNetwork = prometheus.NewDesc(
+		"kube_podsecuritypolicy_spec_host_network",
+		"Whether pods may use the host network.",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicySpecHostPID = prometheus.NewDesc(
+		"kube_podsecuritypolicy_spec_host_pid",
+		"Whether pods may use the host PID namespace.",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicySpecHostIPC = prometheus.NewDesc(
+		"kube_podsecuritypolicy_spec_host_ipc",
+		"Whether pods may use the host IPC namespace.",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicySpecAllowPrivilegeEscalation = prometheus.NewDesc(
+		"kube_podsecuritypolicy_spec_allow_privilege_escalation",
+		"Whether containers may request privilege escalation.",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicySpecReadOnlyRootFilesystem = prometheus.NewDesc(
+		"kube_podsecuritypolicy_spec_read_only_root_filesystem",
+		"Whether containers have to run with a read-only root filesystem.",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+	descPodSecurityPolicySpecAllowedCapabilities = prometheus.NewDesc(
+		"kube_podsecuritypolicy_spec_allowed_capabilities",
+		"Number of capabilities containers may add.",
+		descPodSecurityPolicyLabelsDefaultLabels,
+		nil,
+	)
+)
+
+type PodSecurityPolicyLister func() ([]policyv1beta1.PodSecurityPolicy, error)
+
+func (l PodSecurityPolicyLister) List() ([]policyv1beta1.PodSecurityPolicy, error) {
+	return l()
+}
+
+func RegisterPodSecurityPolicyCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Policy().V1beta1().PodSecurityPolicies().Informer(), opts))
+	}
+
+	podSecurityPolicyLister := PodSecurityPolicyLister(func() (podSecurityPolicies []policyv1beta1.PodSecurityPolicy, err error) {
+		for _, pspinf := range infs {
+			for _, psp := range pspinf.GetStore().List() {
+				podSecurityPolicies = append(podSecurityPolicies, *(psp.(*policyv1beta1.PodSecurityPolicy)))
+			}
+		}
+		return podSecurityPolicies, nil
+	})
+
+	registry.MustRegister(&podSecurityPolicyCollector{store: podSecurityPolicyLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_podsecuritypolicy_deleted", descPodSecurityPolicyLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("podsecuritypolicy", infs)
+	infs.Run(context.Background().Done())
+}
+
+type podSecurityPolicyStore interface {
+	List() (podSecurityPolicies []policyv1beta1.PodSecurityPolicy, err error)
+}
+
+// podSecurityPolicyCollector collects metrics about all podSecurityPolicies in the cluster.
+type podSecurityPolicyCollector struct {
+	store podSecurityPolicyStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (pspc *podSecurityPolicyCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descPodSecurityPolicyInfo
+	ch <- descPodSecurityPolicyCreated
+	ch <- descPodSecurityPolicyLabels
+	ch <- descPodSecurityPolicySpecPrivileged
+	ch <- descPodSecurityPolicySpecHostNetwork
+	ch <- descPodSecurityPolicySpecHostPID
+	ch <- descPodSecurityPolicySpecHostIPC
+	ch <- descPodSecurityPolicySpecAllowPrivilegeEscalation
+	ch <- descPodSecurityPolicySpecReadOnlyRootFilesystem
+	ch <- descPodSecurityPolicySpecAllowedCapabilities
+}
+
+// Collect implements the prometheus.Collector interface.
+func (pspc *podSecurityPolicyCollector) Collect(ch chan<- prometheus.Metric) {
+	podSecurityPolicies, err := pspc.store.List()
+	if err != nil {
+		addScrapeError(ch, "podsecuritypolicy", err)
+		glog.Errorf("listing podsecuritypolicies failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "podsecuritypolicy"}).Add(0)
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "podsecuritypolicy"}).Observe(float64(len(podSecurityPolicies)))
+	for _, psp := range podSecurityPolicies {
+		pspc.collectPodSecurityPolicy(ch, psp)
+	}
+
+	glog.V(4).Infof("collected %d podsecuritypolicies", len(podSecurityPolicies))
+}
+
+func podSecurityPolicyLabelsDesc(labelKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descPodSecurityPolicyLabelsName,
+		descPodSecurityPolicyLabelsHelp,
+		append(descPodSecurityPolicyLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func (pspc *podSecurityPolicyCollector) collectPodSecurityPolicy(ch chan<- prometheus.Metric, psp policyv1beta1.PodSecurityPolicy) {
+	defer recoverObjectError("podsecuritypolicy", &psp.ObjectMeta)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{psp.Name}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	addGauge(descPodSecurityPolicyInfo, 1,
+		string(psp.Spec.RunAsUser.Rule),
+		string(psp.Spec.SELinux.Rule),
+		string(psp.Spec.SupplementalGroups.Rule),
+		string(psp.Spec.FSGroup.Rule),
+	)
+	if !psp.CreationTimestamp.IsZero() {
+		addGauge(descPodSecurityPolicyCreated, float64(psp.CreationTimestamp.Unix()))
+	}
+
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(psp.Labels, pspc.opts.MaxLabelValueLength)
+	addGauge(podSecurityPolicyLabelsDesc(labelKeys), 1, labelValues...)
+
+	addGauge(descPodSecurityPolicySpecPrivileged, boolFloat64(psp.Spec.Privileged))
+	addGauge(descPodSecurityPolicySpecHostNetwork, boolFloat64(psp.Spec.HostNetwork))
+	addGauge(descPodSecurityPolicySpecHostPID, boolFloat64(psp.Spec.HostPID))
+	addGauge(descPodSecurityPolicySpecHostIPC, boolFloat64(psp.Spec.HostIPC))
+	// Privilege escalation is allowed unless explicitly disallowed.
+	addGauge(descPodSecurityPolicySpecAllowPrivilegeEscalation, boolFloat64(psp.Spec.AllowPrivilegeEscalation == nil || *psp.Spec.AllowPrivilegeEscalation))
+	addGauge(descPodSecurityPolicySpecReadOnlyRootFilesystem, boolFloat64(psp.Spec.ReadOnlyRootFilesystem))
+	addGauge(descPodSecurityPolicySpecAllowedCapabilities, float64(len(psp.Spec.AllowedCapabilities)))
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockPodSecurityPolicyStore struct {
+	list func() ([]policyv1beta1.PodSecurityPolicy, error)
+}
+
+func (ps mockPodSecurityPolicyStore) List() ([]policyv1beta1.PodSecurityPolicy, error) {
+	return ps.list()
+}
+
+func TestPodSecurityPolicyCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+
+	disallowed := false
+
+	const metadata = `
+		# HELP kube_podsecuritypolicy_info Information about podsecuritypolicy.
+		# TYPE kube_podsecuritypolicy_info gauge
+		# HELP kube_podsecuritypolicy_created Unix creation timestamp
+		# TYPE kube_podsecuritypolicy_created gauge
+		# HELP kube_podsecuritypolicy_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_podsecuritypolicy_labels gauge
+		# HELP kube_podsecuritypolicy_spec_privileged Whether privileged pods are allowed.
+		# TYPE kube_podsecuritypolicy_spec_privileged gauge
+		# HELP kube_podsecuritypolicy_spec_host_network Whether pods may use the host network.
+		# TYPE kube_podsecuritypolicy_spec_host_network gauge
+		# HELP kube_podsecuritypolicy_spec_host_pid Whether pods may use the host PID namespace.
+		# TYPE kube_podsecuritypolicy_spec_host_pid gauge
+		# HELP kube_podsecuritypolicy_spec_host_ipc Whether pods may use the host IPC namespace.
+		# TYPE kube_podsecuritypolicy_spec_host_ipc gauge
+		# HELP kube_podsecuritypolicy_spec_allow_privilege_escalation Whether containers may request privilege escalation.
+		# TYPE kube_podsecuritypolicy_spec_allow_privilege_escalation gauge
+		# HELP kube_podsecuritypolicy_spec_read_only_root_filesystem Whether containers have to run with a read-only root filesystem.
+		# TYPE kube_podsecuritypolicy_spec_read_only_root_filesystem gauge
+		# HELP kube_podsecuritypolicy_spec_allowed_capabilities Number of capabilities containers may add.
+		# TYPE kube_podsecuritypolicy_spec_allowed_capabilities gauge
+	`
+	cases := []struct {
+		podSecurityPolicies []policyv1beta1.PodSecurityPolicy
+		want                string
+		metrics             []string
+	}{
+		{
+			podSecurityPolicies: []policyv1beta1.PodSecurityPolicy{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "privileged",
+						CreationTimestamp: metav1StartTime,
+						Labels: map[string]string{
+							"app": "system",
+						},
+					},
+					Spec: policyv1beta1.PodSecurityPolicySpec{
+						Privileged:          true,
+						HostNetwork:         true,
+						HostPID:             true,
+						HostIPC:             true,
+						AllowedCapabilities: []v1.Capability{"NET_ADMIN", "SYS_ADMIN"},
+						RunAsUser:           policyv1beta1.RunAsUserStrategyOptions{Rule: policyv1beta1.RunAsUserStrategyRunAsAny},
+						SELinux:             policyv1beta1.SELinuxStrategyOptions{Rule: policyv1beta1.SELinuxStrategyRunAsAny},
+						SupplementalGroups:  policyv1beta1.SupplementalGroupsStrategyOptions{Rule: policyv1beta1.SupplementalGroupsStrategyRunAsAny},
+						FSGroup:             policyv1beta1.FSGroupStrategyOptions{Rule: policyv1beta1.FSGroupStrategyRunAsAny},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "restricted",
+					},
+					Spec: policyv1beta1.PodSecurityPolicySpec{
+						AllowPrivilegeEscalation: &disallowed,
+						ReadOnlyRootFilesystem:   true,
+						RunAsUser:                policyv1beta1.RunAsUserStrategyOptions{Rule: policyv1beta1.RunAsUserStrategyMustRunAsNonRoot},
+						SELinux:                  policyv1beta1.SELinuxStrategyOptions{Rule: policyv1beta1.SELinuxStrategyRunAsAny},
+						SupplementalGroups:       policyv1beta1.SupplementalGroupsStrategyOptions{Rule: policyv1beta1.SupplementalGroupsStrategyMustRunAs},
+						FSGroup:                  policyv1beta1.FSGroupStrategyOptions{Rule: policyv1beta1.FSGroupStrategyMustRunAs},
+					},
+				},
+			},
+			want: metadata + `
+				kube_podsecuritypolicy_info{fs_group_rule="MustRunAs",podsecuritypolicy="restricted",run_as_user_rule="MustRunAsNonRoot",se_linux_rule="RunAsAny",supplemental_groups_rule="MustRunAs"} 1
+				kube_podsecuritypolicy_info{fs_group_rule="RunAsAny",podsecuritypolicy="privileged",run_as_user_rule="RunAsAny",se_linux_rule="RunAsAny",supplemental_groups_rule="RunAsAny"} 1
+				kube_podsecuritypolicy_created{podsecuritypolicy="privileged"} 1.501569018e+09
+				kube_podsecuritypolicy_labels{label_app="system",podsecuritypolicy="privileged"} 1
+				kube_podsecuritypolicy_labels{podsecuritypolicy="restricted"} 1
+				kube_podsecuritypolicy_spec_privileged{podsecuritypolicy="privileged"} 1
+				kube_podsecuritypolicy_spec_privileged{podsecuritypolicy="restricted"} 0
+				kube_podsecuritypolicy_spec_host_network{podsecuritypolicy="privileged"} 1
+				kube_podsecuritypolicy_spec_host_network{podsecuritypolicy="restricted"} 0
+				kube_podsecuritypolicy_spec_host_pid{podsecuritypolicy="privileged"} 1
+				kube_podsecuritypolicy_spec_host_pid{podsecuritypolicy="restricted"} 0
+				kube_podsecuritypolicy_spec_host_ipc{podsecuritypolicy="privileged"} 1
+				kube_podsecuritypolicy_spec_host_ipc{podsecuritypolicy="restricted"} 0
+				kube_podsecuritypolicy_spec_allow_privilege_escalation{podsecuritypolicy="privileged"} 1
+				kube_podsecuritypolicy_spec_allow_privilege_escalation{podsecuritypolicy="restricted"} 0
+				kube_podsecuritypolicy_spec_read_only_root_filesystem{podsecuritypolicy="privileged"} 0
+				kube_podsecuritypolicy_spec_read_only_root_filesystem{podsecuritypolicy="restricted"} 1
+				kube_podsecuritypolicy_spec_allowed_capabilities{podsecuritypolicy="privileged"} 2
+				kube_podsecuritypolicy_spec_allowed_capabilities{podsecuritypolicy="restricted"} 0
+			`,
+		},
+	}
+	for _, c := range cases {
+		pspc := &podSecurityPolicyCollector{
+			store: mockPodSecurityPolicyStore{
+				list: func() ([]policyv1beta1.PodSecurityPolicy, error) { return c.podSecurityPolicies, nil },
+			},
+			opts: &options.Options{},
+		}
+		if err := testutils.GatherAndCompare(pspc, c.want, c.metrics); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
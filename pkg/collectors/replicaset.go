@@ -23,8 +23,8 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/context"
 	"k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -72,12 +72,26 @@ var (
 		descReplicaSetLabelsDefaultLabels,
 		nil,
 	)
+	descReplicaSetCreatedBy = prometheus.NewDesc(
+		"kube_replicaset_created_by",
+		"The controller that created the ReplicaSet, usually a Deployment, and the pod template hash of the revision it represents.",
+		append(descReplicaSetLabelsDefaultLabels, "created_by_kind", "created_by_name", "pod_template_hash"),
+		nil,
+	)
 	descReplicaSetOwner = prometheus.NewDesc(
 		"kube_replicaset_owner",
 		"Information about the ReplicaSet's owner.",
 		append(descReplicaSetLabelsDefaultLabels, "owner_kind", "owner_name", "owner_is_controller"),
 		nil,
 	)
+	descReplicaSetOrphan = prometheus.NewDesc(
+		"kube_replicaset_orphan",
+		"Whether the ReplicaSet has no controller owner.",
+		descReplicaSetLabelsDefaultLabels,
+		nil,
+	)
+
+	descReplicaSetSummarizedObjects = newSummarizedObjectsDesc("replicaset")
 )
 
 type ReplicaSetLister func() ([]v1beta1.ReplicaSet, error)
@@ -90,7 +104,7 @@ func RegisterReplicaSetCollector(registry prometheus.Registerer, informerFactori
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Extensions().V1beta1().ReplicaSets().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Extensions().V1beta1().ReplicaSets().Informer(), opts))
 	}
 
 	replicaSetLister := ReplicaSetLister(func() (replicasets []v1beta1.ReplicaSet, err error) {
@@ -102,7 +116,13 @@ func RegisterReplicaSetCollector(registry prometheus.Registerer, informerFactori
 		return replicasets, nil
 	})
 
-	registry.MustRegister(&replicasetCollector{store: replicaSetLister, opts: opts})
+	rsc := &replicasetCollector{store: replicaSetLister, opts: opts}
+	rsc.metrics = newObjectMetricsCache(registry, infs, opts, func(ch chan<- prometheus.Metric, obj interface{}) {
+		rsc.collectReplicaSet(ch, *obj.(*v1beta1.ReplicaSet))
+	})
+	registry.MustRegister(rsc)
+	registerDeletedObjects(registry, infs, "kube_replicaset_deleted", descReplicaSetLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("replicaset", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -112,8 +132,9 @@ type replicasetStore interface {
 
 // replicasetCollector collects metrics about all replicasets in the cluster.
 type replicasetCollector struct {
-	store replicasetStore
-	opts  *options.Options
+	store   replicasetStore
+	opts    *options.Options
+	metrics *objectMetricsCache
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -126,30 +147,43 @@ func (rsc *replicasetCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descReplicaSetSpecReplicas
 	ch <- descReplicaSetMetadataGeneration
 	ch <- descReplicaSetOwner
+	ch <- descReplicaSetOrphan
+	ch <- descReplicaSetCreatedBy
+	ch <- descReplicaSetSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (rsc *replicasetCollector) Collect(ch chan<- prometheus.Metric) {
 	rss, err := rsc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "replicaset"}).Inc()
+		addScrapeError(ch, "replicaset", err)
 		glog.Errorf("listing replicasets failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "replicaset"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "replicaset"}).Observe(float64(len(rss)))
+	summarized := map[string]int{}
 	for _, d := range rss {
-		rsc.collectReplicaSet(ch, d)
+		if !detailed(rsc.opts, &d.ObjectMeta) {
+			summarized[d.Namespace]++
+			continue
+		}
+		if rsc.metrics == nil {
+			rsc.collectReplicaSet(ch, d)
+		}
 	}
+	addSummarizedObjects(ch, descReplicaSetSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d replicasets", len(rss))
 }
 
 func (rsc *replicasetCollector) collectReplicaSet(ch chan<- prometheus.Metric, d v1beta1.ReplicaSet) {
+	defer recoverObjectError("replicaset", &d.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{d.Namespace, d.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 	if !d.CreationTimestamp.IsZero() {
 		addGauge(descReplicaSetCreated, float64(d.CreationTimestamp.Unix()))
@@ -167,6 +201,13 @@ func (rsc *replicasetCollector) collectReplicaSet(ch chan<- prometheus.Metric, d
 			}
 		}
 	}
+	addGauge(descReplicaSetOrphan, boolFloat64(metav1.GetControllerOf(&d) == nil))
+
+	createdByKind, createdByName := "<none>", "<none>"
+	if createdBy := metav1.GetControllerOf(&d); createdBy != nil {
+		createdByKind, createdByName = createdBy.Kind, createdBy.Name
+	}
+	addGauge(descReplicaSetCreatedBy, 1, createdByKind, createdByName, d.Labels[v1beta1.DefaultDeploymentUniqueLabelKey])
 
 	addGauge(descReplicaSetStatusReplicas, float64(d.Status.Replicas))
 	addGauge(descReplicaSetStatusFullyLabeledReplicas, float64(d.Status.FullyLabeledReplicas))
@@ -22,8 +22,8 @@ import (
 
 	"k8s.io/api/extensions/v1beta1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 var (
@@ -61,6 +61,10 @@ func TestReplicaSetCollector(t *testing.T) {
 		# TYPE kube_replicaset_spec_replicas gauge
 		# HELP kube_replicaset_owner Information about the ReplicaSet's owner.
 		# TYPE kube_replicaset_owner gauge
+		# HELP kube_replicaset_orphan Whether the ReplicaSet has no controller owner.
+		# TYPE kube_replicaset_orphan gauge
+		# HELP kube_replicaset_created_by The controller that created the ReplicaSet, usually a Deployment, and the pod template hash of the revision it represents.
+		# TYPE kube_replicaset_created_by gauge
 	`
 	cases := []struct {
 		rss  []v1beta1.ReplicaSet
@@ -74,6 +78,10 @@ func TestReplicaSetCollector(t *testing.T) {
 						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 						Namespace:         "ns1",
 						Generation:        21,
+						Labels: map[string]string{
+							"app":               "example",
+							"pod-template-hash": "5b7c6f9d8",
+						},
 						OwnerReferences: []metav1.OwnerReference{
 							{
 								Kind:       "Deployment",
@@ -124,6 +132,10 @@ func TestReplicaSetCollector(t *testing.T) {
 				kube_replicaset_spec_replicas{namespace="ns2",replicaset="rs2"} 0
 				kube_replicaset_owner{namespace="ns1",replicaset="rs1",owner_kind="Deployment",owner_name="dp-name",owner_is_controller="true"} 1
 				kube_replicaset_owner{namespace="ns2",replicaset="rs2",owner_kind="<none>",owner_name="<none>",owner_is_controller="<none>"} 1
+				kube_replicaset_orphan{namespace="ns1",replicaset="rs1"} 0
+				kube_replicaset_orphan{namespace="ns2",replicaset="rs2"} 1
+				kube_replicaset_created_by{namespace="ns1",replicaset="rs1",created_by_kind="Deployment",created_by_name="dp-name",pod_template_hash="5b7c6f9d8"} 1
+				kube_replicaset_created_by{namespace="ns2",replicaset="rs2",created_by_kind="<none>",created_by_name="<none>",pod_template_hash=""} 1
 			`,
 		},
 	}
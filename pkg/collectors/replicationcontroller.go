@@ -23,13 +23,18 @@ import (
 	"github.com/prometheus/client_golang/prometheus"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
 var (
 	descReplicationControllerLabelsDefaultLabels = []string{"namespace", "replicationcontroller"}
 
+	descReplicationControllerInfo = prometheus.NewDesc(
+		"kube_replicationcontroller_info",
+		"Information about replicationcontroller.",
+		append(descReplicationControllerLabelsDefaultLabels, "workload_id"),
+		nil,
+	)
 	descReplicationControllerCreated = prometheus.NewDesc(
 		"kube_replicationcontroller_created",
 		"Unix creation timestamp",
@@ -78,6 +83,8 @@ var (
 		descReplicationControllerLabelsDefaultLabels,
 		nil,
 	)
+
+	descReplicationControllerSummarizedObjects = newSummarizedObjectsDesc("replicationcontroller")
 )
 
 type ReplicationControllerLister func() ([]v1.ReplicationController, error)
@@ -90,7 +97,7 @@ func RegisterReplicationControllerCollector(registry prometheus.Registerer, info
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().ReplicationControllers().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().ReplicationControllers().Informer(), opts))
 	}
 
 	replicationControllerLister := ReplicationControllerLister(func() (rcs []v1.ReplicationController, err error) {
@@ -103,6 +110,8 @@ func RegisterReplicationControllerCollector(registry prometheus.Registerer, info
 	})
 
 	registry.MustRegister(&replicationcontrollerCollector{store: replicationControllerLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_replicationcontroller_deleted", descReplicationControllerLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("replicationcontroller", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -117,6 +126,7 @@ type replicationcontrollerCollector struct {
 
 // Describe implements the prometheus.Collector interface.
 func (dc *replicationcontrollerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descReplicationControllerInfo
 	ch <- descReplicationControllerCreated
 	ch <- descReplicationControllerStatusReplicas
 	ch <- descReplicationControllerStatusFullyLabeledReplicas
@@ -125,31 +135,41 @@ func (dc *replicationcontrollerCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descReplicationControllerStatusObservedGeneration
 	ch <- descReplicationControllerSpecReplicas
 	ch <- descReplicationControllerMetadataGeneration
+	ch <- descReplicationControllerSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (dc *replicationcontrollerCollector) Collect(ch chan<- prometheus.Metric) {
 	rcs, err := dc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "replicationcontroller"}).Inc()
+		addScrapeError(ch, "replicationcontroller", err)
 		glog.Errorf("listing replicationcontrollers failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "replicationcontroller"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "replicationcontroller"}).Observe(float64(len(rcs)))
+	summarized := map[string]int{}
 	for _, d := range rcs {
+		if !detailed(dc.opts, &d.ObjectMeta) {
+			summarized[d.Namespace]++
+			continue
+		}
 		dc.collectReplicationController(ch, d)
 	}
+	addSummarizedObjects(ch, descReplicationControllerSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d replicationcontrollers", len(rcs))
 }
 
 func (dc *replicationcontrollerCollector) collectReplicationController(ch chan<- prometheus.Metric, d v1.ReplicationController) {
+	defer recoverObjectError("replicationcontroller", &d.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{d.Namespace, d.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
+	addGauge(descReplicationControllerInfo, 1, workloadID(d.Namespace, "ReplicationController", d.Name))
 	if !d.CreationTimestamp.IsZero() {
 		addGauge(descReplicationControllerCreated, float64(d.CreationTimestamp.Unix()))
 	}
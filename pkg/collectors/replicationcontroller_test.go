@@ -22,8 +22,8 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 var (
@@ -43,6 +43,8 @@ func TestReplicationControllerCollector(t *testing.T) {
 	// Fixed metadata on type and help text. We prepend this to every expected
 	// output so we only have to modify a single place when doing adjustments.
 	const metadata = `
+		# HELP kube_replicationcontroller_info Information about replicationcontroller.
+		# TYPE kube_replicationcontroller_info gauge
 		# HELP kube_replicationcontroller_created Unix creation timestamp
 		# TYPE kube_replicationcontroller_created gauge
 	  # HELP kube_replicationcontroller_metadata_generation Sequence number representing a specific generation of the desired state.
@@ -102,6 +104,8 @@ func TestReplicationControllerCollector(t *testing.T) {
 				},
 			},
 			want: metadata + `
+				kube_replicationcontroller_info{namespace="ns1",replicationcontroller="rc1",workload_id="bb8e3810ff8157f1"} 1
+				kube_replicationcontroller_info{namespace="ns2",replicationcontroller="rc2",workload_id="2be6f2157d55feb1"} 1
 				kube_replicationcontroller_created{namespace="ns1",replicationcontroller="rc1"} 1.5e+09
 				kube_replicationcontroller_metadata_generation{namespace="ns1",replicationcontroller="rc1"} 21
 				kube_replicationcontroller_metadata_generation{namespace="ns2",replicationcontroller="rc2"} 14
@@ -22,13 +22,17 @@ import (
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
 var (
+	descResourceQuotaLabelsName          = "kube_resourcequota_labels"
+	descResourceQuotaLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descResourceQuotaLabelsDefaultLabels = []string{"resourcequota", "namespace"}
 
+	descResourceQuotaAnnotationsName = "kube_resourcequota_annotations"
+	descResourceQuotaAnnotationsHelp = "Kubernetes annotations converted to Prometheus labels."
+
 	descResourceQuotaCreated = prometheus.NewDesc(
 		"kube_resourcequota_created",
 		"Unix creation timestamp",
@@ -43,6 +47,21 @@ var (
 			"type",
 		), nil,
 	)
+
+	descResourceQuotaLabels = prometheus.NewDesc(
+		descResourceQuotaLabelsName,
+		descResourceQuotaLabelsHelp,
+		descResourceQuotaLabelsDefaultLabels,
+		nil,
+	)
+	descResourceQuotaAnnotations = prometheus.NewDesc(
+		descResourceQuotaAnnotationsName,
+		descResourceQuotaAnnotationsHelp,
+		descResourceQuotaLabelsDefaultLabels,
+		nil,
+	)
+
+	descResourceQuotaSummarizedObjects = newSummarizedObjectsDesc("resourcequota")
 )
 
 type ResourceQuotaLister func() (v1.ResourceQuotaList, error)
@@ -55,7 +74,7 @@ func RegisterResourceQuotaCollector(registry prometheus.Registerer, informerFact
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().ResourceQuotas().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().ResourceQuotas().Informer(), opts))
 	}
 
 	resourceQuotaLister := ResourceQuotaLister(func() (quotas v1.ResourceQuotaList, err error) {
@@ -68,6 +87,8 @@ func RegisterResourceQuotaCollector(registry prometheus.Registerer, informerFact
 	})
 
 	registry.MustRegister(&resourceQuotaCollector{store: resourceQuotaLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_resourcequota_deleted", descResourceQuotaLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("resourcequota", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -85,35 +106,68 @@ type resourceQuotaCollector struct {
 func (rqc *resourceQuotaCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descResourceQuotaCreated
 	ch <- descResourceQuota
+	ch <- descResourceQuotaLabels
+	ch <- descResourceQuotaAnnotations
+	ch <- descResourceQuotaSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (rqc *resourceQuotaCollector) Collect(ch chan<- prometheus.Metric) {
 	resourceQuota, err := rqc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "resourcequota"}).Inc()
+		addScrapeError(ch, "resourcequota", err)
 		glog.Errorf("listing resource quotas failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "resourcequota"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "resourcequota"}).Observe(float64(len(resourceQuota.Items)))
+	summarized := map[string]int{}
 	for _, rq := range resourceQuota.Items {
+		if !detailed(rqc.opts, &rq.ObjectMeta) {
+			summarized[rq.Namespace]++
+			continue
+		}
 		rqc.collectResourceQuota(ch, rq)
 	}
+	addSummarizedObjects(ch, descResourceQuotaSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d resourcequotas", len(resourceQuota.Items))
 }
 
+func resourceQuotaLabelsDesc(labelKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descResourceQuotaLabelsName,
+		descResourceQuotaLabelsHelp,
+		append(descResourceQuotaLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func resourceQuotaAnnotationsDesc(annotationKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descResourceQuotaAnnotationsName,
+		descResourceQuotaAnnotationsHelp,
+		append(descResourceQuotaLabelsDefaultLabels, annotationKeys...),
+		nil,
+	)
+}
+
 func (rqc *resourceQuotaCollector) collectResourceQuota(ch chan<- prometheus.Metric, rq v1.ResourceQuota) {
+	defer recoverObjectError("resourcequota", &rq.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{rq.Name, rq.Namespace}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 
 	if !rq.CreationTimestamp.IsZero() {
 		addGauge(descResourceQuotaCreated, float64(rq.CreationTimestamp.Unix()))
 	}
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(rq.Labels, rqc.opts.MaxLabelValueLength)
+	addGauge(resourceQuotaLabelsDesc(labelKeys), 1, labelValues...)
+	annotationKeys, annotationValues := kubeWhitelistedAnnotationsToPrometheusAnnotations(rq.Annotations, rqc.opts.AnnotationWhitelist, rqc.opts.MaxLabelValueLength)
+	addGauge(resourceQuotaAnnotationsDesc(annotationKeys), 1, annotationValues...)
 	for res, qty := range rq.Status.Hard {
 		addGauge(descResourceQuota, float64(qty.MilliValue())/1000, string(res), "hard")
 	}
@@ -23,8 +23,8 @@ import (
 	"k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockResourceQuotaStore struct {
@@ -43,6 +43,10 @@ func TestResourceQuotaCollector(t *testing.T) {
 	# TYPE kube_resourcequota gauge
 	# HELP kube_resourcequota_created Unix creation timestamp
 	# TYPE kube_resourcequota_created gauge
+	# HELP kube_resourcequota_labels Kubernetes labels converted to Prometheus labels.
+	# TYPE kube_resourcequota_labels gauge
+	# HELP kube_resourcequota_annotations Kubernetes annotations converted to Prometheus labels.
+	# TYPE kube_resourcequota_annotations gauge
 	`
 	cases := []struct {
 		quotas  []v1.ResourceQuota
@@ -57,12 +61,19 @@ func TestResourceQuotaCollector(t *testing.T) {
 						Name:              "quotaTest",
 						CreationTimestamp: metav1.Time{Time: time.Unix(1500000000, 0)},
 						Namespace:         "testNS",
+						Labels:            map[string]string{"team": "platform"},
+						Annotations: map[string]string{
+							"owner":       "platform@example.com",
+							"description": "Namespace quota",
+						},
 					},
 					Status: v1.ResourceQuotaStatus{},
 				},
 			},
 			want: metadata + `
 			kube_resourcequota_created{namespace="testNS",resourcequota="quotaTest"} 1.5e+09
+			kube_resourcequota_labels{label_team="platform",namespace="testNS",resourcequota="quotaTest"} 1
+			kube_resourcequota_annotations{annotation_owner="platform@example.com",namespace="testNS",resourcequota="quotaTest"} 1
 			`,
 		},
 		// Verify resource metrics.
@@ -122,6 +133,8 @@ func TestResourceQuotaCollector(t *testing.T) {
 				},
 			},
 			want: metadata + `
+			kube_resourcequota_labels{namespace="testNS",resourcequota="quotaTest"} 1
+			kube_resourcequota_annotations{namespace="testNS",resourcequota="quotaTest"} 1
 			kube_resourcequota{resourcequota="quotaTest",namespace="testNS",resource="cpu",type="hard"} 4.3
 			kube_resourcequota{resourcequota="quotaTest",namespace="testNS",resource="cpu",type="used"} 2.1
 			kube_resourcequota{resourcequota="quotaTest",namespace="testNS",resource="memory",type="hard"} 2.1e+09
@@ -156,7 +169,7 @@ func TestResourceQuotaCollector(t *testing.T) {
 					return v1.ResourceQuotaList{Items: c.quotas}, nil
 				},
 			},
-			opts: &options.Options{},
+			opts: &options.Options{AnnotationWhitelist: options.AnnotationSet{"owner": struct{}{}}},
 		}
 		if err := testutils.GatherAndCompare(dc, c.want, c.metrics); err != nil {
 			t.Errorf("unexpected collecting result:\n%s", err)
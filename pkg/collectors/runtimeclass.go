@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// RuntimeClasses were added to node.k8s.io after the vendored client-go,
+	// which has no typed client for them.
+	runtimeClassResource = CustomResource{
+		Group:    "node.k8s.io",
+		Version:  "v1",
+		Resource: "runtimeclasses",
+		Kind:     "RuntimeClass",
+	}
+
+	descRuntimeClassLabelsDefaultLabels = []string{"runtimeclass"}
+
+	descRuntimeClassInfo = prometheus.NewDesc(
+		"kube_runtimeclass_info",
+		"Information about the runtime class.",
+		append(descRuntimeClassLabelsDefaultLabels, "handler"),
+		nil,
+	)
+	descRuntimeClassCreated = prometheus.NewDesc(
+		"kube_runtimeclass_created",
+		"Unix creation timestamp",
+		descRuntimeClassLabelsDefaultLabels,
+		nil,
+	)
+)
+
+// RegisterRuntimeClassCollector registers a collector of the RuntimeClasses,
+// which are cluster-scoped. Like the endpointslices collector it lists them
+// on every scrape.
+func RegisterRuntimeClassCollector(registry prometheus.Registerer, client rest.Interface, opts *options.Options) {
+	registry.MustRegister(&runtimeClassCollector{store: restCustomResourceStore{client: client}, opts: opts})
+}
+
+// runtimeClassCollector collects metrics about all RuntimeClasses in the
+// cluster.
+type runtimeClassCollector struct {
+	store customResourceStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (rc *runtimeClassCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descRuntimeClassInfo
+	ch <- descRuntimeClassCreated
+}
+
+// Collect implements the prometheus.Collector interface.
+func (rc *runtimeClassCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, rc.store, runtimeClassResource, options.NamespaceList{""}, rc.opts, func(obj unstructured.Unstructured) {
+		rc.collectRuntimeClass(ch, obj)
+	})
+}
+
+func (rc *runtimeClassCollector) collectRuntimeClass(ch chan<- prometheus.Metric, c unstructured.Unstructured) {
+	defer recoverObjectError("runtimeclass", &c)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{c.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	// The handler is a top-level field, not part of a spec.
+	handler, _, _ := unstructured.NestedString(c.Object, "handler")
+	addGauge(descRuntimeClassInfo, 1, handler)
+
+	if t := c.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descRuntimeClassCreated, float64(t.Unix()))
+	}
+}
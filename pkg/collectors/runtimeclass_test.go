@@ -0,0 +1,60 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestRuntimeClassCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_runtimeclass_info Information about the runtime class.
+		# TYPE kube_runtimeclass_info gauge
+		# HELP kube_runtimeclass_created Unix creation timestamp
+		# TYPE kube_runtimeclass_created gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"runtimeclasses": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "gvisor",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+				},
+				"handler": "runsc",
+			}},
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name": "kata",
+				},
+				"handler": "kata-qemu",
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_runtimeclass_info{handler="runsc",runtimeclass="gvisor"} 1
+		kube_runtimeclass_info{handler="kata-qemu",runtimeclass="kata"} 1
+		kube_runtimeclass_created{runtimeclass="gvisor"} 1.501569018e+09
+	`
+	rc := &runtimeClassCollector{store: store, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(rc, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
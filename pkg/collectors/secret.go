@@ -17,12 +17,13 @@ limitations under the License.
 package collectors
 
 import (
+	"time"
+
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -65,6 +66,22 @@ var (
 		append(descSecretLabelsDefaultLabels, "resource_version"),
 		nil,
 	)
+
+	descSecretServiceAccountTokenInfo = prometheus.NewDesc(
+		"kube_secret_service_account_token_info",
+		"Information about the service account a token secret is bound to.",
+		append(descSecretLabelsDefaultLabels, "service_account"),
+		nil,
+	)
+
+	descSecretServiceAccountTokenAge = prometheus.NewDesc(
+		"kube_secret_service_account_token_age_seconds",
+		"The number of seconds since a service account token secret was created.",
+		descSecretLabelsDefaultLabels,
+		nil,
+	)
+
+	descSecretSummarizedObjects = newSummarizedObjectsDesc("secret")
 )
 
 type SecretLister func() ([]v1.Secret, error)
@@ -77,7 +94,7 @@ func RegisterSecretCollector(registry prometheus.Registerer, informerFactories [
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().Secrets().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().Secrets().Informer(), opts))
 	}
 
 	secretLister := SecretLister(func() (secrets []v1.Secret, err error) {
@@ -89,7 +106,9 @@ func RegisterSecretCollector(registry prometheus.Registerer, informerFactories [
 		return secrets, nil
 	})
 
-	registry.MustRegister(&secretCollector{store: secretLister, opts: opts})
+	registry.MustRegister(&secretCollector{store: secretLister, opts: opts, now: time.Now})
+	registerDeletedObjects(registry, infs, "kube_secret_deleted", descSecretLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("secret", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -101,6 +120,9 @@ type secretStore interface {
 type secretCollector struct {
 	store secretStore
 	opts  *options.Options
+	// now is used to compute the age of service account tokens, it is only
+	// overridden in tests.
+	now func() time.Time
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -110,22 +132,31 @@ func (sc *secretCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descSecretLabels
 	ch <- descSecretMetadataResourceVersion
 	ch <- descSecretType
+	ch <- descSecretServiceAccountTokenInfo
+	ch <- descSecretServiceAccountTokenAge
+	ch <- descSecretSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (sc *secretCollector) Collect(ch chan<- prometheus.Metric) {
 	secrets, err := sc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "secret"}).Inc()
+		addScrapeError(ch, "secret", err)
 		glog.Errorf("listing secrets failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "secret"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "secret"}).Observe(float64(len(secrets)))
+	summarized := map[string]int{}
 	for _, s := range secrets {
+		if !detailed(sc.opts, &s.ObjectMeta) {
+			summarized[s.Namespace]++
+			continue
+		}
 		sc.collectSecret(ch, s)
 	}
+	addSummarizedObjects(ch, descSecretSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d secrets", len(secrets))
 }
@@ -140,9 +171,11 @@ func secretLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (sc *secretCollector) collectSecret(ch chan<- prometheus.Metric, s v1.Secret) {
+	defer recoverObjectError("secret", &s.ObjectMeta)
+
 	addConstMetric := func(desc *prometheus.Desc, t prometheus.ValueType, v float64, lv ...string) {
 		lv = append([]string{s.Namespace, s.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, t, v, lv...)
+		ch <- mustNewConstMetric(desc, t, v, lv...)
 	}
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		addConstMetric(desc, prometheus.GaugeValue, v, lv...)
@@ -153,8 +186,15 @@ func (sc *secretCollector) collectSecret(ch chan<- prometheus.Metric, s v1.Secre
 	if !s.CreationTimestamp.IsZero() {
 		addGauge(descSecretCreated, float64(s.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels, sc.opts.MaxLabelValueLength)
 	addGauge(secretLabelsDesc(labelKeys), 1, labelValues...)
 
 	addGauge(descSecretMetadataResourceVersion, 1, string(s.ObjectMeta.ResourceVersion))
+
+	if s.Type == v1.SecretTypeServiceAccountToken {
+		addGauge(descSecretServiceAccountTokenInfo, 1, s.Annotations[v1.ServiceAccountNameKey])
+		if !s.CreationTimestamp.IsZero() {
+			addGauge(descSecretServiceAccountTokenAge, sc.now().Sub(s.CreationTimestamp.Time).Seconds())
+		}
+	}
 }
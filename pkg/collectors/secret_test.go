@@ -18,11 +18,12 @@ package collectors
 
 import (
 	"testing"
+	"time"
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockSecretStore struct {
@@ -51,6 +52,10 @@ func TestSecretCollector(t *testing.T) {
 		# TYPE kube_secret_created gauge
 		# HELP kube_secret_metadata_resource_version Resource version representing a specific version of secret.
 		# TYPE kube_secret_metadata_resource_version gauge
+		# HELP kube_secret_service_account_token_info Information about the service account a token secret is bound to.
+		# TYPE kube_secret_service_account_token_info gauge
+		# HELP kube_secret_service_account_token_age_seconds The number of seconds since a service account token secret was created.
+		# TYPE kube_secret_service_account_token_age_seconds gauge
 	`
 	cases := []struct {
 		secrets []v1.Secret
@@ -73,6 +78,9 @@ func TestSecretCollector(t *testing.T) {
 						Namespace:         "ns2",
 						CreationTimestamp: metav1StartTime,
 						ResourceVersion:   "123456",
+						Annotations: map[string]string{
+							v1.ServiceAccountNameKey: "default",
+						},
 					},
 					Type: v1.SecretTypeServiceAccountToken,
 				},
@@ -105,6 +113,34 @@ func TestSecretCollector(t *testing.T) {
 				`,
 			metrics: []string{"kube_secret_info", "kube_secret_metadata_resource_version", "kube_secret_created", "kube_secret_labels", "kube_secret_type"},
 		},
+		{
+			secrets: []v1.Secret{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "default-token-abcde",
+						Namespace:         "ns1",
+						CreationTimestamp: metav1StartTime,
+						Annotations: map[string]string{
+							v1.ServiceAccountNameKey: "default",
+						},
+					},
+					Type: v1.SecretTypeServiceAccountToken,
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "secret1",
+						Namespace:         "ns1",
+						CreationTimestamp: metav1StartTime,
+					},
+					Type: v1.SecretTypeOpaque,
+				},
+			},
+			want: metadata + `
+				kube_secret_service_account_token_info{namespace="ns1",secret="default-token-abcde",service_account="default"} 1
+				kube_secret_service_account_token_age_seconds{namespace="ns1",secret="default-token-abcde"} 86400
+				`,
+			metrics: []string{"kube_secret_service_account_token_info", "kube_secret_service_account_token_age_seconds"},
+		},
 	}
 	for _, c := range cases {
 		sc := &secretCollector{
@@ -112,6 +148,7 @@ func TestSecretCollector(t *testing.T) {
 				f: func() ([]v1.Secret, error) { return c.secrets, nil },
 			},
 			opts: &options.Options{},
+			now:  func() time.Time { return time.Unix(int64(startTime+86400), 0) },
 		}
 		if err := testutils.GatherAndCompare(sc, c.want, c.metrics); err != nil {
 			t.Errorf("unexpected collecting result:\n%s", err)
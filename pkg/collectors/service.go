@@ -17,12 +17,13 @@ limitations under the License.
 package collectors
 
 import (
+	"strconv"
+
 	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/net/context"
 	"k8s.io/api/core/v1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -52,12 +53,42 @@ var (
 		nil,
 	)
 
+	descServicePortInfo = prometheus.NewDesc(
+		"kube_service_spec_port_info",
+		"Information about a port of the service.",
+		append(descServiceLabelsDefaultLabels, "port_name", "protocol", "port", "target_port", "node_port"),
+		nil,
+	)
+
+	descServiceSpecNodePorts = prometheus.NewDesc(
+		"kube_service_spec_node_ports",
+		"The number of node ports allocated to the service, including its health check node port.",
+		descServiceLabelsDefaultLabels,
+		nil,
+	)
+
+	descServiceNodePortsAllocated = prometheus.NewDesc(
+		"kube_service_node_ports_allocated",
+		"The number of node ports allocated to all services.",
+		nil,
+		nil,
+	)
+
+	descServiceNodePortRangeSize = prometheus.NewDesc(
+		"kube_service_node_port_range_size",
+		"The number of node ports of the configured service node port range.",
+		nil,
+		nil,
+	)
+
 	descServiceLabels = prometheus.NewDesc(
 		descServiceLabelsName,
 		descServiceLabelsHelp,
 		descServiceLabelsDefaultLabels,
 		nil,
 	)
+
+	descServiceSummarizedObjects = newSummarizedObjectsDesc("service")
 )
 
 type ServiceLister func() ([]v1.Service, error)
@@ -70,7 +101,7 @@ func RegisterServiceCollector(registry prometheus.Registerer, informerFactories
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Core().V1().Services().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Core().V1().Services().Informer(), opts))
 	}
 
 	serviceLister := ServiceLister(func() (services []v1.Service, err error) {
@@ -82,7 +113,13 @@ func RegisterServiceCollector(registry prometheus.Registerer, informerFactories
 		return services, nil
 	})
 
-	registry.MustRegister(&serviceCollector{store: serviceLister, opts: opts})
+	sc := &serviceCollector{store: serviceLister, opts: opts}
+	sc.metrics = newObjectMetricsCache(registry, infs, opts, func(ch chan<- prometheus.Metric, obj interface{}) {
+		sc.collectService(ch, *obj.(*v1.Service))
+	})
+	registry.MustRegister(sc)
+	registerDeletedObjects(registry, infs, "kube_service_deleted", descServiceLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("service", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -92,8 +129,9 @@ type serviceStore interface {
 
 // serviceCollector collects metrics about all services in the cluster.
 type serviceCollector struct {
-	store serviceStore
-	opts  *options.Options
+	store   serviceStore
+	opts    *options.Options
+	metrics *objectMetricsCache
 }
 
 // Describe implements the prometheus.Collector interface.
@@ -102,21 +140,46 @@ func (pc *serviceCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descServiceLabels
 	ch <- descServiceCreated
 	ch <- descServiceSpecType
+	ch <- descServicePortInfo
+	ch <- descServiceSpecNodePorts
+	ch <- descServiceNodePortsAllocated
+	ch <- descServiceNodePortRangeSize
+	ch <- descServiceSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (sc *serviceCollector) Collect(ch chan<- prometheus.Metric) {
 	services, err := sc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "service"}).Inc()
+		addScrapeError(ch, "service", err)
 		glog.Errorf("listing services failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "service"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "service"}).Observe(float64(len(services)))
+	summarized := map[string]int{}
+	nodePorts := 0
 	for _, s := range services {
-		sc.collectService(ch, s)
+		nodePorts += len(serviceNodePorts(s))
+		if !detailed(sc.opts, &s.ObjectMeta) {
+			summarized[s.Namespace]++
+			continue
+		}
+		if sc.metrics == nil {
+			sc.collectService(ch, s)
+		}
+	}
+	addSummarizedObjects(ch, descServiceSummarizedObjects, summarized)
+
+	// Node ports are allocated across namespaces, so their number is only
+	// known if the services of all namespaces are watched.
+	if sc.opts.WatchesAllNamespaces() {
+		ch <- mustNewConstMetric(descServiceNodePortsAllocated, prometheus.GaugeValue, float64(nodePorts))
+	}
+	// The range is the same for all shards, so only the first exposes it.
+	if size := sc.opts.ServiceNodePortRange.Size; size > 0 && sc.opts.Shard == 0 {
+		ch <- mustNewConstMetric(descServiceNodePortRangeSize, prometheus.GaugeValue, float64(size))
 	}
 	glog.V(4).Infof("collected %d services", len(services))
 }
@@ -131,19 +194,47 @@ func serviceLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (sc *serviceCollector) collectService(ch chan<- prometheus.Metric, s v1.Service) {
+	defer recoverObjectError("service", &s.ObjectMeta)
+
 	addConstMetric := func(desc *prometheus.Desc, t prometheus.ValueType, v float64, lv ...string) {
 		lv = append([]string{s.Namespace, s.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, t, v, lv...)
+		ch <- mustNewConstMetric(desc, t, v, lv...)
 	}
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		addConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
 	addGauge(descServiceSpecType, 1, string(s.Spec.Type))
 
+	// Port names carry the application protocol for service meshes relying
+	// on naming conventions like http-api or grpc-backend.
+	for _, p := range s.Spec.Ports {
+		nodePort := ""
+		if p.NodePort != 0 {
+			nodePort = strconv.Itoa(int(p.NodePort))
+		}
+		addGauge(descServicePortInfo, 1, p.Name, string(p.Protocol), strconv.Itoa(int(p.Port)), p.TargetPort.String(), nodePort)
+	}
+	addGauge(descServiceSpecNodePorts, float64(len(serviceNodePorts(s))))
+
 	addGauge(descServiceInfo, 1, s.Spec.ClusterIP)
 	if !s.CreationTimestamp.IsZero() {
 		addGauge(descServiceCreated, float64(s.CreationTimestamp.Unix()))
 	}
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(s.Labels, sc.opts.MaxLabelValueLength)
 	addGauge(serviceLabelsDesc(labelKeys), 1, labelValues...)
 }
+
+// serviceNodePorts returns the node ports allocated to s. Ports of different
+// protocols sharing a node port only allocate it once.
+func serviceNodePorts(s v1.Service) map[int32]struct{} {
+	ports := map[int32]struct{}{}
+	for _, p := range s.Spec.Ports {
+		if p.NodePort != 0 {
+			ports[p.NodePort] = struct{}{}
+		}
+	}
+	if s.Spec.HealthCheckNodePort != 0 {
+		ports[s.Spec.HealthCheckNodePort] = struct{}{}
+	}
+	return ports
+}
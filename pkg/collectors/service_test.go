@@ -22,8 +22,9 @@ import (
 
 	"k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 type mockServiceStore struct {
@@ -46,6 +47,12 @@ func TestServiceCollector(t *testing.T) {
 		# TYPE kube_service_labels gauge
 		# HELP kube_service_spec_type Type about service.
 		# TYPE kube_service_spec_type gauge
+		# HELP kube_service_spec_port_info Information about a port of the service.
+		# TYPE kube_service_spec_port_info gauge
+		# HELP kube_service_spec_node_ports The number of node ports allocated to the service, including its health check node port.
+		# TYPE kube_service_spec_node_ports gauge
+		# HELP kube_service_node_ports_allocated The number of node ports allocated to all services.
+		# TYPE kube_service_node_ports_allocated gauge
 	`
 	cases := []struct {
 		services []v1.Service
@@ -127,8 +134,37 @@ func TestServiceCollector(t *testing.T) {
 				kube_service_spec_type{namespace="default",service="test-service2",type="NodePort"} 1
 				kube_service_spec_type{namespace="default",service="test-service3",type="LoadBalancer"} 1
 				kube_service_spec_type{namespace="default",service="test-service4",type="ExternalName"} 1
+				kube_service_spec_node_ports{namespace="default",service="test-service1"} 0
+				kube_service_spec_node_ports{namespace="default",service="test-service2"} 0
+				kube_service_spec_node_ports{namespace="default",service="test-service3"} 0
+				kube_service_spec_node_ports{namespace="default",service="test-service4"} 0
+				kube_service_node_ports_allocated 0
 			`,
 		},
+		{
+			services: []v1.Service{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:      "test-service5",
+						Namespace: "default",
+					},
+					Spec: v1.ServiceSpec{
+						Type: v1.ServiceTypeNodePort,
+						Ports: []v1.ServicePort{
+							{Name: "http-api", Protocol: v1.ProtocolTCP, Port: 80, TargetPort: intstr.FromString("http"), NodePort: 30080},
+							{Name: "grpc-backend", Protocol: v1.ProtocolTCP, Port: 9090, TargetPort: intstr.FromInt(9091), NodePort: 30090},
+							{Protocol: v1.ProtocolUDP, Port: 53, TargetPort: intstr.FromInt(53)},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_service_spec_port_info{namespace="default",node_port="30080",port="80",port_name="http-api",protocol="TCP",service="test-service5",target_port="http"} 1
+				kube_service_spec_port_info{namespace="default",node_port="30090",port="9090",port_name="grpc-backend",protocol="TCP",service="test-service5",target_port="9091"} 1
+				kube_service_spec_port_info{namespace="default",node_port="",port="53",port_name="",protocol="UDP",service="test-service5",target_port="53"} 1
+			`,
+			metrics: []string{"kube_service_spec_port_info"},
+		},
 	}
 	for _, c := range cases {
 		sc := &serviceCollector{
@@ -144,3 +180,118 @@ func TestServiceCollector(t *testing.T) {
 		}
 	}
 }
+
+func TestServiceCollectorNodePorts(t *testing.T) {
+	const want = `
+		# HELP kube_service_spec_node_ports The number of node ports allocated to the service, including its health check node port.
+		# TYPE kube_service_spec_node_ports gauge
+		kube_service_spec_node_ports{namespace="default",service="dns"} 1
+		kube_service_spec_node_ports{namespace="default",service="ingress"} 3
+		# HELP kube_service_node_ports_allocated The number of node ports allocated to all services.
+		# TYPE kube_service_node_ports_allocated gauge
+		kube_service_node_ports_allocated 4
+		# HELP kube_service_node_port_range_size The number of node ports of the configured service node port range.
+		# TYPE kube_service_node_port_range_size gauge
+		kube_service_node_port_range_size 2768
+	`
+	services := []v1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "default"},
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeLoadBalancer,
+				Ports: []v1.ServicePort{
+					{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, NodePort: 30080},
+					{Name: "https", Protocol: v1.ProtocolTCP, Port: 443, NodePort: 30443},
+				},
+				ExternalTrafficPolicy: v1.ServiceExternalTrafficPolicyTypeLocal,
+				HealthCheckNodePort:   32000,
+			},
+		},
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "dns", Namespace: "default"},
+			Spec: v1.ServiceSpec{
+				Type: v1.ServiceTypeNodePort,
+				Ports: []v1.ServicePort{
+					{Name: "dns-tcp", Protocol: v1.ProtocolTCP, Port: 53, NodePort: 30053},
+					{Name: "dns-udp", Protocol: v1.ProtocolUDP, Port: 53, NodePort: 30053},
+				},
+			},
+		},
+	}
+	sc := &serviceCollector{
+		store: &mockServiceStore{
+			list: func() ([]v1.Service, error) {
+				return services, nil
+			},
+		},
+		opts: options.NewOptions(),
+	}
+	metrics := []string{"kube_service_spec_node_ports", "kube_service_node_ports_allocated", "kube_service_node_port_range_size"}
+	if err := testutils.GatherAndCompare(sc, want, metrics); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestServiceCollectorNodePortsWithShardsAndNamespaces(t *testing.T) {
+	const metadata = `
+		# HELP kube_service_node_ports_allocated The number of node ports allocated to all services.
+		# TYPE kube_service_node_ports_allocated gauge
+		# HELP kube_service_node_port_range_size The number of node ports of the configured service node port range.
+		# TYPE kube_service_node_port_range_size gauge
+	`
+	services := []v1.Service{
+		{
+			ObjectMeta: metav1.ObjectMeta{Name: "ingress", Namespace: "default"},
+			Spec: v1.ServiceSpec{
+				Type:  v1.ServiceTypeNodePort,
+				Ports: []v1.ServicePort{{Name: "http", Protocol: v1.ProtocolTCP, Port: 80, NodePort: 30080}},
+			},
+		},
+	}
+
+	cases := []struct {
+		shard      int
+		namespaces options.NamespaceList
+		want       string
+	}{
+		{
+			shard: 0,
+			want: metadata + `
+				kube_service_node_ports_allocated 1
+				kube_service_node_port_range_size 2768
+			`,
+		},
+		// The range size isn't exposed once per shard.
+		{
+			shard: 1,
+			want: metadata + `
+				kube_service_node_ports_allocated 1
+			`,
+		},
+		// The node ports of other namespaces are unknown.
+		{
+			shard:      0,
+			namespaces: options.NamespaceList{"default"},
+			want: metadata + `
+				kube_service_node_port_range_size 2768
+			`,
+		},
+	}
+	for _, c := range cases {
+		opts := options.NewOptions()
+		opts.Shard, opts.TotalShards = c.shard, 2
+		opts.Namespaces = c.namespaces
+		sc := &serviceCollector{
+			store: &mockServiceStore{
+				list: func() ([]v1.Service, error) {
+					return services, nil
+				},
+			},
+			opts: opts,
+		}
+		metrics := []string{"kube_service_node_ports_allocated", "kube_service_node_port_range_size"}
+		if err := testutils.GatherAndCompare(sc, c.want, metrics); err != nil {
+			t.Errorf("shard %d of namespaces %q: unexpected collecting result:\n%s", c.shard, c.namespaces, err)
+		}
+	}
+}
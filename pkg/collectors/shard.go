@@ -0,0 +1,152 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"hash/fnv"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// ownedByShard returns whether the object with the given UID belongs to the
+// shard of opts. Objects are assigned to shards by a hash of their UID, so
+// that every object, cluster-scoped ones included, belongs to exactly one
+// shard.
+func ownedByShard(opts *options.Options, uid types.UID) bool {
+	if opts.TotalShards <= 1 {
+		return true
+	}
+	h := fnv.New64a()
+	h.Write([]byte(uid))
+	return h.Sum64()%uint64(opts.TotalShards) == uint64(opts.Shard)
+}
+
+// ownedObject returns whether obj belongs to the shard of opts. Objects
+// without metadata belong to every shard.
+func ownedObject(opts *options.Options, obj interface{}) bool {
+	if d, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = d.Obj
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return true
+	}
+	return ownedByShard(opts, o.GetUID())
+}
+
+// shardedInformer only exposes the objects of the shard of opts through its
+// store and its event handlers. The underlying informer can still be shared
+// with collectors which need all objects.
+type shardedInformer struct {
+	cache.SharedInformer
+	opts *options.Options
+	// owned returns whether an object belongs to the shard of opts.
+	owned func(*options.Options, interface{}) bool
+}
+
+// newShardedInformer returns inf restricted to the objects of the shard of
+// opts, or inf itself if objects are not sharded.
+func newShardedInformer(inf cache.SharedInformer, opts *options.Options) cache.SharedInformer {
+	if opts.TotalShards <= 1 {
+		return inf
+	}
+	return &shardedInformer{SharedInformer: inf, opts: opts, owned: ownedObject}
+}
+
+func (i *shardedInformer) filter(handler cache.ResourceEventHandler) cache.ResourceEventHandler {
+	return cache.FilteringResourceEventHandler{
+		FilterFunc: func(obj interface{}) bool { return i.owned(i.opts, obj) },
+		Handler:    handler,
+	}
+}
+
+// AddEventHandler implements the cache.SharedInformer interface.
+func (i *shardedInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	i.SharedInformer.AddEventHandler(i.filter(handler))
+}
+
+// AddEventHandlerWithResyncPeriod implements the cache.SharedInformer
+// interface.
+func (i *shardedInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	i.SharedInformer.AddEventHandlerWithResyncPeriod(i.filter(handler), resyncPeriod)
+}
+
+// GetStore implements the cache.SharedInformer interface.
+func (i *shardedInformer) GetStore() cache.Store {
+	return shardedStore{Store: i.SharedInformer.GetStore(), opts: i.opts, owned: i.owned}
+}
+
+// ownedByControllerShard returns whether the controller of obj belongs to the
+// shard of opts. Objects without a controller belong to the shard of their own
+// UID and objects without metadata to every shard.
+func ownedByControllerShard(opts *options.Options, obj interface{}) bool {
+	if d, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = d.Obj
+	}
+	o, err := meta.Accessor(obj)
+	if err != nil {
+		return true
+	}
+	for _, ref := range o.GetOwnerReferences() {
+		if ref.Controller != nil && *ref.Controller {
+			return ownedByShard(opts, ref.UID)
+		}
+	}
+	return ownedByShard(opts, o.GetUID())
+}
+
+// newControllerShardedInformer returns inf restricted to the objects whose
+// controller belongs to the shard of opts, so that metrics attributed to the
+// controller, such as the job completions of a cronjob, are only exposed by
+// its shard.
+func newControllerShardedInformer(inf cache.SharedInformer, opts *options.Options) cache.SharedInformer {
+	if opts.TotalShards <= 1 {
+		return inf
+	}
+	return &shardedInformer{SharedInformer: inf, opts: opts, owned: ownedByControllerShard}
+}
+
+// unshardedInformer returns the informer underlying inf, which is the same
+// for all collectors sharing it.
+func unshardedInformer(inf cache.SharedInformer) cache.SharedInformer {
+	if s, ok := inf.(*shardedInformer); ok {
+		return s.SharedInformer
+	}
+	return inf
+}
+
+// shardedStore only lists the objects of the shard of opts.
+type shardedStore struct {
+	cache.Store
+	opts  *options.Options
+	owned func(*options.Options, interface{}) bool
+}
+
+// List implements the cache.Store interface.
+func (s shardedStore) List() []interface{} {
+	var owned []interface{}
+	for _, obj := range s.Store.List() {
+		if s.owned(s.opts, obj) {
+			owned = append(owned, obj)
+		}
+	}
+	return owned
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	batchv1 "k8s.io/api/batch/v1"
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestShardedInformer(t *testing.T) {
+	const totalShards = 3
+
+	var objs []runtime.Object
+	for i := 0; i < 30; i++ {
+		objs = append(objs, &v1.Node{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("node%d", i),
+			UID:  types.UID(fmt.Sprintf("uid%d", i)),
+		}})
+	}
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(objs...), 0)
+
+	var (
+		mu    sync.Mutex
+		added = map[string]int{}
+	)
+	infs := SharedInformerList{}
+	for shard := 0; shard < totalShards; shard++ {
+		opts := &options.Options{Shard: shard, TotalShards: totalShards}
+		inf := newShardedInformer(factory.Core().V1().Nodes().Informer(), opts)
+		inf.AddEventHandler(cache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				mu.Lock()
+				defer mu.Unlock()
+				added[obj.(*v1.Node).Name]++
+			},
+		})
+		infs = append(infs, inf)
+	}
+	// The underlying informer is only started once.
+	stop := make(chan struct{})
+	defer close(stop)
+	infs.Run(stop)
+	if !cache.WaitForCacheSync(stop, infs.HasSynced) {
+		t.Fatal("informers did not sync")
+	}
+
+	owners := map[string]int{}
+	for shard, inf := range infs {
+		listed := inf.GetStore().List()
+		if len(listed) == 0 || len(listed) == len(objs) {
+			t.Errorf("expected shard %d to own some of the nodes, got %d", shard, len(listed))
+		}
+		for _, obj := range listed {
+			owners[obj.(*v1.Node).Name]++
+		}
+	}
+	for _, obj := range objs {
+		name := obj.(*v1.Node).Name
+		if owners[name] != 1 {
+			t.Errorf("expected %s to be listed by exactly one shard, got %d", name, owners[name])
+		}
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	for _, obj := range objs {
+		name := obj.(*v1.Node).Name
+		if added[name] != 1 {
+			t.Errorf("expected %s to be handled by exactly one shard, got %d", name, added[name])
+		}
+	}
+}
+
+func TestOwnedByControllerShard(t *testing.T) {
+	const totalShards = 3
+	controller := true
+	for i := 0; i < 30; i++ {
+		cronJobUID := types.UID(fmt.Sprintf("cronjob%d", i))
+		job := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("job%d", i),
+			UID:  types.UID(fmt.Sprintf("job%d", i)),
+			OwnerReferences: []metav1.OwnerReference{
+				{Kind: "CronJob", UID: cronJobUID, Controller: &controller},
+			},
+		}}
+		for shard := 0; shard < totalShards; shard++ {
+			opts := &options.Options{Shard: shard, TotalShards: totalShards}
+			// Jobs belong to the shard of their cronjob, whatever their
+			// own UID.
+			if got, want := ownedByControllerShard(opts, job), ownedByShard(opts, cronJobUID); got != want {
+				t.Errorf("shard %d: expected %s to be owned %t, got %t", shard, job.Name, want, got)
+			}
+		}
+	}
+
+	orphan := &batchv1.Job{ObjectMeta: metav1.ObjectMeta{Name: "orphan", UID: "orphan"}}
+	for shard := 0; shard < totalShards; shard++ {
+		opts := &options.Options{Shard: shard, TotalShards: totalShards}
+		if got, want := ownedByControllerShard(opts, orphan), ownedByShard(opts, orphan.UID); got != want {
+			t.Errorf("shard %d: expected job without controller to be owned %t, got %t", shard, want, got)
+		}
+	}
+}
+
+func TestSyncTrackerObjectCounts(t *testing.T) {
+	factory := informers.NewSharedInformerFactory(fake.NewSimpleClientset(
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node1", UID: "uid1"}},
+		&v1.Node{ObjectMeta: metav1.ObjectMeta{Name: "node2", UID: "uid2"}},
+		&v1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod1", Namespace: "ns1", UID: "uid3"}},
+	), 0)
+	nodes := factory.Core().V1().Nodes().Informer()
+	pods := factory.Core().V1().Pods().Informer()
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	if !cache.WaitForCacheSync(stop, nodes.HasSynced, pods.HasSynced) {
+		t.Fatal("informers did not sync")
+	}
+
+	total := 0
+	for shard := 0; shard < 2; shard++ {
+		opts := &options.Options{Shard: shard, TotalShards: 2}
+		tracker := NewSyncTracker()
+		tracker.Track("node", SharedInformerList{newShardedInformer(nodes, opts)})
+		// The pods of nodes are not counted as nodes.
+		tracker.TrackDependencies("node", SharedInformerList{pods})
+		total += tracker.ObjectCounts()["node"]
+	}
+	if total != 2 {
+		t.Errorf("expected the shards to own 2 nodes in total, got %d", total)
+	}
+}
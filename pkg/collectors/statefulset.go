@@ -22,7 +22,6 @@ import (
 	"golang.org/x/net/context"
 	"k8s.io/api/apps/v1beta1"
 	"k8s.io/client-go/informers"
-	"k8s.io/client-go/tools/cache"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -31,6 +30,12 @@ var (
 	descStatefulSetLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
 	descStatefulSetLabelsDefaultLabels = []string{"namespace", "statefulset"}
 
+	descStatefulSetInfo = prometheus.NewDesc(
+		"kube_statefulset_info",
+		"Information about statefulset.",
+		append(descStatefulSetLabelsDefaultLabels, "workload_id"),
+		nil,
+	)
 	descStatefulSetCreated = prometheus.NewDesc(
 		"kube_statefulset_created",
 		"Unix creation timestamp",
@@ -73,12 +78,30 @@ var (
 		descStatefulSetLabelsDefaultLabels,
 		nil,
 	)
+	descStatefulSetLastRolloutDuration = prometheus.NewDesc(
+		"kube_statefulset_last_rollout_duration_seconds",
+		"The duration of the last rollout of the statefulset observed by kube-state-metrics, from the generation change until all replicas are updated and ready.",
+		descStatefulSetLabelsDefaultLabels,
+		nil,
+	)
 	descStatefulSetMetadataGeneration = prometheus.NewDesc(
 		"kube_statefulset_metadata_generation",
 		"Sequence number representing a specific generation of the desired state for the StatefulSet.",
 		descStatefulSetLabelsDefaultLabels,
 		nil,
 	)
+	descStatefulSetSpecTemplateResourceRequests = prometheus.NewDesc(
+		"kube_statefulset_spec_template_resource_requests",
+		"The number of requested resource by a container of the pod template.",
+		append(descStatefulSetLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+	descStatefulSetSpecTemplateResourceLimits = prometheus.NewDesc(
+		"kube_statefulset_spec_template_resource_limits",
+		"The number of resource limit of a container of the pod template.",
+		append(descStatefulSetLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
 	descStatefulSetLabels = prometheus.NewDesc(
 		descStatefulSetLabelsName,
 		descStatefulSetLabelsHelp,
@@ -97,6 +120,8 @@ var (
 		append(descStatefulSetLabelsDefaultLabels, "revision"),
 		nil,
 	)
+
+	descStatefulSetSummarizedObjects = newSummarizedObjectsDesc("statefulset")
 )
 
 type StatefulSetLister func() ([]v1beta1.StatefulSet, error)
@@ -109,7 +134,7 @@ func RegisterStatefulSetCollector(registry prometheus.Registerer, informerFactor
 
 	infs := SharedInformerList{}
 	for _, f := range informerFactories {
-		infs = append(infs, f.Apps().V1beta1().StatefulSets().Informer().(cache.SharedInformer))
+		infs = append(infs, newShardedInformer(f.Apps().V1beta1().StatefulSets().Informer(), opts))
 	}
 
 	statefulSetLister := StatefulSetLister(func() (statefulSets []v1beta1.StatefulSet, err error) {
@@ -121,7 +146,14 @@ func RegisterStatefulSetCollector(registry prometheus.Registerer, informerFactor
 		return statefulSets, nil
 	})
 
-	registry.MustRegister(&statefulSetCollector{store: statefulSetLister, opts: opts})
+	rollouts := newRolloutTracker(statefulSetRolledOut)
+	for _, dinf := range infs {
+		dinf.AddEventHandler(rollouts.handler())
+	}
+
+	registry.MustRegister(&statefulSetCollector{store: statefulSetLister, opts: opts, rollouts: rollouts})
+	registerDeletedObjects(registry, infs, "kube_statefulset_deleted", descStatefulSetLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("statefulset", infs)
 	infs.Run(context.Background().Done())
 }
 
@@ -130,12 +162,32 @@ type statefulSetStore interface {
 }
 
 type statefulSetCollector struct {
-	store statefulSetStore
-	opts  *options.Options
+	store    statefulSetStore
+	opts     *options.Options
+	rollouts *rolloutTracker
+}
+
+// statefulSetRolledOut reports whether the current generation of a
+// statefulset has been rolled out, that is observed by the controller and all
+// replicas are updated and ready.
+func statefulSetRolledOut(obj interface{}) (namespace, name string, generation int64, done, ok bool) {
+	s, ok := obj.(*v1beta1.StatefulSet)
+	if !ok {
+		return "", "", 0, false, false
+	}
+	replicas := int32(1)
+	if s.Spec.Replicas != nil {
+		replicas = *s.Spec.Replicas
+	}
+	done = s.Status.ObservedGeneration != nil && *s.Status.ObservedGeneration >= s.Generation &&
+		s.Status.UpdatedReplicas == replicas &&
+		s.Status.ReadyReplicas == replicas
+	return s.Namespace, s.Name, s.Generation, done, true
 }
 
 // Describe implements the prometheus.Collector interface.
 func (dc *statefulSetCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descStatefulSetInfo
 	ch <- descStatefulSetCreated
 	ch <- descStatefulSetStatusReplicas
 	ch <- descStatefulSetStatusReplicasCurrent
@@ -144,25 +196,35 @@ func (dc *statefulSetCollector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- descStatefulSetStatusObservedGeneration
 	ch <- descStatefulSetSpecReplicas
 	ch <- descStatefulSetMetadataGeneration
+	ch <- descStatefulSetSpecTemplateResourceRequests
+	ch <- descStatefulSetSpecTemplateResourceLimits
+	ch <- descStatefulSetLastRolloutDuration
 	ch <- descStatefulSetLabels
 	ch <- descStatefulSetCurrentRevision
 	ch <- descStatefulSetUpdateRevision
+	ch <- descStatefulSetSummarizedObjects
 }
 
 // Collect implements the prometheus.Collector interface.
 func (sc *statefulSetCollector) Collect(ch chan<- prometheus.Metric) {
 	sss, err := sc.store.List()
 	if err != nil {
-		ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "statefulset"}).Inc()
+		addScrapeError(ch, "statefulset", err)
 		glog.Errorf("listing statefulsets failed: %s", err)
 		return
 	}
 	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "statefulset"}).Add(0)
 
 	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "statefulset"}).Observe(float64(len(sss)))
+	summarized := map[string]int{}
 	for _, d := range sss {
+		if !detailed(sc.opts, &d.ObjectMeta) {
+			summarized[d.Namespace]++
+			continue
+		}
 		sc.collectStatefulSet(ch, d)
 	}
+	addSummarizedObjects(ch, descStatefulSetSummarizedObjects, summarized)
 
 	glog.V(4).Infof("collected %d statefulsets", len(sss))
 }
@@ -177,10 +239,13 @@ func statefulSetLabelsDesc(labelKeys []string) *prometheus.Desc {
 }
 
 func (dc *statefulSetCollector) collectStatefulSet(ch chan<- prometheus.Metric, statefulSet v1beta1.StatefulSet) {
+	defer recoverObjectError("statefulset", &statefulSet.ObjectMeta)
+
 	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
 		lv = append([]string{statefulSet.Namespace, statefulSet.Name}, lv...)
-		ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
 	}
+	addGauge(descStatefulSetInfo, 1, workloadID(statefulSet.Namespace, "StatefulSet", statefulSet.Name))
 	if !statefulSet.CreationTimestamp.IsZero() {
 		addGauge(descStatefulSetCreated, float64(statefulSet.CreationTimestamp.Unix()))
 	}
@@ -196,8 +261,16 @@ func (dc *statefulSetCollector) collectStatefulSet(ch chan<- prometheus.Metric,
 		addGauge(descStatefulSetSpecReplicas, float64(*statefulSet.Spec.Replicas))
 	}
 	addGauge(descStatefulSetMetadataGeneration, float64(statefulSet.ObjectMeta.Generation))
+	addPodTemplateResourceMetrics(ch, descStatefulSetSpecTemplateResourceRequests, descStatefulSetSpecTemplateResourceLimits,
+		statefulSet.Spec.Template.Spec, statefulSet.Namespace, statefulSet.Name)
+
+	if dc.rollouts != nil {
+		if duration, ok := dc.rollouts.lastDuration(statefulSet.Namespace, statefulSet.Name); ok {
+			addGauge(descStatefulSetLastRolloutDuration, duration.Seconds())
+		}
+	}
 
-	labelKeys, labelValues := kubeLabelsToPrometheusLabels(statefulSet.Labels)
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(statefulSet.Labels, dc.opts.MaxLabelValueLength)
 	addGauge(statefulSetLabelsDesc(labelKeys), 1, labelValues...)
 
 	addGauge(descStatefulSetCurrentRevision, 1, statefulSet.Status.CurrentRevision)
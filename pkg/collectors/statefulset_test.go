@@ -21,9 +21,11 @@ import (
 	"time"
 
 	"k8s.io/api/apps/v1beta1"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/kube-state-metrics/pkg/collectors/testutils"
 	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
 )
 
 var (
@@ -47,6 +49,8 @@ func TestStatefuleSetCollector(t *testing.T) {
 	// Fixed metadata on type and help text. We prepend this to every expected
 	// output so we only have to modify a single place when doing adjustments.
 	const metadata = `
+		# HELP kube_statefulset_info Information about statefulset.
+		# TYPE kube_statefulset_info gauge
 		# HELP kube_statefulset_created Unix creation timestamp
 		# TYPE kube_statefulset_created gauge
 		# HELP kube_statefulset_status_current_revision Indicates the version of the StatefulSet used to generate Pods in the sequence [0,currentReplicas).
@@ -69,6 +73,10 @@ func TestStatefuleSetCollector(t *testing.T) {
  		# TYPE kube_statefulset_metadata_generation gauge
 		# HELP kube_statefulset_labels Kubernetes labels converted to Prometheus labels.
 		# TYPE kube_statefulset_labels gauge
+		# HELP kube_statefulset_spec_template_resource_requests The number of requested resource by a container of the pod template.
+		# TYPE kube_statefulset_spec_template_resource_requests gauge
+		# HELP kube_statefulset_spec_template_resource_limits The number of resource limit of a container of the pod template.
+		# TYPE kube_statefulset_spec_template_resource_limits gauge
  	`
 	cases := []struct {
 		depls []v1beta1.StatefulSet
@@ -89,6 +97,24 @@ func TestStatefuleSetCollector(t *testing.T) {
 					Spec: v1beta1.StatefulSetSpec{
 						Replicas:    &statefulSet1Replicas,
 						ServiceName: "statefulset1service",
+						Template: v1.PodTemplateSpec{
+							Spec: v1.PodSpec{
+								Containers: []v1.Container{
+									{
+										Name: "trainer",
+										Resources: v1.ResourceRequirements{
+											Requests: v1.ResourceList{
+												v1.ResourceCPU:                    resource.MustParse("2"),
+												v1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+											},
+											Limits: v1.ResourceList{
+												v1.ResourceName("nvidia.com/gpu"): resource.MustParse("1"),
+											},
+										},
+									},
+								},
+							},
+						},
 					},
 					Status: v1beta1.StatefulSetStatus{
 						ObservedGeneration: &statefulSet1ObservedGeneration,
@@ -140,6 +166,9 @@ func TestStatefuleSetCollector(t *testing.T) {
 				},
 			},
 			want: metadata + `
+				kube_statefulset_info{namespace="ns1",statefulset="statefulset1",workload_id="e4695e96d12c1d0c"} 1
+				kube_statefulset_info{namespace="ns2",statefulset="statefulset2",workload_id="bf1419840ecb45ba"} 1
+				kube_statefulset_info{namespace="ns3",statefulset="statefulset3",workload_id="ccae903f42cc25c4"} 1
 				kube_statefulset_created{namespace="ns1",statefulset="statefulset1"} 1.5e+09
 				kube_statefulset_status_current_revision{namespace="ns1",revision="cr1",statefulset="statefulset1"} 1
 				kube_statefulset_status_current_revision{namespace="ns2",revision="cr2",statefulset="statefulset2"} 1
@@ -170,6 +199,9 @@ func TestStatefuleSetCollector(t *testing.T) {
 				kube_statefulset_labels{label_app="example1",namespace="ns1",statefulset="statefulset1"} 1
 				kube_statefulset_labels{label_app="example2",namespace="ns2",statefulset="statefulset2"} 1
 				kube_statefulset_labels{label_app="example3",namespace="ns3",statefulset="statefulset3"} 1
+				kube_statefulset_spec_template_resource_requests{container="trainer",namespace="ns1",resource="cpu",statefulset="statefulset1",unit="core"} 2
+				kube_statefulset_spec_template_resource_requests{container="trainer",namespace="ns1",resource="nvidia_com_gpu",statefulset="statefulset1",unit="integer"} 1
+				kube_statefulset_spec_template_resource_limits{container="trainer",namespace="ns1",resource="nvidia_com_gpu",statefulset="statefulset1",unit="integer"} 1
  			`,
 		},
 	}
@@ -185,3 +217,56 @@ func TestStatefuleSetCollector(t *testing.T) {
 		}
 	}
 }
+
+func TestStatefulSetRolloutDuration(t *testing.T) {
+	const metadata = `
+		# HELP kube_statefulset_last_rollout_duration_seconds The duration of the last rollout of the statefulset observed by kube-state-metrics, from the generation change until all replicas are updated and ready.
+		# TYPE kube_statefulset_last_rollout_duration_seconds gauge
+	`
+	replicas := int32(3)
+	statefulSet := func(generation, observedGeneration int64, ready int32) *v1beta1.StatefulSet {
+		return &v1beta1.StatefulSet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "statefulset1",
+				Namespace:  "ns1",
+				Generation: generation,
+			},
+			Spec: v1beta1.StatefulSetSpec{
+				Replicas: &replicas,
+			},
+			Status: v1beta1.StatefulSetStatus{
+				ObservedGeneration: &observedGeneration,
+				UpdatedReplicas:    replicas,
+				ReadyReplicas:      ready,
+			},
+		}
+	}
+
+	now := time.Unix(1500000000, 0)
+	rollouts := newRolloutTracker(statefulSetRolledOut)
+	rollouts.now = func() time.Time { return now }
+	h := rollouts.handler()
+
+	h.OnAdd(statefulSet(1, 1, 3))
+	h.OnUpdate(statefulSet(1, 1, 3), statefulSet(2, 1, 3))
+	now = now.Add(45 * time.Second)
+	h.OnUpdate(statefulSet(2, 1, 3), statefulSet(2, 2, 2))
+	now = now.Add(15 * time.Second)
+	h.OnUpdate(statefulSet(2, 2, 2), statefulSet(2, 2, 3))
+
+	sc := &statefulSetCollector{
+		store: mockStatefulSetStore{
+			f: func() ([]v1beta1.StatefulSet, error) {
+				return []v1beta1.StatefulSet{*statefulSet(2, 2, 3)}, nil
+			},
+		},
+		opts:     &options.Options{},
+		rollouts: rollouts,
+	}
+	want := metadata + `
+		kube_statefulset_last_rollout_duration_seconds{namespace="ns1",statefulset="statefulset1"} 60
+	`
+	if err := testutils.GatherAndCompare(sc, want, []string{"kube_statefulset_last_rollout_duration_seconds"}); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
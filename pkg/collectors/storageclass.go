@@ -0,0 +1,220 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descStorageClassLabelsName          = "kube_storageclass_labels"
+	descStorageClassLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descStorageClassLabelsDefaultLabels = []string{"storageclass"}
+
+	descStorageClassInfo = prometheus.NewDesc(
+		"kube_storageclass_info",
+		"Information about storageclass.",
+		append(descStorageClassLabelsDefaultLabels, "provisioner", "reclaimPolicy", "volumeBindingMode"),
+		nil,
+	)
+	descStorageClassCreated = prometheus.NewDesc(
+		"kube_storageclass_created",
+		"Unix creation timestamp",
+		descStorageClassLabelsDefaultLabels,
+		nil,
+	)
+	descStorageClassLabels = prometheus.NewDesc(
+		descStorageClassLabelsName,
+		descStorageClassLabelsHelp,
+		descStorageClassLabelsDefaultLabels,
+		nil,
+	)
+	descStorageClassIsDefault = prometheus.NewDesc(
+		"kube_storageclass_is_default",
+		"Whether the storageclass is marked as the default storageclass.",
+		descStorageClassLabelsDefaultLabels,
+		nil,
+	)
+	descStorageClassDefaults = prometheus.NewDesc(
+		"kube_storageclass_defaults",
+		"The number of storageclasses marked as the default storageclass. Anything but 1 means that claims without a storageclass are either not provisioned or provisioned by an arbitrary one of them.",
+		nil,
+		nil,
+	)
+
+	// isDefaultStorageClassAnnotations mark a storageclass as the default,
+	// the beta annotation is still honored by the API server.
+	isDefaultStorageClassAnnotations = []string{
+		"storageclass.kubernetes.io/is-default-class",
+		"storageclass.beta.kubernetes.io/is-default-class",
+	}
+
+	// The API server defaults these on creation, but objects created by
+	// older versions may lack them.
+	defaultReclaimPolicy     = v1.PersistentVolumeReclaimDelete
+	defaultVolumeBindingMode = storagev1.VolumeBindingImmediate
+)
+
+type StorageClassLister func() ([]storagev1.StorageClass, error)
+
+func (l StorageClassLister) List() ([]storagev1.StorageClass, error) {
+	return l()
+}
+
+func RegisterStorageClassCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Storage().V1().StorageClasses().Informer(), opts))
+	}
+
+	storageClassLister := StorageClassLister(func() (storageClasses []storagev1.StorageClass, err error) {
+		for _, scinf := range infs {
+			for _, sc := range scinf.GetStore().List() {
+				storageClasses = append(storageClasses, *(sc.(*storagev1.StorageClass)))
+			}
+		}
+		return storageClasses, nil
+	})
+
+	// The default storageclasses are counted among those of all shards.
+	allStorageClassLister := StorageClassLister(func() (storageClasses []storagev1.StorageClass, err error) {
+		for _, scinf := range infs {
+			for _, sc := range unshardedInformer(scinf).GetStore().List() {
+				storageClasses = append(storageClasses, *(sc.(*storagev1.StorageClass)))
+			}
+		}
+		return storageClasses, nil
+	})
+
+	registry.MustRegister(&storageClassCollector{store: storageClassLister, allStorageClasses: allStorageClassLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_storageclass_deleted", descStorageClassLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("storageclass", infs)
+	infs.Run(context.Background().Done())
+}
+
+type storageClassStore interface {
+	List() (storageClasses []storagev1.StorageClass, err error)
+}
+
+// storageClassCollector collects metrics about all storageClasses in the cluster.
+type storageClassCollector struct {
+	store             storageClassStore
+	allStorageClasses storageClassStore
+	opts              *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (scc *storageClassCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descStorageClassInfo
+	ch <- descStorageClassCreated
+	ch <- descStorageClassLabels
+	ch <- descStorageClassIsDefault
+	ch <- descStorageClassDefaults
+}
+
+// Collect implements the prometheus.Collector interface.
+func (scc *storageClassCollector) Collect(ch chan<- prometheus.Metric) {
+	storageClasses, err := scc.store.List()
+	if err != nil {
+		addScrapeError(ch, "storageclass", err)
+		glog.Errorf("listing storageclasses failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "storageclass"}).Add(0)
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "storageclass"}).Observe(float64(len(storageClasses)))
+	for _, sc := range storageClasses {
+		scc.collectStorageClass(ch, sc)
+	}
+	scc.collectDefaults(ch)
+
+	glog.V(4).Infof("collected %d storageclasses", len(storageClasses))
+}
+
+// collectDefaults exposes the number of default storageclasses in the
+// cluster. It describes the cluster rather than a storageclass, so only the
+// first shard exposes it.
+func (scc *storageClassCollector) collectDefaults(ch chan<- prometheus.Metric) {
+	if scc.opts.Shard != 0 {
+		return
+	}
+	storageClasses, err := scc.allStorageClasses.List()
+	if err != nil {
+		addScrapeError(ch, "storageclass", err)
+		glog.Errorf("listing storageclasses failed: %s", err)
+		return
+	}
+	defaults := 0
+	for _, sc := range storageClasses {
+		if isDefaultStorageClass(sc) {
+			defaults++
+		}
+	}
+	ch <- mustNewConstMetric(descStorageClassDefaults, prometheus.GaugeValue, float64(defaults))
+}
+
+func isDefaultStorageClass(sc storagev1.StorageClass) bool {
+	for _, a := range isDefaultStorageClassAnnotations {
+		if sc.Annotations[a] == "true" {
+			return true
+		}
+	}
+	return false
+}
+
+func storageClassLabelsDesc(labelKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descStorageClassLabelsName,
+		descStorageClassLabelsHelp,
+		append(descStorageClassLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func (scc *storageClassCollector) collectStorageClass(ch chan<- prometheus.Metric, sc storagev1.StorageClass) {
+	defer recoverObjectError("storageclass", &sc.ObjectMeta)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{sc.Name}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	reclaimPolicy := defaultReclaimPolicy
+	if sc.ReclaimPolicy != nil {
+		reclaimPolicy = *sc.ReclaimPolicy
+	}
+	volumeBindingMode := defaultVolumeBindingMode
+	if sc.VolumeBindingMode != nil {
+		volumeBindingMode = *sc.VolumeBindingMode
+	}
+	addGauge(descStorageClassInfo, 1, sc.Provisioner, string(reclaimPolicy), string(volumeBindingMode))
+
+	if !sc.CreationTimestamp.IsZero() {
+		addGauge(descStorageClassCreated, float64(sc.CreationTimestamp.Unix()))
+	}
+
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(sc.Labels, scc.opts.MaxLabelValueLength)
+	addGauge(storageClassLabelsDesc(labelKeys), 1, labelValues...)
+	addGauge(descStorageClassIsDefault, boolFloat64(isDefaultStorageClass(sc)))
+}
@@ -0,0 +1,186 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/api/core/v1"
+	storagev1 "k8s.io/api/storage/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockStorageClassStore struct {
+	list func() ([]storagev1.StorageClass, error)
+}
+
+func (ss mockStorageClassStore) List() ([]storagev1.StorageClass, error) {
+	return ss.list()
+}
+
+func TestStorageClassCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+
+	reclaimRetain := v1.PersistentVolumeReclaimRetain
+	waitForFirstConsumer := storagev1.VolumeBindingWaitForFirstConsumer
+
+	const metadata = `
+		# HELP kube_storageclass_info Information about storageclass.
+		# TYPE kube_storageclass_info gauge
+		# HELP kube_storageclass_created Unix creation timestamp
+		# TYPE kube_storageclass_created gauge
+		# HELP kube_storageclass_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_storageclass_labels gauge
+		# HELP kube_storageclass_is_default Whether the storageclass is marked as the default storageclass.
+		# TYPE kube_storageclass_is_default gauge
+		# HELP kube_storageclass_defaults The number of storageclasses marked as the default storageclass. Anything but 1 means that claims without a storageclass are either not provisioned or provisioned by an arbitrary one of them.
+		# TYPE kube_storageclass_defaults gauge
+	`
+	cases := []struct {
+		storageClasses []storagev1.StorageClass
+		want           string
+		metrics        []string
+	}{
+		{
+			storageClasses: []storagev1.StorageClass{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "standard",
+						Annotations: map[string]string{
+							"storageclass.kubernetes.io/is-default-class": "true",
+						},
+					},
+					Provisioner: "kubernetes.io/gce-pd",
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "local",
+						CreationTimestamp: metav1StartTime,
+						Labels: map[string]string{
+							"tier": "fast",
+						},
+					},
+					Provisioner:       "kubernetes.io/no-provisioner",
+					ReclaimPolicy:     &reclaimRetain,
+					VolumeBindingMode: &waitForFirstConsumer,
+				},
+			},
+			want: metadata + `
+				kube_storageclass_info{provisioner="kubernetes.io/gce-pd",reclaimPolicy="Delete",storageclass="standard",volumeBindingMode="Immediate"} 1
+				kube_storageclass_info{provisioner="kubernetes.io/no-provisioner",reclaimPolicy="Retain",storageclass="local",volumeBindingMode="WaitForFirstConsumer"} 1
+				kube_storageclass_created{storageclass="local"} 1.501569018e+09
+				kube_storageclass_labels{storageclass="standard"} 1
+				kube_storageclass_labels{label_tier="fast",storageclass="local"} 1
+				kube_storageclass_is_default{storageclass="standard"} 1
+				kube_storageclass_is_default{storageclass="local"} 0
+				kube_storageclass_defaults 1
+			`,
+		},
+		{
+			storageClasses: []storagev1.StorageClass{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "standard",
+						Annotations: map[string]string{
+							"storageclass.beta.kubernetes.io/is-default-class": "true",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "fast",
+						Annotations: map[string]string{
+							"storageclass.kubernetes.io/is-default-class": "true",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "slow",
+						Annotations: map[string]string{
+							"storageclass.kubernetes.io/is-default-class": "false",
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_storageclass_is_default{storageclass="standard"} 1
+				kube_storageclass_is_default{storageclass="fast"} 1
+				kube_storageclass_is_default{storageclass="slow"} 0
+				kube_storageclass_defaults 2
+			`,
+			metrics: []string{"kube_storageclass_is_default", "kube_storageclass_defaults"},
+		},
+		{
+			want: metadata + `
+				kube_storageclass_defaults 0
+			`,
+			metrics: []string{"kube_storageclass_defaults"},
+		},
+	}
+	for _, c := range cases {
+		store := mockStorageClassStore{
+			list: func() ([]storagev1.StorageClass, error) { return c.storageClasses, nil },
+		}
+		scc := &storageClassCollector{store: store, allStorageClasses: store, opts: &options.Options{}}
+		if err := testutils.GatherAndCompare(scc, c.want, c.metrics); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
+
+func TestStorageClassCollectorDefaultsWithShards(t *testing.T) {
+	const metadata = `
+		# HELP kube_storageclass_defaults The number of storageclasses marked as the default storageclass. Anything but 1 means that claims without a storageclass are either not provisioned or provisioned by an arbitrary one of them.
+		# TYPE kube_storageclass_defaults gauge
+	`
+	standard := storagev1.StorageClass{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "standard",
+			Annotations: map[string]string{"storageclass.kubernetes.io/is-default-class": "true"},
+		},
+	}
+	store := mockStorageClassStore{
+		list: func() ([]storagev1.StorageClass, error) { return nil, nil },
+	}
+	allStorageClasses := mockStorageClassStore{
+		list: func() ([]storagev1.StorageClass, error) { return []storagev1.StorageClass{standard}, nil },
+	}
+
+	cases := []struct {
+		shard int
+		want  string
+	}{
+		// The first shard counts the default storageclasses of all shards.
+		{shard: 0, want: metadata + `
+			kube_storageclass_defaults 1
+		`},
+		// The other shards don't expose the count at all.
+		{shard: 1, want: ""},
+	}
+	for _, c := range cases {
+		scc := &storageClassCollector{store: store, allStorageClasses: allStorageClasses, opts: &options.Options{Shard: c.shard, TotalShards: 2}}
+		if err := testutils.GatherAndCompare(scc, c.want, []string{"kube_storageclass_defaults"}); err != nil {
+			t.Errorf("shard %d: unexpected collecting result:\n%s", c.shard, err)
+		}
+	}
+}
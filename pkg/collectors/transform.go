@@ -0,0 +1,693 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"time"
+
+	"github.com/ghodss/yaml"
+	admissionregistrationv1beta1 "k8s.io/api/admissionregistration/v1beta1"
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	autoscalingv2beta1 "k8s.io/api/autoscaling/v2beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv1beta1 "k8s.io/api/batch/v1beta1"
+	certificatesv1beta1 "k8s.io/api/certificates/v1beta1"
+	"k8s.io/api/core/v1"
+	"k8s.io/api/extensions/v1beta1"
+	policyv1beta1 "k8s.io/api/policy/v1beta1"
+	storagev1 "k8s.io/api/storage/v1"
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// InformerTransformConfig is the file format of --informer-transform-config.
+type InformerTransformConfig struct {
+	// Resources maps collector resource names, e.g. pods, to the transform
+	// applied to their objects.
+	Resources map[string]InformerTransform `json:"resources"`
+}
+
+// InformerTransform describes what is stripped from the objects of a resource
+// before they are stored by informers. Nothing stripped is ever exposed.
+type InformerTransform struct {
+	// DropAnnotations lists annotation keys which are removed.
+	DropAnnotations []string `json:"dropAnnotations"`
+	// DropUnrequestedAnnotations removes all annotations except the ones
+	// listed in KeepAnnotations.
+	DropUnrequestedAnnotations bool `json:"dropUnrequestedAnnotations"`
+	// KeepAnnotations lists annotation keys which are kept by
+	// DropUnrequestedAnnotations.
+	KeepAnnotations []string `json:"keepAnnotations"`
+	// MaxAnnotationValueLength removes annotations whose values are longer
+	// than the given number of bytes. Zero means no limit.
+	MaxAnnotationValueLength int `json:"maxAnnotationValueLength"`
+	// DropContainerDetails removes the environment, command and arguments of
+	// the containers of pods and pod templates.
+	DropContainerDetails bool `json:"dropContainerDetails"`
+	// DropData removes the data of configmaps and secrets.
+	DropData bool `json:"dropData"`
+}
+
+// transformableResource is a resource whose informer can be replaced with one
+// transforming its objects.
+type transformableResource struct {
+	obj       runtime.Object
+	listWatch func(c clientset.Interface, namespace string) *cache.ListWatch
+	// podSpecs returns the pod specs of an object, if any.
+	podSpecs func(obj runtime.Object) []*v1.PodSpec
+	// dropData removes the data of an object, if any.
+	dropData func(obj runtime.Object)
+	// annotations lists the annotation keys the collector of the resource
+	// derives metrics from.
+	annotations []string
+	// allAnnotations is set if the collector exposes all annotations.
+	allAnnotations bool
+	// whitelistedAnnotations is set if the collector exposes the annotations
+	// of --annotation-whitelist.
+	whitelistedAnnotations bool
+}
+
+var transformableResources = map[string]transformableResource{
+	"pods": {
+		obj: &v1.Pod{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.CoreV1().Pods(ns).List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.CoreV1().Pods(ns).Watch(o) },
+			}
+		},
+		podSpecs:    func(obj runtime.Object) []*v1.PodSpec { return []*v1.PodSpec{&obj.(*v1.Pod).Spec} },
+		annotations: []string{podMirrorAnnotation, podConfigSourceAnnotation},
+	},
+	"configmaps": {
+		obj: &v1.ConfigMap{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.CoreV1().ConfigMaps(ns).List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.CoreV1().ConfigMaps(ns).Watch(o) },
+			}
+		},
+		dropData: func(obj runtime.Object) {
+			cm := obj.(*v1.ConfigMap)
+			cm.Data = nil
+			cm.BinaryData = nil
+		},
+	},
+	"secrets": {
+		obj: &v1.Secret{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.CoreV1().Secrets(ns).List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.CoreV1().Secrets(ns).Watch(o) },
+			}
+		},
+		dropData: func(obj runtime.Object) {
+			s := obj.(*v1.Secret)
+			s.Data = nil
+			s.StringData = nil
+		},
+		annotations: []string{v1.ServiceAccountNameKey},
+	},
+	"services": {
+		obj: &v1.Service{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.CoreV1().Services(ns).List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.CoreV1().Services(ns).Watch(o) },
+			}
+		},
+	},
+	"nodes": {
+		obj: &v1.Node{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.CoreV1().Nodes().List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.CoreV1().Nodes().Watch(o) },
+			}
+		},
+	},
+	"namespaces": {
+		obj: &v1.Namespace{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.CoreV1().Namespaces().List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.CoreV1().Namespaces().Watch(o) },
+			}
+		},
+		allAnnotations: true,
+	},
+	"deployments": {
+		obj: &v1beta1.Deployment{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.ExtensionsV1beta1().Deployments(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.ExtensionsV1beta1().Deployments(ns).Watch(o)
+				},
+			}
+		},
+		podSpecs: func(obj runtime.Object) []*v1.PodSpec {
+			return []*v1.PodSpec{&obj.(*v1beta1.Deployment).Spec.Template.Spec}
+		},
+	},
+	"replicasets": {
+		obj: &v1beta1.ReplicaSet{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.ExtensionsV1beta1().ReplicaSets(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.ExtensionsV1beta1().ReplicaSets(ns).Watch(o)
+				},
+			}
+		},
+		podSpecs: func(obj runtime.Object) []*v1.PodSpec {
+			return []*v1.PodSpec{&obj.(*v1beta1.ReplicaSet).Spec.Template.Spec}
+		},
+	},
+	"daemonsets": {
+		obj: &v1beta1.DaemonSet{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.ExtensionsV1beta1().DaemonSets(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.ExtensionsV1beta1().DaemonSets(ns).Watch(o)
+				},
+			}
+		},
+		podSpecs: func(obj runtime.Object) []*v1.PodSpec {
+			return []*v1.PodSpec{&obj.(*v1beta1.DaemonSet).Spec.Template.Spec}
+		},
+		whitelistedAnnotations: true,
+	},
+	"statefulsets": {
+		obj: &appsv1beta1.StatefulSet{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.AppsV1beta1().StatefulSets(ns).List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.AppsV1beta1().StatefulSets(ns).Watch(o) },
+			}
+		},
+		podSpecs: func(obj runtime.Object) []*v1.PodSpec {
+			return []*v1.PodSpec{&obj.(*appsv1beta1.StatefulSet).Spec.Template.Spec}
+		},
+	},
+	"jobs": {
+		obj: &batchv1.Job{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc:  func(o metav1.ListOptions) (runtime.Object, error) { return c.BatchV1().Jobs(ns).List(o) },
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) { return c.BatchV1().Jobs(ns).Watch(o) },
+			}
+		},
+		podSpecs: func(obj runtime.Object) []*v1.PodSpec {
+			return []*v1.PodSpec{&obj.(*batchv1.Job).Spec.Template.Spec}
+		},
+	},
+	"cronjobs": {
+		obj: &batchv1beta1.CronJob{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.BatchV1beta1().CronJobs(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.BatchV1beta1().CronJobs(ns).Watch(o)
+				},
+			}
+		},
+		podSpecs: func(obj runtime.Object) []*v1.PodSpec {
+			return []*v1.PodSpec{&obj.(*batchv1beta1.CronJob).Spec.JobTemplate.Spec.Template.Spec}
+		},
+	},
+	"replicationcontrollers": {
+		obj: &v1.ReplicationController{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CoreV1().ReplicationControllers(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CoreV1().ReplicationControllers(ns).Watch(o)
+				},
+			}
+		},
+		podSpecs: func(obj runtime.Object) []*v1.PodSpec {
+			if t := obj.(*v1.ReplicationController).Spec.Template; t != nil {
+				return []*v1.PodSpec{&t.Spec}
+			}
+			return nil
+		},
+	},
+	"limitranges": {
+		obj: &v1.LimitRange{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CoreV1().LimitRanges(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CoreV1().LimitRanges(ns).Watch(o)
+				},
+			}
+		},
+		whitelistedAnnotations: true,
+	},
+	"resourcequotas": {
+		obj: &v1.ResourceQuota{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CoreV1().ResourceQuotas(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CoreV1().ResourceQuotas(ns).Watch(o)
+				},
+			}
+		},
+		whitelistedAnnotations: true,
+	},
+	"endpoints": {
+		obj: &v1.Endpoints{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CoreV1().Endpoints(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CoreV1().Endpoints(ns).Watch(o)
+				},
+			}
+		},
+	},
+	"events": {
+		obj: &v1.Event{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CoreV1().Events(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CoreV1().Events(ns).Watch(o)
+				},
+			}
+		},
+	},
+	"persistentvolumes": {
+		obj: &v1.PersistentVolume{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CoreV1().PersistentVolumes().List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CoreV1().PersistentVolumes().Watch(o)
+				},
+			}
+		},
+	},
+	"persistentvolumeclaims": {
+		obj: &v1.PersistentVolumeClaim{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CoreV1().PersistentVolumeClaims(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CoreV1().PersistentVolumeClaims(ns).Watch(o)
+				},
+			}
+		},
+		annotations: []string{v1.BetaStorageClassAnnotation, storageProvisionerAnnotation, betaStorageProvisionerAnnotation},
+	},
+	"horizontalpodautoscalers": {
+		obj: &autoscalingv2beta1.HorizontalPodAutoscaler{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.AutoscalingV2beta1().HorizontalPodAutoscalers(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.AutoscalingV2beta1().HorizontalPodAutoscalers(ns).Watch(o)
+				},
+			}
+		},
+	},
+	"certificatesigningrequests": {
+		obj: &certificatesv1beta1.CertificateSigningRequest{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.CertificatesV1beta1().CertificateSigningRequests().List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.CertificatesV1beta1().CertificateSigningRequests().Watch(o)
+				},
+			}
+		},
+	},
+	"poddisruptionbudgets": {
+		obj: &policyv1beta1.PodDisruptionBudget{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.PolicyV1beta1().PodDisruptionBudgets(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.PolicyV1beta1().PodDisruptionBudgets(ns).Watch(o)
+				},
+			}
+		},
+	},
+	"podsecuritypolicies": {
+		obj: &policyv1beta1.PodSecurityPolicy{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.PolicyV1beta1().PodSecurityPolicies().List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.PolicyV1beta1().PodSecurityPolicies().Watch(o)
+				},
+			}
+		},
+	},
+	"ingresses": {
+		obj: &v1beta1.Ingress{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.ExtensionsV1beta1().Ingresses(ns).List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.ExtensionsV1beta1().Ingresses(ns).Watch(o)
+				},
+			}
+		},
+	},
+	"storageclasses": {
+		obj: &storagev1.StorageClass{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.StorageV1().StorageClasses().List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.StorageV1().StorageClasses().Watch(o)
+				},
+			}
+		},
+		annotations: isDefaultStorageClassAnnotations,
+	},
+	"volumeattachments": {
+		obj: &storagev1beta1.VolumeAttachment{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.StorageV1beta1().VolumeAttachments().List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.StorageV1beta1().VolumeAttachments().Watch(o)
+				},
+			}
+		},
+	},
+	"mutatingwebhookconfigurations": {
+		obj: &admissionregistrationv1beta1.MutatingWebhookConfiguration{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.AdmissionregistrationV1beta1().MutatingWebhookConfigurations().Watch(o)
+				},
+			}
+		},
+	},
+	"validatingwebhookconfigurations": {
+		obj: &admissionregistrationv1beta1.ValidatingWebhookConfiguration{},
+		listWatch: func(c clientset.Interface, ns string) *cache.ListWatch {
+			return &cache.ListWatch{
+				ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+					return c.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().List(o)
+				},
+				WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+					return c.AdmissionregistrationV1beta1().ValidatingWebhookConfigurations().Watch(o)
+				},
+			}
+		},
+	},
+}
+
+// LoadInformerTransformConfig reads and validates the informer transforms of
+// the given YAML file.
+func LoadInformerTransformConfig(path string) (*InformerTransformConfig, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseInformerTransformConfig(b)
+}
+
+// ParseInformerTransformConfig parses and validates YAML informer transforms.
+func ParseInformerTransformConfig(b []byte) (*InformerTransformConfig, error) {
+	var c InformerTransformConfig
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+	for _, resource := range sortedTransformResources(c.Resources) {
+		t := c.Resources[resource]
+		r, ok := transformableResources[resource]
+		if !ok {
+			return nil, fmt.Errorf("resource %q: transforms are not supported", resource)
+		}
+		if t.MaxAnnotationValueLength < 0 {
+			return nil, fmt.Errorf("resource %q: negative maxAnnotationValueLength", resource)
+		}
+		if t.DropContainerDetails && r.podSpecs == nil {
+			return nil, fmt.Errorf("resource %q: dropContainerDetails is only supported for resources with pod specs", resource)
+		}
+		if t.DropData && r.dropData == nil {
+			return nil, fmt.Errorf("resource %q: dropData is only supported for configmaps and secrets", resource)
+		}
+	}
+	return &c, nil
+}
+
+// WithUnrequestedAnnotationsDropped returns a copy of c additionally dropping
+// the kubectl.kubernetes.io/last-applied-configuration annotation, a copy of
+// the whole object, and all annotations no metric is derived from from the
+// objects of all resources. Annotations exposed because of the given
+// whitelist are kept. c may be nil.
+func (c *InformerTransformConfig) WithUnrequestedAnnotationsDropped(whitelist options.AnnotationSet) *InformerTransformConfig {
+	out := &InformerTransformConfig{Resources: map[string]InformerTransform{}}
+	for resource, r := range transformableResources {
+		t, _ := c.resource(resource)
+		t.DropAnnotations = append(append([]string{}, t.DropAnnotations...), v1.LastAppliedConfigAnnotation)
+		if !r.allAnnotations && !(r.whitelistedAnnotations && whitelist.Has("*")) {
+			keep := append(append([]string{}, t.KeepAnnotations...), r.annotations...)
+			if r.whitelistedAnnotations {
+				for key := range whitelist {
+					keep = append(keep, key)
+				}
+			}
+			t.DropUnrequestedAnnotations = true
+			t.KeepAnnotations = keep
+		}
+		out.Resources[resource] = t
+	}
+	return out
+}
+
+// RegisterTransformingInformers registers informers applying the configured
+// transforms with the given factory, watching the given namespace. It has to
+// be called before collectors get their informers from the factory, as the
+// first informer registered for a type is the one shared. If tweak is not nil,
+// it is applied to the list options like the tweak of the factory.
+func RegisterTransformingInformers(factory informers.SharedInformerFactory, client clientset.Interface, namespace string, config *InformerTransformConfig, tweak func(*metav1.ListOptions)) {
+	for resource, t := range config.Resources {
+		r := transformableResources[resource]
+		transform := r.transformFunc(t)
+		factory.InformerFor(r.obj, func(c clientset.Interface, resync time.Duration) cache.SharedIndexInformer {
+			return cache.NewSharedIndexInformer(
+				transformingListWatch(tweakingListWatch(r.listWatch(c, namespace), tweak), transform),
+				r.obj,
+				resync,
+				cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+			)
+		})
+	}
+}
+
+// RegisterPodInformer registers an informer of the pods matching the given
+// field selector with the given factory, watching the given namespace. The pod
+// transforms of config are applied as well, if any. Like
+// RegisterTransformingInformers, it has to be called before collectors get
+// their informers from the factory.
+func RegisterPodInformer(factory informers.SharedInformerFactory, client clientset.Interface, namespace, fieldSelector string, config *InformerTransformConfig, tweak func(*metav1.ListOptions)) {
+	r := transformableResources["pods"]
+	transform := func(runtime.Object) {}
+	if t, ok := config.resource("pods"); ok {
+		transform = r.transformFunc(t)
+	}
+	factory.InformerFor(r.obj, func(c clientset.Interface, resync time.Duration) cache.SharedIndexInformer {
+		return cache.NewSharedIndexInformer(
+			transformingListWatch(fieldSelectingListWatch(tweakingListWatch(r.listWatch(c, namespace), tweak), fieldSelector), transform),
+			r.obj,
+			resync,
+			cache.Indexers{cache.NamespaceIndex: cache.MetaNamespaceIndexFunc},
+		)
+	})
+}
+
+// resource returns the transform of the given resource, if c is not nil and
+// configures one.
+func (c *InformerTransformConfig) resource(resource string) (InformerTransform, bool) {
+	if c == nil {
+		return InformerTransform{}, false
+	}
+	t, ok := c.Resources[resource]
+	return t, ok
+}
+
+// fieldSelectingListWatch restricts lw to the objects matching the given
+// field selector.
+func fieldSelectingListWatch(lw *cache.ListWatch, fieldSelector string) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+			o.FieldSelector = fieldSelector
+			return lw.List(o)
+		},
+		WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+			o.FieldSelector = fieldSelector
+			return lw.Watch(o)
+		},
+	}
+}
+
+// transformingListWatch applies transform to copies of all objects listed
+// and watched by lw. The objects themselves may be shared with the client,
+// e.g. with the tracker of a fake clientset, so they are never modified.
+func transformingListWatch(lw *cache.ListWatch, transform func(runtime.Object)) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(o metav1.ListOptions) (runtime.Object, error) {
+			list, err := lw.List(o)
+			if err != nil {
+				return nil, err
+			}
+			list = list.DeepCopyObject()
+			err = meta.EachListItem(list, func(obj runtime.Object) error {
+				transform(obj)
+				return nil
+			})
+			return list, err
+		},
+		WatchFunc: func(o metav1.ListOptions) (watch.Interface, error) {
+			w, err := lw.Watch(o)
+			if err != nil {
+				return nil, err
+			}
+			return watch.Filter(w, func(e watch.Event) (watch.Event, bool) {
+				if e.Type != watch.Error {
+					e.Object = e.Object.DeepCopyObject()
+					transform(e.Object)
+				}
+				return e, true
+			}), nil
+		},
+	}
+}
+
+// transformFunc returns a function stripping objects of r as described by t.
+func (r transformableResource) transformFunc(t InformerTransform) func(runtime.Object) {
+	return func(obj runtime.Object) {
+		if o, err := meta.Accessor(obj); err == nil {
+			if annotations := o.GetAnnotations(); len(annotations) > 0 {
+				for _, key := range t.DropAnnotations {
+					delete(annotations, key)
+				}
+				if t.DropUnrequestedAnnotations {
+					for key := range annotations {
+						if !containsString(t.KeepAnnotations, key) {
+							delete(annotations, key)
+						}
+					}
+				}
+				if t.MaxAnnotationValueLength > 0 {
+					for key, value := range annotations {
+						if len(value) > t.MaxAnnotationValueLength {
+							delete(annotations, key)
+						}
+					}
+				}
+			}
+		}
+		if t.DropContainerDetails && r.podSpecs != nil {
+			for _, spec := range r.podSpecs(obj) {
+				dropContainerDetails(spec.InitContainers)
+				dropContainerDetails(spec.Containers)
+			}
+		}
+		if t.DropData && r.dropData != nil {
+			r.dropData(obj)
+		}
+	}
+}
+
+func dropContainerDetails(containers []v1.Container) {
+	for i := range containers {
+		containers[i].Env = nil
+		containers[i].EnvFrom = nil
+		containers[i].Command = nil
+		containers[i].Args = nil
+	}
+}
+
+func sortedTransformResources(m map[string]InformerTransform) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func containsString(ss []string, s string) bool {
+	for _, v := range ss {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,360 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+	"k8s.io/client-go/tools/cache"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+const testInformerTransformConfig = `
+resources:
+  pods:
+    dropAnnotations:
+    - kubectl.kubernetes.io/last-applied-configuration
+    maxAnnotationValueLength: 8
+    dropContainerDetails: true
+  configmaps:
+    dropData: true
+`
+
+func TestInformerTransforms(t *testing.T) {
+	config, err := ParseInformerTransformConfig([]byte(testInformerTransformConfig))
+	if err != nil {
+		t.Fatalf("unexpected error parsing config: %v", err)
+	}
+
+	client := fake.NewSimpleClientset(
+		&v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "pod1",
+				Namespace: "ns1",
+				Annotations: map[string]string{
+					"kubectl.kubernetes.io/last-applied-configuration": "{}",
+					"large": "0123456789",
+					"short": "value",
+				},
+			},
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{
+					Name:    "app",
+					Image:   "app:1",
+					Command: []string{"/app"},
+					Args:    []string{"--verbose"},
+					Env:     []v1.EnvVar{{Name: "KEY", Value: "value"}},
+				}},
+			},
+		},
+		&v1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "cm1", Namespace: "ns1"},
+			Data:       map[string]string{"key": "value"},
+		},
+		&v1.Service{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "svc1",
+				Namespace:   "ns1",
+				Annotations: map[string]string{"large": "0123456789"},
+			},
+		},
+	)
+	factory := informers.NewSharedInformerFactory(client, 0)
+	RegisterTransformingInformers(factory, client, metav1.NamespaceAll, config, nil)
+
+	pods := factory.Core().V1().Pods().Informer()
+	configMaps := factory.Core().V1().ConfigMaps().Informer()
+	services := factory.Core().V1().Services().Informer()
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	if !cache.WaitForCacheSync(stop, pods.HasSynced, configMaps.HasSynced, services.HasSynced) {
+		t.Fatal("informers did not sync")
+	}
+
+	// Objects added after the initial list are transformed as well.
+	if _, err := client.CoreV1().Pods("ns1").Create(&v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "pod2",
+			Namespace:   "ns1",
+			Annotations: map[string]string{"large": "0123456789"},
+		},
+	}); err != nil {
+		t.Fatalf("unexpected error creating pod: %v", err)
+	}
+	var pod2 interface{}
+	for i := 0; i < 100; i++ {
+		var exists bool
+		if pod2, exists, _ = pods.GetStore().GetByKey("ns1/pod2"); exists {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if pod2 == nil {
+		t.Fatal("pod2 was not observed")
+	}
+	if a := pod2.(*v1.Pod).Annotations; len(a) != 0 {
+		t.Errorf("expected annotations of watched pod to be dropped, got %v", a)
+	}
+
+	obj, _, _ := pods.GetStore().GetByKey("ns1/pod1")
+	pod := obj.(*v1.Pod)
+	if want := map[string]string{"short": "value"}; !reflect.DeepEqual(pod.Annotations, want) {
+		t.Errorf("expected pod annotations %v, got %v", want, pod.Annotations)
+	}
+	want := []v1.Container{{Name: "app", Image: "app:1"}}
+	if !reflect.DeepEqual(pod.Spec.Containers, want) {
+		t.Errorf("expected containers %v, got %v", want, pod.Spec.Containers)
+	}
+
+	obj, _, _ = configMaps.GetStore().GetByKey("ns1/cm1")
+	if data := obj.(*v1.ConfigMap).Data; data != nil {
+		t.Errorf("expected configmap data to be dropped, got %v", data)
+	}
+
+	// Resources without transforms are stored as they are.
+	obj, _, _ = services.GetStore().GetByKey("ns1/svc1")
+	if a := obj.(*v1.Service).Annotations; len(a) != 1 {
+		t.Errorf("expected service annotations to be kept, got %v", a)
+	}
+}
+
+func TestParseInformerTransformConfig(t *testing.T) {
+	cases := []struct {
+		config string
+		err    bool
+	}{
+		{config: testInformerTransformConfig},
+		{
+			config: `
+resources:
+  certificates:
+    dropAnnotations: [foo]
+`,
+			err: true,
+		},
+		{
+			config: `
+resources:
+  services:
+    dropData: true
+`,
+			err: true,
+		},
+		{
+			config: `
+resources:
+  nodes:
+    dropContainerDetails: true
+`,
+			err: true,
+		},
+		{
+			config: `
+resources:
+  pods:
+    maxAnnotationValueLength: -1
+`,
+			err: true,
+		},
+	}
+	for i, c := range cases {
+		_, err := ParseInformerTransformConfig([]byte(c.config))
+		if c.err != (err != nil) {
+			t.Errorf("case %d: expected error %t, got %v", i, c.err, err)
+		}
+	}
+}
+
+func TestRegisterPodInformer(t *testing.T) {
+	client := fake.NewSimpleClientset()
+	var (
+		mu        sync.Mutex
+		selectors = map[string]string{}
+	)
+	client.PrependReactor("list", "pods", func(action clienttesting.Action) (bool, runtime.Object, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		selectors["list"] = action.(clienttesting.ListAction).GetListRestrictions().Fields.String()
+		return false, nil, nil
+	})
+	client.PrependWatchReactor("pods", func(action clienttesting.Action) (bool, watch.Interface, error) {
+		mu.Lock()
+		defer mu.Unlock()
+		selectors["watch"] = action.(clienttesting.WatchAction).GetWatchRestrictions().Fields.String()
+		return false, nil, nil
+	})
+
+	factory := informers.NewSharedInformerFactory(client, 0)
+	RegisterPodInformer(factory, client, metav1.NamespaceAll, "spec.nodeName=node1", nil, nil)
+	pods := factory.Core().V1().Pods().Informer()
+	stop := make(chan struct{})
+	defer close(stop)
+	factory.Start(stop)
+	if !cache.WaitForCacheSync(stop, pods.HasSynced) {
+		t.Fatal("informer did not sync")
+	}
+
+	for i := 0; i < 100; i++ {
+		mu.Lock()
+		n := len(selectors)
+		mu.Unlock()
+		if n == 2 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	for _, verb := range []string{"list", "watch"} {
+		if got := selectors[verb]; got != "spec.nodeName=node1" {
+			t.Errorf("expected pods to be %sed with field selector spec.nodeName=node1, got %q", verb, got)
+		}
+	}
+}
+
+func TestWithUnrequestedAnnotationsDropped(t *testing.T) {
+	config, err := ParseInformerTransformConfig([]byte(testInformerTransformConfig))
+	if err != nil {
+		t.Fatalf("unexpected error parsing config: %v", err)
+	}
+	annotations := map[string]string{
+		v1.LastAppliedConfigAnnotation:   "{}",
+		v1.BetaStorageClassAnnotation:    "fast",
+		storageProvisionerAnnotation:     "rbd.csi.ceph.com",
+		betaStorageProvisionerAnnotation: "kubernetes.io/rbd",
+		podMirrorAnnotation:              "mirror",
+		"team":                           "a",
+		"description":                    "unused",
+	}
+
+	tests := []struct {
+		resource  string
+		whitelist options.AnnotationSet
+		want      map[string]string
+	}{
+		{
+			resource: "pods",
+			want:     map[string]string{podMirrorAnnotation: "mirror"},
+		},
+		{
+			resource: "persistentvolumeclaims",
+			want: map[string]string{
+				v1.BetaStorageClassAnnotation:    "fast",
+				storageProvisionerAnnotation:     "rbd.csi.ceph.com",
+				betaStorageProvisionerAnnotation: "kubernetes.io/rbd",
+			},
+		},
+		{
+			resource:  "services",
+			whitelist: options.AnnotationSet{"team": struct{}{}},
+			want:      map[string]string{},
+		},
+		{
+			resource:  "daemonsets",
+			whitelist: options.AnnotationSet{"team": struct{}{}},
+			want:      map[string]string{"team": "a"},
+		},
+		{
+			resource:  "resourcequotas",
+			whitelist: options.AnnotationSet{"*": struct{}{}},
+			want: map[string]string{
+				v1.BetaStorageClassAnnotation:    "fast",
+				storageProvisionerAnnotation:     "rbd.csi.ceph.com",
+				betaStorageProvisionerAnnotation: "kubernetes.io/rbd",
+				podMirrorAnnotation:              "mirror",
+				"team":                           "a",
+				"description":                    "unused",
+			},
+		},
+		{
+			resource: "namespaces",
+			want: map[string]string{
+				v1.BetaStorageClassAnnotation:    "fast",
+				storageProvisionerAnnotation:     "rbd.csi.ceph.com",
+				betaStorageProvisionerAnnotation: "kubernetes.io/rbd",
+				podMirrorAnnotation:              "mirror",
+				"team":                           "a",
+				"description":                    "unused",
+			},
+		},
+	}
+	for _, test := range tests {
+		dropped := config.WithUnrequestedAnnotationsDropped(test.whitelist)
+		if len(dropped.Resources) != len(transformableResources) {
+			t.Errorf("expected transforms of all %d resources, got %d", len(transformableResources), len(dropped.Resources))
+		}
+		obj := &v1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{}}}
+		for k, v := range annotations {
+			obj.Annotations[k] = v
+		}
+		r := transformableResources[test.resource]
+		r.podSpecs, r.dropData = nil, nil
+		r.transformFunc(dropped.Resources[test.resource])(obj)
+		if !reflect.DeepEqual(obj.Annotations, test.want) {
+			t.Errorf("%s: expected annotations %v, got %v", test.resource, test.want, obj.Annotations)
+		}
+	}
+
+	// The configured transforms are kept and the original config is not
+	// changed.
+	pods := config.WithUnrequestedAnnotationsDropped(nil).Resources["pods"]
+	if pods.MaxAnnotationValueLength != 8 || !pods.DropContainerDetails {
+		t.Errorf("expected configured pod transform to be kept, got %+v", pods)
+	}
+	if got := config.Resources["pods"]; got.DropUnrequestedAnnotations || len(got.DropAnnotations) != 1 {
+		t.Errorf("expected original pod transform to be unchanged, got %+v", got)
+	}
+
+	var nilConfig *InformerTransformConfig
+	if got := nilConfig.WithUnrequestedAnnotationsDropped(nil).Resources["nodes"]; !got.DropUnrequestedAnnotations {
+		t.Errorf("expected node transform of nil config to drop unrequested annotations, got %+v", got)
+	}
+}
+
+func TestTransformableResourcesCoverInformers(t *testing.T) {
+	// These collectors don't use informers.
+	withoutInformers := map[string]bool{
+		"apiresources":              true,
+		"volumesnapshots":           true,
+		"volumesnapshotcontents":    true,
+		"verticalpodautoscalers":    true,
+		"csinodes":                  true,
+		"csidrivers":                true,
+		"customresourcedefinitions": true,
+		"apiservices":               true,
+		"runtimeclasses":            true,
+		"ingressclasses":            true,
+		"endpointslices":            true,
+	}
+	for c := range options.AvailableCollectors {
+		if _, ok := transformableResources[c]; !ok && !withoutInformers[c] {
+			t.Errorf("collector %q has no transformable resource", c)
+		}
+	}
+}
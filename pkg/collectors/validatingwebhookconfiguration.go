@@ -0,0 +1,130 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descValidatingWebhookConfigurationLabelsDefaultLabels = []string{"validatingwebhookconfiguration"}
+
+	descValidatingWebhookConfigurationInfo = prometheus.NewDesc(
+		"kube_validatingwebhookconfiguration_info",
+		"Information about the validating webhook configuration.",
+		descValidatingWebhookConfigurationLabelsDefaultLabels,
+		nil,
+	)
+	descValidatingWebhookConfigurationCreated = prometheus.NewDesc(
+		"kube_validatingwebhookconfiguration_created",
+		"Unix creation timestamp",
+		descValidatingWebhookConfigurationLabelsDefaultLabels,
+		nil,
+	)
+	descValidatingWebhookConfigurationWebhook = prometheus.NewDesc(
+		"kube_validatingwebhookconfiguration_webhook",
+		"Information about a webhook of the validating webhook configuration.",
+		append(descValidatingWebhookConfigurationLabelsDefaultLabels, "webhook", "failure_policy", "service_namespace", "service_name"),
+		nil,
+	)
+)
+
+type ValidatingWebhookConfigurationLister func() ([]admissionregistration.ValidatingWebhookConfiguration, error)
+
+func (l ValidatingWebhookConfigurationLister) List() ([]admissionregistration.ValidatingWebhookConfiguration, error) {
+	return l()
+}
+
+func RegisterValidatingWebhookConfigurationCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Admissionregistration().V1beta1().ValidatingWebhookConfigurations().Informer(), opts))
+	}
+
+	validatingWebhookConfigurationLister := ValidatingWebhookConfigurationLister(func() (configurations []admissionregistration.ValidatingWebhookConfiguration, err error) {
+		for _, inf := range infs {
+			for _, c := range inf.GetStore().List() {
+				configurations = append(configurations, *(c.(*admissionregistration.ValidatingWebhookConfiguration)))
+			}
+		}
+		return configurations, nil
+	})
+
+	registry.MustRegister(&validatingWebhookConfigurationCollector{store: validatingWebhookConfigurationLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_validatingwebhookconfiguration_deleted", descValidatingWebhookConfigurationLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("validatingwebhookconfiguration", infs)
+	infs.Run(context.Background().Done())
+}
+
+type validatingWebhookConfigurationStore interface {
+	List() ([]admissionregistration.ValidatingWebhookConfiguration, error)
+}
+
+// validatingWebhookConfigurationCollector collects metrics about all validating webhook configurations in the cluster.
+type validatingWebhookConfigurationCollector struct {
+	store validatingWebhookConfigurationStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *validatingWebhookConfigurationCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descValidatingWebhookConfigurationInfo
+	ch <- descValidatingWebhookConfigurationCreated
+	ch <- descValidatingWebhookConfigurationWebhook
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *validatingWebhookConfigurationCollector) Collect(ch chan<- prometheus.Metric) {
+	configurations, err := c.store.List()
+	if err != nil {
+		addScrapeError(ch, "validatingwebhookconfiguration", err)
+		glog.Errorf("listing validatingwebhookconfigurations failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "validatingwebhookconfiguration"}).Add(0)
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "validatingwebhookconfiguration"}).Observe(float64(len(configurations)))
+	for _, configuration := range configurations {
+		c.collectValidatingWebhookConfiguration(ch, configuration)
+	}
+
+	glog.V(4).Infof("collected %d validatingwebhookconfigurations", len(configurations))
+}
+
+func (c *validatingWebhookConfigurationCollector) collectValidatingWebhookConfiguration(ch chan<- prometheus.Metric, configuration admissionregistration.ValidatingWebhookConfiguration) {
+	defer recoverObjectError("validatingwebhookconfiguration", &configuration.ObjectMeta)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{configuration.Name}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+	addGauge(descValidatingWebhookConfigurationInfo, 1)
+
+	if !configuration.CreationTimestamp.IsZero() {
+		addGauge(descValidatingWebhookConfigurationCreated, float64(configuration.CreationTimestamp.Unix()))
+	}
+
+	for _, w := range configuration.Webhooks {
+		addGauge(descValidatingWebhookConfigurationWebhook, 1, webhookLabelValues(w)...)
+	}
+}
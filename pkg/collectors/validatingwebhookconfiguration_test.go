@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	admissionregistration "k8s.io/api/admissionregistration/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockValidatingWebhookConfigurationStore struct {
+	f func() ([]admissionregistration.ValidatingWebhookConfiguration, error)
+}
+
+func (s mockValidatingWebhookConfigurationStore) List() ([]admissionregistration.ValidatingWebhookConfiguration, error) {
+	return s.f()
+}
+
+func TestValidatingWebhookConfigurationCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+
+	fail := admissionregistration.Fail
+	path := "/validator"
+	url := "https://validator.example.com"
+
+	const metadata = `
+		# HELP kube_validatingwebhookconfiguration_info Information about the validating webhook configuration.
+		# TYPE kube_validatingwebhookconfiguration_info gauge
+		# HELP kube_validatingwebhookconfiguration_created Unix creation timestamp
+		# TYPE kube_validatingwebhookconfiguration_created gauge
+		# HELP kube_validatingwebhookconfiguration_webhook Information about a webhook of the validating webhook configuration.
+		# TYPE kube_validatingwebhookconfiguration_webhook gauge
+	`
+	cases := []struct {
+		configurations []admissionregistration.ValidatingWebhookConfiguration
+		want           string
+	}{
+		{
+			configurations: []admissionregistration.ValidatingWebhookConfiguration{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "config1",
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "config2",
+						CreationTimestamp: metav1StartTime,
+					},
+					Webhooks: []admissionregistration.Webhook{
+						{
+							Name: "service.example.com",
+							ClientConfig: admissionregistration.WebhookClientConfig{
+								Service: &admissionregistration.ServiceReference{
+									Namespace: "ns1",
+									Name:      "validator",
+									Path:      &path,
+								},
+							},
+							FailurePolicy: &fail,
+						},
+						{
+							Name: "url.example.com",
+							ClientConfig: admissionregistration.WebhookClientConfig{
+								URL: &url,
+							},
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_validatingwebhookconfiguration_info{validatingwebhookconfiguration="config1"} 1
+				kube_validatingwebhookconfiguration_info{validatingwebhookconfiguration="config2"} 1
+				kube_validatingwebhookconfiguration_created{validatingwebhookconfiguration="config2"} 1.501569018e+09
+				kube_validatingwebhookconfiguration_webhook{failure_policy="Fail",validatingwebhookconfiguration="config2",service_name="validator",service_namespace="ns1",webhook="service.example.com"} 1
+				kube_validatingwebhookconfiguration_webhook{failure_policy="Ignore",validatingwebhookconfiguration="config2",service_name="",service_namespace="",webhook="url.example.com"} 1
+			`,
+		},
+	}
+	for _, c := range cases {
+		wc := &validatingWebhookConfigurationCollector{
+			store: mockValidatingWebhookConfigurationStore{
+				f: func() ([]admissionregistration.ValidatingWebhookConfiguration, error) { return c.configurations, nil },
+			},
+			opts: &options.Options{},
+		}
+		if err := testutils.GatherAndCompare(wc, c.want, nil); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/constant"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// VerticalPodAutoscalers are defined by the CustomResourceDefinition of
+	// the autoscaler project, there is no typed client for them.
+	verticalPodAutoscalerResource = CustomResource{
+		Group:      "autoscaling.k8s.io",
+		Version:    "v1",
+		Resource:   "verticalpodautoscalers",
+		Kind:       "VerticalPodAutoscaler",
+		Namespaced: true,
+	}
+
+	// verticalPodAutoscalerUpdateModes are the update modes of the update
+	// policy of VerticalPodAutoscalers, of which Auto is the default.
+	verticalPodAutoscalerUpdateModes = []string{"Off", "Initial", "Recreate", "Auto"}
+
+	descVerticalPodAutoscalerLabelsDefaultLabels = []string{"namespace", "verticalpodautoscaler", "target_api_version", "target_kind", "target_name"}
+
+	descVerticalPodAutoscalerUpdateMode = prometheus.NewDesc(
+		"kube_verticalpodautoscaler_spec_updatepolicy_updatemode",
+		"Update mode of the VerticalPodAutoscaler.",
+		append(descVerticalPodAutoscalerLabelsDefaultLabels, "update_mode"),
+		nil,
+	)
+	descVerticalPodAutoscalerRecommendationLowerBound = prometheus.NewDesc(
+		"kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound",
+		"Minimum resources the container can use before the VerticalPodAutoscaler updater evicts it.",
+		append(descVerticalPodAutoscalerLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+	descVerticalPodAutoscalerRecommendationTarget = prometheus.NewDesc(
+		"kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target",
+		"Target resources the VerticalPodAutoscaler recommends for the container.",
+		append(descVerticalPodAutoscalerLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+	descVerticalPodAutoscalerRecommendationUncappedTarget = prometheus.NewDesc(
+		"kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget",
+		"Target resources the VerticalPodAutoscaler recommends for the container ignoring bounds.",
+		append(descVerticalPodAutoscalerLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+	descVerticalPodAutoscalerRecommendationUpperBound = prometheus.NewDesc(
+		"kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound",
+		"Maximum resources the container can use before the VerticalPodAutoscaler updater evicts it.",
+		append(descVerticalPodAutoscalerLabelsDefaultLabels, "container", "resource", "unit"),
+		nil,
+	)
+)
+
+// RegisterVerticalPodAutoscalerCollector registers a collector of the
+// VerticalPodAutoscalers in the given namespaces. Like the endpointslices
+// collector it lists them on every scrape.
+func RegisterVerticalPodAutoscalerCollector(registry prometheus.Registerer, client rest.Interface, namespaces options.NamespaceList, opts *options.Options) {
+	registry.MustRegister(&verticalPodAutoscalerCollector{store: restCustomResourceStore{client: client}, namespaces: namespaces, opts: opts})
+}
+
+// verticalPodAutoscalerCollector collects metrics about all
+// VerticalPodAutoscalers in the cluster.
+type verticalPodAutoscalerCollector struct {
+	store      customResourceStore
+	namespaces options.NamespaceList
+	opts       *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (vc *verticalPodAutoscalerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descVerticalPodAutoscalerUpdateMode
+	ch <- descVerticalPodAutoscalerRecommendationLowerBound
+	ch <- descVerticalPodAutoscalerRecommendationTarget
+	ch <- descVerticalPodAutoscalerRecommendationUncappedTarget
+	ch <- descVerticalPodAutoscalerRecommendationUpperBound
+}
+
+// Collect implements the prometheus.Collector interface.
+func (vc *verticalPodAutoscalerCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, vc.store, verticalPodAutoscalerResource, vc.namespaces, vc.opts, func(obj unstructured.Unstructured) {
+		vc.collectVerticalPodAutoscaler(ch, obj)
+	})
+}
+
+func (vc *verticalPodAutoscalerCollector) collectVerticalPodAutoscaler(ch chan<- prometheus.Metric, a unstructured.Unstructured) {
+	defer recoverObjectError("verticalpodautoscaler", &a)
+
+	targetAPIVersion, _, _ := unstructured.NestedString(a.Object, "spec", "targetRef", "apiVersion")
+	targetKind, _, _ := unstructured.NestedString(a.Object, "spec", "targetRef", "kind")
+	targetName, _, _ := unstructured.NestedString(a.Object, "spec", "targetRef", "name")
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{a.GetNamespace(), a.GetName(), targetAPIVersion, targetKind, targetName}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	updateMode, ok, _ := unstructured.NestedString(a.Object, "spec", "updatePolicy", "updateMode")
+	if !ok {
+		updateMode = "Auto"
+	}
+	for _, mode := range verticalPodAutoscalerUpdateModes {
+		addGauge(descVerticalPodAutoscalerUpdateMode, boolFloat64(mode == updateMode), mode)
+	}
+
+	recommendations, _, _ := unstructured.NestedSlice(a.Object, "status", "recommendation", "containerRecommendations")
+	for _, r := range recommendations {
+		recommendation, ok := r.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		container, _, _ := unstructured.NestedString(recommendation, "containerName")
+		for field, desc := range map[string]*prometheus.Desc{
+			"lowerBound":     descVerticalPodAutoscalerRecommendationLowerBound,
+			"target":         descVerticalPodAutoscalerRecommendationTarget,
+			"uncappedTarget": descVerticalPodAutoscalerRecommendationUncappedTarget,
+			"upperBound":     descVerticalPodAutoscalerRecommendationUpperBound,
+		} {
+			resources, _, _ := unstructured.NestedMap(recommendation, field)
+			for name, value := range resources {
+				q, ok := unstructuredQuantity(value)
+				if !ok {
+					continue
+				}
+				switch v1.ResourceName(name) {
+				case v1.ResourceCPU:
+					addGauge(desc, float64(q.MilliValue())/1000, container, sanitizeLabelName(name), string(constant.UnitCore))
+				case v1.ResourceMemory:
+					addGauge(desc, float64(q.Value()), container, sanitizeLabelName(name), string(constant.UnitByte))
+				}
+			}
+		}
+	}
+}
+
+// unstructuredQuantity returns the quantity held by a field of an
+// unstructured object, which is a string or, for whole numbers, a number.
+func unstructuredQuantity(v interface{}) (resource.Quantity, bool) {
+	switch q := v.(type) {
+	case string:
+		parsed, err := resource.ParseQuantity(q)
+		return parsed, err == nil
+	case int64:
+		return *resource.NewQuantity(q, resource.DecimalSI), true
+	case float64:
+		return *resource.NewMilliQuantity(int64(q*1000), resource.DecimalSI), true
+	}
+	return resource.Quantity{}, false
+}
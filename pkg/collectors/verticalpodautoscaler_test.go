@@ -0,0 +1,98 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestVerticalPodAutoscalerCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_verticalpodautoscaler_spec_updatepolicy_updatemode Update mode of the VerticalPodAutoscaler.
+		# TYPE kube_verticalpodautoscaler_spec_updatepolicy_updatemode gauge
+		# HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound Minimum resources the container can use before the VerticalPodAutoscaler updater evicts it.
+		# TYPE kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound gauge
+		# HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target Target resources the VerticalPodAutoscaler recommends for the container.
+		# TYPE kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target gauge
+		# HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget Target resources the VerticalPodAutoscaler recommends for the container ignoring bounds.
+		# TYPE kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget gauge
+		# HELP kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound Maximum resources the container can use before the VerticalPodAutoscaler updater evicts it.
+		# TYPE kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"verticalpodautoscalers": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":      "web",
+					"namespace": "ns1",
+				},
+				"spec": map[string]interface{}{
+					"targetRef":    map[string]interface{}{"apiVersion": "apps/v1", "kind": "Deployment", "name": "web"},
+					"updatePolicy": map[string]interface{}{"updateMode": "Off"},
+				},
+				"status": map[string]interface{}{
+					"recommendation": map[string]interface{}{
+						"containerRecommendations": []interface{}{
+							map[string]interface{}{
+								"containerName":  "app",
+								"lowerBound":     map[string]interface{}{"cpu": "100m", "memory": "128Mi"},
+								"target":         map[string]interface{}{"cpu": "250m", "memory": "256Mi"},
+								"uncappedTarget": map[string]interface{}{"cpu": int64(2)},
+								"upperBound":     map[string]interface{}{"cpu": "1", "memory": "1Gi"},
+							},
+						},
+					},
+				},
+			}},
+			// Without recommendations yet and the default update mode.
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":      "worker",
+					"namespace": "ns2",
+				},
+				"spec": map[string]interface{}{
+					"targetRef": map[string]interface{}{"apiVersion": "apps/v1", "kind": "StatefulSet", "name": "worker"},
+				},
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="web",update_mode="Auto",verticalpodautoscaler="web"} 0
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="web",update_mode="Initial",verticalpodautoscaler="web"} 0
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="web",update_mode="Off",verticalpodautoscaler="web"} 1
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns1",target_api_version="apps/v1",target_kind="Deployment",target_name="web",update_mode="Recreate",verticalpodautoscaler="web"} 0
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns2",target_api_version="apps/v1",target_kind="StatefulSet",target_name="worker",update_mode="Auto",verticalpodautoscaler="worker"} 1
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns2",target_api_version="apps/v1",target_kind="StatefulSet",target_name="worker",update_mode="Initial",verticalpodautoscaler="worker"} 0
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns2",target_api_version="apps/v1",target_kind="StatefulSet",target_name="worker",update_mode="Off",verticalpodautoscaler="worker"} 0
+		kube_verticalpodautoscaler_spec_updatepolicy_updatemode{namespace="ns2",target_api_version="apps/v1",target_kind="StatefulSet",target_name="worker",update_mode="Recreate",verticalpodautoscaler="worker"} 0
+		kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound{container="app",namespace="ns1",resource="cpu",target_api_version="apps/v1",target_kind="Deployment",target_name="web",unit="core",verticalpodautoscaler="web"} 0.1
+		kube_verticalpodautoscaler_status_recommendation_containerrecommendations_lowerbound{container="app",namespace="ns1",resource="memory",target_api_version="apps/v1",target_kind="Deployment",target_name="web",unit="byte",verticalpodautoscaler="web"} 1.34217728e+08
+		kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target{container="app",namespace="ns1",resource="cpu",target_api_version="apps/v1",target_kind="Deployment",target_name="web",unit="core",verticalpodautoscaler="web"} 0.25
+		kube_verticalpodautoscaler_status_recommendation_containerrecommendations_target{container="app",namespace="ns1",resource="memory",target_api_version="apps/v1",target_kind="Deployment",target_name="web",unit="byte",verticalpodautoscaler="web"} 2.68435456e+08
+		kube_verticalpodautoscaler_status_recommendation_containerrecommendations_uncappedtarget{container="app",namespace="ns1",resource="cpu",target_api_version="apps/v1",target_kind="Deployment",target_name="web",unit="core",verticalpodautoscaler="web"} 2
+		kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound{container="app",namespace="ns1",resource="cpu",target_api_version="apps/v1",target_kind="Deployment",target_name="web",unit="core",verticalpodautoscaler="web"} 1
+		kube_verticalpodautoscaler_status_recommendation_containerrecommendations_upperbound{container="app",namespace="ns1",resource="memory",target_api_version="apps/v1",target_kind="Deployment",target_name="web",unit="byte",verticalpodautoscaler="web"} 1.073741824e+09
+	`
+	vc := &verticalPodAutoscalerCollector{store: store, namespaces: options.NamespaceList{""}, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(vc, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"context"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
+	"k8s.io/client-go/informers"
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	descVolumeAttachmentLabelsName          = "kube_volumeattachment_labels"
+	descVolumeAttachmentLabelsHelp          = "Kubernetes labels converted to Prometheus labels."
+	descVolumeAttachmentLabelsDefaultLabels = []string{"volumeattachment"}
+
+	descVolumeAttachmentInfo = prometheus.NewDesc(
+		"kube_volumeattachment_info",
+		"Information about volumeattachment.",
+		append(descVolumeAttachmentLabelsDefaultLabels, "attacher", "node"),
+		nil,
+	)
+	descVolumeAttachmentCreated = prometheus.NewDesc(
+		"kube_volumeattachment_created",
+		"Unix creation timestamp",
+		descVolumeAttachmentLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeAttachmentLabels = prometheus.NewDesc(
+		descVolumeAttachmentLabelsName,
+		descVolumeAttachmentLabelsHelp,
+		descVolumeAttachmentLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeAttachmentSpecSourcePersistentVolume = prometheus.NewDesc(
+		"kube_volumeattachment_spec_source_persistentvolume",
+		"The persistentvolume to be attached.",
+		append(descVolumeAttachmentLabelsDefaultLabels, "persistentvolume"),
+		nil,
+	)
+	descVolumeAttachmentStatusAttached = prometheus.NewDesc(
+		"kube_volumeattachment_status_attached",
+		"Whether the volume is attached.",
+		descVolumeAttachmentLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeAttachmentStatusAttachError = prometheus.NewDesc(
+		"kube_volumeattachment_status_attach_error",
+		"Whether the last attach operation of the volume failed.",
+		descVolumeAttachmentLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeAttachmentStatusDetachError = prometheus.NewDesc(
+		"kube_volumeattachment_status_detach_error",
+		"Whether the last detach operation of the volume failed.",
+		descVolumeAttachmentLabelsDefaultLabels,
+		nil,
+	)
+)
+
+type VolumeAttachmentLister func() ([]storagev1beta1.VolumeAttachment, error)
+
+func (l VolumeAttachmentLister) List() ([]storagev1beta1.VolumeAttachment, error) {
+	return l()
+}
+
+func RegisterVolumeAttachmentCollector(registry prometheus.Registerer, informerFactories []informers.SharedInformerFactory, opts *options.Options) {
+	infs := SharedInformerList{}
+	for _, f := range informerFactories {
+		infs = append(infs, newShardedInformer(f.Storage().V1beta1().VolumeAttachments().Informer(), opts))
+	}
+
+	volumeAttachmentLister := VolumeAttachmentLister(func() (volumeAttachments []storagev1beta1.VolumeAttachment, err error) {
+		for _, vainf := range infs {
+			for _, va := range vainf.GetStore().List() {
+				volumeAttachments = append(volumeAttachments, *(va.(*storagev1beta1.VolumeAttachment)))
+			}
+		}
+		return volumeAttachments, nil
+	})
+
+	registry.MustRegister(&volumeAttachmentCollector{store: volumeAttachmentLister, opts: opts})
+	registerDeletedObjects(registry, infs, "kube_volumeattachment_deleted", descVolumeAttachmentLabelsDefaultLabels, opts)
+	InformerSyncTracker.Track("volumeattachment", infs)
+	infs.Run(context.Background().Done())
+}
+
+type volumeAttachmentStore interface {
+	List() (volumeAttachments []storagev1beta1.VolumeAttachment, err error)
+}
+
+// volumeAttachmentCollector collects metrics about all volumeAttachments in the cluster.
+type volumeAttachmentCollector struct {
+	store volumeAttachmentStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (vac *volumeAttachmentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descVolumeAttachmentInfo
+	ch <- descVolumeAttachmentCreated
+	ch <- descVolumeAttachmentLabels
+	ch <- descVolumeAttachmentSpecSourcePersistentVolume
+	ch <- descVolumeAttachmentStatusAttached
+	ch <- descVolumeAttachmentStatusAttachError
+	ch <- descVolumeAttachmentStatusDetachError
+}
+
+// Collect implements the prometheus.Collector interface.
+func (vac *volumeAttachmentCollector) Collect(ch chan<- prometheus.Metric) {
+	volumeAttachments, err := vac.store.List()
+	if err != nil {
+		addScrapeError(ch, "volumeattachment", err)
+		glog.Errorf("listing volumeattachments failed: %s", err)
+		return
+	}
+	ScrapeErrorTotalMetric.With(prometheus.Labels{"resource": "volumeattachment"}).Add(0)
+
+	ResourcesPerScrapeMetric.With(prometheus.Labels{"resource": "volumeattachment"}).Observe(float64(len(volumeAttachments)))
+	for _, va := range volumeAttachments {
+		vac.collectVolumeAttachment(ch, va)
+	}
+
+	glog.V(4).Infof("collected %d volumeattachments", len(volumeAttachments))
+}
+
+func volumeAttachmentLabelsDesc(labelKeys []string) *prometheus.Desc {
+	return prometheus.NewDesc(
+		descVolumeAttachmentLabelsName,
+		descVolumeAttachmentLabelsHelp,
+		append(descVolumeAttachmentLabelsDefaultLabels, labelKeys...),
+		nil,
+	)
+}
+
+func (vac *volumeAttachmentCollector) collectVolumeAttachment(ch chan<- prometheus.Metric, va storagev1beta1.VolumeAttachment) {
+	defer recoverObjectError("volumeattachment", &va.ObjectMeta)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{va.Name}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	addGauge(descVolumeAttachmentInfo, 1, va.Spec.Attacher, va.Spec.NodeName)
+	if !va.CreationTimestamp.IsZero() {
+		addGauge(descVolumeAttachmentCreated, float64(va.CreationTimestamp.Unix()))
+	}
+
+	labelKeys, labelValues := kubeLabelsToPrometheusLabels(va.Labels, vac.opts.MaxLabelValueLength)
+	addGauge(volumeAttachmentLabelsDesc(labelKeys), 1, labelValues...)
+
+	if pv := va.Spec.Source.PersistentVolumeName; pv != nil {
+		addGauge(descVolumeAttachmentSpecSourcePersistentVolume, 1, *pv)
+	}
+
+	addGauge(descVolumeAttachmentStatusAttached, boolFloat64(va.Status.Attached))
+	addGauge(descVolumeAttachmentStatusAttachError, boolFloat64(va.Status.AttachError != nil))
+	addGauge(descVolumeAttachmentStatusDetachError, boolFloat64(va.Status.DetachError != nil))
+}
@@ -0,0 +1,131 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"testing"
+
+	storagev1beta1 "k8s.io/api/storage/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+type mockVolumeAttachmentStore struct {
+	list func() ([]storagev1beta1.VolumeAttachment, error)
+}
+
+func (vs mockVolumeAttachmentStore) List() ([]storagev1beta1.VolumeAttachment, error) {
+	return vs.list()
+}
+
+func TestVolumeAttachmentCollector(t *testing.T) {
+	// Fixed metadata on type and help text. We prepend this to every expected
+	// output so we only have to modify a single place when doing adjustments.
+	startTime := 1501569018
+	metav1StartTime := metav1.Unix(int64(startTime), 0)
+
+	pv := "pv1"
+
+	const metadata = `
+		# HELP kube_volumeattachment_info Information about volumeattachment.
+		# TYPE kube_volumeattachment_info gauge
+		# HELP kube_volumeattachment_created Unix creation timestamp
+		# TYPE kube_volumeattachment_created gauge
+		# HELP kube_volumeattachment_labels Kubernetes labels converted to Prometheus labels.
+		# TYPE kube_volumeattachment_labels gauge
+		# HELP kube_volumeattachment_spec_source_persistentvolume The persistentvolume to be attached.
+		# TYPE kube_volumeattachment_spec_source_persistentvolume gauge
+		# HELP kube_volumeattachment_status_attached Whether the volume is attached.
+		# TYPE kube_volumeattachment_status_attached gauge
+		# HELP kube_volumeattachment_status_attach_error Whether the last attach operation of the volume failed.
+		# TYPE kube_volumeattachment_status_attach_error gauge
+		# HELP kube_volumeattachment_status_detach_error Whether the last detach operation of the volume failed.
+		# TYPE kube_volumeattachment_status_detach_error gauge
+	`
+	cases := []struct {
+		volumeAttachments []storagev1beta1.VolumeAttachment
+		want              string
+		metrics           []string
+	}{
+		{
+			volumeAttachments: []storagev1beta1.VolumeAttachment{
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name:              "csi-attached",
+						CreationTimestamp: metav1StartTime,
+						Labels: map[string]string{
+							"app": "db",
+						},
+					},
+					Spec: storagev1beta1.VolumeAttachmentSpec{
+						Attacher: "csi.example.com",
+						NodeName: "node1",
+						Source: storagev1beta1.VolumeAttachmentSource{
+							PersistentVolumeName: &pv,
+						},
+					},
+					Status: storagev1beta1.VolumeAttachmentStatus{
+						Attached: true,
+						DetachError: &storagev1beta1.VolumeError{
+							Message: "volume is in use",
+						},
+					},
+				},
+				{
+					ObjectMeta: metav1.ObjectMeta{
+						Name: "csi-stuck",
+					},
+					Spec: storagev1beta1.VolumeAttachmentSpec{
+						Attacher: "csi.example.com",
+						NodeName: "node2",
+					},
+					Status: storagev1beta1.VolumeAttachmentStatus{
+						AttachError: &storagev1beta1.VolumeError{
+							Message: "rpc error: code = DeadlineExceeded",
+						},
+					},
+				},
+			},
+			want: metadata + `
+				kube_volumeattachment_info{attacher="csi.example.com",node="node1",volumeattachment="csi-attached"} 1
+				kube_volumeattachment_info{attacher="csi.example.com",node="node2",volumeattachment="csi-stuck"} 1
+				kube_volumeattachment_created{volumeattachment="csi-attached"} 1.501569018e+09
+				kube_volumeattachment_labels{label_app="db",volumeattachment="csi-attached"} 1
+				kube_volumeattachment_labels{volumeattachment="csi-stuck"} 1
+				kube_volumeattachment_spec_source_persistentvolume{persistentvolume="pv1",volumeattachment="csi-attached"} 1
+				kube_volumeattachment_status_attached{volumeattachment="csi-attached"} 1
+				kube_volumeattachment_status_attached{volumeattachment="csi-stuck"} 0
+				kube_volumeattachment_status_attach_error{volumeattachment="csi-attached"} 0
+				kube_volumeattachment_status_attach_error{volumeattachment="csi-stuck"} 1
+				kube_volumeattachment_status_detach_error{volumeattachment="csi-attached"} 1
+				kube_volumeattachment_status_detach_error{volumeattachment="csi-stuck"} 0
+			`,
+		},
+	}
+	for _, c := range cases {
+		vac := &volumeAttachmentCollector{
+			store: mockVolumeAttachmentStore{
+				list: func() ([]storagev1beta1.VolumeAttachment, error) { return c.volumeAttachments, nil },
+			},
+			opts: &options.Options{},
+		}
+		if err := testutils.GatherAndCompare(vac, c.want, c.metrics); err != nil {
+			t.Errorf("unexpected collecting result:\n%s", err)
+		}
+	}
+}
@@ -0,0 +1,251 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/client-go/rest"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+var (
+	// The snapshot resources are defined by the CustomResourceDefinitions
+	// of the external snapshotter, there are no typed clients for them.
+	volumeSnapshotResource = CustomResource{
+		Group:      "snapshot.storage.k8s.io",
+		Version:    "v1",
+		Resource:   "volumesnapshots",
+		Kind:       "VolumeSnapshot",
+		Namespaced: true,
+	}
+	volumeSnapshotContentResource = CustomResource{
+		Group:    "snapshot.storage.k8s.io",
+		Version:  "v1",
+		Resource: "volumesnapshotcontents",
+		Kind:     "VolumeSnapshotContent",
+	}
+
+	descVolumeSnapshotLabelsDefaultLabels = []string{"namespace", "volumesnapshot"}
+
+	descVolumeSnapshotInfo = prometheus.NewDesc(
+		"kube_volumesnapshot_info",
+		"Information about volume snapshot.",
+		append(descVolumeSnapshotLabelsDefaultLabels, "source_persistentvolumeclaim", "volumesnapshotclass", "volumesnapshotcontent"),
+		nil,
+	)
+	descVolumeSnapshotCreated = prometheus.NewDesc(
+		"kube_volumesnapshot_created",
+		"Unix creation timestamp",
+		descVolumeSnapshotLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeSnapshotStatusReadyToUse = prometheus.NewDesc(
+		"kube_volumesnapshot_status_ready_to_use",
+		"Whether the volume snapshot is ready to be used to restore a volume.",
+		descVolumeSnapshotLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeSnapshotStatusRestoreSize = prometheus.NewDesc(
+		"kube_volumesnapshot_status_restore_size_bytes",
+		"The minimum size of a volume restored from the volume snapshot.",
+		descVolumeSnapshotLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeSnapshotStatusError = prometheus.NewDesc(
+		"kube_volumesnapshot_status_error",
+		"Whether the last attempt to take or bind the volume snapshot failed.",
+		descVolumeSnapshotLabelsDefaultLabels,
+		nil,
+	)
+
+	descVolumeSnapshotContentLabelsDefaultLabels = []string{"volumesnapshotcontent"}
+
+	descVolumeSnapshotContentInfo = prometheus.NewDesc(
+		"kube_volumesnapshotcontent_info",
+		"Information about volume snapshot content.",
+		append(descVolumeSnapshotContentLabelsDefaultLabels, "driver", "deletion_policy", "volumesnapshot_namespace", "volumesnapshot"),
+		nil,
+	)
+	descVolumeSnapshotContentCreated = prometheus.NewDesc(
+		"kube_volumesnapshotcontent_created",
+		"Unix creation timestamp",
+		descVolumeSnapshotContentLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeSnapshotContentStatusReadyToUse = prometheus.NewDesc(
+		"kube_volumesnapshotcontent_status_ready_to_use",
+		"Whether the snapshot of the volume snapshot content is ready to be used to restore a volume.",
+		descVolumeSnapshotContentLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeSnapshotContentStatusRestoreSize = prometheus.NewDesc(
+		"kube_volumesnapshotcontent_status_restore_size_bytes",
+		"The minimum size of a volume restored from the snapshot of the volume snapshot content.",
+		descVolumeSnapshotContentLabelsDefaultLabels,
+		nil,
+	)
+	descVolumeSnapshotContentStatusError = prometheus.NewDesc(
+		"kube_volumesnapshotcontent_status_error",
+		"Whether the last attempt to take the snapshot of the volume snapshot content failed.",
+		descVolumeSnapshotContentLabelsDefaultLabels,
+		nil,
+	)
+)
+
+// RegisterVolumeSnapshotCollector registers a collector of the volume
+// snapshots in the given namespaces. Like the customresources collector it
+// lists them on every scrape.
+func RegisterVolumeSnapshotCollector(registry prometheus.Registerer, client rest.Interface, namespaces options.NamespaceList, opts *options.Options) {
+	registry.MustRegister(&volumeSnapshotCollector{store: restCustomResourceStore{client: client}, namespaces: namespaces, opts: opts})
+}
+
+// RegisterVolumeSnapshotContentCollector registers a collector of the volume
+// snapshot contents, which are cluster-scoped.
+func RegisterVolumeSnapshotContentCollector(registry prometheus.Registerer, client rest.Interface, opts *options.Options) {
+	registry.MustRegister(&volumeSnapshotContentCollector{store: restCustomResourceStore{client: client}, opts: opts})
+}
+
+// snapshotStatus returns the readiness, restore size and error presence of
+// the status of a snapshot object. Unset fields are not returned.
+func snapshotStatus(obj map[string]interface{}) (ready *bool, restoreSize *float64, failed bool) {
+	if v, ok, _ := unstructured.NestedBool(obj, "status", "readyToUse"); ok {
+		ready = &v
+	}
+	// The restore size of volume snapshots is a quantity, the one of
+	// volume snapshot contents a number of bytes.
+	if v, ok, _ := unstructured.NestedFieldNoCopy(obj, "status", "restoreSize"); ok {
+		switch size := v.(type) {
+		case int64:
+			f := float64(size)
+			restoreSize = &f
+		case float64:
+			restoreSize = &size
+		case string:
+			if q, err := resource.ParseQuantity(size); err == nil {
+				f := float64(q.Value())
+				restoreSize = &f
+			}
+		}
+	}
+	_, failed, _ = unstructured.NestedMap(obj, "status", "error")
+	return ready, restoreSize, failed
+}
+
+// volumeSnapshotCollector collects metrics about all volume snapshots in the
+// cluster.
+type volumeSnapshotCollector struct {
+	store      customResourceStore
+	namespaces options.NamespaceList
+	opts       *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (vc *volumeSnapshotCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descVolumeSnapshotInfo
+	ch <- descVolumeSnapshotCreated
+	ch <- descVolumeSnapshotStatusReadyToUse
+	ch <- descVolumeSnapshotStatusRestoreSize
+	ch <- descVolumeSnapshotStatusError
+}
+
+// Collect implements the prometheus.Collector interface.
+func (vc *volumeSnapshotCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, vc.store, volumeSnapshotResource, vc.namespaces, vc.opts, func(obj unstructured.Unstructured) {
+		vc.collectVolumeSnapshot(ch, obj)
+	})
+}
+
+func (vc *volumeSnapshotCollector) collectVolumeSnapshot(ch chan<- prometheus.Metric, s unstructured.Unstructured) {
+	defer recoverObjectError("volumesnapshot", &s)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{s.GetNamespace(), s.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	pvc, _, _ := unstructured.NestedString(s.Object, "spec", "source", "persistentVolumeClaimName")
+	class, _, _ := unstructured.NestedString(s.Object, "spec", "volumeSnapshotClassName")
+	content, _, _ := unstructured.NestedString(s.Object, "status", "boundVolumeSnapshotContentName")
+	addGauge(descVolumeSnapshotInfo, 1, pvc, class, content)
+
+	if t := s.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descVolumeSnapshotCreated, float64(t.Unix()))
+	}
+
+	ready, restoreSize, failed := snapshotStatus(s.Object)
+	if ready != nil {
+		addGauge(descVolumeSnapshotStatusReadyToUse, boolFloat64(*ready))
+	}
+	if restoreSize != nil {
+		addGauge(descVolumeSnapshotStatusRestoreSize, *restoreSize)
+	}
+	addGauge(descVolumeSnapshotStatusError, boolFloat64(failed))
+}
+
+// volumeSnapshotContentCollector collects metrics about all volume snapshot
+// contents in the cluster.
+type volumeSnapshotContentCollector struct {
+	store customResourceStore
+	opts  *options.Options
+}
+
+// Describe implements the prometheus.Collector interface.
+func (vc *volumeSnapshotContentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descVolumeSnapshotContentInfo
+	ch <- descVolumeSnapshotContentCreated
+	ch <- descVolumeSnapshotContentStatusReadyToUse
+	ch <- descVolumeSnapshotContentStatusRestoreSize
+	ch <- descVolumeSnapshotContentStatusError
+}
+
+// Collect implements the prometheus.Collector interface.
+func (vc *volumeSnapshotContentCollector) Collect(ch chan<- prometheus.Metric) {
+	collectCustomResourceObjects(ch, vc.store, volumeSnapshotContentResource, options.NamespaceList{""}, vc.opts, func(obj unstructured.Unstructured) {
+		vc.collectVolumeSnapshotContent(ch, obj)
+	})
+}
+
+func (vc *volumeSnapshotContentCollector) collectVolumeSnapshotContent(ch chan<- prometheus.Metric, c unstructured.Unstructured) {
+	defer recoverObjectError("volumesnapshotcontent", &c)
+
+	addGauge := func(desc *prometheus.Desc, v float64, lv ...string) {
+		lv = append([]string{c.GetName()}, lv...)
+		ch <- mustNewConstMetric(desc, prometheus.GaugeValue, v, lv...)
+	}
+
+	driver, _, _ := unstructured.NestedString(c.Object, "spec", "driver")
+	deletionPolicy, _, _ := unstructured.NestedString(c.Object, "spec", "deletionPolicy")
+	snapshotNamespace, _, _ := unstructured.NestedString(c.Object, "spec", "volumeSnapshotRef", "namespace")
+	snapshot, _, _ := unstructured.NestedString(c.Object, "spec", "volumeSnapshotRef", "name")
+	addGauge(descVolumeSnapshotContentInfo, 1, driver, deletionPolicy, snapshotNamespace, snapshot)
+
+	if t := c.GetCreationTimestamp(); !t.IsZero() {
+		addGauge(descVolumeSnapshotContentCreated, float64(t.Unix()))
+	}
+
+	ready, restoreSize, failed := snapshotStatus(c.Object)
+	if ready != nil {
+		addGauge(descVolumeSnapshotContentStatusReadyToUse, boolFloat64(*ready))
+	}
+	if restoreSize != nil {
+		addGauge(descVolumeSnapshotContentStatusRestoreSize, *restoreSize)
+	}
+	addGauge(descVolumeSnapshotContentStatusError, boolFloat64(failed))
+}
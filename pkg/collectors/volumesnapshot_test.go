@@ -0,0 +1,178 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package collectors
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/kube-state-metrics/pkg/metrics"
+	"k8s.io/kube-state-metrics/pkg/options"
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestVolumeSnapshotCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_volumesnapshot_info Information about volume snapshot.
+		# TYPE kube_volumesnapshot_info gauge
+		# HELP kube_volumesnapshot_created Unix creation timestamp
+		# TYPE kube_volumesnapshot_created gauge
+		# HELP kube_volumesnapshot_status_ready_to_use Whether the volume snapshot is ready to be used to restore a volume.
+		# TYPE kube_volumesnapshot_status_ready_to_use gauge
+		# HELP kube_volumesnapshot_status_restore_size_bytes The minimum size of a volume restored from the volume snapshot.
+		# TYPE kube_volumesnapshot_status_restore_size_bytes gauge
+		# HELP kube_volumesnapshot_status_error Whether the last attempt to take or bind the volume snapshot failed.
+		# TYPE kube_volumesnapshot_status_error gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"volumesnapshots": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "data-snapshot",
+					"namespace":         "ns1",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+				},
+				"spec": map[string]interface{}{
+					"source":                  map[string]interface{}{"persistentVolumeClaimName": "data"},
+					"volumeSnapshotClassName": "csi-snapclass",
+				},
+				"status": map[string]interface{}{
+					"boundVolumeSnapshotContentName": "snapcontent-1",
+					"readyToUse":                     true,
+					"restoreSize":                    "1Gi",
+				},
+			}},
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":      "stuck-snapshot",
+					"namespace": "ns2",
+				},
+				"spec": map[string]interface{}{
+					"source": map[string]interface{}{"persistentVolumeClaimName": "logs"},
+				},
+				"status": map[string]interface{}{
+					"readyToUse": false,
+					"error":      map[string]interface{}{"message": "failed to take snapshot"},
+				},
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_volumesnapshot_info{namespace="ns1",source_persistentvolumeclaim="data",volumesnapshot="data-snapshot",volumesnapshotclass="csi-snapclass",volumesnapshotcontent="snapcontent-1"} 1
+		kube_volumesnapshot_info{namespace="ns2",source_persistentvolumeclaim="logs",volumesnapshot="stuck-snapshot",volumesnapshotclass="",volumesnapshotcontent=""} 1
+		kube_volumesnapshot_created{namespace="ns1",volumesnapshot="data-snapshot"} 1.501569018e+09
+		kube_volumesnapshot_status_ready_to_use{namespace="ns1",volumesnapshot="data-snapshot"} 1
+		kube_volumesnapshot_status_ready_to_use{namespace="ns2",volumesnapshot="stuck-snapshot"} 0
+		kube_volumesnapshot_status_restore_size_bytes{namespace="ns1",volumesnapshot="data-snapshot"} 1.073741824e+09
+		kube_volumesnapshot_status_error{namespace="ns1",volumesnapshot="data-snapshot"} 0
+		kube_volumesnapshot_status_error{namespace="ns2",volumesnapshot="stuck-snapshot"} 1
+	`
+	vc := &volumeSnapshotCollector{store: store, namespaces: options.NamespaceList{""}, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(vc, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestVolumeSnapshotContentCollector(t *testing.T) {
+	const metadata = `
+		# HELP kube_volumesnapshotcontent_info Information about volume snapshot content.
+		# TYPE kube_volumesnapshotcontent_info gauge
+		# HELP kube_volumesnapshotcontent_created Unix creation timestamp
+		# TYPE kube_volumesnapshotcontent_created gauge
+		# HELP kube_volumesnapshotcontent_status_ready_to_use Whether the snapshot of the volume snapshot content is ready to be used to restore a volume.
+		# TYPE kube_volumesnapshotcontent_status_ready_to_use gauge
+		# HELP kube_volumesnapshotcontent_status_restore_size_bytes The minimum size of a volume restored from the snapshot of the volume snapshot content.
+		# TYPE kube_volumesnapshotcontent_status_restore_size_bytes gauge
+		# HELP kube_volumesnapshotcontent_status_error Whether the last attempt to take the snapshot of the volume snapshot content failed.
+		# TYPE kube_volumesnapshotcontent_status_error gauge
+	`
+	store := mockCustomResourceStore{objects: map[string][]unstructured.Unstructured{
+		"volumesnapshotcontents": {
+			{Object: map[string]interface{}{
+				"metadata": map[string]interface{}{
+					"name":              "snapcontent-1",
+					"creationTimestamp": "2017-08-01T06:30:18Z",
+				},
+				"spec": map[string]interface{}{
+					"driver":            "hostpath.csi.k8s.io",
+					"deletionPolicy":    "Delete",
+					"volumeSnapshotRef": map[string]interface{}{"namespace": "ns1", "name": "data-snapshot"},
+				},
+				"status": map[string]interface{}{
+					"readyToUse":  true,
+					"restoreSize": int64(1073741824),
+				},
+			}},
+		},
+	}}
+	want := metadata + `
+		kube_volumesnapshotcontent_info{deletion_policy="Delete",driver="hostpath.csi.k8s.io",volumesnapshot="data-snapshot",volumesnapshot_namespace="ns1",volumesnapshotcontent="snapcontent-1"} 1
+		kube_volumesnapshotcontent_created{volumesnapshotcontent="snapcontent-1"} 1.501569018e+09
+		kube_volumesnapshotcontent_status_ready_to_use{volumesnapshotcontent="snapcontent-1"} 1
+		kube_volumesnapshotcontent_status_restore_size_bytes{volumesnapshotcontent="snapcontent-1"} 1.073741824e+09
+		kube_volumesnapshotcontent_status_error{volumesnapshotcontent="snapcontent-1"} 0
+	`
+	vc := &volumeSnapshotContentCollector{store: store, opts: &options.Options{}}
+	if err := testutils.GatherAndCompare(vc, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+type notFoundCustomResourceStore struct{}
+
+func (notFoundCustomResourceStore) List(r CustomResource, namespace string) ([]unstructured.Unstructured, error) {
+	return nil, apierrors.NewNotFound(schema.GroupResource{Group: r.Group, Resource: r.Resource}, "")
+}
+
+func TestListSnapshotObjectsWithoutCRDs(t *testing.T) {
+	objs, err := listCustomResourceObjects(notFoundCustomResourceStore{}, volumeSnapshotResource, options.NamespaceList{"ns1", "ns2"})
+	if err != nil || len(objs) != 0 {
+		t.Errorf("expected no snapshots and no error without the snapshot resources, got %v, %v", objs, err)
+	}
+}
+
+func TestListSnapshotObjectsLeavesReadinessAlone(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	defer srv.Close()
+	client, err := rest.RESTClientFor(&rest.Config{
+		Host: srv.URL,
+		ContentConfig: rest.ContentConfig{
+			GroupVersion:         &schema.GroupVersion{},
+			NegotiatedSerializer: scheme.Codecs,
+		},
+		WrapTransport: metrics.ListWatchErrorTransport,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	metrics.ListWatchFailures = metrics.NewFailureTracker()
+	defer func() { metrics.ListWatchFailures = metrics.NewFailureTracker() }()
+
+	objs, err := listCustomResourceObjects(restCustomResourceStore{client: client}, volumeSnapshotResource, options.NamespaceList{""})
+	if err != nil || len(objs) != 0 {
+		t.Errorf("expected no snapshots and no error without the snapshot resources, got %v, %v", objs, err)
+	}
+	if since := metrics.ListWatchFailures.FailingSince(); len(since) != 0 {
+		t.Errorf("expected the 404 of the absent CRD not to be tracked as list failure, got %v", since)
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fixtures loads Kubernetes objects from YAML manifests into a fake
+// clientset, so that kube-state-metrics can run without a cluster.
+package fixtures
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+// NewClientset returns a fake clientset seeded with the objects of the
+// manifests at the given paths.
+func NewClientset(paths ...string) (*fake.Clientset, error) {
+	objs, err := Load(paths...)
+	if err != nil {
+		return nil, err
+	}
+	return fake.NewSimpleClientset(objs...), nil
+}
+
+// Load decodes the objects of the manifests at the given paths. A path is
+// either a YAML file, possibly with several documents, or a directory whose
+// .yaml and .yml files are loaded in lexical order.
+func Load(paths ...string) ([]runtime.Object, error) {
+	var objs []runtime.Object
+	for _, path := range paths {
+		files, err := manifestFiles(path)
+		if err != nil {
+			return nil, err
+		}
+		for _, file := range files {
+			fileObjs, err := loadFile(file)
+			if err != nil {
+				return nil, err
+			}
+			objs = append(objs, fileObjs...)
+		}
+	}
+	return objs, nil
+}
+
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	var files []string
+	for _, pattern := range []string{"*.yaml", "*.yml"} {
+		matches, err := filepath.Glob(filepath.Join(path, pattern))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, matches...)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+func loadFile(file string) ([]runtime.Object, error) {
+	b, err := ioutil.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var objs []runtime.Object
+	r := yaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+	for {
+		doc, err := r.Read()
+		if err == io.EOF {
+			return objs, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("reading %s failed: %v", file, err)
+		}
+		if len(bytes.TrimSpace(doc)) == 0 {
+			continue
+		}
+		js, err := yaml.ToJSON(doc)
+		if err != nil {
+			return nil, fmt.Errorf("converting %s to JSON failed: %v", file, err)
+		}
+		if string(js) == "null" {
+			continue
+		}
+		obj, _, err := scheme.Codecs.UniversalDeserializer().Decode(js, nil, nil)
+		if err != nil {
+			return nil, fmt.Errorf("decoding %s failed: %v", file, err)
+		}
+		objs = append(objs, obj)
+	}
+}
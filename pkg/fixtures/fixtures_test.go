@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package fixtures
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestNewClientset(t *testing.T) {
+	dir, err := ioutil.TempDir("", "fixtures")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifests := map[string]string{
+		"pods.yaml": `
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod1
+  namespace: ns1
+---
+# A comment only document.
+---
+apiVersion: v1
+kind: Pod
+metadata:
+  name: pod2
+  namespace: ns1
+`,
+		"nodes.yml": `
+apiVersion: v1
+kind: Node
+metadata:
+  name: node1
+`,
+		"README.md": `Not a manifest.`,
+	}
+	for name, content := range manifests {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	client, err := NewClientset(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	pods, err := client.CoreV1().Pods("ns1").List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(pods.Items) != 2 {
+		t.Errorf("want 2 pods, got %d", len(pods.Items))
+	}
+	nodes, err := client.CoreV1().Nodes().List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(nodes.Items) != 1 {
+		t.Errorf("want 1 node, got %d", len(nodes.Items))
+	}
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "invalid.yaml"), []byte("kind: Unknown\napiVersion: v1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewClientset(dir); err == nil {
+		t.Error("loading a manifest of an unknown kind succeeded")
+	}
+}
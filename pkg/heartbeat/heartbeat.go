@@ -0,0 +1,133 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package heartbeat periodically pushes the health of a kube-state-metrics
+// instance to a central endpoint, for setups where scraping the self metrics
+// of every instance is impractical.
+package heartbeat
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/golang/glog"
+)
+
+// Heartbeat is the JSON document posted on every interval.
+type Heartbeat struct {
+	Instance  string `json:"instance"`
+	Version   string `json:"version"`
+	Timestamp int64  `json:"timestamp"`
+	// Shard and TotalShards identify the objects the instance exposes the
+	// metrics of.
+	Shard       int `json:"shard"`
+	TotalShards int `json:"totalShards"`
+	// MetricsURL is the URL the metrics of the instance can be scraped at,
+	// so that a discovery service can derive scrape targets from heartbeats.
+	MetricsURL string `json:"metricsURL,omitempty"`
+	// Labels are attached to the scrape target, e.g. the cluster name.
+	Labels map[string]string `json:"labels,omitempty"`
+	// LastSyncTimestamps holds the unix timestamp of the last informer
+	// event per resource.
+	LastSyncTimestamps map[string]int64 `json:"lastSyncTimestamps"`
+}
+
+// Sender posts heartbeats to a URL.
+type Sender struct {
+	URL      string
+	Instance string
+	Version  string
+	// Shard and TotalShards are reported as they are.
+	Shard       int
+	TotalShards int
+	// MetricsURL and Labels announce the scrape target of the instance.
+	MetricsURL string
+	Labels     map[string]string
+	// LastSyncTimes returns the time of the last informer event per
+	// resource.
+	LastSyncTimes func() map[string]time.Time
+
+	client *http.Client
+	now    func() time.Time
+}
+
+// NewSender returns a Sender posting to url whose requests time out after
+// timeout.
+func NewSender(url, instance, version string, lastSyncTimes func() map[string]time.Time, timeout time.Duration) *Sender {
+	return &Sender{
+		URL:           url,
+		Instance:      instance,
+		Version:       version,
+		LastSyncTimes: lastSyncTimes,
+		client:        &http.Client{Timeout: timeout},
+		now:           time.Now,
+	}
+}
+
+// Run posts a heartbeat on every interval until stopCh is closed. Failures
+// are logged and retried on the next interval.
+func (s *Sender) Run(interval time.Duration, stopCh <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.Send(); err != nil {
+			glog.Errorf("sending heartbeat to %s failed: %v", s.URL, err)
+		}
+
+		select {
+		case <-stopCh:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Send posts a single heartbeat.
+func (s *Sender) Send() error {
+	hb := Heartbeat{
+		Instance:           s.Instance,
+		Version:            s.Version,
+		Timestamp:          s.now().Unix(),
+		Shard:              s.Shard,
+		TotalShards:        s.TotalShards,
+		MetricsURL:         s.MetricsURL,
+		Labels:             s.Labels,
+		LastSyncTimestamps: map[string]int64{},
+	}
+	for resource, t := range s.LastSyncTimes() {
+		hb.LastSyncTimestamps[resource] = t.Unix()
+	}
+
+	body, err := json.Marshal(hb)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.client.Post(s.URL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package heartbeat
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestSend(t *testing.T) {
+	var got Heartbeat
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("unexpected method %s", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("unexpected content type %s", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("decoding heartbeat failed: %v", err)
+		}
+	}))
+	defer srv.Close()
+
+	s := NewSender(srv.URL, "cluster-a", "v1.3.0", func() map[string]time.Time {
+		return map[string]time.Time{"pod": time.Unix(1500000000, 0)}
+	}, time.Second)
+	s.now = func() time.Time { return time.Unix(1500000060, 0) }
+	s.Shard, s.TotalShards = 1, 2
+	s.MetricsURL = "http://10.0.0.1:8080/metrics"
+	s.Labels = map[string]string{"cluster": "a"}
+
+	if err := s.Send(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := Heartbeat{
+		Instance:           "cluster-a",
+		Version:            "v1.3.0",
+		Timestamp:          1500000060,
+		Shard:              1,
+		TotalShards:        2,
+		MetricsURL:         "http://10.0.0.1:8080/metrics",
+		Labels:             map[string]string{"cluster": "a"},
+		LastSyncTimestamps: map[string]int64{"pod": 1500000000},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %#v, got %#v", want, got)
+	}
+}
+
+func TestSendUnexpectedStatus(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+
+	s := NewSender(srv.URL, "cluster-a", "v1.3.0", func() map[string]time.Time { return nil }, time.Second)
+	if err := s.Send(); err == nil {
+		t.Error("expected an error for a non 2xx response")
+	}
+}
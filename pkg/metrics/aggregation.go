@@ -0,0 +1,239 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io/ioutil"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+// AggregationRule describes a metric family computed from another one by
+// aggregating its series by a set of labels, similar to a Prometheus
+// recording rule like sum by (namespace) (source).
+type AggregationRule struct {
+	// Output is the name of the computed metric family.
+	Output string `json:"output"`
+	// Source is the name of the aggregated metric family.
+	Source string `json:"source"`
+	// By are the labels kept in the computed series. All other labels of
+	// the source series are aggregated away.
+	By []string `json:"by"`
+	// Operation is one of sum, count, min, max, avg or quantile.
+	Operation string `json:"operation"`
+	// Quantile is the φ-quantile between 0 and 1 computed by the quantile
+	// operation, like 0.95 for the 95th percentile.
+	Quantile float64 `json:"quantile,omitempty"`
+}
+
+// AggregationConfig is the format of the aggregation configuration file.
+type AggregationConfig struct {
+	Rules []AggregationRule `json:"rules"`
+}
+
+var aggregationOperations = map[string]func(values []float64) float64{
+	"sum": func(values []float64) float64 {
+		s := 0.0
+		for _, v := range values {
+			s += v
+		}
+		return s
+	},
+	"count": func(values []float64) float64 {
+		return float64(len(values))
+	},
+	"min": func(values []float64) float64 {
+		m := math.Inf(1)
+		for _, v := range values {
+			m = math.Min(m, v)
+		}
+		return m
+	},
+	"max": func(values []float64) float64 {
+		m := math.Inf(-1)
+		for _, v := range values {
+			m = math.Max(m, v)
+		}
+		return m
+	},
+	"avg": func(values []float64) float64 {
+		s := 0.0
+		for _, v := range values {
+			s += v
+		}
+		return s / float64(len(values))
+	},
+}
+
+// LoadAggregationRules reads and validates the aggregation rules of the
+// given YAML file.
+func LoadAggregationRules(path string) ([]AggregationRule, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseAggregationRules(b)
+}
+
+// ParseAggregationRules parses and validates YAML aggregation rules.
+func ParseAggregationRules(b []byte) ([]AggregationRule, error) {
+	var c AggregationConfig
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	outputs := map[string]bool{}
+	for i, r := range c.Rules {
+		if !model.IsValidMetricName(model.LabelValue(r.Output)) {
+			return nil, fmt.Errorf("rule %d: invalid output metric name %q", i, r.Output)
+		}
+		if outputs[r.Output] {
+			return nil, fmt.Errorf("rule %d: duplicate output metric name %q", i, r.Output)
+		}
+		outputs[r.Output] = true
+		if r.Source == "" {
+			return nil, fmt.Errorf("rule %d: missing source metric name", i)
+		}
+		if r.Operation == "quantile" {
+			if r.Quantile < 0 || r.Quantile > 1 {
+				return nil, fmt.Errorf("rule %d: quantile %v not between 0 and 1", i, r.Quantile)
+			}
+		} else if _, ok := aggregationOperations[r.Operation]; !ok {
+			return nil, fmt.Errorf("rule %d: unknown operation %q", i, r.Operation)
+		}
+		for _, l := range r.By {
+			if !model.LabelName(l).IsValid() {
+				return nil, fmt.Errorf("rule %d: invalid label name %q", i, l)
+			}
+		}
+	}
+	return c.Rules, nil
+}
+
+// AggregatingGatherer wraps a prometheus.Gatherer to add the metric families
+// computed by the given aggregation rules. Rules whose source family is not
+// gathered do not yield any family. Only gauge, counter and untyped source
+// families are aggregated.
+func AggregatingGatherer(r prometheus.Gatherer, rules []AggregationRule) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		metricFamilies, err := r.Gather()
+		if err != nil {
+			return nil, err
+		}
+
+		byName := make(map[string]*dto.MetricFamily, len(metricFamilies))
+		for _, mf := range metricFamilies {
+			byName[mf.GetName()] = mf
+		}
+
+		for _, rule := range rules {
+			source, ok := byName[rule.Source]
+			if !ok {
+				continue
+			}
+			if mf := aggregate(rule, source); mf != nil {
+				metricFamilies = append(metricFamilies, mf)
+			}
+		}
+		sort.Slice(metricFamilies, func(i, j int) bool {
+			return metricFamilies[i].GetName() < metricFamilies[j].GetName()
+		})
+
+		return metricFamilies, nil
+	})
+}
+
+func aggregate(rule AggregationRule, source *dto.MetricFamily) *dto.MetricFamily {
+	var value func(m *dto.Metric) float64
+	switch source.GetType() {
+	case dto.MetricType_GAUGE:
+		value = func(m *dto.Metric) float64 { return m.GetGauge().GetValue() }
+	case dto.MetricType_COUNTER:
+		value = func(m *dto.Metric) float64 { return m.GetCounter().GetValue() }
+	case dto.MetricType_UNTYPED:
+		value = func(m *dto.Metric) float64 { return m.GetUntyped().GetValue() }
+	default:
+		return nil
+	}
+
+	by := make(map[string]bool, len(rule.By))
+	for _, l := range rule.By {
+		by[l] = true
+	}
+
+	var (
+		keys   []string
+		labels = map[string][]*dto.LabelPair{}
+		values = map[string][]float64{}
+	)
+	for _, m := range source.Metric {
+		var lps []*dto.LabelPair
+		for _, lp := range m.Label {
+			if by[lp.GetName()] {
+				lps = append(lps, lp)
+			}
+		}
+		sort.Sort(prometheus.LabelPairSorter(lps))
+
+		key := labelPairsKey(lps)
+		if _, ok := values[key]; !ok {
+			keys = append(keys, key)
+			labels[key] = lps
+		}
+		values[key] = append(values[key], value(m))
+	}
+	sort.Strings(keys)
+
+	op := aggregationOperations[rule.Operation]
+	operation := rule.Operation
+	if rule.Operation == "quantile" {
+		op = func(values []float64) float64 { return quantile(rule.Quantile, values) }
+		operation = fmt.Sprintf("%v-quantile", rule.Quantile)
+	}
+	mf := &dto.MetricFamily{
+		Name: proto.String(rule.Output),
+		Help: proto.String(fmt.Sprintf("%s of %s by (%s).", operation, rule.Source, strings.Join(rule.By, ", "))),
+		Type: dto.MetricType_GAUGE.Enum(),
+	}
+	for _, key := range keys {
+		mf.Metric = append(mf.Metric, &dto.Metric{
+			Label: labels[key],
+			Gauge: &dto.Gauge{Value: proto.Float64(op(values[key]))},
+		})
+	}
+	return mf
+}
+
+// quantile returns the q-quantile of values, interpolating linearly between
+// the two nearest ranks like the quantile aggregation of PromQL.
+func quantile(q float64, values []float64) float64 {
+	sorted := append([]float64(nil), values...)
+	sort.Float64s(sorted)
+	rank := q * float64(len(sorted)-1)
+	lower := math.Floor(rank)
+	upper := math.Ceil(rank)
+	weight := rank - lower
+	return sorted[int(lower)]*(1-weight) + sorted[int(upper)]*weight
+}
@@ -0,0 +1,164 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestParseAggregationRules(t *testing.T) {
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{
+			config: `
+rules:
+- output: namespace_cpu_requests_cores
+  source: kube_pod_container_resource_requests_cpu_cores
+  by: [namespace]
+  operation: sum
+`,
+		},
+		{
+			config: `
+rules:
+- output: namespace-cpu
+  source: kube_pod_container_resource_requests_cpu_cores
+  operation: sum
+`,
+			err: `rule 0: invalid output metric name "namespace-cpu"`,
+		},
+		{
+			config: `
+rules:
+- output: namespace_cpu
+  source: kube_pod_container_resource_requests_cpu_cores
+  operation: sum
+- output: namespace_cpu
+  source: kube_pod_container_resource_limits_cpu_cores
+  operation: sum
+`,
+			err: `rule 1: duplicate output metric name "namespace_cpu"`,
+		},
+		{
+			config: `
+rules:
+- output: namespace_cpu
+  operation: sum
+`,
+			err: `rule 0: missing source metric name`,
+		},
+		{
+			config: `
+rules:
+- output: namespace_cpu
+  source: kube_pod_container_resource_requests_cpu_cores
+  operation: median
+`,
+			err: `rule 0: unknown operation "median"`,
+		},
+		{
+			config: `
+rules:
+- output: namespace_cpu_requests_p95_cores
+  source: kube_pod_container_resource_requests_cpu_cores
+  by: [namespace]
+  operation: quantile
+  quantile: 1.5
+`,
+			err: `rule 0: quantile 1.5 not between 0 and 1`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseAggregationRules([]byte(test.config))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("unexpected error: %v", err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestAggregatingGatherer(t *testing.T) {
+	r := prometheus.NewRegistry()
+	requests := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_container_resource_requests_cpu_cores",
+			Help: "The number of requested cpu cores by a container.",
+		},
+		[]string{"namespace", "pod", "container", "node"},
+	)
+	r.MustRegister(requests)
+	requests.WithLabelValues("ns1", "pod1", "c1", "node1").Set(0.5)
+	requests.WithLabelValues("ns1", "pod1", "c2", "node1").Set(0.25)
+	requests.WithLabelValues("ns1", "pod2", "c1", "node2").Set(1)
+	requests.WithLabelValues("ns2", "pod3", "c1", "node2").Set(2)
+
+	rules := []AggregationRule{
+		{Output: "namespace_cpu_requests_cores", Source: "kube_pod_container_resource_requests_cpu_cores", By: []string{"namespace"}, Operation: "sum"},
+		{Output: "node_containers", Source: "kube_pod_container_resource_requests_cpu_cores", By: []string{"node"}, Operation: "count"},
+		{Output: "cluster_cpu_requests_max_cores", Source: "kube_pod_container_resource_requests_cpu_cores", Operation: "max"},
+		{Output: "namespace_cpu_requests_p50_cores", Source: "kube_pod_container_resource_requests_cpu_cores", By: []string{"namespace"}, Operation: "quantile", Quantile: 0.5},
+		{Output: "namespace_cpu_requests_p95_cores", Source: "kube_pod_container_resource_requests_cpu_cores", By: []string{"namespace"}, Operation: "quantile", Quantile: 0.95},
+		{Output: "missing", Source: "kube_pod_container_resource_limits_cpu_cores", Operation: "sum"},
+	}
+
+	mfs, err := AggregatingGatherer(r, rules).Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b strings.Builder
+	for _, mf := range mfs {
+		if mf.GetName() == "kube_pod_container_resource_requests_cpu_cores" {
+			continue
+		}
+		if _, err := expfmt.MetricFamilyToText(&b, mf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := `# HELP cluster_cpu_requests_max_cores max of kube_pod_container_resource_requests_cpu_cores by ().
+# TYPE cluster_cpu_requests_max_cores gauge
+cluster_cpu_requests_max_cores 2
+# HELP namespace_cpu_requests_cores sum of kube_pod_container_resource_requests_cpu_cores by (namespace).
+# TYPE namespace_cpu_requests_cores gauge
+namespace_cpu_requests_cores{namespace="ns1"} 1.75
+namespace_cpu_requests_cores{namespace="ns2"} 2
+# HELP namespace_cpu_requests_p50_cores 0.5-quantile of kube_pod_container_resource_requests_cpu_cores by (namespace).
+# TYPE namespace_cpu_requests_p50_cores gauge
+namespace_cpu_requests_p50_cores{namespace="ns1"} 0.5
+namespace_cpu_requests_p50_cores{namespace="ns2"} 2
+# HELP namespace_cpu_requests_p95_cores 0.95-quantile of kube_pod_container_resource_requests_cpu_cores by (namespace).
+# TYPE namespace_cpu_requests_p95_cores gauge
+namespace_cpu_requests_p95_cores{namespace="ns1"} 0.95
+namespace_cpu_requests_p95_cores{namespace="ns2"} 2
+# HELP node_containers count of kube_pod_container_resource_requests_cpu_cores by (node).
+# TYPE node_containers gauge
+node_containers{node="node1"} 2
+node_containers{node="node2"} 2
+`
+	if got := b.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
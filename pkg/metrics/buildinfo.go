@@ -0,0 +1,49 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"k8s.io/kube-state-metrics/pkg/version"
+)
+
+var descBuildInfo = prometheus.NewDesc(
+	"kube_state_metrics_build_info",
+	"A metric with a constant '1' value labeled by the version, revision and Go version kube-state-metrics was built from.",
+	[]string{"version", "revision", "goversion"}, nil,
+)
+
+// BuildInfoCollector exposes the version of kube-state-metrics.
+type BuildInfoCollector struct {
+	version version.Version
+}
+
+// NewBuildInfoCollector returns a BuildInfoCollector of the given version.
+func NewBuildInfoCollector(v version.Version) *BuildInfoCollector {
+	return &BuildInfoCollector{version: v}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *BuildInfoCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descBuildInfo
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *BuildInfoCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(descBuildInfo, prometheus.GaugeValue, 1, c.version.Release, c.version.GitCommit, c.version.GoVersion)
+}
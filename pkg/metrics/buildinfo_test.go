@@ -0,0 +1,36 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/testutils"
+	"k8s.io/kube-state-metrics/pkg/version"
+)
+
+func TestBuildInfoCollector(t *testing.T) {
+	c := NewBuildInfoCollector(version.Version{Release: "v1.4.0", GitCommit: "3b2d4a1", GoVersion: "go1.10.3"})
+	want := `
+		# HELP kube_state_metrics_build_info A metric with a constant '1' value labeled by the version, revision and Go version kube-state-metrics was built from.
+		# TYPE kube_state_metrics_build_info gauge
+		kube_state_metrics_build_info{goversion="go1.10.3",revision="3b2d4a1",version="v1.4.0"} 1
+	`
+	if err := testutils.GatherAndCompare(c, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
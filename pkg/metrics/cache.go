@@ -0,0 +1,287 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// CachedHandler serves the metrics of a prometheus.Gatherer and caches the
+// rendered output until the generation reported by its generation function
+// changes or the output is older than its maximum age. Every response carries
+// an ETag so that scrapers sending If-None-Match get a 304 as long as nothing
+// changed. The ETag differs per format and content coding, as the responses
+// do.
+//
+// Metric families are rendered lazily, one at a time, so that scrapers
+// restricting the response to some families with include[] query parameters
+// don't pay for rendering the others.
+type CachedHandler struct {
+	gatherer   prometheus.Gatherer
+	generation func() uint64
+	maxAge     time.Duration
+	now        func() time.Time
+
+	mu    sync.Mutex
+	entry *cacheEntry
+}
+
+type cacheEntry struct {
+	generation uint64
+	gatheredAt time.Time
+	etag       string
+	families   []*dto.MetricFamily
+	// rendered holds the encoding of every family per format, nil for
+	// families which have not been requested yet.
+	rendered map[renderKey][][]byte
+}
+
+type renderKey struct {
+	format expfmt.Format
+	gzip   bool
+}
+
+// NewCachedHandler returns a CachedHandler for the given gatherer. The
+// generation function must return a different value whenever the gathered
+// metrics may have changed. As metrics derived from the current time change
+// without the generation changing, the output is never served for longer
+// than maxAge.
+func NewCachedHandler(g prometheus.Gatherer, generation func() uint64, maxAge time.Duration) *CachedHandler {
+	return &CachedHandler{
+		gatherer:   g,
+		generation: generation,
+		maxAge:     maxAge,
+		now:        time.Now,
+	}
+}
+
+// ServeHTTP implements the http.Handler interface.
+func (h *CachedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	key := renderKey{
+		format: expfmt.Negotiate(r.Header),
+		gzip:   acceptsGzip(r.Header),
+	}
+	include := includedFamilies(r.URL.Query())
+	etag, body, notModified, err := h.lookup(key, include, r.Header.Get("If-None-Match"))
+	if err != nil {
+		glog.Errorf("rendering metrics failed: %v", err)
+		http.Error(w, fmt.Sprintf("An error has occurred during metrics rendering:\n\n%s", err), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("ETag", etag)
+	w.Header().Add("Vary", "Accept")
+	w.Header().Add("Vary", "Accept-Encoding")
+	if notModified {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+	w.Header().Set("Content-Type", string(key.format))
+	if key.gzip {
+		// Concatenated gzip members form a valid gzip stream, so the
+		// separately compressed families can be written one after another.
+		w.Header().Set("Content-Encoding", "gzip")
+	}
+	for _, b := range body {
+		w.Write(b)
+	}
+}
+
+// lookup returns the current ETag and the rendered families, unless the ETag
+// matches ifNoneMatch. A nil include set selects all families.
+func (h *CachedHandler) lookup(key renderKey, include map[string]bool, ifNoneMatch string) (etag string, body [][]byte, notModified bool, err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if err := h.refresh(); err != nil {
+		return "", nil, false, err
+	}
+	etag = h.entry.etag
+	if include != nil {
+		etag = subsetETag(etag, include)
+	}
+	etag = representationETag(etag, key)
+	if etagMatches(ifNoneMatch, etag) {
+		return etag, nil, true, nil
+	}
+	body, err = h.entry.render(key, include)
+	return etag, body, false, err
+}
+
+// refresh gathers the metrics again if the cached entry is outdated.
+func (h *CachedHandler) refresh() error {
+	gen := h.generation()
+	now := h.now()
+	if h.entry != nil && h.entry.generation == gen && now.Sub(h.entry.gatheredAt) < h.maxAge {
+		return nil
+	}
+
+	families, err := h.gatherer.Gather()
+	if err != nil {
+		return err
+	}
+
+	h.entry = &cacheEntry{
+		generation: gen,
+		gatheredAt: now,
+		etag:       fmt.Sprintf(`"%x-%x"`, gen, now.UnixNano()),
+		families:   families,
+		rendered:   map[renderKey][][]byte{},
+	}
+	return nil
+}
+
+// render returns the encoding of the included families, encoding those
+// which have not been requested in the given format before.
+func (e *cacheEntry) render(key renderKey, include map[string]bool) ([][]byte, error) {
+	rendered, ok := e.rendered[key]
+	if !ok {
+		rendered = make([][]byte, len(e.families))
+		e.rendered[key] = rendered
+	}
+
+	var body [][]byte
+	for i, mf := range e.families {
+		if include != nil && !include[mf.GetName()] {
+			continue
+		}
+		if rendered[i] == nil {
+			b, err := encodeFamily(mf, key)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = b
+		}
+		body = append(body, rendered[i])
+	}
+	return body, nil
+}
+
+func encodeFamily(mf *dto.MetricFamily, key renderKey) ([]byte, error) {
+	var buf bytes.Buffer
+	if !key.gzip {
+		err := expfmt.NewEncoder(&buf, key.format).Encode(mf)
+		return buf.Bytes(), err
+	}
+
+	gz := getGzipWriter(&buf)
+	defer putGzipWriter(gz)
+	if err := expfmt.NewEncoder(gz, key.format).Encode(mf); err != nil {
+		return nil, err
+	}
+	if err := gz.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// includedFamilies returns the set of families selected with include[]
+// query parameters, or nil if all families are selected.
+func includedFamilies(query url.Values) map[string]bool {
+	names := append(query["include[]"], query["include"]...)
+	if len(names) == 0 {
+		return nil
+	}
+
+	include := map[string]bool{}
+	for _, name := range names {
+		for _, n := range strings.Split(name, ",") {
+			if n = strings.TrimSpace(n); n != "" {
+				include[n] = true
+			}
+		}
+	}
+	return include
+}
+
+// subsetETag derives the ETag of a response restricted to the included
+// families from the ETag of the full response.
+func subsetETag(etag string, include map[string]bool) string {
+	names := make([]string, 0, len(include))
+	for n := range include {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+
+	h := fnv.New64a()
+	for _, n := range names {
+		h.Write([]byte(n))
+		h.Write([]byte{0})
+	}
+	return fmt.Sprintf(`%s-%x"`, strings.TrimSuffix(etag, `"`), h.Sum64())
+}
+
+// representationETag derives the ETag of a response in the format and
+// content coding of key from the ETag of the metrics it encodes.
+func representationETag(etag string, key renderKey) string {
+	h := fnv.New64a()
+	h.Write([]byte(key.format))
+	if key.gzip {
+		h.Write([]byte{0})
+		h.Write([]byte("gzip"))
+	}
+	return fmt.Sprintf(`%s-%x"`, strings.TrimSuffix(etag, `"`), h.Sum64())
+}
+
+// etagMatches reports whether the If-None-Match header value matches the
+// given ETag.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// acceptsGzip reports whether the Accept-Encoding header accepts gzip. A
+// q-value of zero refuses it.
+func acceptsGzip(h http.Header) bool {
+	for _, part := range strings.Split(h.Get("Accept-Encoding"), ",") {
+		params := strings.Split(part, ";")
+		if !strings.EqualFold(strings.TrimSpace(params[0]), "gzip") {
+			continue
+		}
+		for _, param := range params[1:] {
+			param = strings.TrimSpace(param)
+			if !strings.HasPrefix(param, "q=") && !strings.HasPrefix(param, "Q=") {
+				continue
+			}
+			q, err := strconv.ParseFloat(param[2:], 64)
+			if err != nil || q <= 0 {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
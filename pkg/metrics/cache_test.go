@@ -0,0 +1,240 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+type countingGatherer struct {
+	prometheus.Gatherer
+	gathered int
+}
+
+func (g *countingGatherer) Gather() ([]*dto.MetricFamily, error) {
+	g.gathered++
+	return g.Gatherer.Gather()
+}
+
+func TestCachedHandler(t *testing.T) {
+	r := prometheus.NewRegistry()
+	c := prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test1",
+		Help: "test1 help",
+	})
+	r.MustRegister(c)
+	g := &countingGatherer{Gatherer: r}
+
+	var generation uint64
+	now := time.Unix(1500000000, 0)
+	h := NewCachedHandler(g, func() uint64 { return generation }, time.Minute)
+	h.now = func() time.Time { return now }
+
+	get := func(etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	rr := get("")
+	if rr.Code != http.StatusOK {
+		t.Fatalf("unexpected status %d", rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "test1 0") {
+		t.Errorf("unexpected body:\n%s", rr.Body.String())
+	}
+	etag := rr.Header().Get("ETag")
+	if etag == "" {
+		t.Fatal("missing ETag")
+	}
+
+	c.Inc()
+	if rr := get(etag); rr.Code != http.StatusNotModified {
+		t.Errorf("unchanged generation: want status %d, got %d", http.StatusNotModified, rr.Code)
+	}
+	if rr := get(""); !strings.Contains(rr.Body.String(), "test1 0") {
+		t.Errorf("unchanged generation: expected cached body, got:\n%s", rr.Body.String())
+	}
+	if g.gathered != 1 {
+		t.Errorf("unchanged generation: want 1 gathering, got %d", g.gathered)
+	}
+
+	generation++
+	rr = get(etag)
+	if rr.Code != http.StatusOK {
+		t.Errorf("changed generation: want status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if !strings.Contains(rr.Body.String(), "test1 1") {
+		t.Errorf("changed generation: unexpected body:\n%s", rr.Body.String())
+	}
+	etag = rr.Header().Get("ETag")
+
+	now = now.Add(time.Minute)
+	if rr := get(etag); rr.Code != http.StatusOK {
+		t.Errorf("expired cache: want status %d, got %d", http.StatusOK, rr.Code)
+	}
+	if g.gathered != 3 {
+		t.Errorf("want 3 gatherings, got %d", g.gathered)
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	tests := []struct {
+		IfNoneMatch string
+		Wanted      bool
+	}{
+		{IfNoneMatch: "", Wanted: false},
+		{IfNoneMatch: `"1-2"`, Wanted: true},
+		{IfNoneMatch: `W/"1-2"`, Wanted: true},
+		{IfNoneMatch: `"0-1", "1-2"`, Wanted: true},
+		{IfNoneMatch: `"0-1"`, Wanted: false},
+		{IfNoneMatch: "*", Wanted: true},
+	}
+
+	for _, test := range tests {
+		if got := etagMatches(test.IfNoneMatch, `"1-2"`); got != test.Wanted {
+			t.Errorf("If-None-Match %q: want %t, got %t", test.IfNoneMatch, test.Wanted, got)
+		}
+	}
+}
+
+func TestAcceptsGzip(t *testing.T) {
+	tests := []struct {
+		AcceptEncoding string
+		Wanted         bool
+	}{
+		{AcceptEncoding: "", Wanted: false},
+		{AcceptEncoding: "gzip", Wanted: true},
+		{AcceptEncoding: "deflate, GZIP", Wanted: true},
+		{AcceptEncoding: "gzip;q=0.5", Wanted: true},
+		{AcceptEncoding: "gzip; q=1.0, identity", Wanted: true},
+		{AcceptEncoding: "gzip;q=0", Wanted: false},
+		{AcceptEncoding: "gzip;q=0.000, identity", Wanted: false},
+		{AcceptEncoding: "x-gzip", Wanted: false},
+	}
+
+	for _, test := range tests {
+		h := http.Header{}
+		h.Set("Accept-Encoding", test.AcceptEncoding)
+		if got := acceptsGzip(h); got != test.Wanted {
+			t.Errorf("Accept-Encoding %q: want %t, got %t", test.AcceptEncoding, test.Wanted, got)
+		}
+	}
+}
+
+func TestCachedHandlerInclude(t *testing.T) {
+	r := prometheus.NewRegistry()
+	for _, name := range []string{"test1", "test2", "test3"} {
+		r.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+			Name: name,
+			Help: name + " help",
+		}))
+	}
+	h := NewCachedHandler(r, func() uint64 { return 0 }, time.Minute)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", target, nil))
+		return rr
+	}
+
+	full := get("/metrics")
+	subset := get("/metrics?include[]=test1&include[]=test3")
+
+	body := subset.Body.String()
+	if !strings.Contains(body, "test1 0") || !strings.Contains(body, "test3 0") || strings.Contains(body, "test2") {
+		t.Errorf("unexpected body:\n%s", body)
+	}
+	if full.Header().Get("ETag") == subset.Header().Get("ETag") {
+		t.Error("expected different ETags for the full and the restricted response")
+	}
+
+	gz := httptest.NewRequest("GET", "/metrics?include[]=test2", nil)
+	gz.Header.Set("Accept-Encoding", "gzip")
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, gz)
+	zr, err := gzip.NewReader(rr.Body)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	b, err := ioutil.ReadAll(zr)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !strings.Contains(string(b), "test2 0") || strings.Contains(string(b), "test1") {
+		t.Errorf("unexpected gzipped body:\n%s", b)
+	}
+}
+
+func TestCachedHandlerRepresentations(t *testing.T) {
+	r := prometheus.NewRegistry()
+	r.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "test1",
+		Help: "test1 help",
+	}))
+	h := NewCachedHandler(r, func() uint64 { return 0 }, time.Minute)
+
+	get := func(accept, acceptEncoding, etag string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		req.Header.Set("Accept", accept)
+		req.Header.Set("Accept-Encoding", acceptEncoding)
+		if etag != "" {
+			req.Header.Set("If-None-Match", etag)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr
+	}
+
+	text := get("", "", "")
+	if vary := text.Header()["Vary"]; len(vary) != 2 || vary[0] != "Accept" || vary[1] != "Accept-Encoding" {
+		t.Errorf("expected to vary on Accept and Accept-Encoding, got %v", vary)
+	}
+	etags := map[string]bool{text.Header().Get("ETag"): true}
+	for _, rr := range []*httptest.ResponseRecorder{
+		get("", "gzip", ""),
+		get(string(expfmt.FmtProtoDelim), "", ""),
+		get(string(expfmt.FmtProtoDelim), "gzip", ""),
+	} {
+		etags[rr.Header().Get("ETag")] = true
+	}
+	if len(etags) != 4 {
+		t.Errorf("expected a different ETag per representation, got %v", etags)
+	}
+
+	// The ETag of one representation doesn't validate another.
+	if rr := get("", "gzip", text.Header().Get("ETag")); rr.Code != http.StatusOK {
+		t.Errorf("want status %d for the ETag of another representation, got %d", http.StatusOK, rr.Code)
+	}
+	if rr := get("", "", text.Header().Get("ETag")); rr.Code != http.StatusNotModified {
+		t.Errorf("want status %d for the ETag of the same representation, got %d", http.StatusNotModified, rr.Code)
+	}
+}
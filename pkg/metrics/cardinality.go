@@ -0,0 +1,149 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// defaultCardinalityTop is the number of most frequent values reported per
+// label if the request doesn't specify it.
+const defaultCardinalityTop = 5
+
+// cardinalityReport holds the number of series per metric family.
+type cardinalityReport struct {
+	Series   int                 `json:"series"`
+	Families []familyCardinality `json:"families"`
+}
+
+// familyCardinality holds the number of series of a metric family and the
+// cardinality of each of its labels.
+type familyCardinality struct {
+	Name   string             `json:"name"`
+	Series int                `json:"series"`
+	Labels []labelCardinality `json:"labels,omitempty"`
+}
+
+// labelCardinality holds the number of distinct values of a label and its
+// most frequent values.
+type labelCardinality struct {
+	Name      string       `json:"name"`
+	Values    int          `json:"values"`
+	TopValues []valueCount `json:"topValues"`
+}
+
+// valueCount holds the number of series with a label value.
+type valueCount struct {
+	Value  string `json:"value"`
+	Series int    `json:"series"`
+}
+
+// newCardinalityReport computes the cardinality of the given metric families,
+// reporting the top most frequent values per label. Families and labels are
+// sorted by descending cardinality.
+func newCardinalityReport(metricFamilies []*dto.MetricFamily, top int) cardinalityReport {
+	var report cardinalityReport
+	for _, mf := range metricFamilies {
+		family := familyCardinality{Name: mf.GetName(), Series: len(mf.Metric)}
+		report.Series += family.Series
+
+		var (
+			labelOrder []string
+			counts     = map[string]map[string]int{}
+		)
+		for _, m := range mf.Metric {
+			for _, lp := range m.Label {
+				values, ok := counts[lp.GetName()]
+				if !ok {
+					values = map[string]int{}
+					counts[lp.GetName()] = values
+					labelOrder = append(labelOrder, lp.GetName())
+				}
+				values[lp.GetValue()]++
+			}
+		}
+		for _, name := range labelOrder {
+			family.Labels = append(family.Labels, newLabelCardinality(name, counts[name], top))
+		}
+		sort.SliceStable(family.Labels, func(i, j int) bool {
+			if family.Labels[i].Values != family.Labels[j].Values {
+				return family.Labels[i].Values > family.Labels[j].Values
+			}
+			return family.Labels[i].Name < family.Labels[j].Name
+		})
+
+		report.Families = append(report.Families, family)
+	}
+	sort.SliceStable(report.Families, func(i, j int) bool {
+		if report.Families[i].Series != report.Families[j].Series {
+			return report.Families[i].Series > report.Families[j].Series
+		}
+		return report.Families[i].Name < report.Families[j].Name
+	})
+	return report
+}
+
+func newLabelCardinality(name string, values map[string]int, top int) labelCardinality {
+	lc := labelCardinality{Name: name, Values: len(values), TopValues: []valueCount{}}
+	for v, n := range values {
+		lc.TopValues = append(lc.TopValues, valueCount{Value: v, Series: n})
+	}
+	sort.Slice(lc.TopValues, func(i, j int) bool {
+		if lc.TopValues[i].Series != lc.TopValues[j].Series {
+			return lc.TopValues[i].Series > lc.TopValues[j].Series
+		}
+		return lc.TopValues[i].Value < lc.TopValues[j].Value
+	})
+	if len(lc.TopValues) > top {
+		lc.TopValues = lc.TopValues[:top]
+	}
+	return lc
+}
+
+// CardinalityHandler serves the cardinality report of the metrics of g as
+// JSON. The top query parameter sets the number of most frequent values
+// reported per label.
+func CardinalityHandler(g prometheus.Gatherer) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		top := defaultCardinalityTop
+		if v := r.URL.Query().Get("top"); v != "" {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				http.Error(w, "top has to be a non-negative integer", http.StatusBadRequest)
+				return
+			}
+			top = n
+		}
+
+		metricFamilies, err := g.Gather()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		enc.Encode(newCardinalityReport(metricFamilies, top))
+	})
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestCardinalityHandler(t *testing.T) {
+	r := prometheus.NewRegistry()
+	phase := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_status_phase",
+			Help: "The pods current phase.",
+		},
+		[]string{"namespace", "pod", "phase"},
+	)
+	nodeInfo := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_node_info",
+			Help: "Information about a cluster node.",
+		},
+		[]string{"node"},
+	)
+	r.MustRegister(phase, nodeInfo)
+	for _, p := range []struct{ namespace, pod string }{
+		{"ns1", "pod1"},
+		{"ns1", "pod2"},
+		{"ns2", "pod3"},
+	} {
+		for _, ph := range []string{"Pending", "Running"} {
+			phase.WithLabelValues(p.namespace, p.pod, ph).Set(0)
+		}
+	}
+	nodeInfo.WithLabelValues("node1").Set(1)
+
+	h := CardinalityHandler(r)
+
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/debug/cardinality?top=1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("want status %d, got %d", http.StatusOK, rr.Code)
+	}
+	var got cardinalityReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &got); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := cardinalityReport{
+		Series: 7,
+		Families: []familyCardinality{
+			{
+				Name:   "kube_pod_status_phase",
+				Series: 6,
+				Labels: []labelCardinality{
+					{Name: "pod", Values: 3, TopValues: []valueCount{{Value: "pod1", Series: 2}}},
+					{Name: "namespace", Values: 2, TopValues: []valueCount{{Value: "ns1", Series: 4}}},
+					{Name: "phase", Values: 2, TopValues: []valueCount{{Value: "Pending", Series: 3}}},
+				},
+			},
+			{
+				Name:   "kube_node_info",
+				Series: 1,
+				Labels: []labelCardinality{
+					{Name: "node", Values: 1, TopValues: []valueCount{{Value: "node1", Series: 1}}},
+				},
+			},
+		},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want report %+v, got %+v", want, got)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/debug/cardinality?top=-1", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("want status %d for a negative top, got %d", http.StatusBadRequest, rr.Code)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestClientCertHandler(t *testing.T) {
+	h := ClientCertHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("ok"))
+	}))
+
+	tests := []struct {
+		Desc   string
+		TLS    *tls.ConnectionState
+		Wanted int
+	}{
+		{Desc: "plain HTTP", Wanted: http.StatusUnauthorized},
+		{Desc: "TLS without client certificate", TLS: &tls.ConnectionState{}, Wanted: http.StatusUnauthorized},
+		{Desc: "verified client certificate", TLS: &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{{}}}}, Wanted: http.StatusOK},
+	}
+	for _, test := range tests {
+		r := httptest.NewRequest("GET", "/metrics", nil)
+		r.TLS = test.TLS
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, r)
+		if w.Code != test.Wanted {
+			t.Errorf("Test error for Desc: %s. Want: %d. Got: %d", test.Desc, test.Wanted, w.Code)
+		}
+	}
+}
+
+func TestClientCertTLSConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "clientcert")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "scraper-ca"},
+		NotBefore:             time.Now(),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	caFile := filepath.Join(dir, "ca.crt")
+	if err := ioutil.WriteFile(caFile, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644); err != nil {
+		t.Fatal(err)
+	}
+	invalidFile := filepath.Join(dir, "invalid.crt")
+	if err := ioutil.WriteFile(invalidFile, []byte("not a certificate"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := ClientCertTLSConfig(caFile)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if config.ClientAuth != tls.VerifyClientCertIfGiven || len(config.ClientCAs.Subjects()) != 1 {
+		t.Errorf("expected client certificates to be verified against the CA, got %+v", config)
+	}
+
+	for _, file := range []string{invalidFile, filepath.Join(dir, "missing.crt")} {
+		if _, err := ClientCertTLSConfig(file); err == nil {
+			t.Errorf("expected an error for CA bundle %s", file)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// CompatGatherer additionally exposes renamed metric families under their old
+// names for a deprecation window. renames maps old names to the names the
+// families were renamed to. Old names which are still gathered themselves are
+// left alone.
+func CompatGatherer(g prometheus.Gatherer, renames map[string]string) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		metricFamilies, err := g.Gather()
+		if err != nil || len(renames) == 0 {
+			return metricFamilies, err
+		}
+
+		byName := make(map[string]*dto.MetricFamily, len(metricFamilies))
+		for _, mf := range metricFamilies {
+			byName[mf.GetName()] = mf
+		}
+
+		added := false
+		for old, renamed := range renames {
+			mf, ok := byName[renamed]
+			if !ok {
+				continue
+			}
+			if _, ok := byName[old]; ok {
+				continue
+			}
+			// The metrics are copied, so that wrapping gatherers can change
+			// the series of either family independently.
+			metrics := make([]*dto.Metric, 0, len(mf.Metric))
+			for _, m := range mf.Metric {
+				metrics = append(metrics, proto.Clone(m).(*dto.Metric))
+			}
+			metricFamilies = append(metricFamilies, &dto.MetricFamily{
+				Name:   proto.String(old),
+				Help:   proto.String(fmt.Sprintf("Deprecated: renamed to %s. %s", renamed, mf.GetHelp())),
+				Type:   mf.Type,
+				Metric: metrics,
+			})
+			added = true
+		}
+		if added {
+			sort.Slice(metricFamilies, func(i, j int) bool {
+				return metricFamilies[i].GetName() < metricFamilies[j].GetName()
+			})
+		}
+
+		return metricFamilies, nil
+	})
+}
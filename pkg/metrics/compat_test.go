@@ -0,0 +1,116 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestCompatGatherer(t *testing.T) {
+	r := prometheus.NewRegistry()
+	renamed := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kube_node_spec_unschedulable_info",
+		Help: "Whether a node can schedule new pods.",
+	}, []string{"node"})
+	renamed.WithLabelValues("node1").Set(1)
+	kept := prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "kube_node_info",
+		Help: "Information about a cluster node.",
+	})
+	kept.Set(1)
+	r.MustRegister(renamed, kept)
+
+	g := CompatGatherer(r, map[string]string{
+		"kube_node_spec_unschedulable": "kube_node_spec_unschedulable_info",
+		// The old family is still exposed itself.
+		"kube_node_info": "kube_node_spec_unschedulable_info",
+		// The renamed family is not exposed.
+		"kube_pod_info": "kube_pod_info_v2",
+	})
+
+	mfs, err := g.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b strings.Builder
+	for _, mf := range mfs {
+		if _, err := expfmt.MetricFamilyToText(&b, mf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := `# HELP kube_node_info Information about a cluster node.
+# TYPE kube_node_info gauge
+kube_node_info 1
+# HELP kube_node_spec_unschedulable Deprecated: renamed to kube_node_spec_unschedulable_info. Whether a node can schedule new pods.
+# TYPE kube_node_spec_unschedulable gauge
+kube_node_spec_unschedulable{node="node1"} 1
+# HELP kube_node_spec_unschedulable_info Whether a node can schedule new pods.
+# TYPE kube_node_spec_unschedulable_info gauge
+kube_node_spec_unschedulable_info{node="node1"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestCompatGathererRelabeled(t *testing.T) {
+	r := prometheus.NewRegistry()
+	renamed := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "new_metric",
+		Help: "Renamed.",
+	}, []string{"namespace"})
+	renamed.WithLabelValues("a").Set(1)
+	r.MustRegister(renamed)
+
+	rules, err := ParseRelabelRules([]byte(`
+rules:
+- source_labels: [__name__, namespace]
+  regex: old_metric;a
+  action: drop
+- source_labels: [namespace]
+  target_label: namespace
+  replacement: x-$1
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// The old family is relabeled independently of the renamed one.
+	mfs, err := RelabelingGatherer(CompatGatherer(r, map[string]string{"old_metric": "new_metric"}), rules).Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b strings.Builder
+	for _, mf := range mfs {
+		if _, err := expfmt.MetricFamilyToText(&b, mf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := `# HELP new_metric Renamed.
+# TYPE new_metric gauge
+new_metric{namespace="x-a"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
@@ -0,0 +1,153 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	scrapeCollectorSuccessName = "kube_state_metrics_scrape_collector_success"
+	scrapeErrorsTotalName      = "kube_state_metrics_scrape_errors_total"
+	scrapeCompletenessName     = "kube_state_metrics_scrape_completeness"
+)
+
+// CollectorGenerateDurationMetric observes how long generating the metrics of
+// each collector takes, so that the collectors dominating the latency of
+// /metrics can be disabled or sharded.
+var CollectorGenerateDurationMetric = prometheus.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Name:    "kube_state_metrics_collector_generate_duration_seconds",
+		Help:    "Duration of generating the metrics of a collector in a scrape",
+		Buckets: prometheus.ExponentialBuckets(0.001, 4, 8),
+	},
+	[]string{"collector"},
+)
+
+// ScrapeResultGatherer wraps the gatherer of a single collector to report
+// whether the collector was rendered successfully and how many of its scrapes
+// have failed so far. A scrape fails if the gather returns an error, e.g. as
+// the collector failed to list its objects. The error is logged instead of
+// returned, so that the metrics gathered nonetheless are still exposed. The
+// duration of every gather is observed in CollectorGenerateDurationMetric.
+// The returned gatherer is a TextGatherer if g is one.
+func ScrapeResultGatherer(collector string, g prometheus.Gatherer) prometheus.Gatherer {
+	srg := &scrapeResultGatherer{collector: collector, g: g}
+	if tg, ok := g.(TextGatherer); ok {
+		return textScrapeResultGatherer{srg, tg}
+	}
+	return srg
+}
+
+type scrapeResultGatherer struct {
+	collector string
+	g         prometheus.Gatherer
+
+	mu     sync.Mutex
+	errors float64
+}
+
+// Gather implements the prometheus.Gatherer interface.
+func (srg *scrapeResultGatherer) Gather() ([]*dto.MetricFamily, error) {
+	start := time.Now()
+	metricFamilies, err := srg.g.Gather()
+	return srg.result(start, metricFamilies, err), nil
+}
+
+// result adds the result of a gather started at start to its metric
+// families.
+func (srg *scrapeResultGatherer) result(start time.Time, metricFamilies []*dto.MetricFamily, err error) []*dto.MetricFamily {
+	CollectorGenerateDurationMetric.WithLabelValues(srg.collector).Observe(time.Since(start).Seconds())
+	success := err == nil
+	if !success {
+		glog.Errorf("error gathering metrics of collector %s: %v", srg.collector, err)
+	}
+
+	srg.mu.Lock()
+	if !success {
+		srg.errors++
+	}
+	total := srg.errors
+	srg.mu.Unlock()
+
+	label := []*dto.LabelPair{{Name: proto.String("collector"), Value: proto.String(srg.collector)}}
+	return append(metricFamilies,
+		&dto.MetricFamily{
+			Name:   proto.String(scrapeCollectorSuccessName),
+			Help:   proto.String("Whether the collector was rendered successfully in this scrape."),
+			Type:   dto.MetricType_GAUGE.Enum(),
+			Metric: []*dto.Metric{{Label: label, Gauge: &dto.Gauge{Value: proto.Float64(boolFloat64(success))}}},
+		},
+		&dto.MetricFamily{
+			Name:   proto.String(scrapeErrorsTotalName),
+			Help:   proto.String("Total number of scrapes in which the collector failed to render."),
+			Type:   dto.MetricType_COUNTER.Enum(),
+			Metric: []*dto.Metric{{Label: label, Counter: &dto.Counter{Value: proto.Float64(total)}}},
+		},
+	)
+}
+
+type textScrapeResultGatherer struct {
+	*scrapeResultGatherer
+	tg TextGatherer
+}
+
+// GatherText implements the TextGatherer interface.
+func (tsrg textScrapeResultGatherer) GatherText() ([]*dto.MetricFamily, []TextSource, error) {
+	start := time.Now()
+	metricFamilies, sources, err := tsrg.tg.GatherText()
+	return tsrg.result(start, metricFamilies, err), sources, nil
+}
+
+// completenessGatherer wraps a prometheus.Gatherer of the metrics of several
+// collectors to add the fraction of collectors rendered successfully, as
+// reported by the gatherers returned by ScrapeResultGatherer.
+func completenessGatherer(g prometheus.Gatherer) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		metricFamilies, err := g.Gather()
+
+		for _, mf := range metricFamilies {
+			if mf.GetName() != scrapeCollectorSuccessName || len(mf.Metric) == 0 {
+				continue
+			}
+			var succeeded float64
+			for _, m := range mf.Metric {
+				succeeded += m.GetGauge().GetValue()
+			}
+			return append(metricFamilies, &dto.MetricFamily{
+				Name:   proto.String(scrapeCompletenessName),
+				Help:   proto.String("Fraction of the collectors of this scrape that were rendered successfully."),
+				Type:   dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(succeeded / float64(len(mf.Metric)))}}},
+			}), err
+		}
+		return metricFamilies, err
+	})
+}
+
+func boolFloat64(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
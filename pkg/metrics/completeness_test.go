@@ -0,0 +1,217 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+)
+
+// failingCollector fails to collect its metrics whenever fail returns true.
+type failingCollector struct {
+	desc *prometheus.Desc
+	fail func() bool
+}
+
+func (c failingCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.desc
+}
+
+func (c failingCollector) Collect(ch chan<- prometheus.Metric) {
+	if c.fail() {
+		ch <- prometheus.NewInvalidMetric(c.desc, errors.New("listing failed"))
+		return
+	}
+	ch <- prometheus.MustNewConstMetric(c.desc, prometheus.GaugeValue, 1)
+}
+
+func TestScrapeCompleteness(t *testing.T) {
+	cg := CollectorGatherers{}
+	for _, name := range []string{"nodes", "pods", "services"} {
+		r := prometheus.NewRegistry()
+		// The pods collector fails on every scrape.
+		failing := name == "pods"
+		r.MustRegister(failingCollector{
+			desc: prometheus.NewDesc("kube_"+name, name+" help", nil, nil),
+			fail: func() bool { return failing },
+		})
+		cg[name] = ScrapeResultGatherer(name, r)
+	}
+
+	tests := []struct {
+		Desc       string
+		Collectors []string
+		Want       string
+	}{
+		{
+			Desc:       "all collectors",
+			Collectors: []string{"nodes", "pods", "services"},
+			Want: `# HELP kube_state_metrics_scrape_collector_success Whether the collector was rendered successfully in this scrape.
+# TYPE kube_state_metrics_scrape_collector_success gauge
+kube_state_metrics_scrape_collector_success{collector="nodes"} 1
+kube_state_metrics_scrape_collector_success{collector="pods"} 0
+kube_state_metrics_scrape_collector_success{collector="services"} 1
+# HELP kube_state_metrics_scrape_errors_total Total number of scrapes in which the collector failed to render.
+# TYPE kube_state_metrics_scrape_errors_total counter
+kube_state_metrics_scrape_errors_total{collector="nodes"} 0
+kube_state_metrics_scrape_errors_total{collector="pods"} 1
+kube_state_metrics_scrape_errors_total{collector="services"} 0
+# HELP kube_state_metrics_scrape_completeness Fraction of the collectors of this scrape that were rendered successfully.
+# TYPE kube_state_metrics_scrape_completeness gauge
+kube_state_metrics_scrape_completeness 0.6666666666666666
+`,
+		},
+		{
+			Desc:       "selected collectors",
+			Collectors: []string{"nodes", "services"},
+			Want: `# HELP kube_state_metrics_scrape_collector_success Whether the collector was rendered successfully in this scrape.
+# TYPE kube_state_metrics_scrape_collector_success gauge
+kube_state_metrics_scrape_collector_success{collector="nodes"} 1
+kube_state_metrics_scrape_collector_success{collector="services"} 1
+# HELP kube_state_metrics_scrape_errors_total Total number of scrapes in which the collector failed to render.
+# TYPE kube_state_metrics_scrape_errors_total counter
+kube_state_metrics_scrape_errors_total{collector="nodes"} 0
+kube_state_metrics_scrape_errors_total{collector="services"} 0
+# HELP kube_state_metrics_scrape_completeness Fraction of the collectors of this scrape that were rendered successfully.
+# TYPE kube_state_metrics_scrape_completeness gauge
+kube_state_metrics_scrape_completeness 1
+`,
+		},
+		{
+			Desc:       "failing collector",
+			Collectors: []string{"pods"},
+			Want: `# HELP kube_state_metrics_scrape_collector_success Whether the collector was rendered successfully in this scrape.
+# TYPE kube_state_metrics_scrape_collector_success gauge
+kube_state_metrics_scrape_collector_success{collector="pods"} 0
+# HELP kube_state_metrics_scrape_errors_total Total number of scrapes in which the collector failed to render.
+# TYPE kube_state_metrics_scrape_errors_total counter
+kube_state_metrics_scrape_errors_total{collector="pods"} 2
+# HELP kube_state_metrics_scrape_completeness Fraction of the collectors of this scrape that were rendered successfully.
+# TYPE kube_state_metrics_scrape_completeness gauge
+kube_state_metrics_scrape_completeness 0
+`,
+		},
+	}
+
+	for _, test := range tests {
+		g, err := cg.Select(test.Collectors)
+		if err != nil {
+			t.Fatalf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+		}
+		mfs, err := g.Gather()
+		if err != nil {
+			t.Fatalf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+		}
+		var b strings.Builder
+		for _, mf := range mfs {
+			if !strings.HasPrefix(mf.GetName(), "kube_state_metrics_") {
+				continue
+			}
+			if _, err := expfmt.MetricFamilyToText(&b, mf); err != nil {
+				t.Fatalf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+			}
+		}
+		if got := b.String(); got != test.Want {
+			t.Errorf("Test error for Desc: %s. Want:\n%s\nGot:\n%s", test.Desc, test.Want, got)
+		}
+	}
+}
+
+func TestScrapeResultGathererDuration(t *testing.T) {
+	CollectorGenerateDurationMetric.Reset()
+	defer CollectorGenerateDurationMetric.Reset()
+
+	g := ScrapeResultGatherer("nodes", prometheus.NewRegistry())
+	for i := 0; i < 3; i++ {
+		if _, err := g.Gather(); err != nil {
+			t.Fatalf("unexpected gather error: %v", err)
+		}
+	}
+
+	r := prometheus.NewRegistry()
+	r.MustRegister(CollectorGenerateDurationMetric)
+	mfs, err := r.Gather()
+	if err != nil {
+		t.Fatalf("unexpected gather error: %v", err)
+	}
+	if len(mfs) != 1 || len(mfs[0].Metric) != 1 {
+		t.Fatalf("expected a single histogram, got %v", mfs)
+	}
+	m := mfs[0].Metric[0]
+	if l := m.GetLabel(); len(l) != 1 || l[0].GetName() != "collector" || l[0].GetValue() != "nodes" {
+		t.Errorf("expected the histogram of the nodes collector, got labels %v", l)
+	}
+	if n := m.GetHistogram().GetSampleCount(); n != 3 {
+		t.Errorf("expected 3 observations, got %d", n)
+	}
+}
+
+func TestScrapeResultGathererConcurrentScrapes(t *testing.T) {
+	// Gathers of the failing collector alternate with gathers of the other
+	// one, so that each failure happens while the other collector is gathered.
+	failing, failed := make(chan struct{}), make(chan struct{})
+	failingRegistry := prometheus.NewRegistry()
+	failingRegistry.MustRegister(failingCollector{
+		desc: prometheus.NewDesc("kube_pods", "pods help", nil, nil),
+		fail: func() bool {
+			<-failing
+			return true
+		},
+	})
+	succeedingRegistry := prometheus.NewRegistry()
+	succeedingRegistry.MustRegister(failingCollector{
+		desc: prometheus.NewDesc("kube_nodes", "nodes help", nil, nil),
+		fail: func() bool {
+			failing <- struct{}{}
+			<-failed
+			return false
+		},
+	})
+	pods := ScrapeResultGatherer("pods", failingRegistry)
+	nodes := ScrapeResultGatherer("nodes", succeedingRegistry)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err := pods.Gather(); err != nil {
+			t.Errorf("unexpected gather error: %v", err)
+		}
+		close(failed)
+	}()
+	mfs, err := nodes.Gather()
+	if err != nil {
+		t.Fatalf("unexpected gather error: %v", err)
+	}
+	wg.Wait()
+
+	for _, mf := range mfs {
+		if mf.GetName() != scrapeCollectorSuccessName {
+			continue
+		}
+		if v := mf.Metric[0].GetGauge().GetValue(); v != 1 {
+			t.Errorf("expected the nodes collector to succeed while the pods collector failed, got %v", v)
+		}
+		return
+	}
+	t.Errorf("expected %s to be gathered", scrapeCollectorSuccessName)
+}
@@ -0,0 +1,66 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// concurrentGatherers gathers the metrics of gs like prometheus.Gatherers,
+// but runs up to workers of the gatherers at the same time. The gathered
+// metrics are merged in the order of gs, so the output does not depend on
+// which gatherer finishes first.
+func concurrentGatherers(gs []prometheus.Gatherer, workers int) prometheus.Gatherer {
+	if workers < 1 {
+		workers = 1
+	}
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		type result struct {
+			metricFamilies []*dto.MetricFamily
+			err            error
+		}
+		results := make([]result, len(gs))
+
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, workers)
+		for i, g := range gs {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(i int, g prometheus.Gatherer) {
+				defer wg.Done()
+				defer func() { <-sem }()
+				mfs, err := g.Gather()
+				results[i] = result{metricFamilies: mfs, err: err}
+			}(i, g)
+		}
+		wg.Wait()
+
+		// prometheus.Gatherers merges and checks the families of all
+		// gatherers and collects their errors.
+		merged := make(prometheus.Gatherers, 0, len(results))
+		for _, r := range results {
+			r := r
+			merged = append(merged, gathererFunc(func() ([]*dto.MetricFamily, error) {
+				return r.metricFamilies, r.err
+			}))
+		}
+		return merged.Gather()
+	})
+}
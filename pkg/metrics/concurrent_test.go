@@ -0,0 +1,86 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+func TestConcurrentGatherers(t *testing.T) {
+	const workers = 2
+	var (
+		mu            sync.Mutex
+		running, most int
+	)
+	gatherer := func(name string, delay time.Duration, err error) prometheus.Gatherer {
+		return gathererFunc(func() ([]*dto.MetricFamily, error) {
+			mu.Lock()
+			running++
+			if running > most {
+				most = running
+			}
+			mu.Unlock()
+			time.Sleep(delay)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			return []*dto.MetricFamily{{
+				Name:   proto.String(name),
+				Help:   proto.String(name),
+				Type:   dto.MetricType_GAUGE.Enum(),
+				Metric: []*dto.Metric{{Gauge: &dto.Gauge{Value: proto.Float64(1)}}},
+			}}, err
+		})
+	}
+	// The first gatherers take longest, so they finish last.
+	var gs []prometheus.Gatherer
+	for i := 0; i < 6; i++ {
+		var err error
+		if i == 3 {
+			err = errors.New("failed")
+		}
+		gs = append(gs, gatherer(fmt.Sprintf("metric_%d", 5-i), time.Duration(6-i)*10*time.Millisecond, err))
+	}
+
+	mfs, err := concurrentGatherers(gs, workers).Gather()
+	if err == nil || !strings.Contains(err.Error(), "failed") {
+		t.Errorf("expected the error of a gatherer, got %v", err)
+	}
+	var got []string
+	for _, mf := range mfs {
+		got = append(got, mf.GetName())
+	}
+	want := []string{"metric_0", "metric_1", "metric_2", "metric_3", "metric_4", "metric_5"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("expected families %v, got %v", want, got)
+	}
+	if most > workers {
+		t.Errorf("expected at most %d gatherers to run at the same time, got %d", workers, most)
+	}
+	if most < 2 {
+		t.Errorf("expected gatherers to run concurrently, got %d at the same time", most)
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"strings"
+	"sync"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DeprecatedAPIUsageMetric marks the APIs the apiserver reported as deprecated
+// while kube-state-metrics used them, so that collectors needing a newer API
+// version are known before the cluster is upgraded.
+var DeprecatedAPIUsageMetric = prometheus.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Name: "kube_state_metrics_deprecated_api_usage",
+		Help: "Whether the apiserver returned a deprecation warning for a used API",
+	},
+	[]string{"group_version", "resource"},
+)
+
+// deprecationWarningCode is the code of the Warning headers apiservers send
+// for requests to deprecated APIs.
+const deprecationWarningCode = "299 "
+
+// loggedDeprecations holds the APIs whose deprecation has been logged, so
+// that every one is only logged once.
+var loggedDeprecations = struct {
+	sync.Mutex
+	apis map[string]bool
+}{apis: map[string]bool{}}
+
+// DeprecationWarningTransport returns a transport recording the Warning
+// headers of the responses of rt in DeprecatedAPIUsageMetric. It can be used
+// as the WrapTransport of client configs. Older apiservers don't send any
+// warnings.
+func DeprecationWarningTransport(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := rt.RoundTrip(req)
+		if err != nil {
+			return resp, err
+		}
+		for _, w := range resp.Header["Warning"] {
+			if !strings.HasPrefix(w, deprecationWarningCode) {
+				continue
+			}
+			groupVersion, resource, ok := apiResource(req.URL.Path)
+			if !ok {
+				continue
+			}
+			DeprecatedAPIUsageMetric.WithLabelValues(groupVersion, resource).Set(1)
+
+			loggedDeprecations.Lock()
+			if api := groupVersion + " " + resource; !loggedDeprecations.apis[api] {
+				loggedDeprecations.apis[api] = true
+				glog.Warningf("Deprecated API %s used: %s", api, w)
+			}
+			loggedDeprecations.Unlock()
+		}
+		return resp, nil
+	})
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// apiResource returns the group version and resource of the path of an API
+// request, e.g. extensions/v1beta1 and ingresses for
+// /apis/extensions/v1beta1/namespaces/default/ingresses/web.
+func apiResource(path string) (groupVersion, resource string, ok bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case len(segments) >= 3 && segments[0] == "api":
+		groupVersion, segments = segments[1], segments[2:]
+	case len(segments) >= 4 && segments[0] == "apis":
+		groupVersion, segments = segments[1]+"/"+segments[2], segments[3:]
+	default:
+		return "", "", false
+	}
+	if segments[0] == "watch" {
+		segments = segments[1:]
+	}
+	// Namespaced resources follow the namespace, namespaces themselves don't.
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		segments = segments[2:]
+	}
+	if len(segments) == 0 || segments[0] == "" {
+		return "", "", false
+	}
+	return groupVersion, segments[0], true
+}
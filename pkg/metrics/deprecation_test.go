@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestDeprecationWarningTransport(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/apis/extensions/v1beta1/namespaces/default/ingresses":
+			w.Header().Add("Warning", `299 - "extensions/v1beta1 Ingress is deprecated in v1.14+, unavailable in v1.22+; use networking.k8s.io/v1 Ingress"`)
+		case "/apis/extensions/v1beta1/watch/daemonsets":
+			w.Header().Add("Warning", `299 - "extensions/v1beta1 DaemonSet is deprecated in v1.9+, unavailable in v1.16+; use apps/v1 DaemonSet"`)
+		case "/api/v1/pods":
+			// Other warnings are not about deprecations.
+			w.Header().Add("Warning", `199 - "miscellaneous warning"`)
+		}
+	}))
+	defer server.Close()
+
+	DeprecatedAPIUsageMetric.Reset()
+	client := &http.Client{Transport: DeprecationWarningTransport(http.DefaultTransport)}
+	for _, path := range []string{
+		"/apis/extensions/v1beta1/namespaces/default/ingresses",
+		"/apis/extensions/v1beta1/namespaces/default/ingresses",
+		"/apis/extensions/v1beta1/watch/daemonsets",
+		"/api/v1/pods",
+	} {
+		resp, err := client.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("unexpected error requesting %s: %v", path, err)
+		}
+		resp.Body.Close()
+	}
+
+	want := `
+		# HELP kube_state_metrics_deprecated_api_usage Whether the apiserver returned a deprecation warning for a used API
+		# TYPE kube_state_metrics_deprecated_api_usage gauge
+		kube_state_metrics_deprecated_api_usage{group_version="extensions/v1beta1",resource="daemonsets"} 1
+		kube_state_metrics_deprecated_api_usage{group_version="extensions/v1beta1",resource="ingresses"} 1
+	`
+	if err := testutils.GatherAndCompare(DeprecatedAPIUsageMetric, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
+
+func TestAPIResource(t *testing.T) {
+	cases := []struct {
+		path         string
+		groupVersion string
+		resource     string
+		ok           bool
+	}{
+		{path: "/api/v1/pods", groupVersion: "v1", resource: "pods", ok: true},
+		{path: "/api/v1/namespaces/default/pods/web", groupVersion: "v1", resource: "pods", ok: true},
+		{path: "/api/v1/namespaces/default", groupVersion: "v1", resource: "namespaces", ok: true},
+		{path: "/api/v1/namespaces", groupVersion: "v1", resource: "namespaces", ok: true},
+		{path: "/apis/batch/v2alpha1/watch/namespaces/default/cronjobs", groupVersion: "batch/v2alpha1", resource: "cronjobs", ok: true},
+		{path: "/apis/apps/v1beta1/statefulsets", groupVersion: "apps/v1beta1", resource: "statefulsets", ok: true},
+		{path: "/api/v1"},
+		{path: "/apis/apps"},
+		{path: "/version"},
+	}
+	for _, c := range cases {
+		groupVersion, resource, ok := apiResource(c.path)
+		if groupVersion != c.groupVersion || resource != c.resource || ok != c.ok {
+			t.Errorf("%s: expected %q, %q, %t, got %q, %q, %t", c.path, c.groupVersion, c.resource, c.ok, groupVersion, resource, ok)
+		}
+	}
+}
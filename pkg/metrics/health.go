@@ -0,0 +1,110 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FailureTracker records since when the requests of each resource have been
+// failing without a successful request in between.
+type FailureTracker struct {
+	mu    sync.Mutex
+	now   func() time.Time
+	since map[string]time.Time
+}
+
+// NewFailureTracker returns a FailureTracker without failures.
+func NewFailureTracker() *FailureTracker {
+	return &FailureTracker{now: time.Now, since: map[string]time.Time{}}
+}
+
+// Failed records a failed request of the resource.
+func (t *FailureTracker) Failed(resource string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if _, ok := t.since[resource]; !ok {
+		t.since[resource] = t.now()
+	}
+}
+
+// Succeeded records a successful request of the resource, which ends its
+// failures.
+func (t *FailureTracker) Succeeded(resource string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	delete(t.since, resource)
+}
+
+// FailingSince returns the time of the first of the consecutive failed
+// requests of every resource whose last request failed.
+func (t *FailureTracker) FailingSince() map[string]time.Time {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	since := make(map[string]time.Time, len(t.since))
+	for resource, s := range t.since {
+		since[resource] = s
+	}
+	return since
+}
+
+// OKHandler answers every request with 200 OK, e.g. for liveness probes.
+func OKHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+}
+
+// ReadyzHandler returns a handler answering 200 OK once the informers of all
+// resources of syncStatus have synced, and 503 Service Unavailable with the
+// reasons otherwise. Until then the metrics of the unsynced resources are
+// incomplete. With a positive threshold, it also answers 503 while the list
+// and watch requests of a resource have been failing for longer than it.
+func ReadyzHandler(syncStatus func() map[string]bool, failingSince func() map[string]time.Time, threshold time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reasons []string
+		for resource, synced := range syncStatus() {
+			if !synced {
+				reasons = append(reasons, fmt.Sprintf("informer of %s not synced", resource))
+			}
+		}
+		if threshold > 0 {
+			now := time.Now()
+			for resource, since := range failingSince() {
+				if d := now.Sub(since); d > threshold {
+					reasons = append(reasons, fmt.Sprintf("list and watch of %s failing for %s", resource, d.Round(time.Second)))
+				}
+			}
+		}
+		if len(reasons) > 0 {
+			sort.Strings(reasons)
+			http.Error(w, strings.Join(reasons, "\n"), http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("ok"))
+	})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestFailureTracker(t *testing.T) {
+	now := time.Unix(1501569018, 0)
+	tracker := NewFailureTracker()
+	tracker.now = func() time.Time { return now }
+
+	tracker.Failed("pods")
+	now = now.Add(time.Minute)
+	tracker.Failed("pods")
+	tracker.Failed("nodes")
+	tracker.Succeeded("nodes")
+
+	since := tracker.FailingSince()
+	if len(since) != 1 || !since["pods"].Equal(time.Unix(1501569018, 0)) {
+		t.Errorf("expected pods to be failing since the first failure only, got %v", since)
+	}
+}
+
+func TestReadyzHandler(t *testing.T) {
+	tests := []struct {
+		Desc         string
+		SyncStatus   map[string]bool
+		FailingSince map[string]time.Time
+		Threshold    time.Duration
+		WantCode     int
+		WantBody     string
+	}{
+		{
+			Desc:       "all synced",
+			SyncStatus: map[string]bool{"pods": true, "nodes": true},
+			Threshold:  5 * time.Minute,
+			WantCode:   http.StatusOK,
+			WantBody:   "ok",
+		},
+		{
+			Desc:       "not synced",
+			SyncStatus: map[string]bool{"pods": false, "nodes": true},
+			Threshold:  5 * time.Minute,
+			WantCode:   http.StatusServiceUnavailable,
+			WantBody:   "informer of pods not synced\n",
+		},
+		{
+			Desc:         "failing within threshold",
+			SyncStatus:   map[string]bool{"pods": true},
+			FailingSince: map[string]time.Time{"pods": time.Now().Add(-time.Minute)},
+			Threshold:    5 * time.Minute,
+			WantCode:     http.StatusOK,
+			WantBody:     "ok",
+		},
+		{
+			Desc:         "failing beyond threshold",
+			SyncStatus:   map[string]bool{"pods": true},
+			FailingSince: map[string]time.Time{"pods": time.Now().Add(-10 * time.Minute)},
+			Threshold:    5 * time.Minute,
+			WantCode:     http.StatusServiceUnavailable,
+			WantBody:     "list and watch of pods failing for 10m0s\n",
+		},
+		{
+			Desc:         "failures ignored without threshold",
+			SyncStatus:   map[string]bool{"pods": true},
+			FailingSince: map[string]time.Time{"pods": time.Now().Add(-10 * time.Minute)},
+			WantCode:     http.StatusOK,
+			WantBody:     "ok",
+		},
+	}
+	for _, test := range tests {
+		h := ReadyzHandler(
+			func() map[string]bool { return test.SyncStatus },
+			func() map[string]time.Time { return test.FailingSince },
+			test.Threshold,
+		)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest("GET", "/readyz", nil))
+		if w.Code != test.WantCode || w.Body.String() != test.WantBody {
+			t.Errorf("Test error for Desc: %s. Want %d %q, got %d %q.", test.Desc, test.WantCode, test.WantBody, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	descInformerSynced = prometheus.NewDesc(
+		"kube_state_metrics_informer_synced",
+		"Whether the informers of a resource have completed their initial list.",
+		[]string{"resource"}, nil,
+	)
+	descInformerLastEvent = prometheus.NewDesc(
+		"kube_state_metrics_informer_last_event_timestamp_seconds",
+		"Unix timestamp of the last event the informers of a resource delivered.",
+		[]string{"resource"}, nil,
+	)
+)
+
+// InformerCollector exposes the state of the informers of every resource, so
+// that a stuck watch can be told apart from a resource that just doesn't
+// change.
+type InformerCollector struct {
+	syncStatus    func() map[string]bool
+	lastSyncTimes func() map[string]time.Time
+}
+
+// NewInformerCollector returns an InformerCollector. syncStatus returns
+// whether the informers of each resource have synced, lastSyncTimes the time
+// of the last event of each synced resource.
+func NewInformerCollector(syncStatus func() map[string]bool, lastSyncTimes func() map[string]time.Time) *InformerCollector {
+	return &InformerCollector{syncStatus: syncStatus, lastSyncTimes: lastSyncTimes}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *InformerCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descInformerSynced
+	ch <- descInformerLastEvent
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *InformerCollector) Collect(ch chan<- prometheus.Metric) {
+	for resource, synced := range c.syncStatus() {
+		v := 0.0
+		if synced {
+			v = 1
+		}
+		ch <- prometheus.MustNewConstMetric(descInformerSynced, prometheus.GaugeValue, v, resource)
+	}
+	for resource, t := range c.lastSyncTimes() {
+		ch <- prometheus.MustNewConstMetric(descInformerLastEvent, prometheus.GaugeValue, float64(t.Unix()), resource)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+	"time"
+
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestInformerCollector(t *testing.T) {
+	c := NewInformerCollector(
+		func() map[string]bool {
+			return map[string]bool{"node": true, "pod": false}
+		},
+		func() map[string]time.Time {
+			return map[string]time.Time{"node": time.Unix(1501569018, 0)}
+		},
+	)
+	want := `
+		# HELP kube_state_metrics_informer_synced Whether the informers of a resource have completed their initial list.
+		# TYPE kube_state_metrics_informer_synced gauge
+		kube_state_metrics_informer_synced{resource="node"} 1
+		kube_state_metrics_informer_synced{resource="pod"} 0
+		# HELP kube_state_metrics_informer_last_event_timestamp_seconds Unix timestamp of the last event the informers of a resource delivered.
+		# TYPE kube_state_metrics_informer_last_event_timestamp_seconds gauge
+		kube_state_metrics_informer_last_event_timestamp_seconds{resource="node"} 1.501569018e+09
+	`
+	if err := testutils.GatherAndCompare(c, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,71 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// NamespaceFilteredGatherer wraps a prometheus.Gatherer to only expose the
+// metrics of the namespaces allowed by the given function. Metrics without a
+// namespace label are dropped, as are metric families left without metrics.
+func NamespaceFilteredGatherer(g prometheus.Gatherer, allowed func(namespace string) bool) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		metricFamilies, err := g.Gather()
+		if err != nil {
+			return nil, err
+		}
+
+		// Most metrics share a few namespaces, so only ask once per
+		// namespace and gathering.
+		decisions := map[string]bool{}
+		filtered := make([]*dto.MetricFamily, 0, len(metricFamilies))
+		for _, mf := range metricFamilies {
+			metrics := make([]*dto.Metric, 0, len(mf.Metric))
+			for _, m := range mf.Metric {
+				namespace, found := labelValue(m, namespaceLabel)
+				if !found {
+					continue
+				}
+				ok, decided := decisions[namespace]
+				if !decided {
+					ok = allowed(namespace)
+					decisions[namespace] = ok
+				}
+				if ok {
+					metrics = append(metrics, m)
+				}
+			}
+			if len(metrics) == 0 {
+				continue
+			}
+			mf.Metric = metrics
+			filtered = append(filtered, mf)
+		}
+		return filtered, nil
+	})
+}
+
+func labelValue(m *dto.Metric, name string) (string, bool) {
+	for _, lp := range m.Label {
+		if lp.GetName() == name {
+			return lp.GetValue(), true
+		}
+	}
+	return "", false
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNamespaceFilteredGatherer(t *testing.T) {
+	r := prometheus.NewRegistry()
+	g := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "test1",
+			Help: "test1 help",
+		},
+		[]string{"namespace", "pod"},
+	)
+	o := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "test2",
+			Help: "test2 help",
+		},
+		[]string{"namespace"},
+	)
+	c := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "test3",
+			Help: "test3 help",
+		},
+	)
+	g.WithLabelValues("team-a", "pod1").Set(1)
+	g.WithLabelValues("team-a", "pod2").Set(1)
+	g.WithLabelValues("team-b", "pod3").Set(1)
+	o.WithLabelValues("team-b").Set(1)
+	c.Inc()
+	r.MustRegister(g, o, c)
+
+	asked := map[string]int{}
+	res, err := NamespaceFilteredGatherer(r, func(namespace string) bool {
+		asked[namespace]++
+		return namespace == "team-a"
+	}).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := map[string][]string{}
+	for _, mf := range res {
+		for _, m := range mf.Metric {
+			pod, _ := labelValue(m, "pod")
+			got[mf.GetName()] = append(got[mf.GetName()], pod)
+		}
+	}
+	want := map[string][]string{"test1": {"pod1", "pod2"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("want %v, got %v", want, got)
+	}
+	if want := map[string]int{"team-a": 1, "team-b": 1}; !reflect.DeepEqual(asked, want) {
+		t.Errorf("want every namespace to be checked once, got %v", asked)
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// ListWatchErrorsTotalMetric counts the failed list and watch requests of the
+// informers. A collector whose requests keep failing silently serves the
+// objects of its last successful list.
+var ListWatchErrorsTotalMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kube_state_metrics_list_watch_errors_total",
+		Help: "Total number of list and watch requests to the apiserver that failed",
+	},
+	[]string{"resource", "verb"},
+)
+
+// ListWatchFailures tracks the resources whose list and watch requests keep
+// failing.
+var ListWatchFailures = NewFailureTracker()
+
+type untrackedKey struct{}
+
+// UntrackedContext returns a context whose requests ListWatchErrorTransport
+// ignores. Collectors listing objects at scrape time instead of through
+// informers use it, so that e.g. the 404 of an absent CRD is neither counted
+// as list error nor makes kube-state-metrics unready.
+func UntrackedContext(ctx context.Context) context.Context {
+	return context.WithValue(ctx, untrackedKey{}, true)
+}
+
+// ListWatchErrorTransport returns a transport counting the list and watch
+// requests of rt that fail or are answered with an error status in
+// ListWatchErrorsTotalMetric and tracking them in ListWatchFailures. It can
+// be used as the WrapTransport of client configs. Requests with an
+// UntrackedContext are passed on as they are.
+func ListWatchErrorTransport(rt http.RoundTripper) http.RoundTripper {
+	return roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		resp, err := rt.RoundTrip(req)
+		if req.Method != http.MethodGet || req.Context().Value(untrackedKey{}) != nil {
+			return resp, err
+		}
+		_, resource, ok := apiResource(req.URL.Path)
+		if !ok {
+			return resp, err
+		}
+		if err == nil && resp.StatusCode < http.StatusBadRequest {
+			ListWatchFailures.Succeeded(resource)
+			return resp, err
+		}
+		ListWatchFailures.Failed(resource)
+		verb := "list"
+		if isWatch(req) {
+			verb = "watch"
+		}
+		ListWatchErrorsTotalMetric.WithLabelValues(resource, verb).Inc()
+		return resp, err
+	})
+}
+
+// isWatch returns whether req is a watch request, either by its watch
+// parameter or by the legacy watch path prefix.
+func isWatch(req *http.Request) bool {
+	if w := req.URL.Query().Get("watch"); w == "true" || w == "1" {
+		return true
+	}
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	switch {
+	case len(segments) > 2 && segments[0] == "api":
+		return segments[2] == "watch"
+	case len(segments) > 3 && segments[0] == "apis":
+		return segments[3] == "watch"
+	}
+	return false
+}
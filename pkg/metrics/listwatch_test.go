@@ -0,0 +1,72 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestListWatchErrorTransport(t *testing.T) {
+	ListWatchErrorsTotalMetric.Reset()
+	defer ListWatchErrorsTotalMetric.Reset()
+	ListWatchFailures = NewFailureTracker()
+	defer func() { ListWatchFailures = NewFailureTracker() }()
+
+	rt := ListWatchErrorTransport(roundTripperFunc(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Query().Get("fail") {
+		case "status":
+			return &http.Response{StatusCode: http.StatusForbidden}, nil
+		case "error":
+			return nil, errors.New("connection refused")
+		}
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}))
+
+	for _, url := range []string{
+		"https://apiserver/api/v1/pods?fail=status",
+		"https://apiserver/api/v1/pods?fail=status&watch=true",
+		"https://apiserver/api/v1/watch/namespaces/default/pods?fail=error",
+		"https://apiserver/apis/apps/v1/namespaces/watch/deployments?fail=error",
+		"https://apiserver/apis/apps/v1/deployments",
+		"https://apiserver/healthz?fail=status",
+	} {
+		rt.RoundTrip(httptest.NewRequest(http.MethodGet, url, nil))
+	}
+	// Lists at scrape time, e.g. of an absent CRD, are not tracked.
+	req := httptest.NewRequest(http.MethodGet, "https://apiserver/apis/snapshot.storage.k8s.io/v1beta1/volumesnapshots?fail=status", nil)
+	rt.RoundTrip(req.WithContext(UntrackedContext(context.Background())))
+
+	want := `
+		# HELP kube_state_metrics_list_watch_errors_total Total number of list and watch requests to the apiserver that failed
+		# TYPE kube_state_metrics_list_watch_errors_total counter
+		kube_state_metrics_list_watch_errors_total{resource="deployments",verb="list"} 1
+		kube_state_metrics_list_watch_errors_total{resource="pods",verb="list"} 1
+		kube_state_metrics_list_watch_errors_total{resource="pods",verb="watch"} 2
+	`
+	if err := testutils.GatherAndCompare(ListWatchErrorsTotalMetric, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+	if since := ListWatchFailures.FailingSince(); len(since) != 1 || since["pods"].IsZero() {
+		t.Errorf("expected only pods to be failing, got %v", since)
+	}
+}
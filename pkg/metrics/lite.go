@@ -0,0 +1,190 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// liteObjectLabels are the labels identifying individual objects, or being
+// unique per object, which are dropped in lite mode.
+var liteObjectLabels = map[string]bool{
+	"apiservice":                     true,
+	"certificatesigningrequest":      true,
+	"configmap":                      true,
+	"container":                      true,
+	"container_id":                   true,
+	"cluster_ip":                     true,
+	"created_by_name":                true,
+	"cronjob":                        true,
+	"csi_volume_handle":              true,
+	"csidriver":                      true,
+	"csinode":                        true,
+	"customresourcedefinition":       true,
+	"daemonset":                      true,
+	"deployment":                     true,
+	"endpoint":                       true,
+	"endpointslice":                  true,
+	"host_ip":                        true,
+	"hpa":                            true,
+	"image":                          true,
+	"image_id":                       true,
+	"ingress":                        true,
+	"ingressclass":                   true,
+	"job_name":                       true,
+	"limitrange":                     true,
+	"mutatingwebhookconfiguration":   true,
+	"node":                           true,
+	"node_id":                        true,
+	"node_port":                      true,
+	"owner_name":                     true,
+	"persistentvolume":               true,
+	"persistentvolumeclaim":          true,
+	"pod":                            true,
+	"pod_ip":                         true,
+	"poddisruptionbudget":            true,
+	"podsecuritypolicy":              true,
+	"pod_template_hash":              true,
+	"provider_id":                    true,
+	"replicaset":                     true,
+	"replicationcontroller":          true,
+	"resource_version":               true,
+	"resourcequota":                  true,
+	"revision":                       true,
+	"runtimeclass":                   true,
+	"secret":                         true,
+	"service":                        true,
+	"service_account":                true,
+	"statefulset":                    true,
+	"storageclass":                   true,
+	"target_name":                    true,
+	"uid":                            true,
+	"verticalpodautoscaler":          true,
+	"validatingwebhookconfiguration": true,
+	"volume":                         true,
+	"volumeattachment":               true,
+	"volumename":                     true,
+	"volumesnapshot":                 true,
+	"volumesnapshotcontent":          true,
+	"workload_id":                    true,
+}
+
+// LiteGatherer wraps a prometheus.Gatherer to only expose aggregates instead
+// of per-object series. Labels identifying objects, as well as the label_*
+// and annotation_* labels, are dropped and the values of the resulting
+// identical series are summed up. For state sets like kube_pod_status_phase
+// this yields the number of objects per namespace and state. Families of
+// timestamps and durations are dropped, as their sums are meaningless.
+func LiteGatherer(r prometheus.Gatherer) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		metricFamilies, err := r.Gather()
+		if err != nil {
+			return nil, err
+		}
+
+		var aggregated []*dto.MetricFamily
+		for _, mf := range metricFamilies {
+			if !liteAggregatable(mf) {
+				countFiltered(mf, "lite")
+				continue
+			}
+			aggregated = append(aggregated, aggregateFamily(mf))
+		}
+		return aggregated, nil
+	})
+}
+
+func liteAggregatable(mf *dto.MetricFamily) bool {
+	name := mf.GetName()
+	if strings.HasSuffix(name, "_created") || strings.HasSuffix(name, "_deleted") || strings.Contains(name, "_time") || strings.Contains(name, "_seconds") {
+		return false
+	}
+	switch mf.GetType() {
+	case dto.MetricType_GAUGE, dto.MetricType_COUNTER, dto.MetricType_UNTYPED:
+		return true
+	}
+	return false
+}
+
+func aggregateFamily(mf *dto.MetricFamily) *dto.MetricFamily {
+	var (
+		order   []string
+		metrics = map[string]*dto.Metric{}
+	)
+	for _, m := range mf.Metric {
+		var labels []*dto.LabelPair
+		for _, lp := range m.Label {
+			name := lp.GetName()
+			if liteObjectLabels[name] || strings.HasPrefix(name, "label_") || strings.HasPrefix(name, "annotation_") {
+				continue
+			}
+			labels = append(labels, lp)
+		}
+		sort.Sort(prometheus.LabelPairSorter(labels))
+
+		key := labelPairsKey(labels)
+		agg, ok := metrics[key]
+		if !ok {
+			agg = &dto.Metric{Label: labels}
+			switch mf.GetType() {
+			case dto.MetricType_GAUGE:
+				agg.Gauge = &dto.Gauge{Value: proto.Float64(0)}
+			case dto.MetricType_COUNTER:
+				agg.Counter = &dto.Counter{Value: proto.Float64(0)}
+			default:
+				agg.Untyped = &dto.Untyped{Value: proto.Float64(0)}
+			}
+			metrics[key] = agg
+			order = append(order, key)
+		}
+
+		switch {
+		case agg.Gauge != nil:
+			agg.Gauge.Value = proto.Float64(agg.Gauge.GetValue() + m.GetGauge().GetValue())
+		case agg.Counter != nil:
+			agg.Counter.Value = proto.Float64(agg.Counter.GetValue() + m.GetCounter().GetValue())
+		default:
+			agg.Untyped.Value = proto.Float64(agg.Untyped.GetValue() + m.GetUntyped().GetValue())
+		}
+	}
+
+	aggregated := &dto.MetricFamily{
+		Name: mf.Name,
+		Help: mf.Help,
+		Type: mf.Type,
+	}
+	for _, key := range order {
+		aggregated.Metric = append(aggregated.Metric, metrics[key])
+	}
+	return aggregated
+}
+
+func labelPairsKey(labels []*dto.LabelPair) string {
+	var b strings.Builder
+	for _, lp := range labels {
+		b.WriteString(lp.GetName())
+		b.WriteByte(0)
+		b.WriteString(lp.GetValue())
+		b.WriteByte(0)
+	}
+	return b.String()
+}
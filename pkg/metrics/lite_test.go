@@ -0,0 +1,154 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/common/expfmt"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+func TestLiteGatherer(t *testing.T) {
+	r := prometheus.NewRegistry()
+	phase := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_status_phase",
+			Help: "The pods current phase.",
+		},
+		[]string{"namespace", "pod", "phase"},
+	)
+	created := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_created",
+			Help: "Unix creation timestamp",
+		},
+		[]string{"namespace", "pod"},
+	)
+	podLabels := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_labels",
+			Help: "Kubernetes labels converted to Prometheus labels.",
+		},
+		[]string{"namespace", "pod", "label_app"},
+	)
+	r.MustRegister(phase, created, podLabels)
+
+	for _, p := range []struct{ namespace, pod, phase, app string }{
+		{"ns1", "pod1", "Running", "web"},
+		{"ns1", "pod2", "Running", "web"},
+		{"ns1", "pod3", "Pending", "db"},
+		{"ns2", "pod4", "Running", "web"},
+	} {
+		for _, ph := range []string{"Pending", "Running"} {
+			v := 0.0
+			if ph == p.phase {
+				v = 1
+			}
+			phase.WithLabelValues(p.namespace, p.pod, ph).Set(v)
+		}
+		created.WithLabelValues(p.namespace, p.pod).Set(1500000000)
+		podLabels.WithLabelValues(p.namespace, p.pod, p.app).Set(1)
+	}
+
+	mfs, err := LiteGatherer(r).Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b strings.Builder
+	for _, mf := range mfs {
+		if _, err := expfmt.MetricFamilyToText(&b, mf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := `# HELP kube_pod_labels Kubernetes labels converted to Prometheus labels.
+# TYPE kube_pod_labels gauge
+kube_pod_labels{namespace="ns1"} 3
+kube_pod_labels{namespace="ns2"} 1
+# HELP kube_pod_status_phase The pods current phase.
+# TYPE kube_pod_status_phase gauge
+kube_pod_status_phase{namespace="ns1",phase="Pending"} 1
+kube_pod_status_phase{namespace="ns1",phase="Running"} 2
+kube_pod_status_phase{namespace="ns2",phase="Pending"} 0
+kube_pod_status_phase{namespace="ns2",phase="Running"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestLiteObjectLabelsCoverCollectors(t *testing.T) {
+	// The labels identifying the objects of every collector. Namespaces are
+	// what lite mode aggregates by, and the apiresources and events
+	// collectors expose no per-object series.
+	objectLabels := map[string][]string{
+		"apiresources":                    nil,
+		"apiservices":                     {"apiservice"},
+		"certificatesigningrequests":      {"certificatesigningrequest"},
+		"configmaps":                      {"configmap"},
+		"cronjobs":                        {"cronjob"},
+		"csidrivers":                      {"csidriver"},
+		"csinodes":                        {"csinode"},
+		"customresourcedefinitions":       {"customresourcedefinition"},
+		"daemonsets":                      {"daemonset"},
+		"deployments":                     {"deployment"},
+		"endpoints":                       {"endpoint"},
+		"endpointslices":                  {"endpointslice"},
+		"events":                          nil,
+		"horizontalpodautoscalers":        {"hpa"},
+		"ingressclasses":                  {"ingressclass"},
+		"ingresses":                       {"ingress"},
+		"jobs":                            {"job_name"},
+		"limitranges":                     {"limitrange"},
+		"mutatingwebhookconfigurations":   {"mutatingwebhookconfiguration"},
+		"namespaces":                      nil,
+		"nodes":                           {"node"},
+		"persistentvolumeclaims":          {"persistentvolumeclaim"},
+		"persistentvolumes":               {"persistentvolume"},
+		"poddisruptionbudgets":            {"poddisruptionbudget"},
+		"pods":                            {"pod"},
+		"podsecuritypolicies":             {"podsecuritypolicy"},
+		"replicasets":                     {"replicaset"},
+		"replicationcontrollers":          {"replicationcontroller"},
+		"resourcequotas":                  {"resourcequota"},
+		"runtimeclasses":                  {"runtimeclass"},
+		"secrets":                         {"secret"},
+		"services":                        {"service"},
+		"statefulsets":                    {"statefulset"},
+		"storageclasses":                  {"storageclass"},
+		"validatingwebhookconfigurations": {"validatingwebhookconfiguration"},
+		"verticalpodautoscalers":          {"verticalpodautoscaler", "target_name"},
+		"volumeattachments":               {"volumeattachment"},
+		"volumesnapshotcontents":          {"volumesnapshotcontent"},
+		"volumesnapshots":                 {"volumesnapshot"},
+	}
+	for c := range options.AvailableCollectors {
+		labels, ok := objectLabels[c]
+		if !ok {
+			t.Errorf("object labels of collector %q are unknown", c)
+		}
+		for _, l := range labels {
+			if !liteObjectLabels[l] {
+				t.Errorf("object label %q of collector %q is not dropped in lite mode", l, c)
+			}
+		}
+	}
+}
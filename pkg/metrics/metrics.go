@@ -17,12 +17,38 @@ limitations under the License.
 package metrics
 
 import (
+	"github.com/golang/glog"
 	"github.com/prometheus/client_golang/prometheus"
 	dto "github.com/prometheus/client_model/go"
 
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
+// SeriesFilteredTotalMetric counts the series not exposed because of the
+// configuration, so that metrics missing on purpose can be told apart from
+// bugs.
+var SeriesFilteredTotalMetric = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "kube_state_metrics_series_filtered_total",
+		Help: "Total number of series not exposed because of the metric whitelist or blacklist or lite mode",
+	},
+	[]string{"family", "reason"},
+)
+
+// countFiltered records that the series of mf were not exposed for the given
+// reason.
+func countFiltered(mf *dto.MetricFamily, reason string) {
+	SeriesFilteredTotalMetric.WithLabelValues(mf.GetName(), reason).Add(float64(len(mf.Metric)))
+}
+
+// PromLogger implements promhttp.Logger by logging errors with glog.
+type PromLogger struct{}
+
+// Println implements promhttp.Logger.
+func (pl PromLogger) Println(v ...interface{}) {
+	glog.Error(v...)
+}
+
 type gathererFunc func() ([]*dto.MetricFamily, error)
 
 func (f gathererFunc) Gather() ([]*dto.MetricFamily, error) {
@@ -47,9 +73,11 @@ func FilteredGatherer(r prometheus.Gatherer, whitelist options.MetricSet, blackl
 				// deferencing this string may be a performance bottleneck
 				name := *metricFamily.Name
 				_, onWhitelist := whitelist[name]
-				if onWhitelist {
-					newMetricFamilies = append(newMetricFamilies, metricFamily)
+				if !onWhitelist {
+					countFiltered(metricFamily, "whitelist")
+					continue
 				}
+				newMetricFamilies = append(newMetricFamilies, metricFamily)
 			}
 
 			return newMetricFamilies, nil
@@ -68,6 +96,7 @@ func FilteredGatherer(r prometheus.Gatherer, whitelist options.MetricSet, blackl
 				name := *metricFamily.Name
 				_, onBlacklist := blacklist[name]
 				if onBlacklist {
+					countFiltered(metricFamily, "blacklist")
 					continue
 				}
 				newMetricFamilies = append(newMetricFamilies, metricFamily)
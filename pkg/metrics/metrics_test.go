@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
 	"k8s.io/kube-state-metrics/pkg/options"
 )
 
@@ -134,4 +135,12 @@ func TestFiltererdGathererBlacklist(t *testing.T) {
 	if found1 || !found2 {
 		t.Fatalf("Expected `test1` to be filtered and `test2` not. `test1`: %t ; `test2`: %t.", found1, found2)
 	}
+
+	m := &dto.Metric{}
+	if err := SeriesFilteredTotalMetric.WithLabelValues("test1", "blacklist").Write(m); err != nil {
+		t.Fatal(err)
+	}
+	if v := m.GetCounter().GetValue(); v != 1 {
+		t.Fatalf("Expected 1 filtered series of `test1`, got %v.", v)
+	}
 }
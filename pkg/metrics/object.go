@@ -0,0 +1,161 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// ObjectKind describes how the metrics of the objects of a kind are
+// identified.
+type ObjectKind struct {
+	// Collector is the name of the collector generating the metrics.
+	Collector string
+	// Label is the label holding the name of the object.
+	Label string
+	// Namespaced is whether the objects are namespaced.
+	Namespaced bool
+}
+
+// ObjectKinds maps the kinds of the objects with metrics to how their metrics
+// are identified.
+var ObjectKinds = map[string]ObjectKind{
+	"CertificateSigningRequest":      {Collector: "certificatesigningrequests", Label: "certificatesigningrequest"},
+	"ConfigMap":                      {Collector: "configmaps", Label: "configmap", Namespaced: true},
+	"CronJob":                        {Collector: "cronjobs", Label: "cronjob", Namespaced: true},
+	"DaemonSet":                      {Collector: "daemonsets", Label: "daemonset", Namespaced: true},
+	"Deployment":                     {Collector: "deployments", Label: "deployment", Namespaced: true},
+	"Endpoints":                      {Collector: "endpoints", Label: "endpoint", Namespaced: true},
+	"HorizontalPodAutoscaler":        {Collector: "horizontalpodautoscalers", Label: "hpa", Namespaced: true},
+	"Ingress":                        {Collector: "ingresses", Label: "ingress", Namespaced: true},
+	"Job":                            {Collector: "jobs", Label: "job_name", Namespaced: true},
+	"LimitRange":                     {Collector: "limitranges", Label: "limitrange", Namespaced: true},
+	"MutatingWebhookConfiguration":   {Collector: "mutatingwebhookconfigurations", Label: "mutatingwebhookconfiguration"},
+	"Namespace":                      {Collector: "namespaces", Label: "namespace"},
+	"Node":                           {Collector: "nodes", Label: "node"},
+	"PersistentVolume":               {Collector: "persistentvolumes", Label: "persistentvolume"},
+	"PersistentVolumeClaim":          {Collector: "persistentvolumeclaims", Label: "persistentvolumeclaim", Namespaced: true},
+	"Pod":                            {Collector: "pods", Label: "pod", Namespaced: true},
+	"PodDisruptionBudget":            {Collector: "poddisruptionbudgets", Label: "poddisruptionbudget", Namespaced: true},
+	"PodSecurityPolicy":              {Collector: "podsecuritypolicies", Label: "podsecuritypolicy"},
+	"ReplicaSet":                     {Collector: "replicasets", Label: "replicaset", Namespaced: true},
+	"ReplicationController":          {Collector: "replicationcontrollers", Label: "replicationcontroller", Namespaced: true},
+	"ResourceQuota":                  {Collector: "resourcequotas", Label: "resourcequota", Namespaced: true},
+	"Secret":                         {Collector: "secrets", Label: "secret", Namespaced: true},
+	"Service":                        {Collector: "services", Label: "service", Namespaced: true},
+	"StatefulSet":                    {Collector: "statefulsets", Label: "statefulset", Namespaced: true},
+	"StorageClass":                   {Collector: "storageclasses", Label: "storageclass"},
+	"ValidatingWebhookConfiguration": {Collector: "validatingwebhookconfigurations", Label: "validatingwebhookconfiguration"},
+	"VolumeAttachment":               {Collector: "volumeattachments", Label: "volumeattachment"},
+}
+
+// ObjectHandler serves the metrics generated for a single object, given by
+// the kind, namespace and name query parameters, e.g.
+// ?kind=Pod&namespace=default&name=web-1. The metrics are served as generated
+// by the collector of the kind, before any filtering or aggregation.
+func ObjectHandler(cg CollectorGatherers) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		kind, namespace, name := q.Get("kind"), q.Get("namespace"), q.Get("name")
+
+		k, ok := ObjectKinds[kind]
+		if !ok {
+			http.Error(w, fmt.Sprintf("unknown kind %q", kind), http.StatusBadRequest)
+			return
+		}
+		if name == "" || (k.Namespaced && namespace == "") {
+			http.Error(w, "name, and namespace for namespaced kinds, are required", http.StatusBadRequest)
+			return
+		}
+		g, ok := cg[k.Collector]
+		if !ok {
+			http.Error(w, fmt.Sprintf("collector %q is not active", k.Collector), http.StatusBadRequest)
+			return
+		}
+
+		metricFamilies, err := g.Gather()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		want := map[string]string{k.Label: name}
+		if k.Namespaced {
+			want["namespace"] = namespace
+		}
+		var buf bytes.Buffer
+		for _, mf := range metricFamilies {
+			mf = objectMetrics(mf, want)
+			if len(mf.Metric) == 0 {
+				continue
+			}
+			if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+		if buf.Len() == 0 {
+			http.Error(w, fmt.Sprintf("no metrics of %s %s found", kind, strings.TrimPrefix(namespace+"/"+name, "/")), http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Type", string(expfmt.FmtText))
+		w.Write(buf.Bytes())
+	})
+}
+
+// objectMetrics returns the metric family with only the metrics having all
+// the given label values.
+func objectMetrics(mf *dto.MetricFamily, want map[string]string) *dto.MetricFamily {
+	filtered := &dto.MetricFamily{
+		Name: mf.Name,
+		Help: mf.Help,
+		Type: mf.Type,
+	}
+	for _, m := range mf.Metric {
+		matched := 0
+		for _, lp := range m.Label {
+			if v, ok := want[lp.GetName()]; ok && v == lp.GetValue() {
+				matched++
+			}
+		}
+		if matched == len(want) {
+			filtered.Metric = append(filtered.Metric, m)
+		}
+	}
+	return filtered
+}
+
+// BearerTokenHandler only passes requests with the given bearer token in the
+// Authorization header on to h.
+func BearerTokenHandler(h http.Handler, token string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth := r.Header.Get("Authorization")
+		if !strings.HasPrefix(auth, "Bearer ") || subtle.ConstantTimeCompare([]byte(strings.TrimPrefix(auth, "Bearer ")), []byte(token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestObjectHandler(t *testing.T) {
+	pods := prometheus.NewRegistry()
+	phase := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_status_phase",
+			Help: "The pods current phase.",
+		},
+		[]string{"namespace", "pod", "phase"},
+	)
+	pods.MustRegister(phase)
+	phase.WithLabelValues("ns1", "pod1", "Running").Set(1)
+	phase.WithLabelValues("ns1", "pod1", "Pending").Set(0)
+	phase.WithLabelValues("ns2", "pod1", "Running").Set(0)
+	phase.WithLabelValues("ns1", "pod2", "Running").Set(1)
+
+	nodes := prometheus.NewRegistry()
+	nodeInfo := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_node_info",
+			Help: "Information about a cluster node.",
+		},
+		[]string{"node"},
+	)
+	nodes.MustRegister(nodeInfo)
+	nodeInfo.WithLabelValues("node1").Set(1)
+
+	h := BearerTokenHandler(ObjectHandler(CollectorGatherers{"pods": pods, "nodes": nodes}), "s3cr3t")
+
+	tests := []struct {
+		Desc       string
+		Target     string
+		Token      string
+		WantedCode int
+		Wanted     string
+	}{
+		{
+			Desc:       "pod",
+			Target:     "/debug/object?kind=Pod&namespace=ns1&name=pod1",
+			Token:      "s3cr3t",
+			WantedCode: http.StatusOK,
+			Wanted: `# HELP kube_pod_status_phase The pods current phase.
+# TYPE kube_pod_status_phase gauge
+kube_pod_status_phase{namespace="ns1",phase="Pending",pod="pod1"} 0
+kube_pod_status_phase{namespace="ns1",phase="Running",pod="pod1"} 1
+`,
+		},
+		{
+			Desc:       "cluster-scoped object",
+			Target:     "/debug/object?kind=Node&name=node1",
+			Token:      "s3cr3t",
+			WantedCode: http.StatusOK,
+			Wanted: `# HELP kube_node_info Information about a cluster node.
+# TYPE kube_node_info gauge
+kube_node_info{node="node1"} 1
+`,
+		},
+		{
+			Desc:       "unknown object",
+			Target:     "/debug/object?kind=Pod&namespace=ns3&name=pod1",
+			Token:      "s3cr3t",
+			WantedCode: http.StatusNotFound,
+		},
+		{
+			Desc:       "missing namespace",
+			Target:     "/debug/object?kind=Pod&name=pod1",
+			Token:      "s3cr3t",
+			WantedCode: http.StatusBadRequest,
+		},
+		{
+			Desc:       "unknown kind",
+			Target:     "/debug/object?kind=Widget&name=w1",
+			Token:      "s3cr3t",
+			WantedCode: http.StatusBadRequest,
+		},
+		{
+			Desc:       "inactive collector",
+			Target:     "/debug/object?kind=Service&namespace=ns1&name=svc1",
+			Token:      "s3cr3t",
+			WantedCode: http.StatusBadRequest,
+		},
+		{
+			Desc:       "wrong token",
+			Target:     "/debug/object?kind=Pod&namespace=ns1&name=pod1",
+			Token:      "guess",
+			WantedCode: http.StatusUnauthorized,
+		},
+		{
+			Desc:       "no token",
+			Target:     "/debug/object?kind=Pod&namespace=ns1&name=pod1",
+			WantedCode: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.Target, nil)
+		if test.Token != "" {
+			req.Header.Set("Authorization", "Bearer "+test.Token)
+		}
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		if rr.Code != test.WantedCode {
+			t.Errorf("Test error for Desc: %s. Want status %d, got %d.", test.Desc, test.WantedCode, rr.Code)
+		}
+		if test.Wanted != "" && rr.Body.String() != test.Wanted {
+			t.Errorf("Test error for Desc: %s. Want:\n%s\nGot:\n%s", test.Desc, test.Wanted, rr.Body.String())
+		}
+	}
+}
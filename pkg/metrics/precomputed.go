@@ -0,0 +1,254 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// TextFamily describes a metric family whose samples are precomputed in the
+// text format.
+type TextFamily struct {
+	Name string
+	// Header holds the HELP and TYPE lines of the family.
+	Header []byte
+	// Series is the number of samples of the family.
+	Series int
+}
+
+// EncodedFamily is a metric family encoded in the text format.
+type EncodedFamily struct {
+	TextFamily
+	Samples []byte
+}
+
+// TextSource holds metric families precomputed in the text format, e.g. the
+// metrics of every object of a resource, updated as the objects change.
+type TextSource interface {
+	// TextFamilies returns the families currently held.
+	TextFamilies() []TextFamily
+	// WriteSamples writes the samples of the family of the given name.
+	WriteSamples(w io.Writer, family string) error
+}
+
+// TextGatherer is a prometheus.Gatherer of which some metrics are
+// precomputed in the text format. Its Gather parses them back into metric
+// families, which is only needed if they have to be transformed.
+type TextGatherer interface {
+	prometheus.Gatherer
+	// GatherText gathers the metrics which are not precomputed and returns
+	// the sources of the precomputed ones.
+	GatherText() ([]*dto.MetricFamily, []TextSource, error)
+}
+
+// Registry is a prometheus.Registry which also holds the precomputed metrics
+// of the collectors registered with it.
+type Registry struct {
+	*prometheus.Registry
+	sources []TextSource
+}
+
+// NewRegistry returns an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{Registry: prometheus.NewRegistry()}
+}
+
+// RegisterTextSource adds the metrics of s to the ones gathered by r. It has
+// to be called before r is gathered.
+func (r *Registry) RegisterTextSource(s TextSource) {
+	r.sources = append(r.sources, s)
+}
+
+// GatherText implements the TextGatherer interface.
+func (r *Registry) GatherText() ([]*dto.MetricFamily, []TextSource, error) {
+	mfs, err := r.Registry.Gather()
+	return mfs, r.sources, err
+}
+
+// Gather implements the prometheus.Gatherer interface.
+func (r *Registry) Gather() ([]*dto.MetricFamily, error) {
+	if len(r.sources) == 0 {
+		return r.Registry.Gather()
+	}
+	return prometheus.Gatherers{r.Registry, textSourcesGatherer(r.sources)}.Gather()
+}
+
+// textSourcesGatherer returns a gatherer parsing the metrics of sources.
+func textSourcesGatherer(sources []TextSource) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		var buf bytes.Buffer
+		for _, tf := range textFamilies(sources, nil) {
+			if err := writeTextFamily(&buf, tf, sources, nil); err != nil {
+				return nil, err
+			}
+		}
+		var parser expfmt.TextParser
+		parsed, err := parser.TextToMetricFamilies(&buf)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing precomputed metrics: %v", err)
+		}
+		mfs := make([]*dto.MetricFamily, 0, len(parsed))
+		for _, mf := range parsed {
+			mfs = append(mfs, mf)
+		}
+		return mfs, nil
+	})
+}
+
+// textFamilies returns the families of sources, once per name, for which
+// filter returns true. All families are returned if filter is nil.
+func textFamilies(sources []TextSource, filter func(TextFamily) bool) []TextFamily {
+	var (
+		tfs   []TextFamily
+		index = map[string]int{}
+	)
+	for _, s := range sources {
+		for _, tf := range s.TextFamilies() {
+			if i, ok := index[tf.Name]; ok {
+				tfs[i].Series += tf.Series
+				continue
+			}
+			index[tf.Name] = len(tfs)
+			tfs = append(tfs, tf)
+		}
+	}
+	if filter == nil {
+		return tfs
+	}
+	filtered := tfs[:0]
+	for _, tf := range tfs {
+		if filter(tf) {
+			filtered = append(filtered, tf)
+		}
+	}
+	return filtered
+}
+
+// writeTextFamily writes the header of tf, its samples held by sources and,
+// if mf is not nil, the samples of mf, which has to be of the same family.
+func writeTextFamily(w io.Writer, tf TextFamily, sources []TextSource, mf *dto.MetricFamily) error {
+	if _, err := w.Write(tf.Header); err != nil {
+		return err
+	}
+	for _, s := range sources {
+		if err := s.WriteSamples(w, tf.Name); err != nil {
+			return err
+		}
+	}
+	if mf == nil || len(mf.Metric) == 0 {
+		return nil
+	}
+	var buf bytes.Buffer
+	if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+		return err
+	}
+	_, samples := splitTextHeader(buf.Bytes())
+	_, err := w.Write(samples)
+	return err
+}
+
+// TextFamilyFilter returns a filter of precomputed metric families applying
+// the white or blacklist like FilteredGatherer.
+func TextFamilyFilter(whitelist options.MetricSet, blacklist options.MetricSet) func(TextFamily) bool {
+	whitelistEnabled := !whitelist.IsEmpty()
+	blacklistEnabled := !blacklist.IsEmpty()
+
+	return func(tf TextFamily) bool {
+		if whitelistEnabled {
+			if _, onWhitelist := whitelist[tf.Name]; !onWhitelist {
+				SeriesFilteredTotalMetric.WithLabelValues(tf.Name, "whitelist").Add(float64(tf.Series))
+				return false
+			}
+		}
+		if blacklistEnabled {
+			if _, onBlacklist := blacklist[tf.Name]; onBlacklist {
+				SeriesFilteredTotalMetric.WithLabelValues(tf.Name, "blacklist").Add(float64(tf.Series))
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// EncodeText encodes metrics in the text format per metric family. The
+// metrics are checked like the ones of a registered collector.
+func EncodeText(metrics []prometheus.Metric) ([]EncodedFamily, error) {
+	if len(metrics) == 0 {
+		return nil, nil
+	}
+	r := prometheus.NewRegistry()
+	if err := r.Register(metricsCollector(metrics)); err != nil {
+		return nil, err
+	}
+	mfs, err := r.Gather()
+	if err != nil {
+		return nil, err
+	}
+
+	efs := make([]EncodedFamily, 0, len(mfs))
+	for _, mf := range mfs {
+		var buf bytes.Buffer
+		if _, err := expfmt.MetricFamilyToText(&buf, mf); err != nil {
+			return nil, err
+		}
+		header, samples := splitTextHeader(buf.Bytes())
+		efs = append(efs, EncodedFamily{
+			TextFamily: TextFamily{Name: mf.GetName(), Header: header, Series: len(mf.Metric)},
+			Samples:    samples,
+		})
+	}
+	return efs, nil
+}
+
+// metricsCollector collects a fixed set of metrics.
+type metricsCollector []prometheus.Metric
+
+// Describe implements the prometheus.Collector interface.
+func (mc metricsCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, m := range mc {
+		ch <- m.Desc()
+	}
+}
+
+// Collect implements the prometheus.Collector interface.
+func (mc metricsCollector) Collect(ch chan<- prometheus.Metric) {
+	for _, m := range mc {
+		ch <- m
+	}
+}
+
+// splitTextHeader splits the text encoding of a metric family into its
+// comment lines and its samples.
+func splitTextHeader(b []byte) (header, samples []byte) {
+	i := 0
+	for bytes.HasPrefix(b[i:], []byte("#")) {
+		j := bytes.IndexByte(b[i:], '\n')
+		if j < 0 {
+			return b, nil
+		}
+		i += j + 1
+	}
+	return b[:i], b[i:]
+}
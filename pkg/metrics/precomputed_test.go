@@ -0,0 +1,158 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+
+	"k8s.io/kube-state-metrics/pkg/options"
+)
+
+// encodedTextSource is a TextSource of families encoded once.
+type encodedTextSource []EncodedFamily
+
+func (s encodedTextSource) TextFamilies() []TextFamily {
+	var tfs []TextFamily
+	for _, ef := range s {
+		tfs = append(tfs, ef.TextFamily)
+	}
+	return tfs
+}
+
+func (s encodedTextSource) WriteSamples(w io.Writer, family string) error {
+	for _, ef := range s {
+		if ef.Name == family {
+			if _, err := w.Write(ef.Samples); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func newPrecomputedRegistry(t *testing.T) *Registry {
+	precomputed := prometheus.NewDesc("test_precomputed", "Precomputed.", []string{"object"}, nil)
+	filtered := prometheus.NewDesc("test_filtered", "Filtered.", nil, nil)
+	efs, err := EncodeText([]prometheus.Metric{
+		prometheus.MustNewConstMetric(precomputed, prometheus.GaugeValue, 1, "a"),
+		prometheus.MustNewConstMetric(precomputed, prometheus.GaugeValue, 2, "b"),
+		prometheus.MustNewConstMetric(filtered, prometheus.GaugeValue, 3),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error encoding metrics: %v", err)
+	}
+
+	r := NewRegistry()
+	r.RegisterTextSource(encodedTextSource(efs))
+	// A family can have metrics generated at scrape time as well.
+	r.MustRegister(metricsCollector{
+		prometheus.MustNewConstMetric(precomputed, prometheus.GaugeValue, 4, "c"),
+		prometheus.MustNewConstMetric(prometheus.NewDesc("test_value", "Value.", nil, nil), prometheus.GaugeValue, 5),
+	})
+	return r
+}
+
+func TestEncodeText(t *testing.T) {
+	efs, err := EncodeText([]prometheus.Metric{
+		prometheus.MustNewConstMetric(prometheus.NewDesc("test_metric", "Test.", []string{"object"}, nil), prometheus.GaugeValue, 1, "a"),
+		prometheus.MustNewConstMetric(prometheus.NewDesc("test_metric", "Test.", []string{"object"}, nil), prometheus.GaugeValue, 2, "b"),
+	})
+	if err != nil {
+		t.Fatalf("unexpected error encoding metrics: %v", err)
+	}
+	if len(efs) != 1 {
+		t.Fatalf("expected 1 encoded family, got %d", len(efs))
+	}
+	ef := efs[0]
+	if ef.Name != "test_metric" || ef.Series != 2 {
+		t.Errorf("expected 2 series of test_metric, got %d of %s", ef.Series, ef.Name)
+	}
+	if want := "# HELP test_metric Test.\n# TYPE test_metric gauge\n"; string(ef.Header) != want {
+		t.Errorf("expected header %q, got %q", want, ef.Header)
+	}
+	if want := "test_metric{object=\"a\"} 1\ntest_metric{object=\"b\"} 2\n"; string(ef.Samples) != want {
+		t.Errorf("expected samples %q, got %q", want, ef.Samples)
+	}
+
+	if efs, err := EncodeText(nil); err != nil || len(efs) != 0 {
+		t.Errorf("expected no encoded families without metrics, got %v, %v", efs, err)
+	}
+}
+
+func TestStreamingHandlerWritesPrecomputedText(t *testing.T) {
+	r := newPrecomputedRegistry(t)
+	filter := TextFamilyFilter(options.MetricSet{}, options.MetricSet{"test_filtered": struct{}{}})
+	wrap := func(g prometheus.Gatherer) prometheus.Gatherer {
+		return FilteredGatherer(g, options.MetricSet{}, options.MetricSet{"test_filtered": struct{}{}})
+	}
+
+	const want = `# HELP test_value Value.
+# TYPE test_value gauge
+test_value 5
+# HELP test_precomputed Precomputed.
+# TYPE test_precomputed gauge
+test_precomputed{object="a"} 1
+test_precomputed{object="b"} 2
+test_precomputed{object="c"} 4
+`
+	rec := httptest.NewRecorder()
+	StreamingHandler([]prometheus.Gatherer{r}, wrap, filter).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expected body\n%s\ngot\n%s", want, got)
+	}
+
+	// Without a text filter, the precomputed metrics are parsed back and
+	// wrapped like any other metric.
+	const wantParsed = `# HELP test_precomputed Precomputed.
+# TYPE test_precomputed gauge
+test_precomputed{object="a"} 1
+test_precomputed{object="b"} 2
+test_precomputed{object="c"} 4
+# HELP test_value Value.
+# TYPE test_value gauge
+test_value 5
+`
+	rec = httptest.NewRecorder()
+	StreamingHandler([]prometheus.Gatherer{r}, wrap, nil).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rec.Body.String(); got != wantParsed {
+		t.Errorf("expected body\n%s\ngot\n%s", wantParsed, got)
+	}
+
+	// So they are for formats other than text.
+	req := httptest.NewRequest("GET", "/metrics", nil)
+	req.Header.Set("Accept", string(expfmt.FmtProtoDelim))
+	rec = httptest.NewRecorder()
+	StreamingHandler([]prometheus.Gatherer{r}, wrap, filter).ServeHTTP(rec, req)
+	dec := expfmt.NewDecoder(rec.Body, expfmt.FmtProtoDelim)
+	series := map[string]int{}
+	for {
+		var mf dto.MetricFamily
+		if err := dec.Decode(&mf); err != nil {
+			break
+		}
+		series[mf.GetName()] = len(mf.Metric)
+	}
+	if series["test_precomputed"] != 3 || series["test_value"] != 1 || series["test_filtered"] != 0 {
+		t.Errorf("unexpected series per family in protobuf response: %v", series)
+	}
+}
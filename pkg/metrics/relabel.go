@@ -0,0 +1,219 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/model"
+)
+
+// metricNameLabel refers to the name of the metric family in the source
+// labels of relabel rules.
+const metricNameLabel = "__name__"
+
+// RelabelRule is a subset of the metric_relabel_configs of Prometheus,
+// applied to every series before it is exposed.
+type RelabelRule struct {
+	// SourceLabels are the labels whose values are joined with Separator
+	// and matched against Regex. __name__ is the name of the metric.
+	SourceLabels []string `json:"source_labels"`
+	// Separator joins the values of SourceLabels. Defaults to ;.
+	Separator *string `json:"separator"`
+	// Regex is matched against the joined values, anchored at both ends.
+	// Defaults to (.*).
+	Regex string `json:"regex"`
+	// TargetLabel is the label replace writes to.
+	TargetLabel string `json:"target_label"`
+	// Replacement is the value written by replace, where $1 and the like
+	// refer to the capture groups of Regex. Defaults to $1. An empty result
+	// removes TargetLabel.
+	Replacement *string `json:"replacement"`
+	// Action is one of replace, keep or drop. Defaults to replace.
+	Action string `json:"action"`
+
+	regex *regexp.Regexp
+}
+
+// RelabelConfig is the format of the relabel configuration file.
+type RelabelConfig struct {
+	Rules []RelabelRule `json:"rules"`
+}
+
+// LoadRelabelRules reads and validates the relabel rules of the given YAML
+// file.
+func LoadRelabelRules(path string) ([]RelabelRule, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseRelabelRules(b)
+}
+
+// ParseRelabelRules parses and validates YAML relabel rules and fills in
+// their defaults.
+func ParseRelabelRules(b []byte) ([]RelabelRule, error) {
+	var c RelabelConfig
+	if err := yaml.Unmarshal(b, &c); err != nil {
+		return nil, err
+	}
+
+	for i := range c.Rules {
+		r := &c.Rules[i]
+		if r.Action == "" {
+			r.Action = "replace"
+		}
+		if r.Separator == nil {
+			r.Separator = proto.String(";")
+		}
+		if r.Regex == "" {
+			r.Regex = "(.*)"
+		}
+		if r.Replacement == nil {
+			r.Replacement = proto.String("$1")
+		}
+
+		re, err := regexp.Compile("^(?:" + r.Regex + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("rule %d: invalid regex: %v", i, err)
+		}
+		r.regex = re
+		for _, l := range r.SourceLabels {
+			if l != metricNameLabel && !model.LabelName(l).IsValid() {
+				return nil, fmt.Errorf("rule %d: invalid source label %q", i, l)
+			}
+		}
+
+		switch r.Action {
+		case "replace":
+			// Renaming metrics would require merging metric families.
+			if !model.LabelName(r.TargetLabel).IsValid() || r.TargetLabel == metricNameLabel {
+				return nil, fmt.Errorf("rule %d: invalid target label %q", i, r.TargetLabel)
+			}
+		case "keep", "drop":
+			if len(r.SourceLabels) == 0 {
+				return nil, fmt.Errorf("rule %d: %s requires source labels", i, r.Action)
+			}
+		default:
+			return nil, fmt.Errorf("rule %d: unknown action %q", i, r.Action)
+		}
+	}
+	return c.Rules, nil
+}
+
+// RelabelingGatherer wraps a prometheus.Gatherer to apply the given relabel
+// rules to every series in order. Series dropped by a keep or drop rule are
+// left out, as are metric families without any series left. The rules have to
+// keep the series of every family distinct.
+func RelabelingGatherer(r prometheus.Gatherer, rules []RelabelRule) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		metricFamilies, err := r.Gather()
+		if err != nil {
+			return nil, err
+		}
+
+		// The gathered families may be shared, e.g. by CompatGatherer or a
+		// cache, so copies of them are relabeled.
+		relabeled := make([]*dto.MetricFamily, 0, len(metricFamilies))
+		for _, mf := range metricFamilies {
+			metrics := make([]*dto.Metric, 0, len(mf.Metric))
+			for _, m := range mf.Metric {
+				rm := *m
+				rm.Label = append([]*dto.LabelPair(nil), m.Label...)
+				if relabel(mf.GetName(), &rm, rules) {
+					metrics = append(metrics, &rm)
+				}
+			}
+			if len(metrics) == 0 {
+				continue
+			}
+			relabeled = append(relabeled, &dto.MetricFamily{
+				Name:   mf.Name,
+				Help:   mf.Help,
+				Type:   mf.Type,
+				Metric: metrics,
+			})
+		}
+		return relabeled, nil
+	})
+}
+
+// relabel applies rules to the labels of m and returns whether m is kept.
+func relabel(name string, m *dto.Metric, rules []RelabelRule) bool {
+	for _, r := range rules {
+		values := make([]string, len(r.SourceLabels))
+		for i, l := range r.SourceLabels {
+			if l == metricNameLabel {
+				values[i] = name
+			} else {
+				values[i], _ = labelValue(m, l)
+			}
+		}
+		value := strings.Join(values, *r.Separator)
+
+		switch r.Action {
+		case "keep":
+			if !r.regex.MatchString(value) {
+				return false
+			}
+		case "drop":
+			if r.regex.MatchString(value) {
+				return false
+			}
+		case "replace":
+			match := r.regex.FindStringSubmatchIndex(value)
+			if match == nil {
+				continue
+			}
+			target := string(r.regex.ExpandString(nil, *r.Replacement, value, match))
+			setLabel(m, r.TargetLabel, target)
+		}
+	}
+	return true
+}
+
+// setLabel sets the label of m to value, removing it if value is empty. The
+// label pairs of m are replaced rather than modified.
+func setLabel(m *dto.Metric, label, value string) {
+	for i, lp := range m.Label {
+		if lp.GetName() != label {
+			continue
+		}
+		if value == "" {
+			m.Label = append(m.Label[:i], m.Label[i+1:]...)
+		} else {
+			m.Label[i] = &dto.LabelPair{Name: lp.Name, Value: proto.String(value)}
+		}
+		return
+	}
+	if value == "" {
+		return
+	}
+	m.Label = append(m.Label, &dto.LabelPair{
+		Name:  proto.String(label),
+		Value: proto.String(value),
+	})
+	sort.Sort(prometheus.LabelPairSorter(m.Label))
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestParseRelabelRules(t *testing.T) {
+	tests := []struct {
+		config string
+		err    string
+	}{
+		{
+			config: `
+rules:
+- source_labels: [namespace]
+  regex: kube-.*
+  action: drop
+- source_labels: [label_team]
+  target_label: team
+`,
+		},
+		{
+			config: `
+rules:
+- source_labels: [namespace]
+  regex: "("
+  action: drop
+`,
+			err: "rule 0: invalid regex: error parsing regexp: missing closing ): `^(?:()$`",
+		},
+		{
+			config: `
+rules:
+- source_labels: [label-team]
+  target_label: team
+`,
+			err: `rule 0: invalid source label "label-team"`,
+		},
+		{
+			config: `
+rules:
+- source_labels: [namespace]
+  target_label: __name__
+`,
+			err: `rule 0: invalid target label "__name__"`,
+		},
+		{
+			config: `
+rules:
+- regex: kube-.*
+  action: keep
+`,
+			err: `rule 0: keep requires source labels`,
+		},
+		{
+			config: `
+rules:
+- source_labels: [namespace]
+  action: labelmap
+`,
+			err: `rule 0: unknown action "labelmap"`,
+		},
+	}
+
+	for _, test := range tests {
+		_, err := ParseRelabelRules([]byte(test.config))
+		switch {
+		case test.err == "" && err != nil:
+			t.Errorf("unexpected error: %v", err)
+		case test.err != "" && (err == nil || err.Error() != test.err):
+			t.Errorf("expected error %q, got %v", test.err, err)
+		}
+	}
+}
+
+func TestRelabelingGatherer(t *testing.T) {
+	r := prometheus.NewRegistry()
+	labels := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_labels",
+			Help: "Kubernetes labels converted to Prometheus labels.",
+		},
+		[]string{"namespace", "pod", "label_team", "label_secret"},
+	)
+	info := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_secret_info",
+			Help: "Information about secret.",
+		},
+		[]string{"namespace", "secret"},
+	)
+	r.MustRegister(labels, info)
+	labels.WithLabelValues("default", "web", "frontend", "s3cr3t").Set(1)
+	labels.WithLabelValues("kube-system", "kube-dns", "platform", "").Set(1)
+	labels.WithLabelValues("monitoring", "prometheus", "", "").Set(1)
+	info.WithLabelValues("default", "token").Set(1)
+
+	rules, err := ParseRelabelRules([]byte(`
+rules:
+- source_labels: [namespace]
+  regex: kube-.*
+  action: drop
+- source_labels: [__name__]
+  regex: kube_secret_.*
+  action: drop
+- source_labels: [label_team, namespace]
+  separator: "/"
+  regex: "(.+)/.*"
+  target_label: team
+- source_labels: []
+  target_label: label_secret
+  replacement: ""
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	mfs, err := RelabelingGatherer(r, rules).Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var b strings.Builder
+	for _, mf := range mfs {
+		if _, err := expfmt.MetricFamilyToText(&b, mf); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	want := `# HELP kube_pod_labels Kubernetes labels converted to Prometheus labels.
+# TYPE kube_pod_labels gauge
+kube_pod_labels{label_team="",namespace="monitoring",pod="prometheus"} 1
+kube_pod_labels{label_team="frontend",namespace="default",pod="web",team="frontend"} 1
+`
+	if got := b.String(); got != want {
+		t.Errorf("want:\n%s\ngot:\n%s", want, got)
+	}
+}
+
+func TestRelabelingGathererLeavesGatheredFamiliesAlone(t *testing.T) {
+	r := prometheus.NewRegistry()
+	info := prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "kube_namespace_info",
+		Help: "Information about a namespace.",
+	}, []string{"namespace"})
+	info.WithLabelValues("a").Set(1)
+	info.WithLabelValues("b").Set(1)
+	r.MustRegister(info)
+	gathered, err := r.Gather()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	// Like a cache, the gatherer returns the same families on every call.
+	g := gathererFunc(func() ([]*dto.MetricFamily, error) {
+		return gathered, nil
+	})
+
+	rules, err := ParseRelabelRules([]byte(`
+rules:
+- source_labels: [namespace]
+  regex: b
+  action: drop
+- source_labels: [namespace]
+  target_label: namespace
+  replacement: x-$1
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	want := `# HELP kube_namespace_info Information about a namespace.
+# TYPE kube_namespace_info gauge
+kube_namespace_info{namespace="x-a"} 1
+`
+	for i := 0; i < 2; i++ {
+		mfs, err := RelabelingGatherer(g, rules).Gather()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var b strings.Builder
+		for _, mf := range mfs {
+			if _, err := expfmt.MetricFamilyToText(&b, mf); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+		if got := b.String(); got != want {
+			t.Errorf("gather %d: want:\n%s\ngot:\n%s", i, want, got)
+		}
+	}
+	if len(gathered[0].Metric) != 2 || gathered[0].Metric[0].Label[0].GetValue() != "a" {
+		t.Errorf("expected the gathered family to be left alone, got %v", gathered[0])
+	}
+}
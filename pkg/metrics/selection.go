@@ -0,0 +1,108 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// CollectorGatherers maps the name of every active collector to the
+// gatherer of its metrics.
+type CollectorGatherers map[string]prometheus.Gatherer
+
+// Gatherer returns a gatherer of the metrics of all collectors.
+func (cg CollectorGatherers) Gatherer() prometheus.Gatherer {
+	g, _ := cg.Select(cg.names())
+	return g
+}
+
+// Gatherers returns the gatherers of all collectors, ordered by the names of
+// the collectors.
+func (cg CollectorGatherers) Gatherers() []prometheus.Gatherer {
+	gs, _ := cg.gatherers(cg.names())
+	return gs
+}
+
+// Select returns a gatherer of the metrics of the given collectors, including
+// the completeness of the scrape. It fails if any of them is not active.
+// The collectors are gathered concurrently, up to one per available CPU.
+func (cg CollectorGatherers) Select(names []string) (prometheus.Gatherer, error) {
+	gs, err := cg.gatherers(names)
+	if err != nil {
+		return nil, err
+	}
+	return completenessGatherer(concurrentGatherers(gs, runtime.GOMAXPROCS(0))), nil
+}
+
+func (cg CollectorGatherers) names() []string {
+	names := make([]string, 0, len(cg))
+	for name := range cg {
+		names = append(names, name)
+	}
+	return names
+}
+
+// gatherers returns the gatherers of the given collectors, ordered by their
+// names. It fails if any of them is not active.
+func (cg CollectorGatherers) gatherers(names []string) ([]prometheus.Gatherer, error) {
+	sort.Strings(names)
+
+	gs := []prometheus.Gatherer{}
+	for _, name := range names {
+		g, ok := cg[name]
+		if !ok {
+			return nil, fmt.Errorf("collector %q is not active", name)
+		}
+		gs = append(gs, g)
+	}
+	return gs, nil
+}
+
+// SelectingHandler serves requests with a collectors query parameter, e.g.
+// ?collectors=pods,nodes, with a handler for the metrics of just the given
+// collectors. All other requests are served by h.
+func SelectingHandler(h http.Handler, cg CollectorGatherers, handlerFor func([]prometheus.Gatherer) http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		values, ok := r.URL.Query()["collectors"]
+		if !ok {
+			h.ServeHTTP(w, r)
+			return
+		}
+
+		var names []string
+		for _, v := range values {
+			for _, name := range strings.Split(v, ",") {
+				if name = strings.TrimSpace(name); name != "" {
+					names = append(names, name)
+				}
+			}
+		}
+
+		gs, err := cg.gatherers(names)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		handlerFor(gs).ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,90 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+func TestSelectingHandler(t *testing.T) {
+	cg := CollectorGatherers{}
+	for _, name := range []string{"nodes", "pods", "services"} {
+		r := prometheus.NewRegistry()
+		r.MustRegister(prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "kube_" + name,
+			Help: name + " help",
+		}))
+		cg[name] = r
+	}
+	handlerFor := func(gs []prometheus.Gatherer) http.Handler {
+		return promhttp.HandlerFor(prometheus.Gatherers(gs), promhttp.HandlerOpts{})
+	}
+	h := SelectingHandler(handlerFor(cg.Gatherers()), cg, handlerFor)
+
+	tests := []struct {
+		Desc       string
+		Target     string
+		WantedCode int
+		Wanted     []string
+		Unwanted   []string
+	}{
+		{
+			Desc:       "all collectors",
+			Target:     "/metrics",
+			WantedCode: http.StatusOK,
+			Wanted:     []string{"kube_nodes", "kube_pods", "kube_services"},
+		},
+		{
+			Desc:       "selected collectors",
+			Target:     "/metrics?collectors=pods,nodes",
+			WantedCode: http.StatusOK,
+			Wanted:     []string{"kube_nodes", "kube_pods"},
+			Unwanted:   []string{"kube_services"},
+		},
+		{
+			Desc:       "inactive collector",
+			Target:     "/metrics?collectors=pods,secrets",
+			WantedCode: http.StatusBadRequest,
+			Unwanted:   []string{"kube_pods"},
+		},
+	}
+
+	for _, test := range tests {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("GET", test.Target, nil))
+		if rr.Code != test.WantedCode {
+			t.Errorf("Test error for Desc: %s. Want status %d, got %d.", test.Desc, test.WantedCode, rr.Code)
+		}
+		body := rr.Body.String()
+		for _, w := range test.Wanted {
+			if !strings.Contains(body, w) {
+				t.Errorf("Test error for Desc: %s. Missing %s in:\n%s", test.Desc, w, body)
+			}
+		}
+		for _, u := range test.Unwanted {
+			if strings.Contains(body, u) {
+				t.Errorf("Test error for Desc: %s. Unexpected %s in:\n%s", test.Desc, u, body)
+			}
+		}
+	}
+}
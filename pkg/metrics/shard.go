@@ -0,0 +1,70 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	descShardOrdinal = prometheus.NewDesc(
+		"kube_state_metrics_shard_ordinal",
+		"The shard of this kube-state-metrics instance.",
+		nil, nil,
+	)
+	descTotalShards = prometheus.NewDesc(
+		"kube_state_metrics_total_shards",
+		"The number of shards objects are split into.",
+		nil, nil,
+	)
+	descShardObjects = prometheus.NewDesc(
+		"kube_state_metrics_shard_objects",
+		"The number of objects of a resource owned by this shard.",
+		[]string{"resource"}, nil,
+	)
+)
+
+// ShardCollector exposes the shard of this instance and the number of objects
+// of every resource it owns, so that operators can verify that the shards
+// together cover all objects exactly once.
+type ShardCollector struct {
+	shard        int
+	totalShards  int
+	objectCounts func() map[string]int
+}
+
+// NewShardCollector returns a ShardCollector of the given shard. objectCounts
+// returns the number of objects owned by the shard per resource.
+func NewShardCollector(shard, totalShards int, objectCounts func() map[string]int) *ShardCollector {
+	return &ShardCollector{shard: shard, totalShards: totalShards, objectCounts: objectCounts}
+}
+
+// Describe implements the prometheus.Collector interface.
+func (c *ShardCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- descShardOrdinal
+	ch <- descTotalShards
+	ch <- descShardObjects
+}
+
+// Collect implements the prometheus.Collector interface.
+func (c *ShardCollector) Collect(ch chan<- prometheus.Metric) {
+	ch <- prometheus.MustNewConstMetric(descShardOrdinal, prometheus.GaugeValue, float64(c.shard))
+	ch <- prometheus.MustNewConstMetric(descTotalShards, prometheus.GaugeValue, float64(c.totalShards))
+	for resource, n := range c.objectCounts() {
+		ch <- prometheus.MustNewConstMetric(descShardObjects, prometheus.GaugeValue, float64(n), resource)
+	}
+}
@@ -0,0 +1,44 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"testing"
+
+	"k8s.io/kube-state-metrics/pkg/testutils"
+)
+
+func TestShardCollector(t *testing.T) {
+	c := NewShardCollector(1, 3, func() map[string]int {
+		return map[string]int{"node": 2, "pod": 40}
+	})
+	want := `
+		# HELP kube_state_metrics_shard_ordinal The shard of this kube-state-metrics instance.
+		# TYPE kube_state_metrics_shard_ordinal gauge
+		kube_state_metrics_shard_ordinal 1
+		# HELP kube_state_metrics_total_shards The number of shards objects are split into.
+		# TYPE kube_state_metrics_total_shards gauge
+		kube_state_metrics_total_shards 3
+		# HELP kube_state_metrics_shard_objects The number of objects of a resource owned by this shard.
+		# TYPE kube_state_metrics_shard_objects gauge
+		kube_state_metrics_shard_objects{resource="node"} 2
+		kube_state_metrics_shard_objects{resource="pod"} 40
+	`
+	if err := testutils.GatherAndCompare(c, want, nil); err != nil {
+		t.Errorf("unexpected collecting result:\n%s", err)
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// sharedFamilies are the families more than one collector has metrics of.
+// While streaming they are held back and merged once all collectors have been
+// gathered, as the metrics of a family have to be encoded together.
+var sharedFamilies = map[string]bool{
+	scrapeCollectorSuccessName: true,
+	scrapeErrorsTotalName:      true,
+	"kube_summarized_objects":  true,
+}
+
+// StreamingHandler serves the metrics of the gatherers of several collectors
+// like promhttp.HandlerFor, but encodes the metric families of every collector
+// straight into the response as soon as it has been gathered and wrapped with
+// wrap. Neither the encoded output nor the metric families of all collectors
+// are ever held at once, which would multiply the memory a scrape of a large
+// cluster takes. The collectors are gathered concurrently, up to one per
+// available CPU, and encoded in the given order, followed by the merged shared
+// families and the completeness of the scrape.
+//
+// If filterText is not nil, the metrics a TextGatherer has precomputed in the
+// text format are written as they are to text responses, skipping wrap, if
+// filterText returns true for their family. Otherwise, and for other formats,
+// they are parsed back and wrapped like any other metric.
+//
+// Errors gathering metrics before anything has been sent are answered with a
+// 500. Later errors can only be logged, as part of the response has been sent
+// already.
+func StreamingHandler(gs []prometheus.Gatherer, wrap func(prometheus.Gatherer) prometheus.Gatherer, filterText func(TextFamily) bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		format := expfmt.Negotiate(r.Header)
+		stop := make(chan struct{})
+		defer close(stop)
+		results, done := gatherAhead(gs, runtime.GOMAXPROCS(0), filterText != nil && format == expfmt.FmtText, stop)
+
+		var (
+			enc     expfmt.Encoder
+			out     io.Writer
+			gz      *gzip.Writer
+			started bool
+			shared  prometheus.Gatherers
+			seen    = map[string]bool{}
+		)
+		defer func() {
+			if gz != nil {
+				gz.Close()
+				putGzipWriter(gz)
+			}
+		}()
+		encode := func(g prometheus.Gatherer, sources []TextSource) bool {
+			mfs, err := wrap(g).Gather()
+			if err != nil {
+				glog.Errorf("error gathering metrics: %v", err)
+				if !started {
+					http.Error(w, fmt.Sprintf("An error has occurred during metrics gathering:\n\n%s", err), http.StatusInternalServerError)
+				}
+				return false
+			}
+			if !started {
+				started = true
+				w.Header().Set("Content-Type", string(format))
+				w.Header().Add("Vary", "Accept-Encoding")
+				out = w
+				if acceptsGzip(r.Header) {
+					w.Header().Set("Content-Encoding", "gzip")
+					gz = getGzipWriter(w)
+					out = gz
+				}
+				enc = expfmt.NewEncoder(out, format)
+			}
+			// Metrics of a precomputed family which are not precomputed
+			// are written along with the precomputed ones.
+			tfs := textFamilies(sources, filterText)
+			merged := map[string]*dto.MetricFamily{}
+			for _, tf := range tfs {
+				merged[tf.Name] = nil
+			}
+			for _, mf := range mfs {
+				if _, ok := merged[mf.GetName()]; ok {
+					merged[mf.GetName()] = mf
+					continue
+				}
+				if seen[mf.GetName()] {
+					glog.Errorf("metric family %s is exposed by several collectors, dropping its duplicate", mf.GetName())
+					continue
+				}
+				seen[mf.GetName()] = true
+				if err := enc.Encode(mf); err != nil {
+					glog.Errorf("error encoding metric family %s: %v", mf.GetName(), err)
+					return false
+				}
+			}
+			for _, tf := range tfs {
+				if seen[tf.Name] {
+					glog.Errorf("metric family %s is exposed by several collectors, dropping its duplicate", tf.Name)
+					continue
+				}
+				seen[tf.Name] = true
+				if err := writeTextFamily(out, tf, sources, merged[tf.Name]); err != nil {
+					glog.Errorf("error writing metric family %s: %v", tf.Name, err)
+					return false
+				}
+			}
+			return true
+		}
+
+		for _, result := range results {
+			res := <-result
+			var own []*dto.MetricFamily
+			var held []*dto.MetricFamily
+			for _, mf := range res.metricFamilies {
+				if sharedFamilies[mf.GetName()] {
+					held = append(held, mf)
+				} else {
+					own = append(own, mf)
+				}
+			}
+			shared = append(shared, staticGatherer(held, nil))
+			ok := encode(staticGatherer(own, res.err), res.textSources)
+			done()
+			if !ok {
+				return
+			}
+		}
+		encode(completenessGatherer(shared), nil)
+	})
+}
+
+// gatherResult is the result of a single gather.
+type gatherResult struct {
+	metricFamilies []*dto.MetricFamily
+	textSources    []TextSource
+	err            error
+}
+
+// gatherAhead gathers gs in their order, up to workers at the same time. It
+// returns a channel per gatherer receiving its result, and a function to call
+// once a result has been consumed, which allows the next gatherer to start.
+// If text is true, the precomputed metrics of TextGatherers are returned as
+// their sources instead of being parsed. No more gatherers are started once
+// stop is closed.
+func gatherAhead(gs []prometheus.Gatherer, workers int, text bool, stop <-chan struct{}) ([]chan gatherResult, func()) {
+	if workers < 1 {
+		workers = 1
+	}
+	results := make([]chan gatherResult, len(gs))
+	for i := range results {
+		results[i] = make(chan gatherResult, 1)
+	}
+	sem := make(chan struct{}, workers)
+	go func() {
+		for i, g := range gs {
+			select {
+			case sem <- struct{}{}:
+			case <-stop:
+				return
+			}
+			go func(i int, g prometheus.Gatherer) {
+				if tg, ok := g.(TextGatherer); ok && text {
+					mfs, sources, err := tg.GatherText()
+					results[i] <- gatherResult{metricFamilies: mfs, textSources: sources, err: err}
+					return
+				}
+				mfs, err := g.Gather()
+				results[i] <- gatherResult{metricFamilies: mfs, err: err}
+			}(i, g)
+		}
+	}()
+	return results, func() { <-sem }
+}
+
+// staticGatherer returns a gatherer returning the given metric families and
+// error.
+func staticGatherer(mfs []*dto.MetricFamily, err error) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		return mfs, err
+	})
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bytes"
+	"compress/gzip"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+func TestStreamingHandler(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "test_metric_1", Help: "Test 1."}, func() float64 { return 1 }))
+	reg.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "test_metric_2", Help: "Test 2."}, func() float64 { return 2 }))
+	h := StreamingHandler([]prometheus.Gatherer{reg}, unwrapped, nil)
+
+	const want = `# HELP test_metric_1 Test 1.
+# TYPE test_metric_1 gauge
+test_metric_1 1
+# HELP test_metric_2 Test 2.
+# TYPE test_metric_2 gauge
+test_metric_2 2
+`
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expected body\n%s\ngot\n%s", want, got)
+	}
+	if got := rec.Header().Get("Content-Type"); got != string(expfmt.FmtText) {
+		t.Errorf("expected content type %q, got %q", expfmt.FmtText, got)
+	}
+
+	// Pooled gzip writers are reused by later requests.
+	for i := 0; i < 2; i++ {
+		req := httptest.NewRequest("GET", "/metrics", nil)
+		req.Header.Set("Accept-Encoding", "gzip")
+		rec = httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if got := rec.Header().Get("Content-Encoding"); got != "gzip" {
+			t.Fatalf("expected gzip content encoding, got %q", got)
+		}
+		gz, err := gzip.NewReader(rec.Body)
+		if err != nil {
+			t.Fatalf("unexpected error reading gzip body: %v", err)
+		}
+		body, err := ioutil.ReadAll(gz)
+		if err != nil {
+			t.Fatalf("unexpected error reading gzip body: %v", err)
+		}
+		if string(body) != want {
+			t.Errorf("expected gzipped body\n%s\ngot\n%s", want, body)
+		}
+	}
+
+	failing := StreamingHandler([]prometheus.Gatherer{gathererFunc(func() ([]*dto.MetricFamily, error) {
+		return nil, errors.New("failed")
+	})}, unwrapped, nil)
+	rec = httptest.NewRecorder()
+	failing.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if rec.Code != http.StatusInternalServerError {
+		t.Errorf("expected status %d on gather errors, got %d", http.StatusInternalServerError, rec.Code)
+	}
+}
+
+func unwrapped(g prometheus.Gatherer) prometheus.Gatherer {
+	return g
+}
+
+func TestStreamingHandlerSharedFamilies(t *testing.T) {
+	var gs []prometheus.Gatherer
+	for _, name := range []string{"nodes", "pods"} {
+		r := prometheus.NewRegistry()
+		r.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "kube_" + name, Help: name + " help"}, func() float64 { return 1 }))
+		r.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name:        "kube_summarized_objects",
+			Help:        "Number of objects per namespace for which no per-object metrics are exposed.",
+			ConstLabels: prometheus.Labels{"resource": strings.TrimSuffix(name, "s")},
+		}, func() float64 { return 2 }))
+		gs = append(gs, ScrapeResultGatherer(name, r))
+	}
+	h := StreamingHandler(gs, unwrapped, nil)
+
+	const want = `# HELP kube_nodes nodes help
+# TYPE kube_nodes gauge
+kube_nodes 1
+# HELP kube_pods pods help
+# TYPE kube_pods gauge
+kube_pods 1
+# HELP kube_state_metrics_scrape_collector_success Whether the collector was rendered successfully in this scrape.
+# TYPE kube_state_metrics_scrape_collector_success gauge
+kube_state_metrics_scrape_collector_success{collector="nodes"} 1
+kube_state_metrics_scrape_collector_success{collector="pods"} 1
+# HELP kube_state_metrics_scrape_errors_total Total number of scrapes in which the collector failed to render.
+# TYPE kube_state_metrics_scrape_errors_total counter
+kube_state_metrics_scrape_errors_total{collector="nodes"} 0
+kube_state_metrics_scrape_errors_total{collector="pods"} 0
+# HELP kube_summarized_objects Number of objects per namespace for which no per-object metrics are exposed.
+# TYPE kube_summarized_objects gauge
+kube_summarized_objects{resource="node"} 2
+kube_summarized_objects{resource="pod"} 2
+# HELP kube_state_metrics_scrape_completeness Fraction of the collectors of this scrape that were rendered successfully.
+# TYPE kube_state_metrics_scrape_completeness gauge
+kube_state_metrics_scrape_completeness 1
+`
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+	if got := rec.Body.String(); got != want {
+		t.Errorf("expected body\n%s\ngot\n%s", want, got)
+	}
+}
+
+// lockedWriter is a http.ResponseWriter whose body can be read while it is
+// written.
+type lockedWriter struct {
+	mu     sync.Mutex
+	header http.Header
+	body   bytes.Buffer
+}
+
+func (w *lockedWriter) Header() http.Header { return w.header }
+func (w *lockedWriter) WriteHeader(int)     {}
+
+func (w *lockedWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.body.Write(b)
+}
+
+func (w *lockedWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.body.String()
+}
+
+func TestStreamingHandlerEncodesEachCollectorWhenGathered(t *testing.T) {
+	w := &lockedWriter{header: http.Header{}}
+
+	// All but the last collector are gathered at once. The last one is only
+	// gathered once the first one has been encoded.
+	first := prometheus.NewRegistry()
+	first.MustRegister(prometheus.NewGaugeFunc(prometheus.GaugeOpts{Name: "test_metric", Help: "Test."}, func() float64 { return 1 }))
+	gs := []prometheus.Gatherer{first}
+	for i := 1; i < runtime.GOMAXPROCS(0); i++ {
+		gs = append(gs, prometheus.NewRegistry())
+	}
+	gs = append(gs, gathererFunc(func() ([]*dto.MetricFamily, error) {
+		if body := w.String(); !strings.Contains(body, "test_metric 1") {
+			t.Errorf("expected the metrics of the first collector to be encoded before the last one is gathered, got\n%s", body)
+		}
+		return nil, nil
+	}))
+
+	StreamingHandler(gs, unwrapped, nil).ServeHTTP(w, httptest.NewRequest("GET", "/metrics", nil))
+	if body := w.String(); !strings.Contains(body, "test_metric 1") {
+		t.Errorf("expected the metrics of the first collector, got\n%s", body)
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"regexp"
+	"sort"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+const (
+	namespaceLabel = "namespace"
+	tenantLabel    = "tenant"
+)
+
+// TenantFunc derives the tenant a namespace belongs to. An empty string
+// means the namespace does not belong to any tenant.
+type TenantFunc func(namespace string) string
+
+// NamespaceRegexTenant derives the tenant from the namespace name. The first
+// capture group of the regular expression is the tenant, or the whole match if
+// it does not have any.
+func NamespaceRegexTenant(re *regexp.Regexp) TenantFunc {
+	return func(namespace string) string {
+		m := re.FindStringSubmatch(namespace)
+		switch len(m) {
+		case 0:
+			return ""
+		case 1:
+			return m[0]
+		default:
+			return m[1]
+		}
+	}
+}
+
+// FirstTenant returns a TenantFunc trying each of the given TenantFuncs in
+// order until one derives a tenant.
+func FirstTenant(fs ...TenantFunc) TenantFunc {
+	return func(namespace string) string {
+		for _, f := range fs {
+			if t := f(namespace); t != "" {
+				return t
+			}
+		}
+		return ""
+	}
+}
+
+// TenantGatherer wraps a prometheus.Gatherer to add a tenant label to every
+// metric carrying a namespace label. Metrics which already have a tenant
+// label are left untouched.
+func TenantGatherer(r prometheus.Gatherer, tenantOf TenantFunc) prometheus.Gatherer {
+	return gathererFunc(func() ([]*dto.MetricFamily, error) {
+		metricFamilies, err := r.Gather()
+		if err != nil {
+			return nil, err
+		}
+
+		// The same namespaces show up in most of the metrics, so only derive
+		// their tenant once per gathering.
+		tenants := map[string]string{}
+		for _, metricFamily := range metricFamilies {
+			for _, m := range metricFamily.Metric {
+				addTenantLabel(m, tenants, tenantOf)
+			}
+		}
+
+		return metricFamilies, nil
+	})
+}
+
+func addTenantLabel(m *dto.Metric, tenants map[string]string, tenantOf TenantFunc) {
+	namespace, found := "", false
+	for _, lp := range m.Label {
+		switch lp.GetName() {
+		case tenantLabel:
+			return
+		case namespaceLabel:
+			namespace, found = lp.GetValue(), true
+		}
+	}
+	if !found {
+		return
+	}
+
+	tenant, ok := tenants[namespace]
+	if !ok {
+		tenant = tenantOf(namespace)
+		tenants[namespace] = tenant
+	}
+	if tenant == "" {
+		return
+	}
+
+	m.Label = append(m.Label, &dto.LabelPair{
+		Name:  proto.String(tenantLabel),
+		Value: proto.String(tenant),
+	})
+	sort.Sort(prometheus.LabelPairSorter(m.Label))
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestNamespaceRegexTenant(t *testing.T) {
+	tests := []struct {
+		Desc      string
+		Regex     string
+		Namespace string
+		Wanted    string
+	}{
+		{
+			Desc:      "capture group",
+			Regex:     "^([a-z]+)-",
+			Namespace: "payments-prod",
+			Wanted:    "payments",
+		},
+		{
+			Desc:      "whole match",
+			Regex:     "^[a-z]+",
+			Namespace: "payments-prod",
+			Wanted:    "payments",
+		},
+		{
+			Desc:      "no match",
+			Regex:     "^([a-z]+)-",
+			Namespace: "default",
+			Wanted:    "",
+		},
+	}
+
+	for _, test := range tests {
+		got := NamespaceRegexTenant(regexp.MustCompile(test.Regex))(test.Namespace)
+		if got != test.Wanted {
+			t.Errorf("Test error for Desc: %s. Want: %q. Got: %q.", test.Desc, test.Wanted, got)
+		}
+	}
+}
+
+func TestTenantGatherer(t *testing.T) {
+	r := prometheus.NewRegistry()
+	g := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "test1",
+			Help: "test1 help",
+		},
+		[]string{"namespace", "pod"},
+	)
+	c := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "test2",
+			Help: "test2 help",
+		},
+	)
+	g.WithLabelValues("payments-prod", "pod1").Set(1)
+	g.WithLabelValues("kube-system", "pod2").Set(1)
+	c.Inc()
+	r.MustRegister(g)
+	r.MustRegister(c)
+
+	tenantOf := FirstTenant(
+		func(namespace string) string {
+			if namespace == "kube-system" {
+				return "platform"
+			}
+			return ""
+		},
+		NamespaceRegexTenant(regexp.MustCompile("^([a-z]+)-prod$")),
+	)
+
+	res, err := TenantGatherer(r, tenantOf).Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tenants := map[string]string{}
+	for _, mf := range res {
+		for _, m := range mf.Metric {
+			var namespace, tenant string
+			for _, lp := range m.Label {
+				switch lp.GetName() {
+				case "namespace":
+					namespace = lp.GetValue()
+				case "tenant":
+					tenant = lp.GetValue()
+				}
+			}
+			if mf.GetName() == "test2" && tenant != "" {
+				t.Fatalf("Expected metric without namespace label to have no tenant, got %q.", tenant)
+			}
+			if namespace != "" {
+				tenants[namespace] = tenant
+			}
+		}
+	}
+
+	if tenants["payments-prod"] != "payments" || tenants["kube-system"] != "platform" {
+		t.Fatalf("Expected tenants `payments` and `platform`, got %v.", tenants)
+	}
+}
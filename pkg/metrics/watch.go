@@ -0,0 +1,169 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/golang/glog"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+
+// WatchHandler streams changes of the metrics of a prometheus.Gatherer as
+// server-sent events. A new stream starts with an add event for every series,
+// followed by add, update and delete events as series appear, change their
+// value and disappear. The data of every event is the series in the
+// Prometheus text exposition format, without the value for delete events.
+//
+// The gatherer is polled every interval, but only gathered again when the
+// generation reported by the generation function changed. As metrics derived
+// from the current time change without the generation changing, they are
+// only streamed along with other changes.
+func WatchHandler(g prometheus.Gatherer, generation func() uint64, interval time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.WriteHeader(http.StatusOK)
+		flusher.Flush()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		var (
+			series  = map[string]float64{}
+			lastGen uint64
+			started bool
+		)
+		for {
+			if gen := generation(); !started || gen != lastGen {
+				metricFamilies, err := g.Gather()
+				if err != nil {
+					glog.Errorf("gathering metrics to watch failed: %v", err)
+				} else {
+					current := seriesValues(metricFamilies)
+					if err := writeSeriesChanges(w, series, current); err != nil {
+						return
+					}
+					flusher.Flush()
+					series, lastGen, started = current, gen, true
+				}
+			}
+
+			select {
+			case <-r.Context().Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	})
+}
+
+// seriesValues returns the value of every gauge, counter and untyped series
+// by its text exposition without the value.
+func seriesValues(metricFamilies []*dto.MetricFamily) map[string]float64 {
+	values := map[string]float64{}
+	for _, mf := range metricFamilies {
+		for _, m := range mf.Metric {
+			var v float64
+			switch mf.GetType() {
+			case dto.MetricType_GAUGE:
+				v = m.GetGauge().GetValue()
+			case dto.MetricType_COUNTER:
+				v = m.GetCounter().GetValue()
+			case dto.MetricType_UNTYPED:
+				v = m.GetUntyped().GetValue()
+			default:
+				continue
+			}
+			values[seriesText(mf.GetName(), m.Label)] = v
+		}
+	}
+	return values
+}
+
+func seriesText(name string, labels []*dto.LabelPair) string {
+	if len(labels) == 0 {
+		return name
+	}
+	var b strings.Builder
+	b.WriteString(name)
+	b.WriteByte('{')
+	for i, lp := range labels {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(lp.GetName())
+		b.WriteString(`="`)
+		b.WriteString(labelValueEscaper.Replace(lp.GetValue()))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// writeSeriesChanges writes an event for every series added, updated or
+// deleted from previous to current, ordered by series.
+func writeSeriesChanges(w io.Writer, previous, current map[string]float64) error {
+	keys := make([]string, 0, len(current)+len(previous))
+	for s := range current {
+		keys = append(keys, s)
+	}
+	for s := range previous {
+		if _, ok := current[s]; !ok {
+			keys = append(keys, s)
+		}
+	}
+	sort.Strings(keys)
+
+	for _, s := range keys {
+		v, ok := current[s]
+		old, existed := previous[s]
+		var err error
+		switch {
+		case !ok:
+			_, err = fmt.Fprintf(w, "event: delete\ndata: %s\n\n", s)
+		case !existed:
+			_, err = fmt.Fprintf(w, "event: add\ndata: %s %s\n\n", s, formatValue(v))
+		case v != old && !(math.IsNaN(v) && math.IsNaN(old)):
+			_, err = fmt.Fprintf(w, "event: update\ndata: %s %s\n\n", s, formatValue(v))
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatValue(v float64) string {
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+func TestWatchHandler(t *testing.T) {
+	r := prometheus.NewRegistry()
+	replicas := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_deployment_status_replicas",
+			Help: "The number of replicas per deployment.",
+		},
+		[]string{"namespace", "deployment"},
+	)
+	r.MustRegister(replicas)
+	replicas.WithLabelValues("ns1", "depl1").Set(1)
+	replicas.WithLabelValues("ns1", "depl2").Set(2)
+
+	var generation uint64
+	srv := httptest.NewServer(WatchHandler(r, func() uint64 { return atomic.LoadUint64(&generation) }, 10*time.Millisecond))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("want content type text/event-stream, got %q", ct)
+	}
+
+	events := make(chan string)
+	go func() {
+		scanner := bufio.NewScanner(resp.Body)
+		var event []string
+		for scanner.Scan() {
+			if scanner.Text() == "" {
+				events <- strings.Join(event, "\n")
+				event = nil
+				continue
+			}
+			event = append(event, scanner.Text())
+		}
+		close(events)
+	}()
+	expectEvents := func(want ...string) {
+		for _, w := range want {
+			select {
+			case got := <-events:
+				if got != w {
+					t.Errorf("want event:\n%s\ngot:\n%s", w, got)
+				}
+			case <-time.After(5 * time.Second):
+				t.Fatalf("timed out waiting for event:\n%s", w)
+			}
+		}
+	}
+
+	expectEvents(
+		"event: add\ndata: kube_deployment_status_replicas{deployment=\"depl1\",namespace=\"ns1\"} 1",
+		"event: add\ndata: kube_deployment_status_replicas{deployment=\"depl2\",namespace=\"ns1\"} 2",
+	)
+
+	replicas.DeleteLabelValues("ns1", "depl1")
+	replicas.WithLabelValues("ns1", "depl2").Set(3)
+	replicas.WithLabelValues("ns2", "depl3").Set(1)
+	atomic.AddUint64(&generation, 1)
+
+	expectEvents(
+		"event: delete\ndata: kube_deployment_status_replicas{deployment=\"depl1\",namespace=\"ns1\"}",
+		"event: update\ndata: kube_deployment_status_replicas{deployment=\"depl2\",namespace=\"ns1\"} 3",
+		"event: add\ndata: kube_deployment_status_replicas{deployment=\"depl3\",namespace=\"ns2\"} 1",
+	)
+}
@@ -22,6 +22,7 @@ import (
 
 var (
 	DefaultNamespaces = NamespaceList{metav1.NamespaceAll}
+	// DefaultCollectors are enabled unless --collectors is set.
 	DefaultCollectors = CollectorSet{
 		"daemonsets":               struct{}{},
 		"deployments":              struct{}{},
@@ -42,5 +43,50 @@ var (
 		"endpoints":                struct{}{},
 		"secrets":                  struct{}{},
 		"configmaps":               struct{}{},
+		"poddisruptionbudgets":     struct{}{},
+	}
+	// AvailableCollectors are all collectors --collectors accepts. Those
+	// which are not default collectors are opt-in, so that upgrading doesn't
+	// require new RBAC rules or change the default output.
+	AvailableCollectors = CollectorSet{
+		"daemonsets":                      struct{}{},
+		"deployments":                     struct{}{},
+		"limitranges":                     struct{}{},
+		"nodes":                           struct{}{},
+		"pods":                            struct{}{},
+		"replicasets":                     struct{}{},
+		"replicationcontrollers":          struct{}{},
+		"resourcequotas":                  struct{}{},
+		"services":                        struct{}{},
+		"jobs":                            struct{}{},
+		"cronjobs":                        struct{}{},
+		"statefulsets":                    struct{}{},
+		"persistentvolumes":               struct{}{},
+		"persistentvolumeclaims":          struct{}{},
+		"namespaces":                      struct{}{},
+		"horizontalpodautoscalers":        struct{}{},
+		"verticalpodautoscalers":          struct{}{},
+		"endpoints":                       struct{}{},
+		"endpointslices":                  struct{}{},
+		"secrets":                         struct{}{},
+		"configmaps":                      struct{}{},
+		"certificatesigningrequests":      struct{}{},
+		"poddisruptionbudgets":            struct{}{},
+		"podsecuritypolicies":             struct{}{},
+		"ingresses":                       struct{}{},
+		"ingressclasses":                  struct{}{},
+		"storageclasses":                  struct{}{},
+		"mutatingwebhookconfigurations":   struct{}{},
+		"validatingwebhookconfigurations": struct{}{},
+		"volumeattachments":               struct{}{},
+		"csinodes":                        struct{}{},
+		"csidrivers":                      struct{}{},
+		"customresourcedefinitions":       struct{}{},
+		"apiservices":                     struct{}{},
+		"runtimeclasses":                  struct{}{},
+		"volumesnapshots":                 struct{}{},
+		"volumesnapshotcontents":          struct{}{},
+		"apiresources":                    struct{}{},
+		"events":                          struct{}{},
 	}
 )
@@ -19,26 +19,78 @@ package options
 import (
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/spf13/pflag"
+	utilnet "k8s.io/apimachinery/pkg/util/net"
 )
 
 type Options struct {
 	Apiserver                            string
 	Kubeconfig                           string
+	Fixtures                             string
 	Help                                 bool
 	Port                                 int
 	Host                                 string
 	TelemetryPort                        int
+	EnablePprof                          bool
 	TelemetryHost                        string
 	Collectors                           CollectorSet
 	Namespaces                           NamespaceList
+	Shard                                int
+	TotalShards                          int
+	Pod                                  string
+	Node                                 string
+	PodFieldSelector                     string
+	ListChunkSize                        int64
 	MetricBlacklist                      MetricSet
 	MetricWhitelist                      MetricSet
+	CompatMetrics                        MetricRenames
+	AnnotationWhitelist                  AnnotationSet
+	MaxLabelValueLength                  int
+	Lite                                 bool
+	AggregationConfig                    string
+	RelabelConfig                        string
+	CustomResourceStateConfigFile        string
+	DeletedObjectRetention               time.Duration
+	InformerTransformConfig              string
+	DetailedNamespaces                   NamespaceList
+	DetailedLabelSelector                LabelSelector
+	TenantNamespaceLabel                 string
+	TenantNamespaceRegex                 string
+	MetricsCacheMaxAge                   time.Duration
+	PrecomputeMetrics                    bool
+	MetricsWatch                         bool
+	NamespaceIsolation                   bool
+	NamespaceIsolationCacheTTL           time.Duration
+	ServerReadTimeout                    time.Duration
+	ServerWriteTimeout                   time.Duration
+	ServerIdleTimeout                    time.Duration
+	ServerMaxHeaderBytes                 int
+	ServerDisableHTTP2                   bool
+	TLSCertFile                          string
+	TLSPrivateKeyFile                    string
+	TLSClientCAFile                      string
+	DelegatedAuth                        bool
+	DelegatedAuthCacheTTL                time.Duration
+	ReadinessFailureThreshold            time.Duration
+	AddonWorkloads                       AddonWorkloads
+	HeartbeatURL                         string
+	HeartbeatInterval                    time.Duration
+	HeartbeatInstance                    string
+	HeartbeatMetricsURL                  string
+	HeartbeatLabels                      LabelMap
+	DebugTokenFile                       string
 	Version                              bool
 	DisablePodNonGenericResourceMetrics  bool
 	DisableNodeNonGenericResourceMetrics bool
+	NodeVolumeInfo                       bool
+	ServiceNodePortRange                 utilnet.PortRange
+	ConditionEncoding                    ConditionEncoding
 
 	flags *pflag.FlagSet
 }
@@ -48,6 +100,14 @@ func NewOptions() *Options {
 		Collectors:      CollectorSet{},
 		MetricWhitelist: MetricSet{},
 		MetricBlacklist: MetricSet{},
+		CompatMetrics:   MetricRenames{},
+		AddonWorkloads:  AddonWorkloads{},
+		HeartbeatLabels: LabelMap{},
+
+		AnnotationWhitelist: AnnotationSet{},
+
+		ServiceNodePortRange: utilnet.PortRange{Base: 30000, Size: 2768},
+		ConditionEncoding:    ConditionEncodingStateSet,
 	}
 }
 
@@ -66,18 +126,65 @@ func (o *Options) AddFlags() {
 
 	o.flags.StringVar(&o.Apiserver, "apiserver", "", `The URL of the apiserver to use as a master`)
 	o.flags.StringVar(&o.Kubeconfig, "kubeconfig", "", "Absolute path to the kubeconfig file")
+	o.flags.StringVar(&o.Fixtures, "fixtures", "", "Path to a YAML manifest or a directory of them. If set, the metrics of the objects in the manifests are exposed through a fake clientset instead of connecting to an apiserver. Meant for development and testing.")
 	o.flags.BoolVarP(&o.Help, "help", "h", false, "Print Help text")
 	o.flags.IntVar(&o.Port, "port", 80, `Port to expose metrics on.`)
 	o.flags.StringVar(&o.Host, "host", "0.0.0.0", `Host to expose metrics on.`)
 	o.flags.IntVar(&o.TelemetryPort, "telemetry-port", 81, `Port to expose kube-state-metrics self metrics on.`)
+	o.flags.BoolVar(&o.EnablePprof, "enable-pprof", false, "Expose the net/http/pprof profiling endpoints under /debug/pprof/ on the telemetry port, behind --delegated-auth if set.")
 	o.flags.StringVar(&o.TelemetryHost, "telemetry-host", "0.0.0.0", `Host to expose kube-state-metrics self metrics on.`)
-	o.flags.Var(&o.Collectors, "collectors", fmt.Sprintf("Comma-separated list of collectors to be enabled. Defaults to %q", &DefaultCollectors))
+	o.flags.Var(&o.Collectors, "collectors", fmt.Sprintf("Comma-separated list of collectors to be enabled. Defaults to %q. Available are %q", &DefaultCollectors, &AvailableCollectors))
 	o.flags.Var(&o.Namespaces, "namespace", fmt.Sprintf("Comma-separated list of namespaces to be enabled. Defaults to %q", &DefaultNamespaces))
+	o.flags.IntVar(&o.Shard, "shard", 0, "The shard of this instance, between 0 and --total-shards minus one. Only the metrics of objects whose UID hashes to this shard are exposed.")
+	o.flags.IntVar(&o.TotalShards, "total-shards", 1, "The number of shards objects are split into, each served by one instance with its own --shard.")
+	o.flags.StringVar(&o.Pod, "pod", "", "Name of the pod of this instance, e.g. $(POD_NAME) set from the downward API. If it is the name of a StatefulSet pod, its ordinal is used as --shard, so that all replicas share the same arguments.")
+	o.flags.StringVar(&o.Node, "node", "", "Name of the node of this instance, e.g. $(NODE_NAME) set from the downward API. If set, only the pods on this node are watched, so that kube-state-metrics can be deployed as a DaemonSet spreading the pod metrics across nodes.")
+	o.flags.StringVar(&o.PodFieldSelector, "pod-field-selector", "", "Field selector restricting the watched pods, e.g. status.phase!=Succeeded. It is combined with --node.")
+	o.flags.Int64Var(&o.ListChunkSize, "list-chunk-size", 0, "Number of objects per page of the initial lists of informers. If set, lists are paginated with limit and continue instead of being served in one response from the watch cache of the apiserver, which reduces its memory spikes on large clusters. Zero disables pagination.")
 	o.flags.Var(&o.MetricWhitelist, "metric-whitelist", "Comma-separated list of metrics to be exposed. The whitelist and blacklist are mutually exclusive.")
 	o.flags.Var(&o.MetricBlacklist, "metric-blacklist", "Comma-separated list of metrics not to be enabled. The whitelist and blacklist are mutually exclusive.")
+	o.flags.Var(&o.CompatMetrics, "compat-metrics", "Comma-separated list of old=new metric names. Metrics renamed to new are also exposed under their old name, so that dashboards can be migrated gradually.")
+	o.flags.Var(&o.AnnotationWhitelist, "annotation-whitelist", "Comma-separated list of annotation keys exposed as annotation_* labels by collectors which only expose whitelisted annotations. Use \"*\" to expose all annotations.")
+	o.flags.IntVar(&o.MaxLabelValueLength, "max-label-value-length", 256, "Maximum length in bytes of label values copied from Kubernetes labels and annotations. Longer values are truncated and end with a tilde followed by a hash of the full value, so it has to be at least 9. Zero means no limit.")
+	o.flags.StringVar(&o.AggregationConfig, "aggregation-config", "", "Path to a YAML file with aggregation rules, whose computed metrics are exposed in addition to the collected ones.")
+	o.flags.StringVar(&o.RelabelConfig, "relabel-config", "", "Path to a YAML file with relabel rules, a subset of the metric_relabel_configs of Prometheus, applied to all series before they are exposed.")
+	o.flags.StringVar(&o.CustomResourceStateConfigFile, "custom-resource-state-config-file", "", "Path to a YAML file declaring metrics generated from the objects of custom resources. The customresources collector is enabled if set.")
+	o.flags.DurationVar(&o.DeletedObjectRetention, "deleted-object-retention", 0, "Duration for which deleted objects are kept as kube_<resource>_deleted series holding their deletion timestamp. Zero disables these series.")
+	o.flags.StringVar(&o.InformerTransformConfig, "informer-transform-config", "", "Path to a YAML file declaring per resource what is stripped from objects before informers store them, e.g. large annotations or configmap data, to reduce memory usage.")
+	o.flags.BoolVar(&o.Lite, "lite", false, "Only expose aggregates instead of per-object series. Labels identifying objects are dropped and the values of the remaining series are summed up, timestamps and durations are dropped.")
+	o.flags.Var(&o.DetailedNamespaces, "detailed-namespaces", "Comma-separated list of namespaces whose objects get per-object metrics. When this or --detailed-label-selector is set, objects of other namespaces are only counted in kube_summarized_objects.")
+	o.flags.Var(&o.DetailedLabelSelector, "detailed-label-selector", "Label selector of objects which get per-object metrics. When this or --detailed-namespaces is set, other namespaced objects are only counted in kube_summarized_objects.")
+	o.flags.StringVar(&o.TenantNamespaceLabel, "tenant-namespace-label", "", "Namespace label whose value is added as tenant label to all metrics with a namespace label.")
+	o.flags.StringVar(&o.TenantNamespaceRegex, "tenant-namespace-regex", "", "Regular expression matched against namespace names to derive the tenant label of all metrics with a namespace label, if --tenant-namespace-label yields none. The first capture group is used as tenant if present.")
+	o.flags.DurationVar(&o.MetricsCacheMaxAge, "metrics-cache-max-age", 0, "Maximum age of the cached /metrics output. The output is rendered again earlier whenever an informer observes a change. Responses carry an ETag so that conditional requests get a 304 while nothing changed. Zero disables the cache.")
+	o.flags.BoolVar(&o.PrecomputeMetrics, "precompute-metrics", false, "Generate the per-object metrics of pods, replicasets, jobs, services, endpoints and configmaps whenever an informer observes a change instead of on every scrape. The metrics are cached encoded in the text format and written as they are to text scrapes. This trades memory for shorter scrapes on large clusters.")
+	o.flags.BoolVar(&o.MetricsWatch, "experimental-metrics-watch", false, "EXPERIMENTAL: Stream additions, updates and deletions of the metrics as server-sent events on /metrics/watch.")
+	o.flags.BoolVar(&o.NamespaceIsolation, "namespace-isolation", false, "Require a bearer token on /metrics and only serve the metrics of the namespaces its user can get pods in, unless it can get pods in all namespaces. Metrics without a namespace label are only served to the latter. Tokens and access are reviewed with TokenReviews and SubjectAccessReviews.")
+	o.flags.DurationVar(&o.NamespaceIsolationCacheTTL, "namespace-isolation-cache-ttl", time.Minute, "Duration the results of TokenReviews and SubjectAccessReviews are cached for with --namespace-isolation.")
+	o.flags.DurationVar(&o.ServerReadTimeout, "server-read-timeout", 0, "Maximum duration for reading an entire request, including the body. Zero means no timeout.")
+	o.flags.DurationVar(&o.ServerWriteTimeout, "server-write-timeout", 0, "Maximum duration before timing out writes of a response. Very large scrapes from slow Prometheus servers may need a generous value. Zero means no timeout.")
+	o.flags.DurationVar(&o.ServerIdleTimeout, "server-idle-timeout", 0, "Maximum time to wait for the next request on a keep-alive connection. Zero means the read timeout is used.")
+	o.flags.IntVar(&o.ServerMaxHeaderBytes, "server-max-header-bytes", http.DefaultMaxHeaderBytes, "Maximum number of bytes the server reads parsing request headers.")
+	o.flags.BoolVar(&o.ServerDisableHTTP2, "server-disable-http2", false, "Disable HTTP/2 on the metrics and telemetry servers.")
+	o.flags.StringVar(&o.TLSCertFile, "tls-cert-file", "", "Path to a certificate the metrics server serves HTTPS with. It serves HTTP if empty.")
+	o.flags.StringVar(&o.TLSPrivateKeyFile, "tls-private-key-file", "", "Path to the private key of --tls-cert-file.")
+	o.flags.StringVar(&o.TLSClientCAFile, "tls-client-ca-file", "", "Path to a CA bundle client certificates are verified against. If set, /metrics, /metrics/watch and the debug endpoints of the metrics server require a client certificate signed by one of its CAs. Requires --tls-cert-file.")
+	o.flags.BoolVar(&o.DelegatedAuth, "delegated-auth", false, "Require a bearer token on /metrics, /metrics/watch and the pprof endpoints of the metrics and telemetry servers whose user can get the requested path as non-resource URL. Tokens and access are reviewed with TokenReviews and SubjectAccessReviews, which replaces a kube-rbac-proxy sidecar.")
+	o.flags.DurationVar(&o.ReadinessFailureThreshold, "readiness-failure-threshold", 5*time.Minute, "Duration the list and watch requests of a resource may keep failing before /readyz reports kube-state-metrics as unready. Zero disables the check.")
+	o.flags.DurationVar(&o.DelegatedAuthCacheTTL, "delegated-auth-cache-ttl", time.Minute, "Duration the results of TokenReviews and SubjectAccessReviews are cached for with --delegated-auth.")
+	o.flags.Var(&o.AddonWorkloads, "addons", "Comma-separated list of cluster addons and the workloads running them, in the format addon=kind/namespace/name with a kind of deployment, daemonset or statefulset, e.g. coredns=deployment/kube-system/coredns. Each yields a kube_addon_workload_available metric.")
+	o.flags.StringVar(&o.HeartbeatURL, "heartbeat-url", "", "URL to periodically POST a JSON heartbeat with the instance and the last informer sync timestamps to. Disabled if empty.")
+	o.flags.DurationVar(&o.HeartbeatInterval, "heartbeat-interval", time.Minute, "Interval between two heartbeats.")
+	o.flags.StringVar(&o.HeartbeatInstance, "heartbeat-instance", "", "Instance name reported in heartbeats. Defaults to the hostname.")
+	o.flags.StringVar(&o.HeartbeatMetricsURL, "heartbeat-metrics-url", "", "URL the metrics of this instance can be scraped at, announced in heartbeats so that a discovery service can derive scrape targets from them. Not announced if empty.")
+	o.flags.Var(&o.HeartbeatLabels, "heartbeat-labels", "Comma-separated list of name=value labels of the scrape target announced in heartbeats, e.g. cluster=prod.")
+	o.flags.StringVar(&o.DebugTokenFile, "debug-token-file", "", "Path to a file with a bearer token required to access the debug endpoints /debug/object, which serves the metrics generated for a single object, and /debug/cardinality, which reports the number of series per metric family and label value. The endpoints are disabled if empty.")
 	o.flags.BoolVarP(&o.Version, "version", "", false, "kube-state-metrics build version information")
 	o.flags.BoolVarP(&o.DisablePodNonGenericResourceMetrics, "disable-pod-non-generic-resource-metrics", "", false, "Disable pod non generic resource request and limit metrics")
 	o.flags.BoolVarP(&o.DisableNodeNonGenericResourceMetrics, "disable-node-non-generic-resource-metrics", "", false, "Disable node non generic resource request and limit metrics")
+	o.flags.Var(&o.ServiceNodePortRange, "service-node-port-range", "The --service-node-port-range of the apiserver, which the number of allocated node ports is compared with. Set it to an empty value to not expose the size of the range.")
+	o.flags.Var(&o.ConditionEncoding, "condition-encoding", "Encoding of the status of conditions, e.g. of kube_node_status_condition. stateset exposes a series per status, true, false and unknown, of which the one of the current status is 1. binary only exposes the series of the true status, which is 1 if the condition is true and 0 otherwise, like all other boolean metrics.")
+	o.flags.BoolVar(&o.NodeVolumeInfo, "node-volume-info", false, "Expose a series per volume attached to or in use by a node in addition to their numbers.")
 }
 
 func (o *Options) Parse() error {
@@ -88,3 +195,29 @@ func (o *Options) Parse() error {
 func (o *Options) Usage() {
 	o.flags.Usage()
 }
+
+// WatchesAllNamespaces returns whether the objects of all namespaces are
+// watched, which --namespace restricts.
+func (o *Options) WatchesAllNamespaces() bool {
+	return len(o.Namespaces) == 0 || o.Namespaces.IsAllNamespaces()
+}
+
+// WatchesAllPods returns whether all pods of the cluster are watched, which
+// --namespace, --node and --pod-field-selector restrict.
+func (o *Options) WatchesAllPods() bool {
+	return o.WatchesAllNamespaces() && o.Node == "" && o.PodFieldSelector == ""
+}
+
+// ShardFromPodName returns the ordinal of a StatefulSet pod, the number after
+// the last dash of its name.
+func ShardFromPodName(pod string) (int, error) {
+	i := strings.LastIndex(pod, "-")
+	if i < 0 {
+		return 0, fmt.Errorf("pod name %q has no StatefulSet ordinal", pod)
+	}
+	ordinal, err := strconv.ParseUint(pod[i+1:], 10, 31)
+	if err != nil {
+		return 0, fmt.Errorf("pod name %q has no StatefulSet ordinal", pod)
+	}
+	return int(ordinal), nil
+}
@@ -73,3 +73,47 @@ func TestOptionsParse(t *testing.T) {
 		}
 	}
 }
+
+func TestShardFromPodName(t *testing.T) {
+	tests := []struct {
+		Pod         string
+		Wanted      int
+		WantedError bool
+	}{
+		{Pod: "kube-state-metrics-0", Wanted: 0},
+		{Pod: "kube-state-metrics-12", Wanted: 12},
+		{Pod: "kube-state-metrics-5d8f9c7b6-x2x4z", WantedError: true},
+		{Pod: "kube-state-metrics", WantedError: true},
+	}
+
+	for _, test := range tests {
+		shard, err := ShardFromPodName(test.Pod)
+		if test.WantedError != (err != nil) {
+			t.Errorf("Test error for pod %s. Want error %t, got %v", test.Pod, test.WantedError, err)
+			continue
+		}
+		if shard != test.Wanted {
+			t.Errorf("Test error for pod %s. Want shard %d, got %d", test.Pod, test.Wanted, shard)
+		}
+	}
+}
+
+func TestWatchesAllPods(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Opts   Options
+		Wanted bool
+	}{
+		{Desc: "defaults", Opts: Options{}, Wanted: true},
+		{Desc: "all namespaces", Opts: Options{Namespaces: DefaultNamespaces}, Wanted: true},
+		{Desc: "some namespaces", Opts: Options{Namespaces: NamespaceList{"default"}}, Wanted: false},
+		{Desc: "node", Opts: Options{Node: "node1"}, Wanted: false},
+		{Desc: "pod field selector", Opts: Options{PodFieldSelector: "status.phase!=Succeeded"}, Wanted: false},
+	}
+
+	for _, test := range tests {
+		if got := test.Opts.WatchesAllPods(); got != test.Wanted {
+			t.Errorf("Test error for %s. Want %t, got %t", test.Desc, test.Wanted, got)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"fmt"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 )
 
 type MetricSet map[string]struct{}
@@ -62,6 +63,178 @@ func (ms *MetricSet) Type() string {
 	return "string"
 }
 
+// AnnotationSet is a set of Kubernetes annotation keys. The special key "*"
+// matches every annotation.
+type AnnotationSet map[string]struct{}
+
+func (as *AnnotationSet) String() string {
+	s := *as
+	ss := s.asSlice()
+	sort.Strings(ss)
+	return strings.Join(ss, ",")
+}
+
+func (as *AnnotationSet) Set(value string) error {
+	s := *as
+	annotations := strings.Split(value, ",")
+	for _, annotation := range annotations {
+		annotation = strings.TrimSpace(annotation)
+		if len(annotation) != 0 {
+			s[annotation] = struct{}{}
+		}
+	}
+	return nil
+}
+
+func (as AnnotationSet) asSlice() []string {
+	annotations := []string{}
+	for annotation := range as {
+		annotations = append(annotations, annotation)
+	}
+	return annotations
+}
+
+// Has returns whether the given annotation key is part of the set.
+func (as AnnotationSet) Has(key string) bool {
+	if _, ok := as["*"]; ok {
+		return true
+	}
+	_, ok := as[key]
+	return ok
+}
+
+func (as *AnnotationSet) Type() string {
+	return "string"
+}
+
+// MetricRenames maps old metric names to the names the metrics were renamed
+// to.
+type MetricRenames map[string]string
+
+func (mr *MetricRenames) String() string {
+	s := *mr
+	ss := []string{}
+	for old, renamed := range s {
+		ss = append(ss, old+"="+renamed)
+	}
+	sort.Strings(ss)
+	return strings.Join(ss, ",")
+}
+
+func (mr *MetricRenames) Set(value string) error {
+	s := *mr
+	renames := strings.Split(value, ",")
+	for _, rename := range renames {
+		rename = strings.TrimSpace(rename)
+		if len(rename) == 0 {
+			continue
+		}
+		parts := strings.Split(rename, "=")
+		if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return fmt.Errorf("invalid metric rename %q, expected old=new", rename)
+		}
+		if parts[0] == parts[1] {
+			return fmt.Errorf("invalid metric rename %q, the names are equal", rename)
+		}
+		s[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (mr *MetricRenames) Type() string {
+	return "string"
+}
+
+// LabelMap holds label names and their values.
+type LabelMap map[string]string
+
+func (lm *LabelMap) String() string {
+	s := *lm
+	ss := []string{}
+	for name, value := range s {
+		ss = append(ss, name+"="+value)
+	}
+	sort.Strings(ss)
+	return strings.Join(ss, ",")
+}
+
+func (lm *LabelMap) Set(value string) error {
+	s := *lm
+	labels := strings.Split(value, ",")
+	for _, label := range labels {
+		label = strings.TrimSpace(label)
+		if len(label) == 0 {
+			continue
+		}
+		parts := strings.SplitN(label, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid label %q, expected name=value", label)
+		}
+		s[parts[0]] = parts[1]
+	}
+	return nil
+}
+
+func (lm *LabelMap) Type() string {
+	return "string"
+}
+
+// WorkloadReference refers to a deployment, daemonset or statefulset by its
+// namespace and name.
+type WorkloadReference struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func (r WorkloadReference) String() string {
+	return r.Kind + "/" + r.Namespace + "/" + r.Name
+}
+
+// AddonWorkloads maps the names of cluster addons to the workloads running
+// them.
+type AddonWorkloads map[string]WorkloadReference
+
+func (aw *AddonWorkloads) String() string {
+	s := *aw
+	ss := []string{}
+	for addon, ref := range s {
+		ss = append(ss, addon+"="+ref.String())
+	}
+	sort.Strings(ss)
+	return strings.Join(ss, ",")
+}
+
+func (aw *AddonWorkloads) Set(value string) error {
+	s := *aw
+	addons := strings.Split(value, ",")
+	for _, addon := range addons {
+		addon = strings.TrimSpace(addon)
+		if len(addon) == 0 {
+			continue
+		}
+		parts := strings.Split(addon, "=")
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid addon %q, expected addon=kind/namespace/name", addon)
+		}
+		ref := strings.Split(parts[1], "/")
+		if len(ref) != 3 || ref[1] == "" || ref[2] == "" {
+			return fmt.Errorf("invalid addon %q, expected addon=kind/namespace/name", addon)
+		}
+		switch ref[0] {
+		case "deployment", "daemonset", "statefulset":
+		default:
+			return fmt.Errorf("invalid addon %q, kind must be deployment, daemonset or statefulset", addon)
+		}
+		s[parts[0]] = WorkloadReference{Kind: ref[0], Namespace: ref[1], Name: ref[2]}
+	}
+	return nil
+}
+
+func (aw *AddonWorkloads) Type() string {
+	return "string"
+}
+
 type CollectorSet map[string]struct{}
 
 func (c *CollectorSet) String() string {
@@ -77,7 +250,7 @@ func (c *CollectorSet) Set(value string) error {
 	for _, col := range cols {
 		col = strings.TrimSpace(col)
 		if len(col) != 0 {
-			_, ok := DefaultCollectors[col]
+			_, ok := AvailableCollectors[col]
 			if !ok {
 				return fmt.Errorf("collector \"%s\" does not exist", col)
 			}
@@ -103,6 +276,36 @@ func (c *CollectorSet) Type() string {
 	return "string"
 }
 
+// ConditionEncoding selects how the status of Kubernetes conditions is
+// exposed.
+type ConditionEncoding string
+
+const (
+	// ConditionEncodingStateSet exposes a series per status, true, false and
+	// unknown, of which the one of the current status is 1.
+	ConditionEncodingStateSet ConditionEncoding = "stateset"
+	// ConditionEncodingBinary only exposes the series of the true status,
+	// which is 1 if the condition is true and 0 otherwise.
+	ConditionEncodingBinary ConditionEncoding = "binary"
+)
+
+func (e *ConditionEncoding) String() string {
+	return string(*e)
+}
+
+func (e *ConditionEncoding) Set(value string) error {
+	switch v := ConditionEncoding(strings.TrimSpace(value)); v {
+	case ConditionEncodingStateSet, ConditionEncodingBinary:
+		*e = v
+		return nil
+	}
+	return fmt.Errorf("invalid condition encoding %q, must be %s or %s", value, ConditionEncodingStateSet, ConditionEncodingBinary)
+}
+
+func (e *ConditionEncoding) Type() string {
+	return "string"
+}
+
 type NamespaceList []string
 
 func (n *NamespaceList) String() string {
@@ -127,3 +330,29 @@ func (n *NamespaceList) Set(value string) error {
 func (n *NamespaceList) Type() string {
 	return "string"
 }
+
+// LabelSelector wraps a labels.Selector so it can be set from a flag. A nil
+// Selector means no selector was configured.
+type LabelSelector struct {
+	labels.Selector
+}
+
+func (ls *LabelSelector) String() string {
+	if ls.Selector == nil {
+		return ""
+	}
+	return ls.Selector.String()
+}
+
+func (ls *LabelSelector) Set(value string) error {
+	selector, err := labels.Parse(value)
+	if err != nil {
+		return err
+	}
+	ls.Selector = selector
+	return nil
+}
+
+func (ls *LabelSelector) Type() string {
+	return "string"
+}
@@ -45,6 +45,15 @@ func TestCollectorSetSet(t *testing.T) {
 			}),
 			WantedError: false,
 		},
+		{
+			Desc:  "opt-in collectors",
+			Value: "events,ingresses",
+			Wanted: CollectorSet(map[string]struct{}{
+				"events":    {},
+				"ingresses": {},
+			}),
+			WantedError: false,
+		},
 		{
 			Desc:        "none exist collectors",
 			Value:       "none-exists",
@@ -61,3 +70,193 @@ func TestCollectorSetSet(t *testing.T) {
 		}
 	}
 }
+
+func TestAnnotationSetHas(t *testing.T) {
+	tests := []struct {
+		Desc   string
+		Value  string
+		Key    string
+		Wanted bool
+	}{
+		{
+			Desc:   "empty set",
+			Value:  "",
+			Key:    "team",
+			Wanted: false,
+		},
+		{
+			Desc:   "whitelisted annotation",
+			Value:  "team, owner",
+			Key:    "owner",
+			Wanted: true,
+		},
+		{
+			Desc:   "not whitelisted annotation",
+			Value:  "team",
+			Key:    "owner",
+			Wanted: false,
+		},
+		{
+			Desc:   "wildcard",
+			Value:  "*",
+			Key:    "owner",
+			Wanted: true,
+		},
+	}
+
+	for _, test := range tests {
+		as := &AnnotationSet{}
+		if err := as.Set(test.Value); err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %v", test.Desc, err)
+		}
+		if got := as.Has(test.Key); got != test.Wanted {
+			t.Errorf("Test error for Desc: %s. Want: %v. Got: %v", test.Desc, test.Wanted, got)
+		}
+	}
+}
+
+func TestMetricRenamesSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      MetricRenames
+		WantedError bool
+	}{
+		{
+			Desc:   "empty renames",
+			Value:  "",
+			Wanted: MetricRenames{},
+		},
+		{
+			Desc:  "normal renames",
+			Value: "kube_a=kube_a_info, kube_b=kube_b_total",
+			Wanted: MetricRenames{
+				"kube_a": "kube_a_info",
+				"kube_b": "kube_b_total",
+			},
+		},
+		{
+			Desc:        "missing new name",
+			Value:       "kube_a=",
+			Wanted:      MetricRenames{},
+			WantedError: true,
+		},
+		{
+			Desc:        "equal names",
+			Value:       "kube_a=kube_a",
+			Wanted:      MetricRenames{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		mr := &MetricRenames{}
+		gotError := mr.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*mr, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *mr, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestLabelMapSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      LabelMap
+		WantedError bool
+	}{
+		{
+			Desc:   "empty labels",
+			Value:  "",
+			Wanted: LabelMap{},
+		},
+		{
+			Desc:  "normal labels",
+			Value: "cluster=prod, region=eu=west",
+			Wanted: LabelMap{
+				"cluster": "prod",
+				"region":  "eu=west",
+			},
+		},
+		{
+			Desc:        "missing value",
+			Value:       "cluster",
+			Wanted:      LabelMap{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		lm := &LabelMap{}
+		gotError := lm.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*lm, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *lm, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestAddonWorkloadsSet(t *testing.T) {
+	tests := []struct {
+		Desc        string
+		Value       string
+		Wanted      AddonWorkloads
+		WantedError bool
+	}{
+		{
+			Desc:   "empty addons",
+			Value:  "",
+			Wanted: AddonWorkloads{},
+		},
+		{
+			Desc:  "normal addons",
+			Value: "coredns=deployment/kube-system/coredns, kube-proxy=daemonset/kube-system/kube-proxy",
+			Wanted: AddonWorkloads{
+				"coredns":    {Kind: "deployment", Namespace: "kube-system", Name: "coredns"},
+				"kube-proxy": {Kind: "daemonset", Namespace: "kube-system", Name: "kube-proxy"},
+			},
+		},
+		{
+			Desc:        "missing namespace",
+			Value:       "coredns=deployment/coredns",
+			Wanted:      AddonWorkloads{},
+			WantedError: true,
+		},
+		{
+			Desc:        "unknown kind",
+			Value:       "coredns=pod/kube-system/coredns",
+			Wanted:      AddonWorkloads{},
+			WantedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		aw := &AddonWorkloads{}
+		gotError := aw.Set(test.Value)
+		if !(((gotError == nil && !test.WantedError) || (gotError != nil && test.WantedError)) && reflect.DeepEqual(*aw, test.Wanted)) {
+			t.Errorf("Test error for Desc: %s. Want: %+v. Got: %+v. Wanted Error: %v, Got Error: %v", test.Desc, test.Wanted, *aw, test.WantedError, gotError)
+		}
+	}
+}
+
+func TestConditionEncodingSet(t *testing.T) {
+	tests := []struct {
+		Value       string
+		Wanted      ConditionEncoding
+		WantedError bool
+	}{
+		{Value: "stateset", Wanted: ConditionEncodingStateSet},
+		{Value: " binary ", Wanted: ConditionEncodingBinary},
+		{Value: "boolean", Wanted: ConditionEncodingStateSet, WantedError: true},
+	}
+
+	for _, test := range tests {
+		e := ConditionEncodingStateSet
+		err := e.Set(test.Value)
+		if (err != nil) != test.WantedError {
+			t.Errorf("Test error for value %q. Unexpected error: %v", test.Value, err)
+		}
+		if e != test.Wanted {
+			t.Errorf("Test error for value %q. Want: %s. Got: %s", test.Value, test.Wanted, e)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package testutils provides helpers to test Prometheus collectors by
+// comparing their output to an expected output in the Prometheus text
+// exposition format. It is used to test the collectors of kube-state-metrics
+// and can be used to test custom collectors the same way.
+//
+// Collectors reading objects from a store can be fed with fixed objects by
+// implementing the store with a function, like the Lister types of the
+// collectors package do:
+//
+//	c := &myCollector{store: myLister(func() ([]v1.Pod, error) {
+//		return []v1.Pod{pod1, pod2}, nil
+//	})}
+//	if err := testutils.GatherAndCompare(c, want, nil); err != nil {
+//		t.Error(err)
+//	}
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Option configures the comparison of GatherAndCompareWithOptions and
+// GatherAndCompareGolden.
+type Option func(*compareOptions)
+
+type compareOptions struct {
+	metricNames  []string
+	ignoreLabels map[string]bool
+	update       bool
+}
+
+// MetricNames only compares the given metrics. All are compared if none are
+// given.
+func MetricNames(names ...string) Option {
+	return func(o *compareOptions) {
+		o.metricNames = append(o.metricNames, names...)
+	}
+}
+
+// IgnoreLabels drops the given labels from the gathered as well as the
+// expected metrics before comparing them. This is useful for labels whose
+// values differ between runs, like uids or generated names.
+func IgnoreLabels(names ...string) Option {
+	return func(o *compareOptions) {
+		if o.ignoreLabels == nil {
+			o.ignoreLabels = map[string]bool{}
+		}
+		for _, name := range names {
+			o.ignoreLabels[name] = true
+		}
+	}
+}
+
+// UpdateGolden makes GatherAndCompareGolden write the gathered metrics to the
+// golden file instead of comparing them, if update is true. It is usually set
+// from a flag of the test, e.g. -update.
+func UpdateGolden(update bool) Option {
+	return func(o *compareOptions) {
+		o.update = update
+	}
+}
+
+// GatherAndCompare retrieves all metrics exposed by a collector and compares it
+// to an expected output in the Prometheus text exposition format.
+// metricNames allows only comparing the given metrics. All are compared if it's nil.
+func GatherAndCompare(c prometheus.Collector, expected string, metricNames []string) error {
+	return GatherAndCompareWithOptions(c, expected, MetricNames(metricNames...))
+}
+
+// GatherAndCompareWithOptions retrieves all metrics exposed by a collector and
+// compares them to an expected output in the Prometheus text exposition
+// format, normalized according to the given options.
+func GatherAndCompareWithOptions(c prometheus.Collector, expected string, opts ...Option) error {
+	o := newCompareOptions(opts)
+	metrics, err := gather(c, o)
+	if err != nil {
+		return err
+	}
+	return compare(metrics, removeUnusedWhitespace(expected), o)
+}
+
+// GatherAndCompareGolden retrieves all metrics exposed by a collector and
+// compares them to the expected output in the Prometheus text exposition format
+// stored in the golden file at path. With UpdateGolden(true) the golden file
+// is written instead.
+func GatherAndCompareGolden(c prometheus.Collector, path string, opts ...Option) error {
+	o := newCompareOptions(opts)
+	metrics, err := gather(c, o)
+	if err != nil {
+		return err
+	}
+
+	if o.update {
+		text, err := encodeText(metrics)
+		if err != nil {
+			return err
+		}
+		if err := ioutil.WriteFile(path, []byte(text), 0644); err != nil {
+			return fmt.Errorf("writing golden file failed: %s", err)
+		}
+		return nil
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("reading golden file failed: %s", err)
+	}
+	return compare(metrics, removeUnusedWhitespace(string(expected)), o)
+}
+
+func newCompareOptions(opts []Option) compareOptions {
+	var o compareOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// gather registers the collector with a pedantic registry and returns its
+// metrics normalized according to o.
+func gather(c prometheus.Collector, o compareOptions) ([]*dto.MetricFamily, error) {
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(c); err != nil {
+		return nil, fmt.Errorf("registering collector failed: %s", err)
+	}
+	metrics, err := reg.Gather()
+	if err != nil {
+		return nil, fmt.Errorf("gathering metrics failed: %s", err)
+	}
+	if o.metricNames != nil {
+		metrics = filterMetrics(metrics, o.metricNames)
+	}
+	if o.ignoreLabels != nil {
+		metrics = normalizeMetricFamilies(dropLabels(metrics, o.ignoreLabels))
+	}
+	return metrics, nil
+}
+
+func compare(metrics []*dto.MetricFamily, expected string, o compareOptions) error {
+	var tp expfmt.TextParser
+	expectedMetrics, err := tp.TextToMetricFamilies(bytes.NewReader([]byte(expected)))
+	if err != nil {
+		return fmt.Errorf("parsing expected metrics failed: %s", err)
+	}
+	if o.ignoreLabels != nil {
+		for _, mf := range expectedMetrics {
+			dropFamilyLabels(mf, o.ignoreLabels)
+		}
+	}
+	for _, mf := range expectedMetrics {
+		dropInfBuckets(mf)
+	}
+	normalizedExpected := normalizeMetricFamilies(expectedMetrics)
+
+	if !reflect.DeepEqual(metrics, normalizedExpected) {
+		// Encode the gathered output to the readbale text format for comparison.
+		got, err := encodeText(metrics)
+		if err != nil {
+			return err
+		}
+		// Encode normalized expected metrics again to generate them in the same ordering
+		// the registry does to spot differences more easily.
+		want, err := encodeText(normalizedExpected)
+		if err != nil {
+			return err
+		}
+
+		return fmt.Errorf(`
+metric output does not match expectation; want:
+
+%s
+
+got:
+
+%s
+`, want, got)
+	}
+	return nil
+}
+
+// dropInfBuckets removes the +Inf bucket of histograms parsed from the text
+// format, as gathered histograms only have it implicitly.
+func dropInfBuckets(mf *dto.MetricFamily) {
+	for _, m := range mf.Metric {
+		h := m.GetHistogram()
+		if h == nil {
+			continue
+		}
+		buckets := h.Bucket[:0]
+		for _, b := range h.Bucket {
+			if !math.IsInf(b.GetUpperBound(), +1) {
+				buckets = append(buckets, b)
+			}
+		}
+		h.Bucket = buckets
+	}
+}
+
+func encodeText(metrics []*dto.MetricFamily) (string, error) {
+	var buf bytes.Buffer
+	enc := expfmt.NewEncoder(&buf, expfmt.FmtText)
+	for _, mf := range metrics {
+		if err := enc.Encode(mf); err != nil {
+			return "", fmt.Errorf("encoding result failed: %s", err)
+		}
+	}
+	return buf.String(), nil
+}
+
+// dropLabels returns the metric families by name with the given labels
+// removed from all metrics.
+func dropLabels(metrics []*dto.MetricFamily, labels map[string]bool) map[string]*dto.MetricFamily {
+	byName := make(map[string]*dto.MetricFamily, len(metrics))
+	for _, mf := range metrics {
+		dropFamilyLabels(mf, labels)
+		byName[mf.GetName()] = mf
+	}
+	return byName
+}
+
+func dropFamilyLabels(mf *dto.MetricFamily, labels map[string]bool) {
+	for _, m := range mf.Metric {
+		kept := m.Label[:0]
+		for _, lp := range m.Label {
+			if !labels[lp.GetName()] {
+				kept = append(kept, lp)
+			}
+		}
+		m.Label = kept
+	}
+}
+
+func filterMetrics(metrics []*dto.MetricFamily, names []string) []*dto.MetricFamily {
+	filtered := []*dto.MetricFamily{}
+	for _, m := range metrics {
+		drop := true
+		for _, name := range names {
+			if m.GetName() == name {
+				drop = false
+				break
+			}
+		}
+		if !drop {
+			filtered = append(filtered, m)
+		}
+	}
+	return filtered
+}
+
+func removeUnusedWhitespace(s string) string {
+	var (
+		trimmedLine  string
+		trimmedLines []string
+		lines        = strings.Split(s, "\n")
+	)
+
+	for _, l := range lines {
+		trimmedLine = strings.TrimSpace(l)
+
+		if len(trimmedLine) > 0 {
+			trimmedLines = append(trimmedLines, trimmedLine)
+		}
+	}
+
+	// The Prometheus metrics representation parser expects an empty line at the
+	// end otherwise fails with an unexpected EOF error.
+	return strings.Join(trimmedLines, "\n") + "\n"
+}
+
+// The below sorting code is copied form the Prometheus client library modulo the added
+// label pair sorting.
+// https://github.com/prometheus/client_golang/blob/ea6e1db4cb8127eeb0b6954f7320363e5451820f/prometheus/registry.go#L642-L684
+
+// metricSorter is a sortable slice of *dto.Metric.
+type metricSorter []*dto.Metric
+
+func (s metricSorter) Len() int {
+	return len(s)
+}
+
+func (s metricSorter) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
+
+func (s metricSorter) Less(i, j int) bool {
+	sort.Sort(prometheus.LabelPairSorter(s[i].Label))
+	sort.Sort(prometheus.LabelPairSorter(s[j].Label))
+
+	if len(s[i].Label) != len(s[j].Label) {
+		return len(s[i].Label) < len(s[j].Label)
+	}
+
+	for n, lp := range s[i].Label {
+		vi := lp.GetValue()
+		vj := s[j].Label[n].GetValue()
+		if vi != vj {
+			return vi < vj
+		}
+	}
+
+	if s[i].TimestampMs == nil {
+		return false
+	}
+	if s[j].TimestampMs == nil {
+		return true
+	}
+	return s[i].GetTimestampMs() < s[j].GetTimestampMs()
+}
+
+// normalizeMetricFamilies returns a MetricFamily slice with empty
+// MetricFamilies pruned and the remaining MetricFamilies sorted by name within
+// the slice, with the contained Metrics sorted within each MetricFamily.
+func normalizeMetricFamilies(metricFamiliesByName map[string]*dto.MetricFamily) []*dto.MetricFamily {
+	for _, mf := range metricFamiliesByName {
+		sort.Sort(metricSorter(mf.Metric))
+	}
+	names := make([]string, 0, len(metricFamiliesByName))
+	for name, mf := range metricFamiliesByName {
+		if len(mf.Metric) > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	result := make([]*dto.MetricFamily, 0, len(names))
+	for _, name := range names {
+		result = append(result, metricFamiliesByName[name])
+	}
+	return result
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2018 The Kubernetes Authors All rights reserved.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package testutils
+
+import (
+	"flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var update = flag.Bool("update", false, "update the golden files")
+
+func newPodInfoCollector() prometheus.Collector {
+	info := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "kube_pod_info",
+			Help: "Information about pod.",
+		},
+		[]string{"namespace", "pod", "uid"},
+	)
+	info.WithLabelValues("ns2", "pod2", "b2c1d2e6").Set(1)
+	info.WithLabelValues("ns1", "pod1", "0a6e4b90").Set(1)
+	return info
+}
+
+func TestGatherAndCompareWithOptions(t *testing.T) {
+	tests := []struct {
+		Desc     string
+		Expected string
+		Options  []Option
+		WantErr  bool
+	}{
+		{
+			Desc: "all labels",
+			Expected: `
+				# HELP kube_pod_info Information about pod.
+				# TYPE kube_pod_info gauge
+				kube_pod_info{namespace="ns1",pod="pod1",uid="0a6e4b90"} 1
+				kube_pod_info{namespace="ns2",pod="pod2",uid="b2c1d2e6"} 1
+			`,
+		},
+		{
+			Desc: "ignored labels",
+			Expected: `
+				# HELP kube_pod_info Information about pod.
+				# TYPE kube_pod_info gauge
+				kube_pod_info{namespace="ns2",pod="pod2",uid="other"} 1
+				kube_pod_info{namespace="ns1",pod="pod1"} 1
+			`,
+			Options: []Option{IgnoreLabels("uid")},
+		},
+		{
+			Desc: "mismatch",
+			Expected: `
+				# HELP kube_pod_info Information about pod.
+				# TYPE kube_pod_info gauge
+				kube_pod_info{namespace="ns1",pod="pod1",uid="0a6e4b90"} 1
+			`,
+			WantErr: true,
+		},
+		{
+			Desc:     "other metric names",
+			Expected: ``,
+			Options:  []Option{MetricNames("kube_pod_created")},
+		},
+	}
+
+	for _, test := range tests {
+		err := GatherAndCompareWithOptions(newPodInfoCollector(), test.Expected, test.Options...)
+		if test.WantErr && err == nil {
+			t.Errorf("Test error for Desc: %s. Want an error, got none.", test.Desc)
+		}
+		if !test.WantErr && err != nil {
+			t.Errorf("Test error for Desc: %s. Unexpected error: %s", test.Desc, err)
+		}
+	}
+}
+
+func TestGatherAndCompareGolden(t *testing.T) {
+	golden := filepath.Join("testdata", "pods.golden")
+	if err := GatherAndCompareGolden(newPodInfoCollector(), golden, IgnoreLabels("uid"), UpdateGolden(*update)); err != nil {
+		t.Error(err)
+	}
+
+	dir, err := ioutil.TempDir("", "testutils")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	written := filepath.Join(dir, "pods.golden")
+	if err := GatherAndCompareGolden(newPodInfoCollector(), written, UpdateGolden(true)); err != nil {
+		t.Fatalf("writing golden file failed: %s", err)
+	}
+	if err := GatherAndCompareGolden(newPodInfoCollector(), written); err != nil {
+		t.Errorf("comparing with written golden file failed: %s", err)
+	}
+	if err := GatherAndCompareGolden(newPodInfoCollector(), written, MetricNames("kube_pod_created")); err == nil {
+		t.Error("comparing other metrics with the written golden file succeeded")
+	}
+}